@@ -0,0 +1,51 @@
+package v1beta1
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+func (la *LogAssert) validate() error {
+	if la.Pod == "" && la.Selector == "" {
+		return errors.New("log assert requires a pod or selector")
+	}
+	if len(la.Match) == 0 && len(la.NotMatch) == 0 {
+		return errors.New("log assert requires a match or notMatch expression")
+	}
+	return nil
+}
+
+// Command provides the command which fetches the logs, its expected output is the log assert.
+func (la *LogAssert) Command() (*Command, error) {
+	if err := la.validate(); err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString("kubectl logs")
+	if len(la.Pod) > 0 {
+		fmt.Fprintf(&b, " %s", la.Pod)
+	}
+	if len(la.Selector) > 0 {
+		// kubectl only returns the last 10 lines of each pod for selectors by default
+		fmt.Fprintf(&b, " -l %s --tail=-1", la.Selector)
+	}
+	ns := la.Namespace
+	if len(la.Namespace) == 0 {
+		ns = "$NAMESPACE"
+	}
+	fmt.Fprintf(&b, " -n %s", ns)
+	if len(la.Container) > 0 {
+		fmt.Fprintf(&b, " -c %s", la.Container)
+	} else {
+		b.WriteString(" --all-containers")
+	}
+	return &Command{
+		Command: b.String(),
+		Stdout: &CommandOutput{
+			Match:    la.Match,
+			NotMatch: la.NotMatch,
+		},
+	}, nil
+}
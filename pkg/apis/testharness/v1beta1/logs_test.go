@@ -0,0 +1,51 @@
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogAssert_Command(t *testing.T) {
+	tests := []struct {
+		name    string
+		logs    LogAssert
+		command string
+		wantErr bool
+	}{
+		{
+			name:    "pod",
+			logs:    LogAssert{Pod: "foo", Match: []string{"started"}},
+			command: "kubectl logs foo -n $NAMESPACE --all-containers",
+		},
+		{
+			name:    "selector and container",
+			logs:    LogAssert{Selector: "app=foo", Namespace: "bar", Container: "manager", NotMatch: []string{"panic"}},
+			command: "kubectl logs -l app=foo --tail=-1 -n bar -c manager",
+		},
+		{
+			name:    "no pod or selector",
+			logs:    LogAssert{Match: []string{"started"}},
+			wantErr: true,
+		},
+		{
+			name:    "no expressions",
+			logs:    LogAssert{Pod: "foo"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := tt.logs.Command()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.command, cmd.Command)
+			assert.Equal(t, tt.logs.Match, cmd.Stdout.Match)
+			assert.Equal(t, tt.logs.NotMatch, cmd.Stdout.NotMatch)
+		})
+	}
+}
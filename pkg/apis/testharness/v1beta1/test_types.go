@@ -2,6 +2,7 @@ package v1beta1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -16,6 +17,10 @@ type TestSuite struct {
 
 	// Path to CRDs to install before running tests.
 	CRDDir string `json:"crdDir"`
+	// CRDs to install before running tests in addition to the CRDs of CRDDir: paths of files or directories, glob
+	// patterns, HTTP(S) URLs of manifests and OCI artifacts (oci://registry/repository:tag) whose layers are
+	// manifests or archives of manifests. The tests start once the CRDs are established.
+	CRDs []string `json:"crds,omitempty"`
 	// Paths to directories containing manifests to install before running tests.
 	ManifestDirs []string `json:"manifestDirs"`
 	// Directories containing test cases to run.
@@ -27,8 +32,22 @@ type TestSuite struct {
 	ControlPlaneArgs []string
 	// Whether or not to start a local kind cluster for the tests.
 	StartKIND bool `json:"startKIND"`
+	// The provider of the local cluster started with StartKIND: kind (default), k3d or minikube. KINDContext is the
+	// name of the cluster and the images of KINDContainers and KINDImages are loaded into it, the other KIND settings
+	// only apply to kind. The k3d and minikube CLIs must be installed to use them.
+	ClusterProvider string `json:"clusterProvider,omitempty"`
+	// Commands which create the cluster of the tests before they start and delete it after they finished,
+	// e.g. a managed cluster. It can not be used with StartKIND or StartControlPlane.
+	Provision *Provision `json:"provision,omitempty"`
+	// Kubernetes versions the test suite is run against one after the other, e.g. 1.18.2, the results are reported
+	// per version. The kube-apiserver and etcd binaries of StartControlPlane are downloaded for each version, the KIND
+	// nodes use the kindest/node image of the version and the commands of Provision get it as $KUTTL_KUBERNETES_VERSION.
+	KubernetesVersions []string `json:"kubernetesVersions,omitempty"`
 	// Path to the KIND configuration file to use.
 	KINDConfig string `json:"kindConfig"`
+	// The configuration of the KIND cluster, it is applied over the KINDConfig file. It allows to start clusters
+	// which are not the default without a KIND configuration file.
+	KINDCluster *KINDCluster `json:"kindCluster,omitempty"`
 	// KIND context to use.
 	KINDContext string `json:"kindContext"`
 	// If set, each node defined in the kind configuration will have a docker named volume mounted into it to persist
@@ -36,22 +55,55 @@ type TestSuite struct {
 	KINDNodeCache bool `json:"kindNodeCache"`
 	// Containers to load to each KIND node prior to running the tests.
 	KINDContainers []string `json:"kindContainers"`
+	// Container images to build before the KIND cluster is started, they are loaded into each KIND node like
+	// KINDContainers. Images which are already loaded with the same digest are not loaded again.
+	KINDImages []ContainerImage `json:"kindImages,omitempty"`
 	// If set, do not delete the resources after running the tests (implies SkipClusterDelete).
 	SkipDelete bool `json:"skipDelete"`
 	// If set, do not delete the mocked control plane or kind cluster.
 	SkipClusterDelete bool `json:"skipClusterDelete"`
+	// If set, the resources and namespace of failed tests are not deleted, unlike SkipDelete the resources of
+	// passed tests are. The cluster is not deleted if any test failed.
+	KeepOnFailure bool `json:"keepOnFailure,omitempty"`
+	// The time in seconds a failed test pauses before its resources are cleaned up or kept, e.g. to inspect its
+	// background processes. 0 means no pause. On an interactive terminal the pause ends early when enter is pressed.
+	PauseOnFailure int `json:"pauseOnFailure,omitempty"`
+	// If set, a failed test starts an interactive shell with KUBECONFIG and NAMESPACE set before its resources
+	// are cleaned up or kept, the test continues when the shell exits. Requires an interactive terminal.
+	DebugShell bool `json:"debugShell,omitempty"`
 	// Override the default timeout of 30 seconds (in seconds).
 	// +kubebuilder:validation:Format:=int64
 	Timeout int `json:"timeout"`
 	// The maximum number of tests to run at once (default: 8).
 	// +kubebuilder:validation:Format:=int64
 	Parallel int `json:"parallel"`
+	// The maximum number of requests per second to the Kubernetes API server (default: 20). The limit is
+	// shared by all clients of the harness, the requests of all tests running at once count towards it.
+	QPS int `json:"qps,omitempty"`
+	// The maximum number of requests to the Kubernetes API server sent at once above QPS (default: 30).
+	Burst int `json:"burst,omitempty"`
+	// Names of test cases which must not run in parallel with other test cases.
+	// They are run one after another before any of the parallel test cases start.
+	Serial []string `json:"serial,omitempty"`
 	// The directory to output artifacts to (current working directory if not specified).
 	ArtifactsDir string `json:"artifactsDir"`
 	// Commands to run prior to running the tests.
 	Commands []Command `json:"commands"`
+	// Path to a directory of test steps which run once before all test cases, e.g. to install an operator or to
+	// create cluster-scoped RBAC. Its objects are not deleted after the steps. If the setup fails, no test case is run.
+	Setup string `json:"setup,omitempty"`
+	// Path to a directory of test steps which run once after all test cases, also if the setup failed.
+	Teardown string `json:"teardown,omitempty"`
+	// An upgrade test of an operator which runs after the setup, the test cases run against the upgraded version.
+	// If any of its phases fails, no test case is run.
+	Upgrade *Upgrade `json:"upgrade,omitempty"`
+	// Environment variables to inject into all commands run by the test suite and its test steps.
+	Env map[string]string `json:"env,omitempty"`
+	// Secrets and config maps in the default namespace whose data is injected as environment variables
+	// into all commands run by the test suite and its test steps.
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
 
-	// ReportFormat determines test report format (JSON|XML|nil) nil == no report
+	// ReportFormat determines test report format (JSON|XML|TAP|HTML|Allure|nil) nil == no report
 	// maps to report.Type, however we don't want generated.deepcopy to have reference to it.
 	ReportFormat string
 
@@ -62,8 +114,155 @@ type TestSuite struct {
 	// Any other value is the name of the namespace to use.  This namespace will be created if it does not exist and will
 	// be removed it was created (unless --skipDelete is used).
 	Namespace string
+	// NamespaceTemplate is the go template of the names of the namespaces generated for the tests. It has access to
+	// the test case name converted to a valid namespace name as .Test and to a random name as .Random,
+	// e.g. "e2e-{{ .Test }}-{{ .Random }}". The names are truncated to 63 characters, the default is
+	// "kudo-test-{{ .Random }}".
+	NamespaceTemplate string `json:"namespaceTemplate,omitempty"`
+	// Labels added to the namespaces kuttl creates for the tests, e.g. pod-security.kubernetes.io/enforce.
+	NamespaceLabels map[string]string `json:"namespaceLabels,omitempty"`
+	// Annotations added to the namespaces kuttl creates for the tests.
+	NamespaceAnnotations map[string]string `json:"namespaceAnnotations,omitempty"`
+	// NamespacePool is the number of namespaces which are created before the tests and reused by them instead
+	// of creating and deleting a namespace per test. The objects in a namespace are deleted before it is reused.
+	// It is not used with a Namespace or SkipDelete, 0 means no pool.
+	NamespacePool int `json:"namespacePool,omitempty"`
 	// Suppress is used to suppress logs
 	Suppress []string
+	// LogFormat is the format of the test logs, "text" (the default) or "json". JSON logs are written to
+	// stdout as they happen with the test suite, test and step of each line.
+	LogFormat string `json:"logFormat,omitempty"`
+	// If set, the text logs of the tests are written to stdout as they happen, prefixed with the test and step,
+	// instead of when each test finished. JSON logs are always written as they happen.
+	StreamLogs bool `json:"streamLogs,omitempty"`
+	// If set, the diffs of failed asserts are not colorized, e.g. for CI logs. The diffs are never colorized
+	// in JSON logs.
+	NoColor bool `json:"noColor,omitempty"`
+	// If set, the test step files and the files referenced by TestStep apply, assert and error paths are rendered
+	// as go templates right before the test step runs. The templates have access to .Namespace, .Values, .Env
+	// and the variables captured by the previous test steps as .Vars.
+	Template bool `json:"template"`
+	// Values available to the templates of test step files as .Values and in the env expansion
+	// of the TestStep apply, assert and error paths.
+	Values map[string]string `json:"values,omitempty"`
+	// Fields which are removed from the expected and actual objects before asserts are compared and diffed,
+	// e.g. `metadata.resourceVersion` or `status.conditions[*].lastTransitionTime`.
+	IgnoredFields []string `json:"ignoredFields,omitempty"`
+	// The backoff of the retries of the asserts of the test steps (default: a constant interval of 1 second).
+	Backoff *Backoff `json:"backoff,omitempty"`
+	// Kinds of objects which are read from shared informers by the asserts of all tests instead of being
+	// requested from the API server on every retry, e.g. Pod or Deployment. The informers watch the objects
+	// of the kinds in all namespaces, they reduce the load on the API server when many tests run at once.
+	CachedKinds []string `json:"cachedKinds,omitempty"`
+	// Matrix of values to run every test case with. Each test case is run once for every combination
+	// of the matrix values, the values of a combination override Values. The combination is appended
+	// to the test case name, e.g. "my-test[tag=1.0,version=2]", and reported as a separate test case.
+	Matrix map[string][]string `json:"matrix,omitempty"`
+	// If set, the golden files of the TestAsserts are rewritten from the objects in the cluster instead
+	// of being compared to them.
+	UpdateGolden bool `json:"updateGolden,omitempty"`
+	// If set, the objects applied by each test step are written as an assert file (e.g. 01-assert.yaml)
+	// into the test case directory after the step succeeded. Existing assert files are not overwritten.
+	// The fields assigned by the cluster which differ between runs, e.g. pod IPs, node names, container IDs
+	// and start times, are not recorded.
+	Record bool `json:"record,omitempty"`
+	// If set, the objects of the test steps are validated with a server-side dry run before they are applied.
+	// The step fails if an object is rejected or if fields of it are unknown to the schema of its kind.
+	ValidateManifests bool `json:"validateManifests,omitempty"`
+	// If set, a test step fails instead of updating an object which already exists with different content that is
+	// managed by another field manager than kuttl, e.g. a leftover of a previous run or an object of another test.
+	StrictApply bool `json:"strictApply,omitempty"`
+	// Tracking of the objects created by the test cases, they are deleted after a test case like its namespace,
+	// e.g. cluster-scoped objects and objects created by commands.
+	Tracking *Tracking `json:"tracking,omitempty"`
+	// If set, the sops-encrypted manifests of the test suite are decrypted when they are loaded, so fixtures
+	// containing credentials can be committed encrypted. The sops binary must be in the PATH, it finds the keys
+	// in the environment, e.g. $SOPS_AGE_KEY_FILE.
+	Sops bool `json:"sops,omitempty"`
+	// If set, only the test cases with any of the tags of their kuttl-case.yaml file are run.
+	Tags []string `json:"tags,omitempty"`
+	// The test cases with any of these tags are skipped, even if they have any of the Tags.
+	SkipTags []string `json:"skipTags,omitempty"`
+	// The number of times a failed test case is rerun from its first step. A test case which passes on a retry
+	// is reported as flaky.
+	Retries int `json:"retries,omitempty"`
+	// A JSON file the recent results of the test cases are recorded in, e.g. to be committed or kept in a CI cache.
+	HistoryFile string `json:"historyFile,omitempty"`
+	// If set, the failures of the test cases which were flaky in the recent runs of the HistoryFile are logged
+	// and reported, but they do not fail the tests.
+	Quarantine bool `json:"quarantine,omitempty"`
+	// If set, the test steps with a lower index are skipped. The cluster is assumed to be in the state
+	// the skipped steps leave it in, e.g. by running the test case in an existing namespace.
+	FromStep *int `json:"fromStep,omitempty"`
+	// If set, the test steps with a higher index are skipped.
+	ToStep *int `json:"toStep,omitempty"`
+	// The time in seconds the whole test suite may take. When it expires, the running test cases are
+	// cancelled and reported as timed out, as are the test cases which did not start yet. 0 means no limit.
+	SuiteTimeout int `json:"suiteTimeout,omitempty"`
+	// The time in seconds each test case may take, in contrast to Timeout which applies to the asserts
+	// of each step. A test case exceeding it is cancelled and reported as timed out. 0 means no limit.
+	TestTimeout int `json:"testTimeout,omitempty"`
+	// A JSON file the failed test cases of the run are saved to, replacing the ones of the previous run.
+	FailedFile string `json:"failedFile,omitempty"`
+	// If set, only the test cases which failed in the previous run, as saved to the FailedFile, are run.
+	RerunFailed bool `json:"rerunFailed,omitempty"`
+	// If set, the progress of the run is saved to .kuttl/progress.json in the working directory and an
+	// interrupted previous run with Resume is resumed: the test cases which passed in it are skipped and
+	// its KIND cluster is re-used. The KIND cluster of an interrupted run is kept for this, the progress
+	// is removed when a run completes.
+	Resume bool `json:"resume,omitempty"`
+	// The number of shards the test cases are split into, e.g. to run a test suite on several CI workers.
+	// A test case belongs to a shard by the hash of its name, only the test cases of ShardIndex are run.
+	// Test cases depending on each other are in the same shard. 0 or 1 means all test cases are run.
+	ShardCount int `json:"shardCount,omitempty"`
+	// The shard of the test cases which are run, from 0 to ShardCount - 1.
+	ShardIndex int `json:"shardIndex,omitempty"`
+	// If set, the test suite is run inside the cluster by a Job for each shard instead of by kuttl itself.
+	// The test directories, CRDDir, ManifestDirs and the files of the TestSteps are packaged into a ConfigMap, the
+	// logs of the Jobs are streamed back and their reports are merged. The Jobs use RemoteServiceAccount. The
+	// packaged paths must be in the working directory and Clusters can not set a kubeconfig.
+	Remote bool `json:"remote,omitempty"`
+	// The image of the Jobs of a remote run, it must contain kubectl-kuttl, sh, tar and base64.
+	// Defaults to kudobuilder/kuttl.
+	RemoteImage string `json:"remoteImage,omitempty"`
+	// The namespace of the Jobs of a remote run, it is created if it does not exist. Defaults to kuttl-runner.
+	RemoteNamespace string `json:"remoteNamespace,omitempty"`
+	// The service account of the Jobs of a remote run in RemoteNamespace. It must exist unless RemoteClusterRole is
+	// set. Defaults to kuttl-runner.
+	RemoteServiceAccount string `json:"remoteServiceAccount,omitempty"`
+	// If set, RemoteServiceAccount is created and bound to this ClusterRole for a remote run, e.g. cluster-admin.
+	// The service account and the binding are deleted after the run, unless they existed before it.
+	RemoteClusterRole string `json:"remoteClusterRole,omitempty"`
+	// Additional clusters of the test suite by name, e.g. to test operators which replicate objects between
+	// clusters. The test steps select the cluster of their objects with TestStep.Cluster or the objects with
+	// the `kuttl.dev/cluster: name` annotation. The namespace of each test case is also created in these clusters.
+	Clusters map[string]Cluster `json:"clusters,omitempty"`
+	// If set, the test cases which have not started yet are skipped after the first failed test case.
+	FailFast bool `json:"failFast,omitempty"`
+	// The number of failed test cases after which the test cases which have not started yet are skipped.
+	// 0 means no limit.
+	MaxFailures int `json:"maxFailures,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TestCase is the metadata of a test case, it is read from the kuttl-case.yaml file of the test case directory.
+type TestCase struct {
+	// The type meta object, should always be a GVK of kuttl.dev/v1beta1/TestCase.
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Tags of the test case to select the test cases to run with, e.g. "smoke" or "slow".
+	Tags []string `json:"tags,omitempty"`
+	// Preconditions of the test case, it is skipped if any of them is not met.
+	Requires *Requirements `json:"requires,omitempty"`
+	// Names of the test cases of the test suite, i.e. their directories, which must pass before the test case starts.
+	// The test case is skipped if any of them fails or is skipped. Test cases which are not run, e.g. because of
+	// their tags, are ignored.
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// Names of the fixtures of the test suite, i.e. their directories in the _fixtures directory, the test case uses.
+	// A fixture is created before the first test case using it starts and deleted after the last one finished.
+	Fixtures []string `json:"fixtures,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -85,14 +284,105 @@ type TestStep struct {
 	Assert []string `json:"assert,omitempty"`
 	Error  []string `json:"error,omitempty"`
 
+	// Generators of copies of templated objects which are applied and asserted like the objects of
+	// Apply and Assert, e.g. to create many custom resources for a scale test.
+	Generate []Generate `json:"generate,omitempty"`
+
+	// If set, the objects applied by the previous test steps of the test case which match the PruneSelector and
+	// are not applied by this test step are deleted after its objects are applied, like `kubectl apply --prune`.
+	Prune bool `json:"prune,omitempty"`
+	// A label selector of the objects to prune, e.g. app=operator, it is required with Prune.
+	PruneSelector string `json:"pruneSelector,omitempty"`
+
 	// Objects to delete at the beginning of the test step.
 	Delete []ObjectReference `json:"delete,omitempty"`
+	// Options used to delete the objects of the Delete list.
+	DeleteOptions *DeleteOptions `json:"deleteOptions,omitempty"`
+	// Objects to delete at the beginning of the test step without waiting for them to be deleted, e.g. to
+	// assert the finalizers of an operator with finalizer asserts while the objects are terminating.
+	Terminate []ObjectReference `json:"terminate,omitempty"`
+
+	// Patches to apply to existing objects prior to applying the test step objects.
+	Patch []Patch `json:"patch,omitempty"`
 
 	// Indicates that this is a unit test - safe to run without a real Kubernetes cluster.
 	UnitTest bool `json:"unitTest"`
 
+	// The number of times a failed test step (commands, apply and assert) is retried before the test fails.
+	Retries int `json:"retries"`
+	// The delay between retries of a failed test step (in seconds).
+	RetryDelay int `json:"retryDelay"`
+
 	// Commands to run prior at the beginning of the test step.
 	Commands []Command `json:"commands"`
+	// Environment variables to inject into the commands of the test step, they override the test suite env.
+	Env map[string]string `json:"env,omitempty"`
+	// Secrets and config maps in the test namespace whose data is injected as environment variables
+	// into the commands of the test step. Variables defined in env take precedence.
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+
+	// Ports of pods or services to forward to local ports while the test step runs. The pods or services
+	// must be created by an earlier test step.
+	PortForward []PortForward `json:"portForward,omitempty"`
+
+	// Commands to run in containers of pods after the commands of the test step.
+	Exec []Exec `json:"exec,omitempty"`
+
+	// Jobs to run to completion in the test namespace after the commands of the test step.
+	Jobs []Job `json:"jobs,omitempty"`
+
+	// Helm releases to install, upgrade or uninstall in order after the jobs of the test step. The releases
+	// installed by the test step are uninstalled when the test case finished.
+	Helm []Helm `json:"helm,omitempty"`
+
+	// Operators to install with the Operator Lifecycle Manager in order after the Helm releases of the test step.
+	// The objects created to install them are deleted when the test case finished.
+	OLM []OLM `json:"olm,omitempty"`
+
+	// Disruptions to cause in order after the operators of the test step are installed, e.g. to test the
+	// resilience of an operator. The network policies and cordons of the test step are removed when the test
+	// case finished.
+	Chaos []Chaos `json:"chaos,omitempty"`
+
+	// Workloads to scale in order after the disruptions of the test step, the test step waits for their rollouts.
+	Scale []Scale `json:"scale,omitempty"`
+
+	// Workloads to restart in order after the workloads of the test step are scaled, like with
+	// `kubectl rollout restart`. The test step waits for their rollouts.
+	Restart []Restart `json:"restart,omitempty"`
+
+	// Conditions to wait for after the commands and jobs of the test step, before its objects are patched and
+	// applied, e.g. the CRDs of the objects are established.
+	WaitFor []WaitFor `json:"waitFor,omitempty"`
+
+	// Objects to delete and commands to run after the test case finished, regardless of the test result.
+	Cleanup *Cleanup `json:"cleanup,omitempty"`
+
+	// The name of the cluster of TestSuite.Clusters the objects and asserts of the test step target, the cluster
+	// of the test suite if empty. An object overrides it with the `kuttl.dev/cluster: name` annotation.
+	Cluster string `json:"cluster,omitempty"`
+
+	// The path to a kubeconfig file, relative to the test case directory, whose user applies, patches and deletes the objects
+	// of the test step, e.g. to test the RBAC rules of an operator. The asserts use the client of the test suite.
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	// The context of the Kubeconfig, or of the kubeconfig of the test suite if Kubeconfig is not set, whose user
+	// applies, patches and deletes the objects of the test step.
+	Context string `json:"context,omitempty"`
+	// The user or service account the objects of the test step are applied, patched and deleted as. The user
+	// of the test suite must be allowed to impersonate it.
+	Impersonate *Impersonate `json:"impersonate,omitempty"`
+
+	// Preconditions of the test step, it is skipped if any of them is not met.
+	Requires *Requirements `json:"requires,omitempty"`
+
+	// Values to capture from objects after the test step succeeded. The captured variables are available
+	// to the commands of the following test steps as environment variables and to their templates as .Vars.
+	Capture []Capture `json:"capture,omitempty"`
+
+	// Objects to snapshot under a name after the test step succeeded. The snapshot asserts of the following
+	// test steps compare the objects to their snapshots, e.g. to assert that restarting an operator does not
+	// change the objects it owns.
+	Snapshot []Snapshot `json:"snapshot,omitempty"`
 
 	// Allowed environment labels
 	// Disallowed environment labels
@@ -106,8 +396,450 @@ type TestAssert struct {
 	metav1.TypeMeta `json:",inline"`
 	// Override the default timeout of 30 seconds (in seconds).
 	Timeout int `json:"timeout"`
+	// If set, the asserts must keep succeeding for this duration (in seconds) after they first succeeded.
+	// It catches resources which flap or controllers which revert changes.
+	Consistently int `json:"consistently,omitempty"`
+	// If set, the objects matching the error objects of the test step are watched while the step runs and the step fails
+	// as soon as one of them matches, even if it no longer matches at the end of the step.
+	WatchErrors bool `json:"watchErrors,omitempty"`
+	// If set, the assert objects with a status only match objects whose status.observedGeneration equals their
+	// metadata.generation, so a status written by a controller for a previous spec does not pass the assert.
+	// An object overrides it with the `kuttl.dev/observed-generation: "true"` or "false" annotation.
+	ObservedGeneration bool `json:"observedGeneration,omitempty"`
+	// Fields which are removed from the expected and actual objects before they are compared and diffed,
+	// in addition to the ignored fields of the test suite.
+	IgnoredFields []string `json:"ignoredFields,omitempty"`
+	// The matching modes of lists by their dot separated path, e.g. `status.conditions: anyElementMatches`.
+	// The modes are exactOrder (default), setEquality, anyElementMatches and allElementsMatch.
+	// An object can override the modes with the `kuttl.dev/array-matching: path=mode,...` annotation.
+	ArrayMatching map[string]string `json:"arrayMatching,omitempty"`
+	// Override the backoff of the retries of the asserts of the test suite.
+	Backoff *Backoff `json:"backoff,omitempty"`
 	// Collectors is a set of pod log collectors fired on an assert failure
 	Collectors []*TestCollector `json:"collectors,omitempty"`
+	// Commands to run on each assert attempt, the assert fails until all commands succeed.
+	// Combined with the expected exit codes and output of a command, it allows to assert on command output.
+	Commands []Command `json:"commands,omitempty"`
+	// Events which must exist (or must not exist) in the test namespace.
+	Events []EventAssert `json:"events,omitempty"`
+	// Logs of pods which must (or must not) contain regular expressions.
+	Logs []LogAssert `json:"logs,omitempty"`
+	// Metrics of Prometheus metrics endpoints which must have the expected values.
+	Metrics []MetricsAssert `json:"metrics,omitempty"`
+	// HTTP requests which must get the expected responses.
+	HTTP []HTTPAssert `json:"http,omitempty"`
+	// Actions which a user or service account must be allowed (or must not be allowed) to perform,
+	// e.g. to test the RBAC rules of an operator.
+	Access []AccessAssert `json:"access,omitempty"`
+	// Commands to run in containers of pods on each assert attempt, the assert fails until all of them succeed.
+	Exec []Exec `json:"exec,omitempty"`
+	// Objects which must exactly match the golden files they were rendered to.
+	Golden []Golden `json:"golden,omitempty"`
+	// Jobs which must complete successfully and CronJobs which must spawn successful jobs.
+	Jobs []JobAssert `json:"jobs,omitempty"`
+	// Pods whose containers must not restart or be OOMKilled during the step or test case.
+	Restarts []RestartAssert `json:"restarts,omitempty"`
+	// Objects whose fields must not change while the step runs.
+	Drift []DriftAssert `json:"drift,omitempty"`
+	// Snapshots of previous test steps which the objects must equal (or must differ from).
+	Snapshots []SnapshotAssert `json:"snapshots,omitempty"`
+	// Conditions of the status.conditions list of objects which must have the expected status.
+	Conditions []ConditionAssert `json:"conditions,omitempty"`
+	// Owners the objects must be owned by, or must have been garbage collected with.
+	Owners []OwnerAssert `json:"owners,omitempty"`
+	// Finalizers of terminating objects, or objects which must have been deleted once their finalizers completed.
+	Finalizers []FinalizerAssert `json:"finalizers,omitempty"`
+}
+
+// Tracking labels the objects applied by a test case with kuttl.dev/test-run set to an ID of the test case, the
+// objects with the label are deleted after the test case in all namespaces and the cluster scope. Commands label
+// the objects they create with the label in $KUTTL_TRACKING_LABEL, e.g. `kubectl label crd x $KUTTL_TRACKING_LABEL`.
+type Tracking struct {
+	// If set, the objects in the namespace of a test case are recorded when the test case starts, the objects
+	// created in it since are deleted after the test case whether they are labeled or not. It is useful with
+	// a namespace which is not deleted after the test cases, see Namespace.
+	SnapshotNamespace bool `json:"snapshotNamespace,omitempty"`
+}
+
+// Upgrade is an upgrade test of an operator, its phases are directories of test steps which run in order like the
+// setup of the test suite. Each phase is reported as a test case of the "upgrade" test suite, the phases after a failed
+// phase are skipped. The phases share the variables captured and the snapshots taken by their steps, e.g. to assert
+// after the upgrade that the objects created before it did not change.
+type Upgrade struct {
+	// Path to a directory of test steps which install the version to upgrade from, e.g. with manifests, Helm or OLM.
+	Install string `json:"install"`
+	// Path to a directory of test steps which run against the version to upgrade from, e.g. to create custom resources.
+	PreUpgrade string `json:"preUpgrade,omitempty"`
+	// Path to a directory of test steps which switch to the version to upgrade to.
+	Upgrade string `json:"upgrade"`
+	// Path to a directory of test steps which assert the state after the upgrade.
+	PostUpgrade string `json:"postUpgrade,omitempty"`
+}
+
+// Impersonate is a user or service account a test step acts as.
+type Impersonate struct {
+	// The name of the user to impersonate.
+	User string `json:"user,omitempty"`
+	// The groups of the impersonated user.
+	Groups []string `json:"groups,omitempty"`
+	// The service account to impersonate instead of a user, either the name of a service account in the test
+	// namespace or namespace/name.
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+}
+
+// Cluster is an additional cluster of a test suite.
+type Cluster struct {
+	// The path to the kubeconfig file of the cluster, the kubeconfig of the test suite if empty.
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	// The context of the kubeconfig to use, its current context if empty.
+	Context string `json:"context,omitempty"`
+}
+
+// Provision are the commands which create and delete the cluster of a test suite. The commands are run with
+// the environment of the test suite and $KUTTL_KUBECONFIG, the path of the kubeconfig of the cluster.
+type Provision struct {
+	// Commands which create the cluster and write its kubeconfig to $KUTTL_KUBECONFIG.
+	Create []Command `json:"create"`
+	// Commands which delete the cluster, unless it is kept with SkipClusterDelete or KeepOnFailure.
+	Delete []Command `json:"delete,omitempty"`
+}
+
+// ContainerImage is a container image which is built with docker build or a command, or a pre-built image if
+// neither Context nor Command is set.
+type ContainerImage struct {
+	// The name of the image, e.g. example.com/operator:test.
+	Image string `json:"image"`
+	// The directory of the build context, relative to the working directory.
+	Context string `json:"context,omitempty"`
+	// The Dockerfile of the build, relative to the context. Defaults to Dockerfile.
+	Dockerfile string `json:"dockerfile,omitempty"`
+	// The build arguments of the build.
+	BuildArgs map[string]string `json:"buildArgs,omitempty"`
+	// A command which builds the image instead of docker build, e.g. `make docker-build IMG=example.com/operator:test`.
+	Command string `json:"command,omitempty"`
+}
+
+// KINDCluster is the configuration of a KIND cluster, see https://kind.sigs.k8s.io/docs/user/configuration.
+type KINDCluster struct {
+	// The nodes of the cluster, they replace the nodes of the KINDConfig file. A single control plane node
+	// is started if there are no nodes.
+	Nodes []KINDNode `json:"nodes,omitempty"`
+	// The number of worker nodes started in addition to the nodes.
+	Workers int `json:"workers,omitempty"`
+	// The image of the nodes which do not set one, e.g. kindest/node:v1.18.2.
+	NodeImage string `json:"nodeImage,omitempty"`
+	// The feature gates of the Kubernetes components, they are merged with the feature gates of the KINDConfig file.
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+	// The network settings of the cluster, they replace the network settings of the KINDConfig file.
+	Networking *KINDNetworking `json:"networking,omitempty"`
+	// Merge patches of the kubeadm configuration of all nodes, appended to the patches of the KINDConfig file.
+	KubeadmConfigPatches []string `json:"kubeadmConfigPatches,omitempty"`
+	// Merge patches of the containerd configuration of all nodes, appended to the patches of the KINDConfig file.
+	ContainerdConfigPatches []string `json:"containerdConfigPatches,omitempty"`
+}
+
+// KINDNode is a node of a KIND cluster.
+type KINDNode struct {
+	// The role of the node: control-plane (default) or worker.
+	Role string `json:"role,omitempty"`
+	// The image of the node, the NodeImage of the cluster or the default image of KIND if empty.
+	Image string `json:"image,omitempty"`
+	// Ports of the node which are mapped to ports of the host, e.g. for an ingress controller.
+	ExtraPortMappings []KINDPortMapping `json:"extraPortMappings,omitempty"`
+	// Directories of the host which are mounted into the node.
+	ExtraMounts []KINDMount `json:"extraMounts,omitempty"`
+	// Merge patches of the kubeadm configuration of the node.
+	KubeadmConfigPatches []string `json:"kubeadmConfigPatches,omitempty"`
+}
+
+// KINDPortMapping maps a port of a KIND node to a port of the host.
+type KINDPortMapping struct {
+	// The port of the node.
+	ContainerPort int `json:"containerPort"`
+	// The port of the host, a random port if not set.
+	HostPort int `json:"hostPort,omitempty"`
+	// The address of the host the port listens on, all addresses if empty.
+	ListenAddress string `json:"listenAddress,omitempty"`
+	// The protocol of the port: TCP (default), UDP or SCTP.
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// KINDMount mounts a directory of the host into a KIND node.
+type KINDMount struct {
+	// The path of the directory in the node.
+	ContainerPath string `json:"containerPath"`
+	// The path of the directory on the host.
+	HostPath string `json:"hostPath"`
+	// If set, the directory is mounted read-only.
+	ReadOnly bool `json:"readOnly,omitempty"`
+}
+
+// KINDNetworking are the network settings of a KIND cluster.
+type KINDNetworking struct {
+	// The IP family of the cluster: ipv4 (default) or ipv6.
+	IPFamily string `json:"ipFamily,omitempty"`
+	// The address of the host the API server listens on.
+	APIServerAddress string `json:"apiServerAddress,omitempty"`
+	// The port of the host the API server listens on, a random port if not set.
+	APIServerPort int `json:"apiServerPort,omitempty"`
+	// The subnet of the pods.
+	PodSubnet string `json:"podSubnet,omitempty"`
+	// The subnet of the services.
+	ServiceSubnet string `json:"serviceSubnet,omitempty"`
+	// If set, no CNI is installed, e.g. to install another one with the commands of the test suite.
+	DisableDefaultCNI bool `json:"disableDefaultCNI,omitempty"`
+}
+
+// Backoff configures the intervals between the retries of the asserts of a test step. The interval starts at
+// the initial interval and is multiplied by the factor after each retry until it reaches the maximum interval.
+type Backoff struct {
+	// The interval before the first retry in milliseconds (default: 1000).
+	Initial int `json:"initial,omitempty"`
+	// The factor the interval is multiplied by after each retry, a number of at least 1, e.g. 1.5 or 2
+	// (default: 1, a constant interval).
+	Factor *resource.Quantity `json:"factor,omitempty"`
+	// The maximum interval in milliseconds, 0 means no limit. The retries never exceed the timeout of the step.
+	Max int `json:"max,omitempty"`
+	// The maximum random increase of each interval in percent of the interval, it spreads the retries of
+	// parallel tests (default: 0).
+	Jitter int `json:"jitter,omitempty"`
+}
+
+// EventAssert asserts on the events of the test namespace. Since event names are generated,
+// events are matched by the object they involve and their reason, type and message.
+type EventAssert struct {
+	// The object the event involves. Unset fields match any value, e.g. without a name
+	// the events of all objects of the kind match.
+	InvolvedObject corev1.ObjectReference `json:"involvedObject"`
+	// The reason of the event, e.g. FailedScheduling.
+	Reason string `json:"reason,omitempty"`
+	// The type of the event: Normal or Warning.
+	Type string `json:"type,omitempty"`
+	// A regular expression the message of the event must match.
+	Message string `json:"message,omitempty"`
+	// If set, the assert fails if a matching event exists.
+	Absent bool `json:"absent,omitempty"`
+}
+
+// AccessAssert asserts whether a user or service account is allowed to perform an action, it is checked
+// with a SubjectAccessReview. Without a user and service account, the user the test step acts as is
+// checked with a SelfSubjectAccessReview.
+type AccessAssert struct {
+	// The user or service account to check, with the same semantics as TestStep.Impersonate.
+	Impersonate `json:",inline"`
+	// The verb of the action, e.g. get, list, create or delete.
+	Verb string `json:"verb"`
+	// The API group of the resource, empty for the core group.
+	Group string `json:"group,omitempty"`
+	// The resource of the action, e.g. pods or deployments.
+	Resource string `json:"resource,omitempty"`
+	// The subresource of the action, e.g. status or log.
+	Subresource string `json:"subresource,omitempty"`
+	// The name of the object, any object of the resource if empty.
+	Name string `json:"name,omitempty"`
+	// namespace to use. The current test namespace will be used by default. Set it to "*" to check
+	// a cluster scoped resource or all namespaces.
+	Namespace string `json:"namespace,omitempty"`
+	// A non resource URL to check instead of a resource, e.g. /metrics.
+	NonResourceURL string `json:"nonResourceURL,omitempty"`
+	// If set, the assert fails if the action is allowed.
+	Denied bool `json:"denied,omitempty"`
+}
+
+// LogAssert asserts on the logs of the pods selected by name or label selector.
+// At least one of `pod` or `selector` is required.
+type LogAssert struct {
+	// The pod name to fetch the logs of.
+	Pod string `json:"pod,omitempty"`
+	// Selector is a label query to select pods.
+	Selector string `json:"selector,omitempty"`
+	// namespace to use. The current test namespace will be used by default.
+	Namespace string `json:"namespace,omitempty"`
+	// Container in pod to get logs from else all containers are used.
+	Container string `json:"container,omitempty"`
+	// Regular expressions which must all match the logs.
+	Match []string `json:"match,omitempty"`
+	// Regular expressions which must not match the logs.
+	NotMatch []string `json:"notMatch,omitempty"`
+}
+
+// MetricsAssert asserts on the value of a metric of a Prometheus metrics endpoint.
+// The endpoint is scraped through the service proxy of the API server.
+type MetricsAssert struct {
+	// The service exposing the metrics endpoint.
+	Service string `json:"service"`
+	// namespace to use. The current test namespace will be used by default.
+	Namespace string `json:"namespace,omitempty"`
+	// The port name or number of the service. The first port of the service is used if not set.
+	Port string `json:"port,omitempty"`
+	// The scheme of the metrics endpoint: http (default) or https.
+	Scheme string `json:"scheme,omitempty"`
+	// The path of the metrics endpoint, /metrics by default.
+	Path string `json:"path,omitempty"`
+	// The name of the metric, e.g. controller_runtime_reconcile_errors_total.
+	// The sum and count of histograms and summaries are referenced with the _sum and _count suffixes.
+	Metric string `json:"metric"`
+	// Labels the series of the metric must have. The values of all matching series are summed up.
+	Labels map[string]string `json:"labels,omitempty"`
+	// The expected value, either a number or a comparison like `kuttl.lt(5)`.
+	Value string `json:"value"`
+}
+
+// HTTPAssert performs an HTTP request and asserts on the response. The request is sent to a URL,
+// e.g. of an Ingress, or to a service through the service proxy of the API server.
+// Exactly one of `url` or `service` is required.
+type HTTPAssert struct {
+	// The URL to request. Environment variables of the test step, e.g. of its port forwards, are expanded.
+	URL string `json:"url,omitempty"`
+	// The service to request through the service proxy of the API server.
+	Service string `json:"service,omitempty"`
+	// namespace of the service. The current test namespace will be used by default.
+	Namespace string `json:"namespace,omitempty"`
+	// The port name or number of the service. The first port of the service is used if not set.
+	Port string `json:"port,omitempty"`
+	// The scheme of the service endpoint: http (default) or https.
+	Scheme string `json:"scheme,omitempty"`
+	// The path requested on the service.
+	Path string `json:"path,omitempty"`
+	// The request method, GET by default.
+	Method string `json:"method,omitempty"`
+	// The request headers.
+	Headers map[string]string `json:"headers,omitempty"`
+	// The request body.
+	Body string `json:"body,omitempty"`
+	// Skip the verification of the server certificate of the URL.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+	// The time in seconds the request may take, 10 by default. Failed requests are retried until the
+	// timeout of the step.
+	Timeout int `json:"timeout,omitempty"`
+	// The expected response.
+	Response HTTPResponse `json:"response,omitempty"`
+}
+
+// HTTPResponse describes the expected response of an HTTP request.
+type HTTPResponse struct {
+	// The expected status code. Any 2xx status code is expected if not set.
+	StatusCode int `json:"statusCode,omitempty"`
+	// Regular expressions the response headers must match by header name.
+	Headers map[string]string `json:"headers,omitempty"`
+	// The expected response body.
+	Body *CommandOutput `json:"body,omitempty"`
+	// The expected values of a JSON response body by JSONPath expression, e.g. `.status: ok`.
+	// The values support the inline operators of assert objects like `kuttl.regexp(...)`.
+	JSONPath map[string]string `json:"jsonPath,omitempty"`
+}
+
+// Golden asserts that an object rendered to YAML matches a golden file exactly. The fields set by the
+// API server like metadata.uid, the namespace and the ignored fields are removed before the comparison.
+type Golden struct {
+	// The object to compare, it must have a name.
+	corev1.ObjectReference `json:",inline"`
+	// The golden file, relative to the test case directory.
+	File string `json:"file"`
+	// The cluster of the object, see TestSuite.Clusters. Defaults to the cluster of the test step.
+	Cluster string `json:"cluster,omitempty"`
+}
+
+// JobAssert asserts that a Job completed successfully or that a CronJob spawned successful jobs, based on the
+// Complete and Failed conditions of the jobs. Exactly one of job and cronJob must be set.
+type JobAssert struct {
+	// The name of a Job which must complete successfully.
+	Job string `json:"job,omitempty"`
+	// The name of a CronJob which must have spawned a successfully completed Job.
+	CronJob string `json:"cronJob,omitempty"`
+	// The namespace of the Job or CronJob, defaults to the test namespace.
+	Namespace string `json:"namespace,omitempty"`
+	// The minimum number of successfully completed Jobs a CronJob must have spawned (default: 1).
+	MinSuccessful int `json:"minSuccessful,omitempty"`
+}
+
+// ConditionAssert asserts on a condition of the conventional status.conditions list of objects. The condition is found
+// by its type regardless of its position in the list and its timestamps are not compared, unlike with the subset
+// matching of the status in an assert file.
+type ConditionAssert struct {
+	// The objects, either a named object or all objects of a kind matching the labels. Each of them must have the
+	// condition.
+	ObjectReference `json:",inline"`
+	// The type of the condition, e.g. Ready.
+	Type string `json:"type"`
+	// The status of the condition: True, False or Unknown (default: True).
+	Status string `json:"status,omitempty"`
+	// The reason of the condition, it is not compared if empty.
+	Reason string `json:"reason,omitempty"`
+	// A regular expression the message of the condition must match, it is not compared if empty.
+	Message string `json:"message,omitempty"`
+}
+
+// FinalizerAssert asserts that objects are terminating and still have a finalizer, or with deleted, that the
+// objects are gone once their finalizers completed. Together with the terminate list of a test step it tests the
+// finalizers of an operator: terminate a custom resource, assert it is held by the finalizer, assert the cleanup
+// done by the operator and finally assert that the custom resource was deleted.
+type FinalizerAssert struct {
+	// The objects, either a named object or all objects of a kind matching the labels. At least one object must
+	// match unless deleted is set.
+	ObjectReference `json:",inline"`
+	// The finalizer the terminating objects must have, required unless deleted is set.
+	Finalizer string `json:"finalizer,omitempty"`
+	// If set, the objects must not exist anymore.
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// OwnerAssert asserts that objects have an owner reference to an owner, e.g. that an operator set itself as the
+// owner of the objects it created. With garbageCollected, it asserts instead that the owner was deleted and that
+// the garbage collector deleted the objects owned by it.
+type OwnerAssert struct {
+	// The owned objects, either a named object or all objects of a kind matching the labels. At least one object
+	// must match unless garbageCollected is set.
+	ObjectReference `json:",inline"`
+	// The owner, its namespace defaults to the namespace of the objects.
+	Owner corev1.ObjectReference `json:"owner"`
+	// If set, the owner must be the controller of the objects.
+	Controller bool `json:"controller,omitempty"`
+	// If set, the owner must not exist and no object may have an owner reference to it, e.g. after the test step
+	// deleted the owner.
+	GarbageCollected bool `json:"garbageCollected,omitempty"`
+}
+
+// RestartAssert asserts that no container of the pods matching a selector restarted or was OOMKilled, it catches
+// silent crash loops, e.g. of the operator under test. The restarts are counted since the step started.
+type RestartAssert struct {
+	// A label selector of the pods, e.g. app=operator.
+	Selector string `json:"selector"`
+	// The namespace of the pods, defaults to the test namespace.
+	Namespace string `json:"namespace,omitempty"`
+	// The name of the container to check, defaults to all containers including init containers.
+	Container string `json:"container,omitempty"`
+	// If set, the restarts are counted since the test case started instead of the step. The restarts of the
+	// pods of templated test steps are counted since the first step with restart asserts of the pods started.
+	SinceTestStart bool `json:"sinceTestStart,omitempty"`
+}
+
+// DriftAssert asserts that fields of an object do not change while the step runs, e.g. because controllers fight
+// over it or reconcile it unexpectedly. The object is read when the step starts, or when it is created, and then
+// watched until the step ends. Changes made by the step itself, e.g. by applying the object, count as drift.
+type DriftAssert struct {
+	// The object to watch, it must have a name.
+	corev1.ObjectReference `json:",inline"`
+	// The dot separated paths of the fields which must not change, e.g. spec.replicas (default: spec).
+	Fields []string `json:"fields,omitempty"`
+}
+
+// Requirements are the preconditions of a test case or test step which are checked against the cluster
+// right before it runs. A test case or test step whose requirements are not met is skipped instead of failed.
+type Requirements struct {
+	// API groups or group versions which must be served, e.g. "monitoring.coreos.com" or "monitoring.coreos.com/v1".
+	APIGroups []string `json:"apiGroups,omitempty"`
+	// Resources which must be served by their plural name and group like the names of CRDs,
+	// e.g. "servicemonitors.monitoring.coreos.com".
+	CRDs []string `json:"crds,omitempty"`
+	// The minimum version of the Kubernetes API server, e.g. "1.18" or "v1.18.2".
+	MinServerVersion string `json:"minServerVersion,omitempty"`
+	// The name of a StorageClass which must exist.
+	StorageClass string `json:"storageClass,omitempty"`
+	// If set, a default StorageClass must exist.
+	DefaultStorageClass bool `json:"defaultStorageClass,omitempty"`
+	// Commands which must exit with zero.
+	Commands []Command `json:"commands,omitempty"`
 }
 
 // ObjectReference is a Kubernetes object reference with added labels to allow referencing
@@ -118,6 +850,37 @@ type ObjectReference struct {
 	Labels map[string]string `json:"labels"`
 }
 
+// DeleteOptions configures how the objects of a test step Delete list are deleted.
+type DeleteOptions struct {
+	// The propagation policy: Foreground, Background or Orphan. The server default is used if not set.
+	PropagationPolicy string `json:"propagationPolicy,omitempty"`
+	// The grace period in seconds. The object type default is used if not set.
+	GracePeriodSeconds *int64 `json:"gracePeriodSeconds,omitempty"`
+	// If set, do not wait for the objects to be deleted.
+	SkipWait bool `json:"skipWait"`
+	// Override the test step timeout to wait for the objects to be deleted (in seconds).
+	Timeout int `json:"timeout"`
+}
+
+// Cleanup describes the objects to delete and commands to run after a test case finished.
+// It is useful for cluster scoped resources or external state which is not removed with the test namespace.
+type Cleanup struct {
+	// Objects to delete after the test case finished.
+	Delete []ObjectReference `json:"delete,omitempty"`
+	// Commands to run after the test case finished.
+	Commands []Command `json:"commands,omitempty"`
+}
+
+// Patch describes a patch applied to existing objects as a part of a test step.
+type Patch struct {
+	// The objects to patch, if no name is set all objects matching the kind and labels are patched.
+	ObjectReference `json:",inline"`
+	// The patch type: merge (default), json or strategic.
+	Type string `json:"type,omitempty"`
+	// The patch payload as YAML or JSON. A json patch is a list of operations.
+	Patch string `json:"patch"`
+}
+
 // Command describes a command to run as a part of a test step or suite.
 type Command struct {
 	// The command and argument to run as a string.
@@ -128,14 +891,268 @@ type Command struct {
 	// namespaced and command should not be used with script.  namespaced is ignored and command is an error.
 	// env expansion is depended upon the shell but ENV is passed to the runtime env.
 	Script string `json:"script"`
-	// If set, exit failures (`exec.ExitError`) will be ignored. `exec.Error` are NOT ignored.
+	// If set, exit failures (`exec.ExitError`) and timeouts will be ignored. `exec.Error` are NOT ignored.
 	IgnoreFailure bool `json:"ignoreFailure"`
 	// If set, the command is run in the background.
+	// Background commands of a test step are killed at the end of the test case.
 	Background bool `json:"background"`
-	// Override the TestSuite timeout for this command (in seconds).
+	// If set on a background command of a test step, the test step fails if the process exits before the step completes.
+	KeepAlive bool `json:"keepAlive"`
+	// Override the TestSuite timeout for this command (in seconds), it may exceed the test step timeout.
+	// A negative value disables the timeout.
 	Timeout int `json:"timeout"`
 	// If set, the output from the command is NOT logged.  Useful for sensitive logs or to reduce noise.
 	SkipLogOutput bool `json:"skipLogOutput"`
+	// The exit codes which are expected from the command, any other exit code is a failure.
+	// Defaults to a zero exit code.
+	ExitCodes []int `json:"exitCodes,omitempty"`
+	// Regular expressions the stdout of the command must or must not match.
+	Stdout *CommandOutput `json:"stdout,omitempty"`
+	// Regular expressions the stderr of the command must or must not match.
+	Stderr *CommandOutput `json:"stderr,omitempty"`
+}
+
+// CommandOutput describes the expected output of a command.
+type CommandOutput struct {
+	// Regular expressions which must all match the output.
+	Match []string `json:"match,omitempty"`
+	// Regular expressions which must not match the output.
+	NotMatch []string `json:"notMatch,omitempty"`
+}
+
+// PortForward forwards a local port to a port of a pod or service while the test step runs.
+// Exactly one of `pod` or `service` is required. The pod, or a pod of the service, must be running
+// within the test step timeout. The forwards are established before the commands of the step run and
+// its objects are applied, so the pod or service must be created by an earlier test step.
+type PortForward struct {
+	// The pod to forward to.
+	Pod string `json:"pod,omitempty"`
+	// The service to forward to, the port is forwarded to a running pod selected by the service.
+	Service string `json:"service,omitempty"`
+	// namespace to use. The current test namespace will be used by default.
+	Namespace string `json:"namespace,omitempty"`
+	// The port number of the pod, or the port name or number of the service.
+	Port string `json:"port"`
+	// The local port, a free port is chosen if not set.
+	LocalPort int `json:"localPort,omitempty"`
+	// The environment variable the local address (host:port) is exposed in to the commands of the test step.
+	Env string `json:"env,omitempty"`
+}
+
+// Exec runs a command in a container of a pod, like `kubectl exec`.
+// At least one of `pod` or `selector` is required.
+type Exec struct {
+	// The pod to run the command in.
+	Pod string `json:"pod,omitempty"`
+	// Selector is a label query to select the pod, the command runs in the first running pod.
+	Selector string `json:"selector,omitempty"`
+	// namespace to use. The current test namespace will be used by default.
+	Namespace string `json:"namespace,omitempty"`
+	// The container to run the command in, the default container of the pod is used if not set.
+	Container string `json:"container,omitempty"`
+	// The command and its arguments, it is not run in a shell.
+	Command []string `json:"command"`
+	// The exit codes which are considered a success, only 0 if not set.
+	ExitCodes []int `json:"exitCodes,omitempty"`
+	// The expected output of the command.
+	Stdout *CommandOutput `json:"stdout,omitempty"`
+	Stderr *CommandOutput `json:"stderr,omitempty"`
+}
+
+// Helm installs, upgrades or uninstalls a Helm release with the Helm SDK.
+type Helm struct {
+	// The name of the release.
+	Release string `json:"release"`
+	// namespace of the release. The current test namespace will be used by default.
+	Namespace string `json:"namespace,omitempty"`
+	// The chart: a path relative to the test case directory, the URL of a chart archive or the name of a chart of
+	// the repository of Repo. It is not required to uninstall a release.
+	Chart string `json:"chart,omitempty"`
+	// The URL of the chart repository of Chart.
+	Repo string `json:"repo,omitempty"`
+	// The version constraint of the chart, the latest version is used if not set.
+	Version string `json:"version,omitempty"`
+	// The values of the release as a YAML document, they override the values of ValuesFiles.
+	Values string `json:"values,omitempty"`
+	// Paths of values files relative to the test case directory or URLs, later files override earlier ones.
+	ValuesFiles []string `json:"valuesFiles,omitempty"`
+	// Upgrade the release if it exists, it is installed otherwise.
+	Upgrade bool `json:"upgrade,omitempty"`
+	// Uninstall the release.
+	Uninstall bool `json:"uninstall,omitempty"`
+	// Wait until the pods, services and deployments of the release are ready.
+	Wait bool `json:"wait,omitempty"`
+	// Assert the objects of the rendered manifest of the release like the objects of an assert file.
+	Assert bool `json:"assert,omitempty"`
+	// Override the test step timeout of the Helm operation (in seconds).
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// OLM installs an operator with the Operator Lifecycle Manager (OLM), which must be installed in the cluster. A
+// Subscription of the package is created and the test step waits until its ClusterServiceVersion succeeded.
+// Exactly one of bundleImage, indexImage and catalogSource is required.
+type OLM struct {
+	// The name of the package of the operator.
+	Package string `json:"package"`
+	// The bundle image of the operator, it is served by a registry pod of a new CatalogSource.
+	BundleImage string `json:"bundleImage,omitempty"`
+	// The index image of a catalog of the operator, a CatalogSource is created for it.
+	IndexImage string `json:"indexImage,omitempty"`
+	// The name of an existing CatalogSource of the operator, e.g. "operatorhubio-catalog".
+	CatalogSource string `json:"catalogSource,omitempty"`
+	// The namespace of the existing CatalogSource, the namespace of the operator is used by default.
+	CatalogSourceNamespace string `json:"catalogSourceNamespace,omitempty"`
+	// The channel of the package, the default channel of the package is used if not set.
+	Channel string `json:"channel,omitempty"`
+	// The name of the ClusterServiceVersion to install, the latest one of the channel is installed if not set.
+	StartingCSV string `json:"startingCSV,omitempty"`
+	// namespace of the operator. The current test namespace will be used by default.
+	Namespace string `json:"namespace,omitempty"`
+	// The target namespaces of the OperatorGroup which is created if the namespace of the operator has none,
+	// all namespaces if empty.
+	TargetNamespaces []string `json:"targetNamespaces,omitempty"`
+	// Override the test step timeout to wait for the ClusterServiceVersion to succeed (in seconds).
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// Scale scales a workload to a number of replicas, the test step waits for its rollout to complete like with
+// `kubectl rollout status`.
+type Scale struct {
+	// The kind of the workload: Deployment, StatefulSet or ReplicaSet.
+	Kind string `json:"kind"`
+	// The name of the workload.
+	Name string `json:"name"`
+	// namespace of the workload. The current test namespace will be used by default.
+	Namespace string `json:"namespace,omitempty"`
+	// The number of replicas.
+	Replicas int32 `json:"replicas"`
+	// Override the test step timeout to wait for the rollout (in seconds).
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// Restart restarts the pods of a workload like `kubectl rollout restart`, the test step waits for its rollout to
+// complete like with `kubectl rollout status`.
+type Restart struct {
+	// The kind of the workload: Deployment, StatefulSet or DaemonSet.
+	Kind string `json:"kind"`
+	// The name of the workload.
+	Name string `json:"name"`
+	// namespace of the workload. The current test namespace will be used by default.
+	Namespace string `json:"namespace,omitempty"`
+	// Override the test step timeout to wait for the rollout (in seconds).
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// Chaos is a disruption caused by a test step, exactly one of killPods, evictPods, isolatePods, cordon, uncordon,
+// drain and bounce must be set. The test step waits until the disruption took effect.
+type Chaos struct {
+	// Deletes the pods with these labels and waits for them to be gone.
+	KillPods map[string]string `json:"killPods,omitempty"`
+	// Evicts the pods with these labels and waits for them to be gone. Evictions are retried while they are
+	// denied by a PodDisruptionBudget.
+	EvictPods map[string]string `json:"evictPods,omitempty"`
+	// Denies the ingress and egress traffic of the pods with these labels with a NetworkPolicy, it is deleted
+	// when the test case finished. The network plugin of the cluster must enforce network policies.
+	IsolatePods map[string]string `json:"isolatePods,omitempty"`
+	// The maximum number of pods which are killed or evicted, all pods with the labels if 0.
+	Count int `json:"count,omitempty"`
+	// The grace period in seconds of the killed or evicted pods. The pod default is used if not set.
+	GracePeriodSeconds *int64 `json:"gracePeriodSeconds,omitempty"`
+	// The name of a node to mark unschedulable.
+	Cordon string `json:"cordon,omitempty"`
+	// The name of a node to mark schedulable.
+	Uncordon string `json:"uncordon,omitempty"`
+	// The name of a node to cordon and to evict the pods from, except the pods of DaemonSets and mirror pods.
+	Drain string `json:"drain,omitempty"`
+	// The name of a deployment to scale to 0 replicas and back. The test step waits for its pods to be gone and
+	// for its replicas to be ready again.
+	Bounce string `json:"bounce,omitempty"`
+	// The namespace of the pods and the deployment. The current test namespace will be used by default.
+	Namespace string `json:"namespace,omitempty"`
+	// Override the test step timeout to wait for the disruption (in seconds).
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// Generate renders go templates of objects for a range of indexes. The templates get the index of the copy as .Index,
+// and the namespace, values, environment variables and captured variables of the test case as for the templates of
+// the test files, the namespace is only set if templating is enabled. E.g. a template with
+// `name: crontab-{{ .Index }}` and a count of 200 generates crontab-0 to crontab-199.
+type Generate struct {
+	// The number of copies.
+	Count int `json:"count"`
+	// The index of the first copy.
+	Start int `json:"start,omitempty"`
+	// Templates of the objects to apply, files or directories relative to the test case directory.
+	Apply []string `json:"apply,omitempty"`
+	// Templates of the objects to assert.
+	Assert []string `json:"assert,omitempty"`
+	// Templates of the objects which must not exist.
+	Error []string `json:"error,omitempty"`
+}
+
+// WaitFor is a condition a test step waits for before it applies its objects, exactly one of crd, apiService,
+// deployment, webhook and rollout is required.
+type WaitFor struct {
+	// The name of a CustomResourceDefinition which must be established, e.g. "crontabs.stable.example.com".
+	CRD string `json:"crd,omitempty"`
+	// The name of an APIService which must be available, e.g. "v1beta1.metrics.k8s.io".
+	APIService string `json:"apiService,omitempty"`
+	// The name of a Deployment which must be available.
+	Deployment string `json:"deployment,omitempty"`
+	// The name of a ValidatingWebhookConfiguration or MutatingWebhookConfiguration whose webhook services must
+	// have ready endpoints.
+	Webhook string `json:"webhook,omitempty"`
+	// The kind and name of a workload whose rollout must be complete like with `kubectl rollout status`,
+	// e.g. deployment/operator, statefulset/db or daemonset/agent.
+	Rollout string `json:"rollout,omitempty"`
+	// namespace of the deployment or rollout. The current test namespace will be used by default.
+	Namespace string `json:"namespace,omitempty"`
+	// Override the test step timeout to wait for the condition (in seconds).
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// Capture extracts a value from an object into a variable of the test case.
+type Capture struct {
+	// The object to extract the value from, it must reference exactly one object.
+	ObjectReference `json:",inline"`
+	// The name of the variable.
+	Variable string `json:"variable"`
+	// The JSONPath expression of the value, e.g. `{.spec.clusterIP}`. The braces are optional.
+	JSONPath string `json:"jsonPath"`
+}
+
+// Snapshot records the objects of a reference under a name, see TestStep.Snapshot.
+type Snapshot struct {
+	// The objects to snapshot, either a named object or all objects of a kind matching the labels.
+	ObjectReference `json:",inline"`
+	// The name of the snapshot, the objects of all references of a test step with the same name are snapshotted
+	// together. A snapshot of a later test step replaces the snapshot with the same name.
+	As string `json:"as"`
+}
+
+// SnapshotAssert compares the objects of a snapshot with their current state. The objects matching the references
+// of the snapshot are read again, so objects created or deleted since the snapshot count as changes.
+type SnapshotAssert struct {
+	// The name of the snapshot.
+	Snapshot string `json:"snapshot"`
+	// If set, the objects must differ from the snapshot instead of equal it.
+	Changed bool `json:"changed,omitempty"`
+	// Fields which are not compared, in addition to the ignored fields of the TestAssert and test suite,
+	// e.g. status. The resource version and managed fields are never compared.
+	IgnoredFields []string `json:"ignoredFields,omitempty"`
+}
+
+// Job describes a Kubernetes Job which is run to completion as a part of a test step.
+type Job struct {
+	// Name of the job, defaults to the test step name with the index of the job, lowercased and with the characters
+	// which are not allowed in a job name replaced by dashes.
+	Name string `json:"name,omitempty"`
+	// The pod spec of the job. The restart policy defaults to Never.
+	Spec corev1.PodSpec `json:"spec"`
+	// If set, a failure of the job does not fail the test step.
+	IgnoreFailure bool `json:"ignoreFailure"`
+	// Override the test step timeout for this job (in seconds).
+	Timeout int `json:"timeout"`
 }
 
 // TestCollector are post assert / error commands that allow for the collection of information sent to the test log.
@@ -160,5 +1177,8 @@ type TestCollector struct {
 	Cmd string `json:"command,omitempty"`
 }
 
+// LogFormatJSON is the LogFormat of JSON logs.
+const LogFormatJSON = "json"
+
 // DefaultKINDContext defines the default kind context to use.
 const DefaultKINDContext = "kind"
@@ -2,12 +2,17 @@ package http
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/dustin/go-humanize"
 
@@ -18,18 +23,39 @@ import (
 type Client struct {
 	client    *http.Client
 	UserAgent string
+	// Retries is the number of times a request is retried if it fails with a network error or a 5xx or 429 status.
+	Retries int
+	// Backoff is the delay before the first retry, it doubles with every retry.
+	Backoff time.Duration
+	// CacheDir is the directory of the files cached by GetCached, the cache is disabled if it is empty.
+	CacheDir string
 }
 
 // Get performs an HTTP get and returns the Response.  The caller is responsible for closing the response.
+// The request is authenticated with the credentials of its host, see Credential, and retried with backoff.
 func (c *Client) Get(url string) (*http.Response, error) {
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("User-Agent", c.UserAgent)
+	backoff := c.Backoff
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("User-Agent", c.UserAgent)
+		if err := authorize(req); err != nil {
+			return nil, err
+		}
 
-	resp, err := c.client.Do(req)
-	return resp, err
+		resp, err := c.client.Do(req)
+		retryable := err != nil || resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		if !retryable || attempt >= c.Retries {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
 }
 
 // Get performs HTTP get, retrieves the contents and returns a bytes.Buffer of the entire contents
@@ -56,15 +82,102 @@ func (c *Client) GetByteBuffer(url string) (*bytes.Buffer, error) {
 	return buf, err
 }
 
+// GetCached returns the contents of a url like GetByteBuffer and caches them in CacheDir. The url may pin the
+// checksum of the contents with a #sha256=<hex> fragment, a pinned url is read from the cache if the checksum of the
+// cached contents matches and its contents are verified. If an unpinned url cannot be fetched, its cached contents
+// are returned.
+func (c *Client) GetCached(rawURL string) (*bytes.Buffer, error) {
+	u, sum, err := splitChecksum(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := ""
+	if c.CacheDir != "" {
+		key := sha256.Sum256([]byte(u))
+		cached = filepath.Join(c.CacheDir, hex.EncodeToString(key[:]))
+	}
+
+	if sum != "" && cached != "" {
+		if data, err := ioutil.ReadFile(cached); err == nil && checksum(data) == sum {
+			return bytes.NewBuffer(data), nil
+		}
+	}
+
+	buf, err := c.GetByteBuffer(u)
+	if err != nil {
+		if sum == "" && cached != "" {
+			if data, cacheErr := ioutil.ReadFile(cached); cacheErr == nil {
+				return bytes.NewBuffer(data), nil
+			}
+		}
+		return nil, err
+	}
+
+	if sum != "" && checksum(buf.Bytes()) != sum {
+		return nil, fmt.Errorf("checksum of %s is sha256:%s, expected sha256:%s", u, checksum(buf.Bytes()), sum)
+	}
+
+	// the cache is best effort, the contents are written to a temporary file which is renamed so readers never see
+	// a partial file
+	if cached != "" && os.MkdirAll(c.CacheDir, 0755) == nil {
+		if tmp, err := ioutil.TempFile(c.CacheDir, "download"); err == nil {
+			_, err = tmp.Write(buf.Bytes())
+			tmp.Close()
+			if err == nil {
+				err = os.Rename(tmp.Name(), cached)
+			}
+			if err != nil {
+				os.Remove(tmp.Name())
+			}
+		}
+	}
+
+	return buf, nil
+}
+
+// splitChecksum splits the sha256 checksum of the #sha256=<hex> fragment of a url from it.
+func splitChecksum(rawURL string) (string, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Fragment == "" {
+		return rawURL, "", nil
+	}
+	if !strings.HasPrefix(u.Fragment, "sha256=") {
+		return "", "", fmt.Errorf("url %s has an unsupported fragment, only #sha256=<hex> checksums are supported", rawURL)
+	}
+
+	sum := strings.ToLower(strings.TrimPrefix(u.Fragment, "sha256="))
+	u.Fragment = ""
+	return u.String(), sum, nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
 // DownloadFile expects a url with a file and will save that file to the path provided preserving the file name.
 func (c *Client) DownloadFile(url, path string) (string, error) {
-	filePath := filepath.Join(path, filepath.Base(url))
+	u, _, err := splitChecksum(url)
+	if err != nil {
+		return "", err
+	}
+	filePath := filepath.Join(path, filepath.Base(u))
 	return filePath, c.Download(url, filePath)
 }
 
 // Download takes a url to download and a filepath to write it to
 // this will write the response to any url request to a file.
+// If the url pins a checksum with a #sha256=<hex> fragment, the file is verified.
 func (c *Client) Download(url string, path string) error {
+	url, sum, err := splitChecksum(url)
+	if err != nil {
+		return err
+	}
+
 	// Get the data
 	resp, err := c.Get(url)
 	if err != nil {
@@ -72,6 +185,10 @@ func (c *Client) Download(url string, path string) error {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("failed to fetch %s : %s", url, resp.Status)
+	}
+
 	_, err = os.Stat(path)
 	if err == nil || os.IsExist(err) {
 		return os.ErrExist
@@ -91,7 +208,8 @@ func (c *Client) Download(url string, path string) error {
 
 	// Create our bytes counter and pass it to be used alongside our writer
 	counter := &writeCounter{Name: filepath.Base(path)}
-	_, err = io.Copy(out, io.TeeReader(resp.Body, counter))
+	digest := sha256.New()
+	_, err = io.Copy(io.MultiWriter(out, digest), io.TeeReader(resp.Body, counter))
 	if err != nil {
 		return err
 	}
@@ -99,6 +217,11 @@ func (c *Client) Download(url string, path string) error {
 	// The progress use the same line so print a new line once it's finished downloading
 	fmt.Println()
 
+	if actual := hex.EncodeToString(digest.Sum(nil)); sum != "" && actual != sum {
+		os.Remove(path + ".tmp")
+		return fmt.Errorf("checksum of %s is sha256:%s, expected sha256:%s", url, actual, sum)
+	}
+
 	// Rename the tmp file back to the original file
 	err = os.Rename(path+".tmp", path)
 	if err != nil {
@@ -141,5 +264,12 @@ func NewClient() *Client {
 
 	client.client = &http.Client{Transport: tr}
 	client.UserAgent = fmt.Sprintf("KUTTL/%s", strings.TrimPrefix(version.Get().GitVersion, "v"))
+	client.Retries = 3
+	client.Backoff = time.Second
+
+	// the files are cached in kuttl/http of the user cache directory, e.g. ~/.cache/kuttl/http
+	if cacheDir, err := os.UserCacheDir(); err == nil {
+		client.CacheDir = filepath.Join(cacheDir, "kuttl", "http")
+	}
 	return &client
 }
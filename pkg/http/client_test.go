@@ -0,0 +1,127 @@
+package http
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	podManifest = "apiVersion: v1\nkind: Pod\nmetadata:\n  name: db\n"
+	// invalidChecksum is not the sha256 checksum of podManifest.
+	invalidChecksum = "b5e8bb5c7e6c4bdb0f0e7e4b8fd2a5d6a40bb1c4a5ce3d4ea5d2ae2b5e9d8f70"
+)
+
+func testClient(t *testing.T) (*Client, func()) {
+	dir, err := ioutil.TempDir("", "kuttl-http")
+	require.NoError(t, err)
+
+	client := NewClient()
+	client.Backoff = time.Millisecond
+	client.CacheDir = dir
+	return client, func() { os.RemoveAll(dir) }
+}
+
+func TestGetRetries(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, podManifest)
+	}))
+	defer server.Close()
+
+	client, cleanup := testClient(t)
+	defer cleanup()
+
+	buf, err := client.GetByteBuffer(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, podManifest, buf.String())
+	assert.Equal(t, 3, requests)
+
+	// a client error is not retried
+	requests = 0
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+	})
+	_, err = client.GetByteBuffer(server.URL)
+	assert.EqualError(t, err, fmt.Sprintf("failed to fetch %s : 404 Not Found", server.URL))
+	assert.Equal(t, 1, requests)
+}
+
+func TestGetCached(t *testing.T) {
+	available := true
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if !available {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, podManifest)
+	}))
+	defer server.Close()
+
+	client, cleanup := testClient(t)
+	defer cleanup()
+	client.Retries = 1
+
+	url := server.URL + "/pod.yaml"
+	buf, err := client.GetCached(url)
+	require.NoError(t, err)
+	assert.Equal(t, podManifest, buf.String())
+
+	// the cached contents are returned if the url cannot be fetched
+	available = false
+	requests = 0
+	buf, err = client.GetCached(url)
+	require.NoError(t, err)
+	assert.Equal(t, podManifest, buf.String())
+	assert.Equal(t, 2, requests)
+
+	// a pinned url is read from the cache
+	requests = 0
+	buf, err = client.GetCached(url + "#sha256=" + checksum([]byte(podManifest)))
+	require.NoError(t, err)
+	assert.Equal(t, podManifest, buf.String())
+	assert.Equal(t, 0, requests)
+
+	// the contents of a pinned url are verified
+	available = true
+	_, err = client.GetCached(url + "#sha256=" + invalidChecksum)
+	assert.EqualError(t, err, fmt.Sprintf("checksum of %s is sha256:%s, expected sha256:%s", url, checksum([]byte(podManifest)), invalidChecksum))
+
+	_, err = client.GetCached(url + "#md5=abc")
+	assert.EqualError(t, err, fmt.Sprintf("url %s#md5=abc has an unsupported fragment, only #sha256=<hex> checksums are supported", url))
+}
+
+func TestDownloadChecksum(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, podManifest)
+	}))
+	defer server.Close()
+
+	client, cleanup := testClient(t)
+	defer cleanup()
+
+	path, err := client.DownloadFile(server.URL+"/pod.yaml#sha256="+checksum([]byte(podManifest)), client.CacheDir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(client.CacheDir, "pod.yaml"), path)
+
+	err = client.Download(server.URL+"/pod.yaml#sha256="+invalidChecksum, filepath.Join(client.CacheDir, "invalid.yaml"))
+	assert.Error(t, err)
+	_, err = os.Stat(filepath.Join(client.CacheDir, "invalid.yaml.tmp"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+)
+
+// CredentialsEnv is the environment variable of the path of the credentials file, it defaults to
+// kuttl/credentials.yaml in the user configuration directory, e.g. ~/.config/kuttl/credentials.yaml.
+const CredentialsEnv = "KUTTL_HTTP_CREDENTIALS"
+
+// TokenEnv is the environment variable of a bearer token which is sent to the hosts without credentials in the
+// credentials file.
+const TokenEnv = "KUTTL_HTTP_TOKEN"
+
+// Credential authenticates the requests to a host. The credentials file is a YAML list of them, e.g.
+//
+//   - host: raw.githubusercontent.com
+//     token: $GITHUB_TOKEN
+//
+// The token, the password and the headers are expanded with the environment, so secrets can be kept out of the file.
+type Credential struct {
+	// The host of the URLs, with the port if it is not the default one of the scheme.
+	Host string `json:"host"`
+	// A bearer token.
+	Token string `json:"token,omitempty"`
+	// The username and password of basic authentication.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Headers sent with the requests, e.g. PRIVATE-TOKEN of GitLab.
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// credentialsPath returns the path of the credentials file, it is empty if there is no user configuration directory.
+func credentialsPath() string {
+	if path := os.Getenv(CredentialsEnv); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "kuttl", "credentials.yaml")
+}
+
+// loadCredentials loads the credentials file, a missing file has no credentials.
+func loadCredentials() ([]Credential, error) {
+	path := credentialsPath()
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := []Credential{}
+	if err := yaml.Unmarshal(data, &credentials); err != nil {
+		return nil, fmt.Errorf("parsing the credentials file %s: %w", path, err)
+	}
+	return credentials, nil
+}
+
+// authorize adds the credentials of the host of a request to it, or the token of $KUTTL_HTTP_TOKEN if the host has
+// none.
+func authorize(req *http.Request) error {
+	credentials, err := loadCredentials()
+	if err != nil {
+		return err
+	}
+
+	for _, credential := range credentials {
+		if credential.Host != req.URL.Host && credential.Host != req.URL.Hostname() {
+			continue
+		}
+
+		if credential.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+os.ExpandEnv(credential.Token))
+		}
+		if credential.Username != "" {
+			req.SetBasicAuth(credential.Username, os.ExpandEnv(credential.Password))
+		}
+		for name, value := range credential.Headers {
+			req.Header.Set(name, os.ExpandEnv(value))
+		}
+		return nil
+	}
+
+	if token := os.Getenv(TokenEnv); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return nil
+}
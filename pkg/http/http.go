@@ -18,6 +18,7 @@ func IsURL(str string) bool {
 
 // ToRuntimeObjects takes a url, pulls the file and returns  []runtime.Object
 // url must be a full path to a manifest file.  that file can have multiple runtime objects.
+// The checksum of the file can be pinned with a #sha256=<hex> fragment, see Client.GetCached.
 func ToRuntimeObjects(urlPath string) ([]runtime.Object, error) {
 	apply := []runtime.Object{}
 
@@ -35,8 +36,8 @@ func ToRuntimeObjects(urlPath string) ([]runtime.Object, error) {
 	return apply, nil
 }
 
-// Read returns a buffer for the file at the url
+// Read returns a buffer for the file at the url, it is cached on disk.
 func Read(urlPath string) (*bytes.Buffer, error) {
 	client := NewClient()
-	return client.GetByteBuffer(urlPath)
+	return client.GetCached(urlPath)
 }
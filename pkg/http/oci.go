@@ -0,0 +1,347 @@
+package http
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// OCIScheme is the prefix of the references of OCI artifacts, e.g. oci://ghcr.io/example/crds:v1.0.0.
+const OCIScheme = "oci://"
+
+// ociManifestTypes are the media types of the manifests of OCI artifacts.
+var ociManifestTypes = []string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// OCILayer is a layer of an OCI artifact.
+type OCILayer struct {
+	MediaType string
+	Data      []byte
+}
+
+// IsOCIReference returns true if the string is a reference of an OCI artifact.
+func IsOCIReference(str string) bool {
+	return strings.HasPrefix(str, OCIScheme)
+}
+
+// OCIToRuntimeObjects pulls an OCI artifact and returns the objects of its layers. A layer is a manifest or a tar
+// archive of manifests, optionally gzipped. The files of an archive which are not YAML or JSON files are ignored.
+func OCIToRuntimeObjects(reference string) ([]runtime.Object, error) {
+	layers, err := PullOCIArtifact(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := []runtime.Object{}
+	for _, layer := range layers {
+		var r io.Reader = bytes.NewReader(layer.Data)
+
+		// gzip magic number
+		if bytes.HasPrefix(layer.Data, []byte{0x1f, 0x8b}) {
+			gz, err := gzip.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			r = gz
+		}
+
+		if !strings.Contains(layer.MediaType, "tar") {
+			objs, err := testutils.LoadYAML(reference, r)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, objs...)
+			continue
+		}
+
+		tr := tar.NewReader(r)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if header.Typeflag != tar.TypeReg || !isManifestFile(header.Name) {
+				continue
+			}
+
+			objs, err := testutils.LoadYAML(header.Name, tr)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, objs...)
+		}
+	}
+	return objects, nil
+}
+
+// isManifestFile checks if a file is a YAML or JSON manifest by its extension.
+func isManifestFile(name string) bool {
+	switch filepath.Ext(name) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// PullOCIArtifact pulls the layers of an OCI artifact from its registry, e.g. oci://ghcr.io/example/crds:v1.0.0 or
+// oci://ghcr.io/example/crds@sha256:... The credentials of the registry are read from the docker config file,
+// without them registries which require authentication must grant anonymous tokens. Registries on localhost are
+// accessed with HTTP.
+func PullOCIArtifact(reference string) ([]OCILayer, error) {
+	registry, repository, ref, err := parseOCIReference(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	username, password, err := dockerCredentials(registry)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := "https"
+	if host := strings.Split(registry, ":")[0]; host == "localhost" || host == "127.0.0.1" {
+		scheme = "http"
+	}
+	base := fmt.Sprintf("%s://%s/v2/%s", scheme, registry, repository)
+
+	c := &ociClient{client: &http.Client{}, username: username, password: password}
+
+	manifest := struct {
+		Layers []struct {
+			MediaType string `json:"mediaType"`
+			Digest    string `json:"digest"`
+		} `json:"layers"`
+	}{}
+	data, err := c.get(fmt.Sprintf("%s/manifests/%s", base, ref), strings.Join(ociManifestTypes, ", "))
+	if err != nil {
+		return nil, fmt.Errorf("pulling the manifest of %s: %w", reference, err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("decoding the manifest of %s: %w", reference, err)
+	}
+
+	layers := []OCILayer{}
+	for _, layer := range manifest.Layers {
+		data, err := c.get(fmt.Sprintf("%s/blobs/%s", base, layer.Digest), "")
+		if err != nil {
+			return nil, fmt.Errorf("pulling layer %s of %s: %w", layer.Digest, reference, err)
+		}
+		layers = append(layers, OCILayer{MediaType: layer.MediaType, Data: data})
+	}
+	return layers, nil
+}
+
+// parseOCIReference splits a reference of an OCI artifact into its registry, repository and tag or digest.
+func parseOCIReference(reference string) (registry, repository, ref string, err error) {
+	name := strings.TrimPrefix(reference, OCIScheme)
+
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", fmt.Errorf("invalid OCI reference %q: must be oci://registry/repository:tag", reference)
+	}
+	registry, repository = parts[0], parts[1]
+
+	if i := strings.Index(repository, "@"); i >= 0 {
+		return registry, repository[:i], repository[i+1:], nil
+	}
+	if i := strings.LastIndex(repository, ":"); i >= 0 {
+		return registry, repository[:i], repository[i+1:], nil
+	}
+	return registry, repository, "latest", nil
+}
+
+// dockerHubRegistries are the names of the registry of Docker Hub, its credentials are stored as index.docker.io.
+var dockerHubRegistries = map[string]bool{
+	"docker.io":            true,
+	"index.docker.io":      true,
+	"registry-1.docker.io": true,
+}
+
+// dockerCredentials returns the username and password of a registry of the docker config file,
+// $DOCKER_CONFIG/config.json or ~/.docker/config.json. They are empty if the file or the registry does not exist.
+// Credential helpers are not supported.
+func dockerCredentials(registry string) (string, string, error) {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", nil
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "config.json"))
+	if os.IsNotExist(err) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	config := struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+	}{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", "", fmt.Errorf("parsing the docker config file: %w", err)
+	}
+
+	for key, auth := range config.Auths {
+		// the keys may be URLs like https://index.docker.io/v1/
+		host := strings.Split(strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://"), "/")[0]
+		if host != registry && !(dockerHubRegistries[host] && dockerHubRegistries[registry]) {
+			continue
+		}
+
+		if auth.Auth == "" {
+			return auth.Username, auth.Password, nil
+		}
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return "", "", fmt.Errorf("decoding the docker credentials of %s: %w", registry, err)
+		}
+		parts := strings.SplitN(string(decoded), ":", 2)
+		if len(parts) != 2 {
+			return "", "", fmt.Errorf("invalid docker credentials of %s", registry)
+		}
+		return parts[0], parts[1], nil
+	}
+	return "", "", nil
+}
+
+// ociClient is a client of the registry API which authenticates the requests if the registry challenges them. It
+// uses basic authentication or gets bearer tokens, anonymous ones if it has no credentials.
+type ociClient struct {
+	client   *http.Client
+	username string
+	password string
+	// authorization is the Authorization header of the requests once the registry challenged a request.
+	authorization string
+}
+
+// bearerChallenge parses the parameters of the Bearer challenge of a WWW-Authenticate header.
+var bearerChallenge = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// get performs an HTTP get and returns the response body, it authenticates if the registry requires it.
+func (c *ociClient) get(url, accept string) ([]byte, error) {
+	resp, err := c.do(url, accept)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && c.authorization == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+
+		if err := c.authenticate(challenge); err != nil {
+			return nil, err
+		}
+		if resp, err = c.do(url, accept); err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s : %s", url, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (c *ociClient) do(url, accept string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if c.authorization != "" {
+		req.Header.Set("Authorization", c.authorization)
+	}
+	return c.client.Do(req)
+}
+
+// authenticate sets the authorization of the requests for a Basic challenge or gets a token from the realm of a
+// Bearer challenge.
+func (c *ociClient) authenticate(challenge string) error {
+	if strings.HasPrefix(challenge, "Basic ") {
+		if c.username == "" {
+			return fmt.Errorf("the registry requires credentials, none are in the docker config file")
+		}
+		c.authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(c.username+":"+c.password))
+		return nil
+	}
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+
+	params := map[string]string{}
+	for _, match := range bearerChallenge.FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return fmt.Errorf("invalid realm of authentication challenge %q", challenge)
+	}
+
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("GET", realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to get a token from %s : %s", realm.Host, resp.Status)
+	}
+
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return err
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	c.authorization = "Bearer " + token.Token
+	return nil
+}
@@ -0,0 +1,206 @@
+package http
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOCIReference(t *testing.T) {
+	tests := []struct {
+		name       string
+		reference  string
+		registry   string
+		repository string
+		ref        string
+		err        string
+	}{
+		{
+			name:       "tag",
+			reference:  "oci://ghcr.io/example/crds:v1.0.0",
+			registry:   "ghcr.io",
+			repository: "example/crds",
+			ref:        "v1.0.0",
+		},
+		{
+			name:       "digest",
+			reference:  "oci://ghcr.io/example/crds@sha256:abc",
+			registry:   "ghcr.io",
+			repository: "example/crds",
+			ref:        "sha256:abc",
+		},
+		{
+			name:       "latest",
+			reference:  "oci://localhost:5000/crds",
+			registry:   "localhost:5000",
+			repository: "crds",
+			ref:        "latest",
+		},
+		{
+			name:      "no repository",
+			reference: "oci://ghcr.io",
+			err:       `invalid OCI reference "oci://ghcr.io": must be oci://registry/repository:tag`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			registry, repository, ref, err := parseOCIReference(tt.reference)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.registry, registry)
+			assert.Equal(t, tt.repository, repository)
+			assert.Equal(t, tt.ref, ref)
+		})
+	}
+}
+
+func TestPullOCIArtifact(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			assert.Equal(t, "repository:example/crds:pull", r.URL.Query().Get("scope"))
+			fmt.Fprint(w, `{"token": "secret"}`)
+			return
+		case r.Header.Get("Authorization") != "Bearer secret":
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:example/crds:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v2/example/crds/manifests/v1.0.0":
+			assert.Contains(t, r.Header.Get("Accept"), "application/vnd.oci.image.manifest.v1+json")
+			fmt.Fprint(w, `{"layers": [{"mediaType": "application/yaml", "digest": "sha256:crds"}]}`)
+		case "/v2/example/crds/blobs/sha256:crds":
+			fmt.Fprint(w, "kind: CustomResourceDefinition")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	registry := strings.TrimPrefix(server.URL, "http://")
+
+	layers, err := PullOCIArtifact(fmt.Sprintf("oci://%s/example/crds:v1.0.0", registry))
+	require.NoError(t, err)
+	assert.Equal(t, []OCILayer{{MediaType: "application/yaml", Data: []byte("kind: CustomResourceDefinition")}}, layers)
+
+	_, err = PullOCIArtifact(fmt.Sprintf("oci://%s/example/crds:v2.0.0", registry))
+	assert.EqualError(t, err, fmt.Sprintf("pulling the manifest of oci://%s/example/crds:v2.0.0: failed to fetch http://%s/v2/example/crds/manifests/v2.0.0 : 404 Not Found", registry, registry))
+}
+
+func TestOCIToRuntimeObjects(t *testing.T) {
+	archive := &bytes.Buffer{}
+	gz := gzip.NewWriter(archive)
+	tw := tar.NewWriter(gz)
+	for name, content := range map[string]string{"manifests/pod.yaml": "apiVersion: v1\nkind: Pod\nmetadata:\n  name: db\n", "LICENSE": "MIT"} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/manifests/manifests/v1":
+			fmt.Fprint(w, `{"layers": [
+				{"mediaType": "application/vnd.oci.image.layer.v1.tar+gzip", "digest": "sha256:archive"},
+				{"mediaType": "application/yaml", "digest": "sha256:manifest"}
+			]}`)
+		case "/v2/manifests/blobs/sha256:archive":
+			_, _ = w.Write(archive.Bytes())
+		case "/v2/manifests/blobs/sha256:manifest":
+			fmt.Fprint(w, "apiVersion: v1\nkind: Service\nmetadata:\n  name: db\n")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	objs, err := OCIToRuntimeObjects(fmt.Sprintf("oci://%s/manifests:v1", strings.TrimPrefix(server.URL, "http://")))
+	require.NoError(t, err)
+	require.Len(t, objs, 2)
+	assert.Equal(t, "Pod", objs[0].GetObjectKind().GroupVersionKind().Kind)
+	assert.Equal(t, "Service", objs[1].GetObjectKind().GroupVersionKind().Kind)
+}
+
+func TestDockerCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-docker")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	config := `{"auths": {
+		"https://index.docker.io/v1/": {"auth": "aHViOnNlY3JldA=="},
+		"ghcr.io": {"username": "octocat", "password": "token"}
+	}}`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0600))
+	defer os.Setenv("DOCKER_CONFIG", os.Getenv("DOCKER_CONFIG"))
+	require.NoError(t, os.Setenv("DOCKER_CONFIG", dir))
+
+	tests := []struct {
+		registry string
+		username string
+		password string
+	}{
+		{"registry-1.docker.io", "hub", "secret"},
+		{"ghcr.io", "octocat", "token"},
+		{"quay.io", "", ""},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.registry, func(t *testing.T) {
+			username, password, err := dockerCredentials(tt.registry)
+			require.NoError(t, err)
+			assert.Equal(t, tt.username, username)
+			assert.Equal(t, tt.password, password)
+		})
+	}
+}
+
+func TestPullOCIArtifactBasicAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "octocat" || password != "token" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"layers": []}`)
+	}))
+	defer server.Close()
+
+	registry := strings.TrimPrefix(server.URL, "http://")
+	reference := fmt.Sprintf("oci://%s/manifests:v1", registry)
+
+	dir, err := ioutil.TempDir("", "kuttl-docker")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer os.Setenv("DOCKER_CONFIG", os.Getenv("DOCKER_CONFIG"))
+	require.NoError(t, os.Setenv("DOCKER_CONFIG", dir))
+
+	_, err = PullOCIArtifact(reference)
+	assert.EqualError(t, err, fmt.Sprintf("pulling the manifest of %s: the registry requires credentials, none are in the docker config file", reference))
+
+	config := fmt.Sprintf(`{"auths": {%q: {"username": "octocat", "password": "token"}}}`, registry)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0600))
+	layers, err := PullOCIArtifact(reference)
+	require.NoError(t, err)
+	assert.Empty(t, layers)
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kudobuilder/kuttl/pkg/report"
+)
+
+var (
+	mergeReportsExample = `  # Merge the junit reports of the shards of a test suite.
+  kubectl kuttl merge-reports --report-name kuttl-test shard-0/kuttl-test.xml shard-1/kuttl-test.xml`
+)
+
+// newMergeReportsCmd returns a new initialized instance of the merge-reports sub command
+func newMergeReportsCmd() *cobra.Command {
+	artifactsDir := "."
+	reportName := "kuttl-test"
+	reportFormat := "xml"
+
+	mergeReportsCmd := &cobra.Command{
+		Use:     "merge-reports",
+		Short:   "Merges the junit reports of the shards of a test suite.",
+		Long:    `Merges the junit (XML) reports of the shards of a test suite, run with --shard-index and --shard-count, into a single report.`,
+		Example: mergeReportsExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("at least one report file argument is required")
+			}
+
+			reports := []*report.Testsuites{}
+			for _, path := range args {
+				ts, err := report.ReadXML(path)
+				if err != nil {
+					return err
+				}
+				reports = append(reports, ts)
+			}
+
+			ftype := reportType(report.Type(strings.ToLower(reportFormat)))
+			if ftype == "" {
+				return fmt.Errorf("unknown report format %q", reportFormat)
+			}
+
+			merged := report.Merge(reports[0].Name, reports)
+			return merged.Write(artifactsDir, reportName, report.Type(ftype))
+		},
+	}
+
+	mergeReportsCmd.Flags().StringVar(&artifactsDir, "artifacts-dir", ".", "Directory to write the merged report to.")
+	mergeReportsCmd.Flags().StringVar(&reportName, "report-name", "kuttl-test", "The name of the merged report.")
+	mergeReportsCmd.Flags().StringVar(&reportFormat, "report", "xml", "Specify JSON|XML|TAP|HTML for the merged report.")
+
+	return mergeReportsCmd
+}
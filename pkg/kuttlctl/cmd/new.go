@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kudobuilder/kuttl/pkg/test"
+)
+
+var (
+	newExample = `  # Create the test case tests/e2e/my-test with a first test step.
+  kubectl kuttl new test my-test --dir tests/e2e
+
+  # Add a test step after the last test step of the test case.
+  kubectl kuttl new step update --dir tests/e2e/my-test`
+)
+
+// newNewCmd returns a new initialized instance of the new sub command
+func newNewCmd() *cobra.Command {
+	newCmd := &cobra.Command{
+		Use:     "new",
+		Short:   "Scaffolds test cases and test steps.",
+		Long:    `Creates the files of a new test case or test step with an example object and assert, using the expected file naming.`,
+		Example: newExample,
+	}
+
+	newCmd.AddCommand(newNewTestCmd())
+	newCmd.AddCommand(newNewStepCmd())
+
+	return newCmd
+}
+
+func newNewTestCmd() *cobra.Command {
+	dir := "."
+
+	newTestCmd := &cobra.Command{
+		Use:   "test <name>",
+		Short: "Creates a new test case.",
+		Long:  `Creates the directory of a new test case in the test directory with a first test step "00-install".`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			files, err := test.NewTestCase(dir, args[0])
+			if err != nil {
+				return err
+			}
+			printCreated(cmd, files)
+			return nil
+		},
+	}
+
+	newTestCmd.Flags().StringVar(&dir, "dir", ".", "The test directory to create the test case in.")
+
+	return newTestCmd
+}
+
+func newNewStepCmd() *cobra.Command {
+	dir := "."
+	index := -1
+
+	newStepCmd := &cobra.Command{
+		Use:   "step <name>",
+		Short: "Creates a new test step.",
+		Long:  `Creates the step and assert files of a new test step in a test case directory.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			files, err := test.NewTestStep(dir, args[0], index)
+			if err != nil {
+				return err
+			}
+			printCreated(cmd, files)
+			return nil
+		},
+	}
+
+	newStepCmd.Flags().StringVar(&dir, "dir", ".", "The test case directory to create the test step in.")
+	newStepCmd.Flags().IntVar(&index, "index", -1, "The index of the test step, -1 adds the test step after the last test step.")
+
+	return newStepCmd
+}
+
+func printCreated(cmd *cobra.Command, files []string) {
+	for _, file := range files {
+		fmt.Fprintf(cmd.OutOrStdout(), "created %s\n", file)
+	}
+}
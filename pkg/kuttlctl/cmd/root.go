@@ -18,6 +18,9 @@ and serves as an API aggregation layer.
 		Example: `  # Run integration tests against a Kubernetes cluster or mocked control plane.
   kubectl kuttl test
 
+  # Create a new test case.
+  kubectl kuttl new test my-test
+
   # View kuttl version
   kubectl kuttl version
 `,
@@ -26,6 +29,8 @@ and serves as an API aggregation layer.
 
 	cmd.AddCommand(newAssertCmd())
 	cmd.AddCommand(newErrorsCmd())
+	cmd.AddCommand(newMergeReportsCmd())
+	cmd.AddCommand(newNewCmd())
 	cmd.AddCommand(newTestCmd())
 	cmd.AddCommand(newVersionCmd())
 
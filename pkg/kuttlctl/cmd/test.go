@@ -33,6 +33,21 @@ var (
 
   Run a Kubernetes control plane and install manifests and CRDs for the running tests:
     kubectl kuttl test --start-control-plane  --crd-dir ./config/crds/ --manifests-dir ./test/manifests/ ./test/integration/
+
+  Print the test cases and steps which would run without connecting to a cluster:
+    kubectl kuttl test --dry-run ./test/integration/
+
+  List the test cases and steps as JSON:
+    kubectl kuttl test --list --list-format json ./test/integration/
+
+  Run only step 7 of a test case in the namespace left over by a previous run:
+    kubectl kuttl test --test my-test --step 7 --namespace my-namespace --skip-delete ./test/integration/
+
+  Run the tests inside the cluster split into 4 Jobs and merge their reports:
+    kubectl kuttl test --remote --remote-cluster-role cluster-admin --shard-count 4 --report xml ./test/integration/
+
+  Render the test files as templates with values from a file and the command line:
+    kubectl kuttl test --template --values values.yaml --set version=1.2.0 ./test/integration/
 `
 )
 
@@ -46,6 +61,8 @@ func newTestCmd() *cobra.Command {
 	startControlPlane := false
 	startKIND := false
 	kindConfig := ""
+	clusterProvider := ""
+	kubernetesVersions := []string{}
 	kindContext := ""
 	skipDelete := false
 	skipClusterDelete := false
@@ -56,6 +73,52 @@ func newTestCmd() *cobra.Command {
 	reportFormat := ""
 	namespace := ""
 	suppress := []string{}
+	template := false
+	valuesFiles := []string{}
+	setValues := []string{}
+	updateGolden := false
+	record := false
+	validateManifests := false
+	strictApply := false
+	sops := false
+	dryRun := false
+	logFormat := ""
+	streamLogs := false
+	noColor := false
+	tags := []string{}
+	skipTags := []string{}
+	retries := 0
+	historyFile := ""
+	quarantine := false
+	suiteTimeout := 0
+	testTimeout := 0
+	failedFile := ""
+	rerunFailed := false
+	resume := false
+	failFast := false
+	maxFailures := 0
+	namespacePool := 0
+	shardIndex := 0
+	shardCount := 0
+	remote := false
+	remoteImage := ""
+	remoteNamespace := ""
+	remoteServiceAccount := ""
+	remoteClusterRole := ""
+	qps := 0
+	cachedKinds := []string{}
+	burst := 0
+	keepOnFailure := false
+	pauseOnFailure := 0
+	debugShell := false
+	namespaceTemplate := ""
+	namespaceLabels := map[string]string{}
+	namespaceAnnotations := map[string]string{}
+	step := -1
+	fromStep := -1
+	toStep := -1
+	list := false
+	listFormat := ""
 
 	options := harness.TestSuite{}
 
@@ -131,6 +194,11 @@ For more detailed documentation, visit: https://kudo.dev/docs/testing`,
 				options.KINDConfig = kindConfig
 			}
 
+			if isSet(flags, "cluster-provider") {
+				options.StartKIND = true
+				options.ClusterProvider = clusterProvider
+			}
+
 			if isSet(flags, "kind-context") {
 				options.KINDContext = kindContext
 			}
@@ -143,6 +211,10 @@ For more detailed documentation, visit: https://kudo.dev/docs/testing`,
 				return errors.New("only one of --start-control-plane and --start-kind can be set")
 			}
 
+			if options.Provision != nil && (options.StartControlPlane || options.StartKIND) {
+				return errors.New("provision can not be used with --start-control-plane or --start-kind")
+			}
+
 			if isSet(flags, "skip-delete") {
 				options.SkipDelete = skipDelete
 			}
@@ -151,6 +223,18 @@ For more detailed documentation, visit: https://kudo.dev/docs/testing`,
 				options.SkipClusterDelete = skipClusterDelete
 			}
 
+			if isSet(flags, "keep-on-failure") {
+				options.KeepOnFailure = keepOnFailure
+			}
+
+			if isSet(flags, "pause-on-failure") {
+				options.PauseOnFailure = pauseOnFailure
+			}
+
+			if isSet(flags, "debug-shell") {
+				options.DebugShell = debugShell
+			}
+
 			if isSet(flags, "parallel") {
 				options.Parallel = parallel
 			}
@@ -181,6 +265,213 @@ For more detailed documentation, visit: https://kudo.dev/docs/testing`,
 				options.Timeout = timeout
 			}
 
+			if isSet(flags, "template") {
+				options.Template = template
+			}
+
+			if isSet(flags, "update-golden") {
+				options.UpdateGolden = updateGolden
+			}
+
+			if isSet(flags, "record") {
+				options.Record = record
+			}
+
+			if isSet(flags, "validate-manifests") {
+				options.ValidateManifests = validateManifests
+			}
+
+			if isSet(flags, "strict-apply") {
+				options.StrictApply = strictApply
+			}
+
+			if isSet(flags, "sops") {
+				options.Sops = sops
+			}
+
+			if isSet(flags, "log-format") {
+				options.LogFormat = strings.ToLower(logFormat)
+			}
+
+			if options.LogFormat != "" && options.LogFormat != "text" && options.LogFormat != harness.LogFormatJSON {
+				return fmt.Errorf("unknown log format %q, must be text or json", options.LogFormat)
+			}
+
+			if isSet(flags, "stream-logs") {
+				options.StreamLogs = streamLogs
+			}
+
+			if isSet(flags, "no-color") {
+				options.NoColor = noColor
+			} else if _, ok := os.LookupEnv("NO_COLOR"); ok {
+				options.NoColor = true
+			}
+
+			if isSet(flags, "tags") {
+				options.Tags = tags
+			}
+
+			if isSet(flags, "skip-tags") {
+				options.SkipTags = skipTags
+			}
+
+			if isSet(flags, "retries") {
+				options.Retries = retries
+			}
+
+			if isSet(flags, "history-file") {
+				options.HistoryFile = historyFile
+			}
+
+			if isSet(flags, "quarantine") {
+				options.Quarantine = quarantine
+			}
+
+			if options.Quarantine && options.HistoryFile == "" {
+				return errors.New("--quarantine requires a --history-file")
+			}
+
+			if isSet(flags, "suite-timeout") {
+				options.SuiteTimeout = suiteTimeout
+			}
+
+			if isSet(flags, "test-timeout") {
+				options.TestTimeout = testTimeout
+			}
+
+			if isSet(flags, "failed-file") {
+				options.FailedFile = failedFile
+			}
+
+			if isSet(flags, "rerun-failed") {
+				options.RerunFailed = rerunFailed
+			}
+
+			if options.RerunFailed && options.FailedFile == "" {
+				return errors.New("--rerun-failed requires a --failed-file")
+			}
+
+			if isSet(flags, "resume") {
+				options.Resume = resume
+			}
+
+			if isSet(flags, "fail-fast") {
+				options.FailFast = failFast
+			}
+
+			if isSet(flags, "max-failures") {
+				if maxFailures < 0 {
+					return errors.New("--max-failures must not be negative")
+				}
+				options.MaxFailures = maxFailures
+			}
+
+			if isSet(flags, "namespace-template") {
+				options.NamespaceTemplate = namespaceTemplate
+			}
+
+			if isSet(flags, "namespace-label") {
+				options.NamespaceLabels = namespaceLabels
+			}
+
+			if isSet(flags, "namespace-annotation") {
+				options.NamespaceAnnotations = namespaceAnnotations
+			}
+
+			if isSet(flags, "namespace-pool") {
+				if namespacePool < 0 {
+					return errors.New("--namespace-pool must not be negative")
+				}
+				options.NamespacePool = namespacePool
+			}
+
+			if isSet(flags, "shard-count") {
+				options.ShardCount = shardCount
+			}
+
+			if isSet(flags, "shard-index") {
+				options.ShardIndex = shardIndex
+			}
+
+			if options.ShardCount < 0 || options.ShardIndex < 0 || (options.ShardCount > 0 && options.ShardIndex >= options.ShardCount) {
+				return fmt.Errorf("invalid shard %d of %d shards: the shard index must be less than the shard count", options.ShardIndex, options.ShardCount)
+			}
+
+			if isSet(flags, "remote") {
+				options.Remote = remote
+			}
+
+			if isSet(flags, "remote-image") {
+				options.RemoteImage = remoteImage
+			}
+
+			if isSet(flags, "remote-namespace") {
+				options.RemoteNamespace = remoteNamespace
+			}
+
+			if isSet(flags, "remote-service-account") {
+				options.RemoteServiceAccount = remoteServiceAccount
+			}
+
+			if isSet(flags, "remote-cluster-role") {
+				options.RemoteClusterRole = remoteClusterRole
+			}
+
+			if options.Remote && options.StartControlPlane {
+				return errors.New("--remote can not be used with --start-control-plane")
+			}
+
+			if isSet(flags, "kubernetes-version") {
+				options.KubernetesVersions = kubernetesVersions
+			}
+
+			if options.Remote && len(options.KubernetesVersions) > 0 {
+				return errors.New("--remote can not be used with kubernetes versions")
+			}
+
+			if isSet(flags, "cached-kind") {
+				options.CachedKinds = cachedKinds
+			}
+
+			if isSet(flags, "qps") {
+				if qps < 0 {
+					return errors.New("--qps must not be negative")
+				}
+				options.QPS = qps
+			}
+
+			if isSet(flags, "burst") {
+				if burst < 0 {
+					return errors.New("--burst must not be negative")
+				}
+				options.Burst = burst
+			}
+
+			if isSet(flags, "step") && (isSet(flags, "from-step") || isSet(flags, "to-step")) {
+				return errors.New("--step can not be set with --from-step or --to-step")
+			}
+
+			if isSet(flags, "step") {
+				options.FromStep = &step
+				options.ToStep = &step
+			}
+
+			if isSet(flags, "from-step") {
+				options.FromStep = &fromStep
+			}
+
+			if isSet(flags, "to-step") {
+				options.ToStep = &toStep
+			}
+
+			if options.FromStep != nil && options.ToStep != nil && *options.FromStep > *options.ToStep {
+				return fmt.Errorf("--from-step %d is after --to-step %d", *options.FromStep, *options.ToStep)
+			}
+
+			if err := setTestValues(&options, valuesFiles, setValues); err != nil {
+				return err
+			}
+
 			if len(args) != 0 {
 				options.TestDirs = args
 			}
@@ -203,12 +494,36 @@ For more detailed documentation, visit: https://kudo.dev/docs/testing`,
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
+			if list {
+				harness := test.Harness{TestSuite: options}
+				if err := harness.List(cmd.OutOrStdout(), testToRun, test.ListFormat(strings.ToLower(listFormat))); err != nil {
+					log.Fatal(err)
+				}
+				return
+			}
+
+			if dryRun {
+				harness := test.Harness{TestSuite: options}
+				if err := harness.Plan(cmd.OutOrStdout(), testToRun); err != nil {
+					log.Fatal(err)
+				}
+				return
+			}
+
 			testutils.RunTests("kuttl", testToRun, options.Parallel, func(t *testing.T) {
 				harness := test.Harness{
 					TestSuite: options,
 					T:         t,
 				}
 
+				if options.Remote {
+					harness.RunRemote()
+					return
+				}
+				if len(options.KubernetesVersions) > 0 {
+					harness.RunKubernetesVersions()
+					return
+				}
 				harness.Run()
 			})
 		},
@@ -222,16 +537,64 @@ For more detailed documentation, visit: https://kudo.dev/docs/testing`,
 	testCmd.Flags().StringVar(&mockControllerFile, "control-plane-config", "", "Path to file to load controller-runtime APIServer configuration arguments (only useful when --startControlPlane).")
 	testCmd.Flags().BoolVar(&startKIND, "start-kind", false, "Start a KIND cluster for the tests (cannot be used with --start-control-plane).")
 	testCmd.Flags().StringVar(&kindConfig, "kind-config", "", "Specify the KIND configuration file path (implies --start-kind, cannot be used with --start-control-plane).")
+	testCmd.Flags().StringVar(&clusterProvider, "cluster-provider", "", "Specify the provider of the local cluster: kind, k3d or minikube (implies --start-kind, default: kind).")
+	testCmd.Flags().StringSliceVar(&kubernetesVersions, "kubernetes-version", []string{}, "Kubernetes versions to run the test suite against one after the other, with --start-control-plane, --start-kind or a provisioned cluster (can be specified multiple times).")
 	testCmd.Flags().StringVar(&kindContext, "kind-context", "", "Specify the KIND context name to use (default: kind).")
 	testCmd.Flags().StringVar(&artifactsDir, "artifacts-dir", "", "Directory to output kind logs to (if not specified, the current working directory).")
+	testCmd.Flags().BoolVar(&keepOnFailure, "keep-on-failure", false, "If set, do not delete the resources and namespaces of failed tests, and the cluster if any test failed.")
+	testCmd.Flags().IntVar(&pauseOnFailure, "pause-on-failure", 0, "The time in seconds a failed test pauses before its resources are cleaned up or kept (0 means no pause). On an interactive terminal, press enter to end the pause.")
+	testCmd.Flags().BoolVar(&debugShell, "debug-shell", false, "If set, a failed test starts an interactive shell with KUBECONFIG and NAMESPACE set before its resources are cleaned up or kept.")
 	testCmd.Flags().BoolVar(&skipDelete, "skip-delete", false, "If set, do not delete resources created during tests (helpful for debugging test failures, implies --skip-cluster-delete).")
 	testCmd.Flags().BoolVar(&skipClusterDelete, "skip-cluster-delete", false, "If set, do not delete the mocked control plane or kind cluster.")
 	// The default value here is only used for the help message. The default is actually enforced in RunTests.
 	testCmd.Flags().IntVar(&parallel, "parallel", 8, "The maximum number of tests to run at once.")
+	testCmd.Flags().IntVar(&qps, "qps", 20, "The maximum number of requests per second to the Kubernetes API server, shared by all tests.")
+	testCmd.Flags().IntVar(&burst, "burst", 30, "The maximum number of requests to the Kubernetes API server sent at once above --qps.")
+	testCmd.Flags().IntVar(&shardCount, "shard-count", 0, "The number of shards the tests are split into, e.g. to run them on several CI workers (0 means all tests are run).")
+	testCmd.Flags().IntVar(&shardIndex, "shard-index", 0, "The shard of the tests which is run, from 0 to --shard-count - 1.")
+	testCmd.Flags().BoolVar(&remote, "remote", false, "If set, the tests are run inside the cluster by a Job for each shard, their logs are streamed back and their reports are merged.")
+	testCmd.Flags().StringVar(&remoteImage, "remote-image", "kudobuilder/kuttl", "The image of the Jobs of --remote, it must contain kubectl-kuttl, sh, tar and base64.")
+	testCmd.Flags().StringVar(&remoteNamespace, "remote-namespace", "kuttl-runner", "The namespace of the Jobs of --remote, it is created if it does not exist.")
+	testCmd.Flags().StringVar(&remoteServiceAccount, "remote-service-account", "kuttl-runner", "The service account of the Jobs of --remote, it must exist unless --remote-cluster-role is set.")
+	testCmd.Flags().StringVar(&remoteClusterRole, "remote-cluster-role", "", "If set, the service account of the Jobs of --remote is created and bound to this ClusterRole, e.g. cluster-admin, and deleted after the run.")
+	testCmd.Flags().StringSliceVar(&cachedKinds, "cached-kind", []string{}, "Kinds of objects which the asserts read from shared informers instead of the API server (e.g. Pod), may be specified multiple times.")
 	testCmd.Flags().IntVar(&timeout, "timeout", 30, "The timeout to use as default for TestSuite configuration.")
-	testCmd.Flags().StringVar(&reportFormat, "report", "", "Specify JSON|XML for report.  Report location determined by --artifacts-dir.")
+	testCmd.Flags().StringVar(&reportFormat, "report", "", "Specify JSON|XML|TAP|HTML|Allure for report.  Report location determined by --artifacts-dir.")
 	testCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace to use for tests. Provided namespaces must exist prior to running tests.")
-	testCmd.Flags().StringSliceVar(&suppress, "suppress-log", []string{}, "Suppress logging for these kinds of logs (events).")
+	testCmd.Flags().BoolVar(&template, "template", false, "If set, test step files are rendered as go templates with the namespace, values and environment variables.")
+	testCmd.Flags().StringArrayVar(&valuesFiles, "values", []string{}, "YAML files with values for the test templates and TestStep paths, later files take precedence (can be repeated).")
+	testCmd.Flags().StringArrayVar(&setValues, "set", []string{}, "A key=value pair for the test templates and TestStep paths, takes precedence over values files (can be repeated).")
+	testCmd.Flags().BoolVar(&updateGolden, "update-golden", false, "If set, the golden files of the asserts are rewritten from the cluster instead of compared.")
+	testCmd.Flags().BoolVar(&record, "record", false, "If set, the objects applied by each test step are written to an assert file of the step unless it already exists.")
+	testCmd.Flags().BoolVar(&validateManifests, "validate-manifests", false, "If set, the objects of the test steps are validated with a server-side dry run before they are applied.")
+	testCmd.Flags().BoolVar(&strictApply, "strict-apply", false, "If set, test steps fail instead of updating objects which exist with different content managed by others.")
+	testCmd.Flags().BoolVar(&sops, "sops", false, "If set, sops-encrypted manifests are decrypted with the sops binary when they are loaded.")
+	testCmd.Flags().BoolVar(&dryRun, "dry-run", false, "If set, the test cases and steps which would run are printed without connecting to a cluster.")
+	testCmd.Flags().StringVar(&logFormat, "log-format", "text", "Specify text|json for the test logs. JSON logs are written to stdout as they happen.")
+	testCmd.Flags().StringSliceVar(&tags, "tags", []string{}, "Only run the tests with any of these tags of their kuttl-case.yaml file.")
+	testCmd.Flags().StringSliceVar(&skipTags, "skip-tags", []string{}, "Skip the tests with any of these tags of their kuttl-case.yaml file.")
+	testCmd.Flags().IntVar(&retries, "retries", 0, "The number of times a failed test is rerun from its first step, tests which pass on a retry are reported as flaky.")
+	testCmd.Flags().StringVar(&historyFile, "history-file", "", "A JSON file the recent results of the tests are recorded in, e.g. to be kept in a CI cache.")
+	testCmd.Flags().BoolVar(&quarantine, "quarantine", false, "If set, the failures of tests which were flaky in the recent runs of the --history-file do not fail the run.")
+	testCmd.Flags().BoolVar(&noColor, "no-color", false, "If set, the diffs of failed asserts are not colorized. Also set by the NO_COLOR environment variable.")
+	testCmd.Flags().BoolVar(&streamLogs, "stream-logs", false, "If set, the test logs are written to stdout as they happen, prefixed with the test and step, instead of when each test finished.")
+	testCmd.Flags().IntVar(&suiteTimeout, "suite-timeout", 0, "The time in seconds all tests may take, running tests are cancelled and reported as timed out when it expires (0 means no limit).")
+	testCmd.Flags().IntVar(&testTimeout, "test-timeout", 0, "The time in seconds each test may take, unlike --timeout which applies to the asserts of each step (0 means no limit).")
+	testCmd.Flags().StringVar(&failedFile, "failed-file", "", "A JSON file the failed tests of the run are saved to, e.g. to run them again with --rerun-failed.")
+	testCmd.Flags().BoolVar(&rerunFailed, "rerun-failed", false, "If set, only the tests which failed in the previous run, as saved to the --failed-file, are run.")
+	testCmd.Flags().BoolVar(&resume, "resume", false, "If set, the progress of the run is saved to .kuttl/progress.json and an interrupted run with --resume is resumed, the tests which passed are skipped and its KIND cluster, which is kept on interrupt, is re-used.")
+	testCmd.Flags().BoolVar(&failFast, "fail-fast", false, "If set, the tests which have not started yet are skipped after the first failed test.")
+	testCmd.Flags().IntVar(&maxFailures, "max-failures", 0, "The number of failed tests after which the tests which have not started yet are skipped (0 means no limit).")
+	testCmd.Flags().StringVar(&namespaceTemplate, "namespace-template", "", "The go template of the generated test namespace names with .Test and .Random, e.g. \"e2e-{{ .Test }}-{{ .Random }}\".")
+	testCmd.Flags().StringToStringVar(&namespaceLabels, "namespace-label", map[string]string{}, "A key=value label added to the namespaces created for the tests (can be repeated).")
+	testCmd.Flags().StringToStringVar(&namespaceAnnotations, "namespace-annotation", map[string]string{}, "A key=value annotation added to the namespaces created for the tests (can be repeated).")
+	testCmd.Flags().IntVar(&namespacePool, "namespace-pool", 0, "The number of namespaces created before the tests and reused by them, their objects are deleted between tests (0 means a namespace per test).")
+	testCmd.Flags().IntVar(&step, "step", -1, "If set, only the test step with this index is run (the cluster is assumed to be in the state of the previous steps).")
+	testCmd.Flags().IntVar(&fromStep, "from-step", -1, "If set, the test steps before this index are skipped (the cluster is assumed to be in the state of the skipped steps).")
+	testCmd.Flags().IntVar(&toStep, "to-step", -1, "If set, the test steps after this index are skipped.")
+	testCmd.Flags().BoolVar(&list, "list", false, "If set, the test cases and their steps are listed without running them.")
+	testCmd.Flags().StringVar(&listFormat, "list-format", string(test.ListTable), "Specify table|json for the output of --list.")
+	testCmd.Flags().StringSliceVar(&suppress, "suppress-log", []string{}, "Suppress logging for these kinds of logs (events). Suppressing events also disables the event collection on step failure.")
 	// This cannot be a global flag because pkg/test/utils.RunTests calls flag.Parse which barfs on unknown top-level flags.
 	// Putting it here at least does not advertise it on a level where using it is impossible.
 	test.SetFlags(testCmd.Flags())
@@ -239,11 +602,48 @@ For more detailed documentation, visit: https://kudo.dev/docs/testing`,
 	return testCmd
 }
 
+// setTestValues merges the values of values files and key=value pairs into the values of the test suite.
+func setTestValues(options *harness.TestSuite, valuesFiles, setValues []string) error {
+	if len(valuesFiles) == 0 && len(setValues) == 0 {
+		return nil
+	}
+
+	if options.Values == nil {
+		options.Values = map[string]string{}
+	}
+
+	for _, file := range valuesFiles {
+		values, err := test.LoadValues(file)
+		if err != nil {
+			return err
+		}
+		for key, value := range values {
+			options.Values[key] = value
+		}
+	}
+
+	for _, set := range setValues {
+		kv := strings.SplitN(set, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return fmt.Errorf("invalid value %q, must be key=value", set)
+		}
+		options.Values[kv[0]] = kv[1]
+	}
+
+	return nil
+}
+
 func reportType(ftype report.Type) string {
 	switch ftype {
 	case report.JSON:
 		fallthrough
 	case report.XML:
+		fallthrough
+	case report.TAP:
+		fallthrough
+	case report.HTML:
+		fallthrough
+	case report.Allure:
 		return string(ftype)
 	default:
 		return ""
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+)
+
+func TestSetTestValues(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-values")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	valuesFile := filepath.Join(dir, "values.yaml")
+	assert.NoError(t, ioutil.WriteFile(valuesFile, []byte("version: 1.2.0\nreplicas: 3\nimage: nginx\n"), 0600))
+	nestedFile := filepath.Join(dir, "nested.yaml")
+	assert.NoError(t, ioutil.WriteFile(nestedFile, []byte("image:\n  tag: latest\n"), 0600))
+
+	options := harness.TestSuite{Values: map[string]string{"version": "1.0.0", "name": "suite"}}
+	assert.NoError(t, setTestValues(&options, []string{valuesFile}, []string{"image=nginx:1.7.9", "empty="}))
+	assert.Equal(t, map[string]string{
+		"name":     "suite",
+		"version":  "1.2.0",
+		"replicas": "3",
+		"image":    "nginx:1.7.9",
+		"empty":    "",
+	}, options.Values)
+
+	assert.Error(t, setTestValues(&harness.TestSuite{}, nil, []string{"novalue"}))
+	assert.Error(t, setTestValues(&harness.TestSuite{}, []string{nestedFile}, nil))
+	assert.Error(t, setTestValues(&harness.TestSuite{}, []string{filepath.Join(dir, "missing.yaml")}, nil))
+}
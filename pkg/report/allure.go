@@ -0,0 +1,214 @@
+package report
+
+import (
+	"crypto/md5" //nolint:gosec
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// AllureResultsDir is the directory of the artifacts directory the allure results are written to.
+const AllureResultsDir = "allure-results"
+
+// The structs below define the allure result files, see https://github.com/allure-framework/allure2.
+// A result file "<uuid>-result.json" is written per test case, its test steps are allure steps.
+
+// allureResult is the result of a test case.
+type allureResult struct {
+	UUID          string         `json:"uuid"`
+	HistoryID     string         `json:"historyId"`
+	TestCaseID    string         `json:"testCaseId"`
+	FullName      string         `json:"fullName"`
+	Name          string         `json:"name"`
+	Status        string         `json:"status"`
+	StatusDetails *allureDetails `json:"statusDetails,omitempty"`
+	Stage         string         `json:"stage"`
+	Start         int64          `json:"start"`
+	Stop          int64          `json:"stop"`
+	Labels        []allureLabel  `json:"labels"`
+	Steps         []allureStep   `json:"steps"`
+}
+
+// allureStep is a test step of a test case.
+type allureStep struct {
+	Name          string             `json:"name"`
+	Status        string             `json:"status"`
+	StatusDetails *allureDetails     `json:"statusDetails,omitempty"`
+	Stage         string             `json:"stage"`
+	Start         int64              `json:"start"`
+	Stop          int64              `json:"stop"`
+	Attachments   []allureAttachment `json:"attachments,omitempty"`
+}
+
+// allureDetails are the details of the status of a failed test case or step.
+type allureDetails struct {
+	Message string `json:"message,omitempty"`
+	Trace   string `json:"trace,omitempty"`
+	Flaky   bool   `json:"flaky,omitempty"`
+}
+
+// allureLabel is a label of a test case, e.g. the suite or a tag.
+type allureLabel struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// allureAttachment is a file of the results directory attached to a step.
+type allureAttachment struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Type   string `json:"type"`
+}
+
+// WriteAllure writes closed TestSuites as allure results to a directory.  The output of the test steps and the
+// files attached to them are written to the directory as allure attachments.
+func WriteAllure(dir string, ts *Testsuites) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, testsuite := range ts.Testsuite {
+		for _, testcase := range testsuite.Testcase {
+			if testcase.step {
+				continue
+			}
+			if err := writeAllureResult(dir, testsuite, testcase); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeAllureResult writes the result file of a test case with the attachments of its steps.
+func writeAllureResult(dir string, testsuite *Testsuite, testcase *Testcase) error {
+	suite := filepath.Base(testsuite.Name)
+	fullName := fmt.Sprintf("%s/%s", suite, testcase.Name)
+	//nolint:gosec
+	history := fmt.Sprintf("%x", md5.Sum([]byte(fullName)))
+
+	result := allureResult{
+		UUID:          newUUID(),
+		HistoryID:     history,
+		TestCaseID:    history,
+		FullName:      fullName,
+		Name:          testcase.Name,
+		Status:        allureStatus(testcase),
+		StatusDetails: allureStatusDetails(testcase),
+		Stage:         "finished",
+		Start:         millis(testcase.start),
+		Stop:          millis(testcase.end),
+		Labels: []allureLabel{
+			{Name: "framework", Value: "kuttl"},
+			{Name: "parentSuite", Value: testsuite.Name},
+			{Name: "suite", Value: suite},
+			{Name: "testClass", Value: testcase.Classname},
+		},
+		Steps: []allureStep{},
+	}
+	for _, tag := range testcase.Tags {
+		result.Labels = append(result.Labels, allureLabel{Name: "tag", Value: tag})
+	}
+
+	for _, step := range testcase.steps {
+		s := allureStep{
+			Name:          strings.TrimPrefix(step.Name, testcase.Name+"/"),
+			Status:        allureStatus(step),
+			StatusDetails: allureStatusDetails(step),
+			Stage:         "finished",
+			Start:         millis(step.start),
+			Stop:          millis(step.end),
+		}
+
+		if step.Output != "" {
+			attachment, err := writeAllureAttachment(dir, "output", "text/plain", ".txt", []byte(step.Output))
+			if err != nil {
+				return err
+			}
+			s.Attachments = append(s.Attachments, attachment)
+		}
+
+		for _, path := range step.Attachments {
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				// the artifacts of the step may have been removed, e.g. with the test directory
+				continue
+			}
+			attachment, err := writeAllureAttachment(dir, filepath.Base(path), "text/yaml", filepath.Ext(path), content)
+			if err != nil {
+				return err
+			}
+			s.Attachments = append(s.Attachments, attachment)
+		}
+
+		result.Steps = append(result.Steps, s)
+	}
+
+	content, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	//nolint:gosec
+	return ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("%s-result.json", result.UUID)), content, 0644)
+}
+
+// writeAllureAttachment writes an attachment file "<uuid>-attachment<ext>" to the results directory.
+func writeAllureAttachment(dir, name, mimeType, ext string, content []byte) (allureAttachment, error) {
+	source := fmt.Sprintf("%s-attachment%s", newUUID(), ext)
+	//nolint:gosec
+	if err := ioutil.WriteFile(filepath.Join(dir, source), content, 0644); err != nil {
+		return allureAttachment{}, err
+	}
+	return allureAttachment{Name: name, Source: source, Type: mimeType}, nil
+}
+
+// allureStatus returns the allure status of a testcase, timed out testcases are broken.
+func allureStatus(testcase *Testcase) string {
+	switch {
+	case testcase.Failure != nil && testcase.Failure.Type == TimeoutFailure:
+		return "broken"
+	case testcase.Failure != nil:
+		return "failed"
+	case testcase.Skipped != nil:
+		return "skipped"
+	default:
+		return "passed"
+	}
+}
+
+// allureStatusDetails returns the failure or skip reason of a testcase, passed testcases which were retried are flaky.
+func allureStatusDetails(testcase *Testcase) *allureDetails {
+	switch {
+	case testcase.Failure != nil:
+		return &allureDetails{Message: testcase.Failure.Message, Trace: testcase.Failure.Text}
+	case testcase.Skipped != nil:
+		return &allureDetails{Message: testcase.Skipped.Message}
+	case len(testcase.retries) > 0:
+		return &allureDetails{Message: testcase.retries[len(testcase.retries)-1].Message, Flaky: true}
+	default:
+		return nil
+	}
+}
+
+// millis returns a time in milliseconds since the epoch.
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// newUUID returns a random version 4 UUID.
+func newUUID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func writeAllureReport(dir string, ts *Testsuites) error {
+	return WriteAllure(filepath.Join(dir, AllureResultsDir), ts)
+}
@@ -0,0 +1,132 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// htmlTemplate renders a JSONReport as a single page without external resources.  Failed test cases and steps are
+// expanded, their failure text (e.g. the diff of a failed assert) and the output of the steps are inlined.
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"lines": diffLines,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ if .Name }}{{ .Name }}{{ else }}kuttl{{ end }} test report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+td, th { padding: 0.2em 0.8em; text-align: left; }
+details { margin: 0.3em 0 0.3em 1.5em; }
+summary { cursor: pointer; }
+pre { background: #f6f8fa; padding: 0.5em; overflow-x: auto; }
+.passed { color: #1a7f37; }
+.failed { color: #cf222e; font-weight: bold; }
+.skipped { color: #6e7781; }
+.flaky { color: #9a6700; font-weight: bold; }
+.add { color: #1a7f37; }
+.del { color: #cf222e; }
+.hunk { color: #0969da; }
+</style>
+</head>
+<body>
+<h1>{{ if .Name }}{{ .Name }}{{ else }}kuttl{{ end }} test report</h1>
+<table>
+<tr><th>Tests</th><td>{{ .Tests }}</td></tr>
+<tr><th>Failures</th><td>{{ .Failures }}</td></tr>
+<tr><th>Flaky</th><td>{{ .Flaky }}</td></tr>
+<tr><th>Time</th><td>{{ printf "%.3f" .Time }}s</td></tr>
+{{- range $name, $value := .Properties }}
+<tr><th>{{ $name }}</th><td>{{ $value }}</td></tr>
+{{- end }}
+</table>
+{{- range .Suites }}
+<h2>{{ .Name }}</h2>
+<p>{{ .Tests }} tests, {{ .Failures }} failures, {{ .Flaky }} flaky, {{ printf "%.3f" .Time }}s</p>
+{{- range .Cases }}
+<details{{ if eq .Status "failed" }} open{{ end }}>
+<summary><span class="{{ .Status }}">{{ .Status }}</span> {{ .Name }} ({{ printf "%.3f" .Time }}s)</summary>
+{{- with .Failure }}
+<p class="failed">{{ .Message }}</p>
+{{- end }}
+{{- range .Retries }}
+<p class="skipped">retried: {{ .Message }}</p>
+{{- end }}
+{{- range .Steps }}
+<details{{ if eq .Status "failed" }} open{{ end }}>
+<summary><span class="{{ .Status }}">{{ .Status }}</span> {{ .Name }} ({{ printf "%.3f" .Time }}s){{ if .SkipReason }} {{ .SkipReason }}{{ end }}</summary>
+{{- with .Failure }}
+<p class="failed">{{ .Message }}</p>
+{{- if .Text }}
+<pre>{{ range lines .Text }}<span class="{{ .Class }}">{{ .Text }}</span>
+{{ end }}</pre>
+{{- end }}
+{{- end }}
+{{- if .Attachments }}
+<ul>
+{{- range .Attachments }}
+<li><a href="{{ . }}">{{ . }}</a></li>
+{{- end }}
+</ul>
+{{- end }}
+{{- if .Output }}
+<details>
+<summary>output</summary>
+<pre>{{ .Output }}</pre>
+</details>
+{{- end }}
+</details>
+{{- end }}
+</details>
+{{- end }}
+{{- end }}
+</body>
+</html>
+`))
+
+// diffLine is a line of a failure text with the class it is highlighted with.
+type diffLine struct {
+	Class string
+	Text  string
+}
+
+// diffLines splits a failure text into lines, the lines of a unified diff are highlighted as added or removed.
+func diffLines(text string) []diffLine {
+	lines := []diffLine{}
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		class := ""
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+		case strings.HasPrefix(line, "@@"):
+			class = "hunk"
+		case strings.HasPrefix(line, "-"):
+			class = "del"
+		case strings.HasPrefix(line, "+"):
+			class = "add"
+		}
+		lines = append(lines, diffLine{Class: class, Text: line})
+	}
+	return lines
+}
+
+// WriteHTML writes closed TestSuites as a browsable html page with a summary per test case and test step.
+func WriteHTML(w io.Writer, ts *Testsuites) error {
+	return htmlTemplate.Execute(w, NewJSONReport(ts))
+}
+
+func writeHTMLReport(dir, name string, ts *Testsuites) error {
+	out := &bytes.Buffer{}
+	if err := WriteHTML(out, ts); err != nil {
+		return err
+	}
+
+	file := filepath.Join(dir, fmt.Sprintf("%s.html", name))
+	//nolint:gosec
+	return ioutil.WriteFile(file, out.Bytes(), 0644)
+}
@@ -0,0 +1,179 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+)
+
+// JSONReportVersion is the version of the schema of JSONReport, it changes with incompatible changes of the schema.
+const JSONReportVersion = "v1"
+
+// Status is the result of a test case or test step in a JSONReport
+type Status string
+
+const (
+	// StatusPassed is the Status of a test case or test step which succeeded
+	StatusPassed Status = "passed"
+	// StatusFailed is the Status of a test case or test step which failed
+	StatusFailed Status = "failed"
+	// StatusFlaky is the Status of a test case which failed and passed on a retry
+	StatusFlaky Status = "flaky"
+	// StatusSkipped is the Status of a test case or test step which did not run
+	StatusSkipped Status = "skipped"
+)
+
+// JSONReport is the json report of all test suites.  Unlike the junit structs, the test steps are nested in their
+// test case and the times are in seconds.  The schema is stable within a JSONReportVersion.
+type JSONReport struct {
+	Version  string `json:"version"`
+	Name     string `json:"name"`
+	Tests    int    `json:"tests"`
+	Failures int    `json:"failures"`
+	// Flaky is the number of test cases which passed on a retry
+	Flaky int `json:"flaky"`
+	// Time is the elapsed time of the entire suite of tests in seconds
+	Time       float64           `json:"time"`
+	Properties map[string]string `json:"properties,omitempty"`
+	Suites     []JSONSuite       `json:"suites"`
+}
+
+// JSONSuite is a test suite of a JSONReport
+type JSONSuite struct {
+	Name     string `json:"name"`
+	Tests    int    `json:"tests"`
+	Failures int    `json:"failures"`
+	Flaky    int    `json:"flaky"`
+	// Time is the elapsed time between the start of the suite and the end of its latest test case in seconds
+	Time       float64           `json:"time"`
+	Properties map[string]string `json:"properties,omitempty"`
+	Cases      []JSONCase        `json:"cases"`
+}
+
+// JSONCase is a test case of a JSONReport
+type JSONCase struct {
+	Name       string  `json:"name"`
+	Status     Status  `json:"status"`
+	Time       float64 `json:"time"`
+	Assertions int     `json:"assertions"`
+	// Failure is the failure of the last attempt of a failed test case
+	Failure *Failure `json:"failure,omitempty"`
+	// Retries are the failures of the retried attempts of the test case
+	Retries []*Failure `json:"retries,omitempty"`
+	Steps   []JSONStep `json:"steps"`
+}
+
+// JSONStep is a test step of a test case of a JSONReport
+type JSONStep struct {
+	// Name is the name of the step, e.g. "1-create"
+	Name        string   `json:"name"`
+	Status      Status   `json:"status"`
+	Time        float64  `json:"time"`
+	Assertions  int      `json:"assertions"`
+	Failure     *Failure `json:"failure,omitempty"`
+	SkipReason  string   `json:"skipReason,omitempty"`
+	Attachments []string `json:"attachments,omitempty"`
+	// Output is the log output of the step including the namespace events and collector output of a failed step
+	Output string `json:"output,omitempty"`
+}
+
+// NewJSONReport creates the json report of closed TestSuites
+func NewJSONReport(ts *Testsuites) *JSONReport {
+	r := &JSONReport{
+		Version:    JSONReportVersion,
+		Name:       ts.Name,
+		Time:       seconds(ts.Time),
+		Properties: propertyMap(ts.Properties),
+		Suites:     []JSONSuite{},
+	}
+
+	for _, testsuite := range ts.Testsuite {
+		suite := JSONSuite{
+			Name:       testsuite.Name,
+			Time:       seconds(testsuite.Time),
+			Properties: propertyMap(testsuite.Properties),
+			Cases:      []JSONCase{},
+		}
+
+		// the testcases of the steps follow their test case in the testsuite and are nested here
+		for _, testcase := range testsuite.Testcase {
+			if testcase.step {
+				continue
+			}
+
+			c := JSONCase{
+				Name:       testcase.Name,
+				Status:     status(testcase),
+				Time:       seconds(testcase.Time),
+				Assertions: testcase.Assertions,
+				Failure:    testcase.Failure,
+				Retries:    testcase.retries,
+				Steps:      []JSONStep{},
+			}
+
+			for _, step := range testcase.steps {
+				s := JSONStep{
+					Name:        strings.TrimPrefix(step.Name, testcase.Name+"/"),
+					Status:      status(step),
+					Time:        seconds(step.Time),
+					Assertions:  step.Assertions,
+					Failure:     step.Failure,
+					Attachments: step.Attachments,
+					Output:      step.Output,
+				}
+				if step.Skipped != nil {
+					s.SkipReason = step.Skipped.Message
+				}
+				c.Steps = append(c.Steps, s)
+			}
+
+			suite.Cases = append(suite.Cases, c)
+			suite.Tests++
+			if testcase.Failure != nil {
+				suite.Failures++
+			}
+			if c.Status == StatusFlaky {
+				suite.Flaky++
+			}
+		}
+
+		r.Suites = append(r.Suites, suite)
+		r.Tests += suite.Tests
+		r.Failures += suite.Failures
+		r.Flaky += suite.Flaky
+	}
+
+	return r
+}
+
+// status returns the Status of a testcase
+func status(testcase *Testcase) Status {
+	switch {
+	case testcase.Failure != nil:
+		return StatusFailed
+	case testcase.Skipped != nil:
+		return StatusSkipped
+	case len(testcase.retries) > 0:
+		return StatusFlaky
+	default:
+		return StatusPassed
+	}
+}
+
+// seconds parses the time of a testcase or testsuite, it is 0 if it is not set
+func seconds(time string) float64 {
+	var s float64
+	_, _ = fmt.Sscanf(time, "%f", &s)
+	return s
+}
+
+// propertyMap returns properties by their name
+func propertyMap(properties *Properties) map[string]string {
+	if properties == nil || len(properties.Property) == 0 {
+		return nil
+	}
+	m := map[string]string{}
+	for _, property := range properties.Property {
+		m[property.Name] = property.Value
+	}
+	return m
+}
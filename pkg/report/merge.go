@@ -0,0 +1,68 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+)
+
+// ReadXML reads a junit xml report, e.g. the report of a shard of a test suite.
+func ReadXML(path string) (*Testsuites, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ts := &Testsuites{}
+	if err := xml.Unmarshal(contents, ts); err != nil {
+		return nil, fmt.Errorf("reading report %s: %w", path, err)
+	}
+	return ts, nil
+}
+
+// Merge merges the reports of the shards of a test suite into a closed report. The testsuites with the same name are
+// merged, their time is the time of the longest of them as the shards run at the same time. The properties of the
+// reports are kept, e.g. the shard of each report.
+func Merge(name string, reports []*Testsuites) *Testsuites {
+	merged := NewSuiteCollection(name)
+	byName := map[string]*Testsuite{}
+
+	for _, ts := range reports {
+		if ts.Properties != nil {
+			for _, property := range ts.Properties.Property {
+				merged.AddProperty(property)
+			}
+		}
+		merged.Time = maxTime(merged.Time, ts.Time)
+
+		for _, testsuite := range ts.Testsuite {
+			suite, ok := byName[testsuite.Name]
+			if !ok {
+				suite = &Testsuite{Name: testsuite.Name, Properties: testsuite.Properties}
+				byName[testsuite.Name] = suite
+				merged.AddTestSuite(suite)
+			}
+
+			suite.Testcase = append(suite.Testcase, testsuite.Testcase...)
+			suite.Tests += testsuite.Tests
+			suite.Failures += testsuite.Failures
+			suite.Time = maxTime(suite.Time, testsuite.Time)
+
+			merged.Tests += testsuite.Tests
+			merged.Failures += testsuite.Failures
+		}
+	}
+
+	return merged
+}
+
+// maxTime returns the longer of two times of a report in seconds.
+func maxTime(a, b string) string {
+	secondsA, errA := strconv.ParseFloat(a, 64)
+	secondsB, errB := strconv.ParseFloat(b, 64)
+	if errA != nil || (errB == nil && secondsB > secondsA) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,61 @@
+package report
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-merge")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for i, names := range [][]string{{"a", "b"}, {"c"}} {
+		ts := NewSuiteCollection("kuttl")
+		ts.AddProperty(Property{Name: "shard", Value: strconv.Itoa(i)})
+		suite := ts.NewSuite("suite")
+		for _, name := range names {
+			tc := NewCase(name)
+			if name == "c" {
+				tc.Failure = NewFailure("failed", nil)
+			}
+			suite.AddTestcase(tc)
+		}
+		require.NoError(t, ts.Report(dir, strconv.Itoa(i), XML))
+	}
+
+	reports := []*Testsuites{}
+	for _, name := range []string{"0.xml", "1.xml"} {
+		ts, err := ReadXML(filepath.Join(dir, name))
+		require.NoError(t, err)
+		reports = append(reports, ts)
+	}
+
+	merged := Merge("kuttl", reports)
+	assert.Equal(t, 3, merged.Tests)
+	assert.Equal(t, 1, merged.Failures)
+	assert.Equal(t, []Property{{Name: "shard", Value: "0"}, {Name: "shard", Value: "1"}}, merged.Properties.Property)
+	require.Equal(t, 1, len(merged.Testsuite))
+	assert.Equal(t, 3, merged.Testsuite[0].Tests)
+	assert.Equal(t, 1, merged.Testsuite[0].Failures)
+
+	names := []string{}
+	for _, tc := range merged.Testsuite[0].Testcase {
+		names = append(names, tc.Name)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+
+	require.NoError(t, merged.Write(dir, "merged", XML))
+	ts, err := ReadXML(filepath.Join(dir, "merged.xml"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, ts.Tests)
+
+	_, err = ReadXML(filepath.Join(dir, "missing.xml"))
+	assert.Error(t, err)
+}
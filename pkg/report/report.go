@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -13,8 +16,8 @@ import (
 // are junit xml compliant.  A number of resources were used but https://www.ibm.com/support/knowledgecenter/SSQ2R2_9.1.1/com.ibm.rsar.analysis.codereview.cobol.doc/topics/cac_useresults_junit.html
 // was very useful.  As well as:  https://www.onlinetool.io/xmltogo/
 
-// KUTTL is different than junit testing in that the test steps could be useful to have a report on.  There could be value in
-// having a TestCaseStep struct providing step details.  Sticking with the JUnit standard for now.
+// KUTTL is different than junit testing in that the test steps are useful to have a report on.  To stick with the JUnit standard,
+// each test step is reported as a testcase named "<test>/<step>" following the testcase of its test.
 
 // Type defines the report.type of report to create
 type Type string
@@ -22,8 +25,14 @@ type Type string
 const (
 	// XML defines the xml Type
 	XML Type = "xml"
-	// JSON defines the json Type
+	// JSON defines the json Type, see JSONReport
 	JSON Type = "json"
+	// TAP defines the Test Anything Protocol Type
+	TAP Type = "tap"
+	// HTML defines the html Type, a browsable summary of the tests and their steps
+	HTML Type = "html"
+	// Allure defines the allure Type, allure result files written to the AllureResultsDir of the report directory
+	Allure Type = "allure"
 )
 
 // Property are name/value pairs which can be provided in the report for things such as kuttl.version
@@ -37,6 +46,17 @@ type Properties struct {
 	Property []Property `xml:"property" json:"property,omitempty"`
 }
 
+// TimeoutFailure is the type of the failure of a testcase which timed out.
+const TimeoutFailure = "timeout"
+
+// colorRegex matches ANSI escape codes, e.g. of colorized diffs, which are removed from the failure texts.
+var colorRegex = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// Skipped marks a testcase which did not run, e.g. a test step after a failed step
+type Skipped struct {
+	Message string `xml:"message,attr" json:"message"`
+}
+
 // Failure defines a test failure
 type Failure struct {
 	// Text provides detailed information regarding failure.  It supports multi-line output.
@@ -58,10 +78,32 @@ type Testcase struct {
 	Assertions int `xml:"assertions,attr" json:"assertions,omitempty"`
 	// Failure defines a failure in this testcase
 	Failure *Failure `xml:"failure" json:"failure,omitempty"`
+	// FlakyFailures are the failures of retried attempts of a testcase which finally passed.
+	// The element name follows the maven surefire junit extension.
+	FlakyFailures []*Failure `xml:"flakyFailure" json:"flakyFailure,omitempty"`
+	// RerunFailures are the failures of retried attempts of a testcase which finally failed.
+	// The element name follows the maven surefire junit extension.
+	RerunFailures []*Failure `xml:"rerunFailure" json:"rerunFailure,omitempty"`
+	// Skipped marks a testcase which did not run
+	Skipped *Skipped `xml:"skipped" json:"skipped,omitempty"`
+	// Tags of the test, they are reported as allure tags
+	Tags []string `xml:"-" json:"tags,omitempty"`
+	// Attachments are the paths of the artifact files of the testcase, e.g. recorded assert files
+	Attachments []string `xml:"-" json:"attachments,omitempty"`
+	// Output is the log output of the testcase, e.g. of a test step with its events and collector output
+	Output string `xml:"-" json:"output,omitempty"`
+	// SystemOut is the output followed by the attachments in the format of the jenkins junit attachments plugin
+	SystemOut string `xml:"system-out,omitempty" json:"-"`
 
 	// start and end are not reported.  They are used to calc duration times for testcase and testsuite.
 	start time.Time
 	end   time.Time
+	// retries are the failures of retried attempts, they are reported as flaky or rerun failures.
+	retries []*Failure
+	// steps are the testcases of the test steps, they are added to the testsuite after the testcase.
+	steps []*Testcase
+	// step is set for the testcases of test steps.
+	step bool
 }
 
 // TestSuite is a collection of Testcase and is a summary of those details
@@ -81,6 +123,8 @@ type Testsuite struct {
 	Testcase []*Testcase `xml:"testcase" json:"testcase,omitempty"`
 
 	start time.Time
+	// lock serializes the testcases added by parallel tests.
+	lock sync.Mutex
 }
 
 // Testsuites is a collection of Testsuite and defines the rollup summary of all stats.
@@ -130,18 +174,83 @@ func NewFailure(msg string, errs []error) *Failure {
 	// error that is interesting.  The diff can be so long... and the second error added to a concat string gets buried
 	// in the noise.  Seems better to just see the reason and have the user look at test stdout for the larger context if desired.
 	if len(errs) > 0 {
-		f.Text = errs[len(errs)-1].Error()
+		f.Text = colorRegex.ReplaceAllString(errs[len(errs)-1].Error(), "")
 	}
 	return f
 }
 
+// NewStepFailure returns the address of a newly created Failure of a test step, unlike NewFailure its text has
+// all errors, including the diff of a failed assert
+func NewStepFailure(msg string, errs []error) *Failure {
+	texts := make([]string, 0, len(errs))
+	for _, err := range errs {
+		texts = append(texts, colorRegex.ReplaceAllString(err.Error(), ""))
+	}
+	return &Failure{Message: msg, Text: strings.Join(texts, "\n")}
+}
+
+// NewStep returns the address of a newly created Testcase of a test step of the testcase
+func (tc *Testcase) NewStep(name string) *Testcase {
+	step := NewCase(fmt.Sprintf("%s/%s", tc.Name, name))
+	step.step = true
+	tc.steps = append(tc.steps, step)
+	return step
+}
+
+// End records the end of a testcase, it is needed for test steps as they end before their test
+func (tc *Testcase) End() {
+	tc.end = time.Now()
+}
+
+// Skip marks a testcase as not run
+func (tc *Testcase) Skip(msg string) {
+	tc.Skipped = &Skipped{Message: msg}
+}
+
+// AddAttachment adds the path of an artifact file to the testcase
+func (tc *Testcase) AddAttachment(path string) {
+	tc.Attachments = append(tc.Attachments, path)
+}
+
+// AddRetry records the failure of an attempt which is retried.
+func (tc *Testcase) AddRetry(failure *Failure) {
+	tc.retries = append(tc.retries, failure)
+}
+
 // AddTestcase adds a testcase to a suite, providing stats and calculations to both
 func (ts *Testsuite) AddTestcase(testcase *Testcase) {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+
 	// this is needed to calc elapse time of testsuite in a async work
 	testcase.end = time.Now()
-	elapsed := time.Since(testcase.start)
+	if len(testcase.retries) > 0 {
+		if testcase.Failure == nil {
+			testcase.FlakyFailures = testcase.retries
+		} else {
+			testcase.RerunFailures = testcase.retries
+		}
+	}
+	ts.addTestcase(testcase)
+
+	for _, step := range testcase.steps {
+		// skipped steps did not run
+		if step.end.IsZero() {
+			step.end = step.start
+		}
+		ts.addTestcase(step)
+	}
+}
+
+// addTestcase adds an ended testcase to a suite
+func (ts *Testsuite) addTestcase(testcase *Testcase) {
+	elapsed := testcase.end.Sub(testcase.start)
 	testcase.Time = fmt.Sprintf("%.3f", elapsed.Seconds())
 	testcase.Classname = filepath.Base(ts.Name)
+	testcase.SystemOut = testcase.Output
+	for _, attachment := range testcase.Attachments {
+		testcase.SystemOut += fmt.Sprintf("[[ATTACHMENT|%s]]\n", attachment)
+	}
 
 	ts.Testcase = append(ts.Testcase, testcase)
 	ts.Tests++
@@ -152,6 +261,9 @@ func (ts *Testsuite) AddTestcase(testcase *Testcase) {
 
 // AddProperty adds a property to a testsuite
 func (ts *Testsuite) AddProperty(property Property) {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+
 	if ts.Properties == nil {
 		ts.Properties = &Properties{Property: []Property{property}}
 		return
@@ -208,9 +320,14 @@ func latestEnd(start time.Time, testcases []*Testcase) time.Time {
 	return end
 }
 
-// Report prints a report for TestSuites to the directory.  ftype == json | xml
+// Report prints a report for TestSuites to the directory.  ftype == json | xml | tap | html | allure
 func (ts *Testsuites) Report(dir, name string, ftype Type) error {
 	ts.Close()
+	return ts.Write(dir, name, ftype)
+}
+
+// Write prints a closed report, e.g. a merged report, to the directory.  ftype == json | xml | tap | html | allure
+func (ts *Testsuites) Write(dir, name string, ftype Type) error {
 	// don't print if there is nothing
 	if len(ts.Testsuite) == 0 {
 		return nil
@@ -218,6 +335,12 @@ func (ts *Testsuites) Report(dir, name string, ftype Type) error {
 	switch ftype {
 	case XML:
 		return writeXMLReport(dir, name, ts)
+	case TAP:
+		return writeTAPReport(dir, name, ts)
+	case HTML:
+		return writeHTMLReport(dir, name, ts)
+	case Allure:
+		return writeAllureReport(dir, ts)
 	case JSON:
 		fallthrough
 	default:
@@ -246,7 +369,7 @@ func writeXMLReport(dir, name string, ts *Testsuites) error {
 
 func writeJSONReport(dir, name string, ts *Testsuites) error {
 	file := filepath.Join(dir, fmt.Sprintf("%s.json", name))
-	jDoc, err := json.MarshalIndent(ts, " ", "  ")
+	jDoc, err := json.MarshalIndent(NewJSONReport(ts), "", "  ")
 	if err != nil {
 		return err
 	}
@@ -1,14 +1,20 @@
 package report
 
 import (
+	"bytes"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"flag"
+	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var updateGolden = flag.Bool("update", false, "update .golden files")
@@ -85,3 +91,248 @@ AssertionError`,
 	}
 	assert.Equal(t, string(gjson), jout, "for golden file: %s", jsonFile)
 }
+
+func TestAddTestcaseRetries(t *testing.T) {
+	retry := NewFailure("failed in step 1-create (attempt 1 of 2)", nil)
+
+	flaky := NewCase("flaky")
+	flaky.AddRetry(retry)
+
+	rerun := NewCase("rerun")
+	rerun.AddRetry(retry)
+	rerun.Failure = NewFailure("failed in step 1-create", nil)
+
+	suite := NewSuite("retries")
+	suite.AddTestcase(flaky)
+	suite.AddTestcase(rerun)
+
+	assert.Equal(t, []*Failure{retry}, flaky.FlakyFailures)
+	assert.Nil(t, flaky.RerunFailures)
+	assert.Equal(t, []*Failure{retry}, rerun.RerunFailures)
+	assert.Nil(t, rerun.FlakyFailures)
+	assert.Equal(t, 1, suite.Failures)
+}
+
+// TestAddTestcaseConcurrently adds testcases like parallel tests do, run it with -race.
+func TestAddTestcaseConcurrently(t *testing.T) {
+	suite := NewSuite("parallel")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		i := i
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tc := NewCase(fmt.Sprintf("test-%d", i))
+			tc.NewStep("0-create").End()
+			if i%2 == 0 {
+				tc.Failure = NewFailure("failed in step 0-create", nil)
+			}
+			suite.AddTestcase(tc)
+			suite.AddProperty(Property{Name: tc.Name, Value: "done"})
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 20, suite.Tests)
+	assert.Equal(t, 20, len(suite.Testcase))
+	assert.Equal(t, 5, suite.Failures)
+	assert.Equal(t, 10, len(suite.Properties.Property))
+}
+
+func TestAddTestcaseSteps(t *testing.T) {
+	tc := NewCase("steps")
+
+	create := tc.NewStep("1-create")
+	create.AddAttachment("steps/01-assert.yaml")
+	create.End()
+
+	update := tc.NewStep("2-update")
+	update.Failure = NewStepFailure("failed in step 2-update", []error{
+		errors.New("\x1b[1;31m-  replicas: 1\x1b[0m"),
+		errors.New("resource Deployment:world/app: .spec.replicas: value mismatch"),
+	})
+	update.End()
+	tc.Failure = NewFailure("failed in step 2-update", nil)
+
+	tc.NewStep("3-delete").Skip("step 2-update failed")
+
+	suite := NewSuite("suite")
+	suite.AddTestcase(tc)
+
+	assert.Equal(t, 4, suite.Tests)
+	assert.Equal(t, 2, suite.Failures)
+
+	names := []string{}
+	for _, testcase := range suite.Testcase {
+		names = append(names, testcase.Name)
+	}
+	assert.Equal(t, []string{"steps", "steps/1-create", "steps/2-update", "steps/3-delete"}, names)
+
+	assert.Equal(t, []string{"steps/01-assert.yaml"}, create.Attachments)
+	assert.Equal(t, "[[ATTACHMENT|steps/01-assert.yaml]]\n", create.SystemOut)
+	assert.Equal(t, "-  replicas: 1\nresource Deployment:world/app: .spec.replicas: value mismatch", update.Failure.Text)
+	assert.Equal(t, "0.000", suite.Testcase[3].Time)
+	assert.Equal(t, &Skipped{Message: "step 2-update failed"}, suite.Testcase[3].Skipped)
+}
+
+// newStepsSuites returns testsuites with a passed and a failed test case with steps.
+func newStepsSuites() *Testsuites {
+	passed := NewCase("passed")
+	passed.NewStep("1-create").End()
+
+	failed := NewCase("failed")
+	failed.AddRetry(NewFailure("failed in step 1-update (attempt 1 of 2)", nil))
+	update := failed.NewStep("1-update")
+	update.Failure = NewStepFailure("failed in step 1-update", []error{errors.New("value mismatch")})
+	update.End()
+	failed.NewStep("2-delete").Skip("step 1-update failed")
+	failed.Failure = NewFailure("failed in step 1-update", []error{errors.New("value mismatch")})
+
+	ts := NewSuiteCollection("kuttl")
+	suite := ts.NewSuite("tests/e2e")
+	suite.AddTestcase(passed)
+	suite.AddTestcase(failed)
+	ts.Close()
+
+	// the times are fixed for the expected output
+	for _, testcase := range suite.Testcase {
+		testcase.Time = "0.500"
+	}
+	return ts
+}
+
+func TestNewJSONReport(t *testing.T) {
+	r := NewJSONReport(newStepsSuites())
+
+	assert.Equal(t, JSONReportVersion, r.Version)
+	assert.Equal(t, 2, r.Tests)
+	assert.Equal(t, 1, r.Failures)
+	assert.Len(t, r.Suites, 1)
+
+	cases := r.Suites[0].Cases
+	assert.Len(t, cases, 2)
+	assert.Equal(t, StatusPassed, cases[0].Status)
+	assert.Equal(t, []JSONStep{{Name: "1-create", Status: StatusPassed, Time: 0.5}}, cases[0].Steps)
+
+	assert.Equal(t, StatusFailed, cases[1].Status)
+	assert.Equal(t, 0.5, cases[1].Time)
+	assert.Len(t, cases[1].Retries, 1)
+	assert.Equal(t, []JSONStep{
+		{
+			Name:    "1-update",
+			Status:  StatusFailed,
+			Time:    0.5,
+			Failure: &Failure{Message: "failed in step 1-update", Text: "value mismatch"},
+		},
+		{Name: "2-delete", Status: StatusSkipped, Time: 0.5, SkipReason: "step 1-update failed"},
+	}, cases[1].Steps)
+}
+
+func TestWriteTAP(t *testing.T) {
+	out := &bytes.Buffer{}
+	assert.NoError(t, WriteTAP(out, newStepsSuites()))
+
+	assert.Equal(t, `TAP version 13
+1..2
+    # Subtest: e2e/passed
+    1..1
+    ok 1 - 1-create
+ok 1 - e2e/passed
+    # Subtest: e2e/failed
+    1..2
+    not ok 1 - 1-update
+      ---
+      data: value mismatch
+      duration_ms: 500
+      message: failed in step 1-update
+      severity: fail
+      ...
+    ok 2 - 2-delete # SKIP step 1-update failed
+not ok 2 - e2e/failed
+  ---
+  data: value mismatch
+  duration_ms: 500
+  message: failed in step 1-update
+  retries:
+  - failed in step 1-update (attempt 1 of 2)
+  severity: fail
+  ...
+`, out.String())
+}
+
+func TestWriteHTML(t *testing.T) {
+	ts := newStepsSuites()
+	ts.Testsuite[0].Testcase[3].Output = "12:00:00 | <events>\n"
+	ts.Testsuite[0].Testcase[3].Failure.Text = "--- expected\n+++ actual\n@@ -1 +1 @@\n-replicas: 1\n+replicas: 2"
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, WriteHTML(out, ts))
+
+	html := out.String()
+	assert.Contains(t, html, `<span class="passed">passed</span> passed (0.500s)`)
+	assert.Contains(t, html, `<details open>
+<summary><span class="failed">failed</span> 1-update (0.500s)</summary>`)
+	assert.Contains(t, html, `<span class="del">-replicas: 1</span>`)
+	assert.Contains(t, html, `<span class="add">&#43;replicas: 2</span>`)
+	assert.Contains(t, html, `<pre>12:00:00 | &lt;events&gt;
+</pre>`)
+	assert.Contains(t, html, `2-delete (0.500s) step 1-update failed`)
+}
+
+func TestAddTestcaseSystemOut(t *testing.T) {
+	tc := NewCase("output")
+	tc.Output = "log line\n"
+	tc.AddAttachment("01-assert.yaml")
+
+	suite := NewSuite("suite")
+	suite.AddTestcase(tc)
+
+	assert.Equal(t, "log line\n[[ATTACHMENT|01-assert.yaml]]\n", tc.SystemOut)
+}
+
+func TestWriteAllure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "allure")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ts := newStepsSuites()
+	ts.Testsuite[0].Testcase[3].Output = "12:00:00 | events\n"
+	ts.Testsuite[0].Testcase[2].Tags = []string{"smoke"}
+
+	require.NoError(t, WriteAllure(dir, ts))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*-result.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	results := map[string]allureResult{}
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		require.NoError(t, err)
+		result := allureResult{}
+		require.NoError(t, json.Unmarshal(content, &result))
+		results[result.FullName] = result
+	}
+
+	passed := results["e2e/passed"]
+	assert.Equal(t, "passed", passed.Status)
+	assert.Nil(t, passed.StatusDetails)
+	assert.Equal(t, []allureStep{{Name: "1-create", Status: "passed", Stage: "finished", Start: passed.Steps[0].Start, Stop: passed.Steps[0].Stop}}, passed.Steps)
+
+	failed := results["e2e/failed"]
+	assert.Equal(t, "failed", failed.Status)
+	assert.Equal(t, &allureDetails{Message: "failed in step 1-update", Trace: "value mismatch"}, failed.StatusDetails)
+	assert.Contains(t, failed.Labels, allureLabel{Name: "tag", Value: "smoke"})
+	assert.Contains(t, failed.Labels, allureLabel{Name: "suite", Value: "e2e"})
+	require.Len(t, failed.Steps, 2)
+	assert.Equal(t, "skipped", failed.Steps[1].Status)
+
+	require.Len(t, failed.Steps[0].Attachments, 1)
+	attachment := failed.Steps[0].Attachments[0]
+	assert.Equal(t, "output", attachment.Name)
+	content, err := ioutil.ReadFile(filepath.Join(dir, attachment.Source))
+	require.NoError(t, err)
+	assert.Equal(t, "12:00:00 | events\n", string(content))
+}
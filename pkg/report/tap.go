@@ -0,0 +1,111 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// WriteTAP writes closed TestSuites in the Test Anything Protocol version 13.  Every test case is a test point
+// named "<suite>/<test>", its test steps are reported as an indented subtest.  Failures are reported as YAML diagnostics.
+func WriteTAP(w io.Writer, ts *Testsuites) error {
+	cases := []*Testcase{}
+	suites := []string{}
+	for _, testsuite := range ts.Testsuite {
+		for _, testcase := range testsuite.Testcase {
+			if !testcase.step {
+				cases = append(cases, testcase)
+				suites = append(suites, filepath.Base(testsuite.Name))
+			}
+		}
+	}
+
+	out := &bytes.Buffer{}
+	fmt.Fprintln(out, "TAP version 13")
+	fmt.Fprintf(out, "1..%d\n", len(cases))
+
+	for i, testcase := range cases {
+		if len(testcase.steps) > 0 {
+			fmt.Fprintf(out, "    # Subtest: %s/%s\n", suites[i], testcase.Name)
+			fmt.Fprintf(out, "    1..%d\n", len(testcase.steps))
+			for j, step := range testcase.steps {
+				if err := writeTAPPoint(out, "    ", j+1, strings.TrimPrefix(step.Name, testcase.Name+"/"), step); err != nil {
+					return err
+				}
+			}
+		}
+		if err := writeTAPPoint(out, "", i+1, fmt.Sprintf("%s/%s", suites[i], testcase.Name), testcase); err != nil {
+			return err
+		}
+	}
+
+	_, err := out.WriteTo(w)
+	return err
+}
+
+// writeTAPPoint writes the test point of a testcase with its failure and retries as YAML diagnostic.
+func writeTAPPoint(out *bytes.Buffer, indent string, number int, name string, testcase *Testcase) error {
+	result := "ok"
+	if testcase.Failure != nil {
+		result = "not ok"
+	}
+
+	directive := ""
+	if testcase.Skipped != nil {
+		directive = " # SKIP " + testcase.Skipped.Message
+	}
+	fmt.Fprintf(out, "%s%s %d - %s%s\n", indent, result, number, name, directive)
+
+	if testcase.Failure == nil && len(testcase.retries) == 0 {
+		return nil
+	}
+
+	diagnostic := map[string]interface{}{
+		"duration_ms": seconds(testcase.Time) * 1000,
+	}
+	if testcase.Failure != nil {
+		diagnostic["message"] = testcase.Failure.Message
+		diagnostic["severity"] = "fail"
+		if testcase.Failure.Type != "" {
+			diagnostic["type"] = testcase.Failure.Type
+		}
+		if testcase.Failure.Text != "" {
+			diagnostic["data"] = testcase.Failure.Text
+		}
+	}
+	if len(testcase.retries) > 0 {
+		retries := []string{}
+		for _, retry := range testcase.retries {
+			retries = append(retries, retry.Message)
+		}
+		diagnostic["retries"] = retries
+	}
+
+	doc, err := yaml.Marshal(diagnostic)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "%s  ---\n", indent)
+	for _, line := range strings.Split(strings.TrimSuffix(string(doc), "\n"), "\n") {
+		fmt.Fprintf(out, "%s  %s\n", indent, line)
+	}
+	fmt.Fprintf(out, "%s  ...\n", indent)
+	return nil
+}
+
+func writeTAPReport(dir, name string, ts *Testsuites) error {
+	out := &bytes.Buffer{}
+	if err := WriteTAP(out, ts); err != nil {
+		return err
+	}
+
+	file := filepath.Join(dir, fmt.Sprintf("%s.tap", name))
+	//nolint:gosec
+	return ioutil.WriteFile(file, out.Bytes(), 0644)
+}
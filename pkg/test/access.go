@@ -0,0 +1,140 @@
+package test
+
+import (
+	"context"
+	"fmt"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+)
+
+// CheckAccess checks the access asserts of the TestAssert with access reviews.
+func (s *Step) CheckAccess(ctx context.Context, namespace string) []error {
+	if s.Assert == nil || len(s.Assert.Access) == 0 {
+		return nil
+	}
+
+	errs := []error{}
+
+	for _, expected := range s.Assert.Access {
+		allowed, err := s.reviewAccess(ctx, expected, namespace)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("reviewing access %s: %w", describeAccessAssert(expected, namespace), err))
+			continue
+		}
+
+		if expected.Denied && allowed {
+			errs = append(errs, fmt.Errorf("access %s is allowed", describeAccessAssert(expected, namespace)))
+		} else if !expected.Denied && !allowed {
+			errs = append(errs, fmt.Errorf("access %s is denied", describeAccessAssert(expected, namespace)))
+		}
+	}
+
+	return errs
+}
+
+// reviewAccess checks if the action of an access assert is allowed. The user the step acts as is checked with a
+// SelfSubjectAccessReview if the assert does not set a user or service account.
+func (s *Step) reviewAccess(ctx context.Context, expected harness.AccessAssert, namespace string) (bool, error) {
+	resource, nonResource := accessAttributes(expected, namespace)
+
+	if expected.User == "" && expected.ServiceAccount == "" {
+		cl, err := s.actingClient(namespace)
+		if err != nil {
+			return false, err
+		}
+
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes:    resource,
+				NonResourceAttributes: nonResource,
+			},
+		}
+		if err := cl.Create(ctx, review); err != nil {
+			return false, err
+		}
+		return review.Status.Allowed, nil
+	}
+
+	subject, err := impersonationConfig(expected.Impersonate, namespace)
+	if err != nil {
+		return false, err
+	}
+
+	cl, err := s.Client(false)
+	if err != nil {
+		return false, err
+	}
+
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes:    resource,
+			NonResourceAttributes: nonResource,
+			User:                  subject.UserName,
+			Groups:                subject.Groups,
+		},
+	}
+	if err := cl.Create(ctx, review); err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
+
+// accessAttributes returns the attributes of the action of an access assert, a namespace of "*" checks a cluster
+// scoped resource or all namespaces.
+func accessAttributes(expected harness.AccessAssert, namespace string) (*authorizationv1.ResourceAttributes, *authorizationv1.NonResourceAttributes) {
+	if expected.NonResourceURL != "" {
+		return nil, &authorizationv1.NonResourceAttributes{Path: expected.NonResourceURL, Verb: expected.Verb}
+	}
+
+	switch expected.Namespace {
+	case "":
+	case "*":
+		namespace = ""
+	default:
+		namespace = expected.Namespace
+	}
+
+	return &authorizationv1.ResourceAttributes{
+		Namespace:   namespace,
+		Verb:        expected.Verb,
+		Group:       expected.Group,
+		Resource:    expected.Resource,
+		Subresource: expected.Subresource,
+		Name:        expected.Name,
+	}, nil
+}
+
+// describeAccessAssert returns a readable description of an access assert for error messages.
+func describeAccessAssert(expected harness.AccessAssert, namespace string) string {
+	description := "of the test step user"
+	if expected.User != "" {
+		description = "of user " + expected.User
+	} else if expected.ServiceAccount != "" {
+		description = "of service account " + expected.ServiceAccount
+	}
+
+	if expected.NonResourceURL != "" {
+		return fmt.Sprintf("%s to %s %s", description, expected.Verb, expected.NonResourceURL)
+	}
+
+	resource := expected.Resource
+	if expected.Group != "" {
+		resource += "." + expected.Group
+	}
+	if expected.Subresource != "" {
+		resource += "/" + expected.Subresource
+	}
+	if expected.Name != "" {
+		resource += " " + expected.Name
+	}
+
+	description += fmt.Sprintf(" to %s %s", expected.Verb, resource)
+
+	attributes, _ := accessAttributes(expected, namespace)
+	if attributes.Namespace != "" {
+		description += " in namespace " + attributes.Namespace
+	}
+	return description
+}
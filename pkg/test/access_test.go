@@ -0,0 +1,115 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// reviewClient answers access reviews, only the service account operator of the test namespace may delete pods
+// and the user of the client may get pods.
+type reviewClient struct {
+	client.Client
+}
+
+func (c *reviewClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	switch review := obj.(type) {
+	case *authorizationv1.SubjectAccessReview:
+		attributes := review.Spec.ResourceAttributes
+		review.Status.Allowed = review.Spec.User == "system:serviceaccount:world:operator" &&
+			attributes != nil && attributes.Verb == "delete" && attributes.Resource == "pods" && attributes.Namespace == testNamespace
+	case *authorizationv1.SelfSubjectAccessReview:
+		attributes := review.Spec.ResourceAttributes
+		review.Status.Allowed = attributes != nil && attributes.Verb == "get" && attributes.Resource == "pods"
+	default:
+		return c.Client.Create(ctx, obj, opts...)
+	}
+	return nil
+}
+
+func TestStepCheckAccess(t *testing.T) {
+	cl := &reviewClient{Client: fake.NewFakeClientWithScheme(scheme.Scheme)}
+
+	deletePods := func(serviceAccount, namespace string) harness.AccessAssert {
+		return harness.AccessAssert{
+			Impersonate: harness.Impersonate{ServiceAccount: serviceAccount},
+			Verb:        "delete",
+			Resource:    "pods",
+			Namespace:   namespace,
+		}
+	}
+
+	for _, test := range []struct {
+		name   string
+		access []harness.AccessAssert
+		errors []string
+	}{
+		{
+			name: "allowed",
+			access: []harness.AccessAssert{
+				deletePods("operator", ""),
+				deletePods("world/operator", testNamespace),
+				{Verb: "get", Resource: "pods"},
+			},
+		},
+		{
+			name: "denied",
+			access: []harness.AccessAssert{
+				deletePods("operator", "other"),
+				{Impersonate: harness.Impersonate{User: "jane"}, Verb: "delete", Resource: "pods"},
+				{Verb: "delete", Group: "apps", Resource: "deployments", Name: "hello", Namespace: "*"},
+			},
+			errors: []string{
+				"access of service account operator to delete pods in namespace other is denied",
+				"access of user jane to delete pods in namespace world is denied",
+				"access of the test step user to delete deployments.apps hello is denied",
+			},
+		},
+		{
+			name: "expected denied",
+			access: []harness.AccessAssert{
+				func() harness.AccessAssert {
+					access := deletePods("operator", "")
+					access.Denied = true
+					return access
+				}(),
+				{Verb: "get", NonResourceURL: "/metrics", Denied: true},
+			},
+			errors: []string{"access of service account operator to delete pods in namespace world is allowed"},
+		},
+		{
+			name: "invalid subject",
+			access: []harness.AccessAssert{
+				{Impersonate: harness.Impersonate{User: "jane", ServiceAccount: "operator"}, Verb: "get", Resource: "pods"},
+			},
+			errors: []string{"reviewing access of user jane to get pods in namespace world: impersonate can not set both a user and a service account"},
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			step := &Step{
+				Client: func(bool) (client.Client, error) { return cl, nil },
+				Logger: testutils.NewTestLogger(t, ""),
+				Assert: &harness.TestAssert{Access: test.access},
+			}
+
+			errs := []string{}
+			for _, err := range step.CheckAccess(context.TODO(), testNamespace) {
+				errs = append(errs, err.Error())
+			}
+			if test.errors == nil {
+				test.errors = []string{}
+			}
+			assert.Equal(t, test.errors, errs)
+		})
+	}
+}
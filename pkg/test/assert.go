@@ -1,6 +1,7 @@
 package test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -38,7 +39,7 @@ func Assert(namespace string, timeout int, assertFiles ...string) error {
 		// start fresh
 		testErrors = []error{}
 		for _, expected := range objects {
-			testErrors = append(testErrors, s.CheckResource(expected, namespace)...)
+			testErrors = append(testErrors, s.CheckResource(context.TODO(), expected, namespace)...)
 		}
 
 		if len(testErrors) == 0 {
@@ -84,7 +85,7 @@ func Errors(namespace string, timeout int, errorFiles ...string) error {
 		// start fresh
 		testErrors = []error{}
 		for _, expected := range objects {
-			if err := s.CheckResourceAbsent(expected, namespace); err != nil {
+			if err := s.CheckResourceAbsent(context.TODO(), expected, namespace); err != nil {
 				testErrors = append(testErrors, err)
 			}
 		}
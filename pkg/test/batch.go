@@ -0,0 +1,122 @@
+package test
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CheckJobs checks the job asserts of the TestAssert: a job must have completed successfully and a cronjob must have
+// spawned enough successfully completed jobs.
+func (s *Step) CheckJobs(ctx context.Context, namespace string) []error {
+	if s.Assert == nil || len(s.Assert.Jobs) == 0 {
+		return nil
+	}
+
+	cl, err := s.Client(false)
+	if err != nil {
+		return []error{err}
+	}
+
+	errs := []error{}
+
+	for _, expected := range s.Assert.Jobs {
+		ns := namespace
+		if expected.Namespace != "" {
+			ns = expected.Namespace
+		}
+
+		var err error
+		switch {
+		case (expected.Job == "") == (expected.CronJob == ""):
+			err = fmt.Errorf("job assert requires exactly one of job and cronJob")
+		case expected.Job != "":
+			err = checkJob(ctx, cl, types.NamespacedName{Namespace: ns, Name: expected.Job})
+		default:
+			err = checkCronJob(ctx, cl, types.NamespacedName{Namespace: ns, Name: expected.CronJob}, expected.MinSuccessful)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// checkJob checks that a job completed successfully.
+func checkJob(ctx context.Context, cl client.Client, key types.NamespacedName) error {
+	job := &batchv1.Job{}
+	if err := cl.Get(ctx, key, job); err != nil {
+		return fmt.Errorf("getting job %s: %w", key, err)
+	}
+
+	if jobCondition(job, batchv1.JobComplete) != nil {
+		return nil
+	}
+	if failed := jobCondition(job, batchv1.JobFailed); failed != nil {
+		return fmt.Errorf("job %s failed: %s: %s", key, failed.Reason, failed.Message)
+	}
+	return fmt.Errorf("job %s is not complete: %d active, %d succeeded and %d failed pods", key, job.Status.Active, job.Status.Succeeded, job.Status.Failed)
+}
+
+// checkCronJob checks that a cronjob spawned at least minSuccessful (default: 1) successfully completed jobs. The jobs
+// of a cronjob are the jobs it controls, jobs which were already cleaned up by its history limits are not counted.
+func checkCronJob(ctx context.Context, cl client.Client, key types.NamespacedName, minSuccessful int) error {
+	if minSuccessful <= 0 {
+		minSuccessful = 1
+	}
+
+	jobs := &batchv1.JobList{}
+	if err := cl.List(ctx, jobs, client.InNamespace(key.Namespace)); err != nil {
+		return fmt.Errorf("listing the jobs of cronjob %s: %w", key, err)
+	}
+
+	spawned, successful := 0, 0
+	var failure error
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if !controlledByCronJob(job, key.Name) {
+			continue
+		}
+
+		spawned++
+		if jobCondition(job, batchv1.JobComplete) != nil {
+			successful++
+		} else if failed := jobCondition(job, batchv1.JobFailed); failed != nil {
+			failure = fmt.Errorf("job %s failed: %s: %s", job.Name, failed.Reason, failed.Message)
+		}
+	}
+
+	if successful >= minSuccessful {
+		return nil
+	}
+	err := fmt.Errorf("cronjob %s spawned %d successful of %d jobs, expected at least %d", key, successful, spawned, minSuccessful)
+	if failure != nil {
+		err = fmt.Errorf("%v, %w", err, failure)
+	}
+	return err
+}
+
+// controlledByCronJob checks if a job is controlled by the cronjob of a name.
+func controlledByCronJob(job *batchv1.Job, name string) bool {
+	for _, owner := range job.OwnerReferences {
+		if owner.Kind == "CronJob" && owner.Name == name && owner.Controller != nil && *owner.Controller {
+			return true
+		}
+	}
+	return false
+}
+
+// jobCondition returns the condition of a type of a job if its status is true, or nil.
+func jobCondition(job *batchv1.Job, conditionType batchv1.JobConditionType) *batchv1.JobCondition {
+	for i, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return &job.Status.Conditions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func batchJob(name, cronJob string, status batchv1.JobStatus) *batchv1.Job {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Status:     status,
+	}
+	if cronJob != "" {
+		controller := true
+		job.OwnerReferences = []metav1.OwnerReference{{APIVersion: "batch/v1beta1", Kind: "CronJob", Name: cronJob, Controller: &controller}}
+	}
+	return job
+}
+
+func jobStatus(conditionType batchv1.JobConditionType, reason, message string) batchv1.JobStatus {
+	return batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+		{Type: conditionType, Status: corev1.ConditionTrue, Reason: reason, Message: message},
+	}}
+}
+
+func TestStepCheckJobs(t *testing.T) {
+	complete := jobStatus(batchv1.JobComplete, "", "")
+	failed := jobStatus(batchv1.JobFailed, "BackoffLimitExceeded", "Job has reached the specified backoff limit")
+
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme,
+		batchJob("migrate", "", complete),
+		batchJob("seed", "", failed),
+		batchJob("import", "", batchv1.JobStatus{Active: 1, Failed: 2}),
+		batchJob("backup-1", "backup", failed),
+		batchJob("backup-2", "backup", complete),
+		batchJob("report-1", "report", failed),
+		batchJob("orphan", "", complete),
+	)
+
+	for _, test := range []struct {
+		name   string
+		jobs   []harness.JobAssert
+		errors []string
+	}{
+		{
+			name: "successful",
+			jobs: []harness.JobAssert{{Job: "migrate"}, {CronJob: "backup"}, {Job: "migrate", Namespace: testNamespace}},
+		},
+		{
+			name:   "failed job",
+			jobs:   []harness.JobAssert{{Job: "seed"}},
+			errors: []string{"job world/seed failed: BackoffLimitExceeded: Job has reached the specified backoff limit"},
+		},
+		{
+			name:   "running job",
+			jobs:   []harness.JobAssert{{Job: "import"}},
+			errors: []string{"job world/import is not complete: 1 active, 0 succeeded and 2 failed pods"},
+		},
+		{
+			name:   "missing job",
+			jobs:   []harness.JobAssert{{Job: "migrate", Namespace: "other"}},
+			errors: []string{`getting job other/migrate: jobs.batch "migrate" not found`},
+		},
+		{
+			name: "cronjob without enough successful jobs",
+			jobs: []harness.JobAssert{{CronJob: "backup", MinSuccessful: 2}, {CronJob: "report"}, {CronJob: "cleanup"}},
+			errors: []string{
+				"cronjob world/backup spawned 1 successful of 2 jobs, expected at least 2, job backup-1 failed: BackoffLimitExceeded: Job has reached the specified backoff limit",
+				"cronjob world/report spawned 0 successful of 1 jobs, expected at least 1, job report-1 failed: BackoffLimitExceeded: Job has reached the specified backoff limit",
+				"cronjob world/cleanup spawned 0 successful of 0 jobs, expected at least 1",
+			},
+		},
+		{
+			name:   "invalid",
+			jobs:   []harness.JobAssert{{}, {Job: "migrate", CronJob: "backup"}},
+			errors: []string{"job assert requires exactly one of job and cronJob", "job assert requires exactly one of job and cronJob"},
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			step := &Step{
+				Logger: testutils.NewTestLogger(t, ""),
+				Assert: &harness.TestAssert{Jobs: test.jobs},
+				Client: func(bool) (client.Client, error) { return cl, nil },
+			}
+
+			var errors []string
+			for _, err := range step.CheckJobs(context.TODO(), testNamespace) {
+				errors = append(errors, err.Error())
+			}
+			assert.Equal(t, test.errors, errors)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package test
+
+import (
+	"context"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// Cache returns the informer cache shared by the test cases, the asserts on the objects of the cached kinds of the
+// test suite read from it instead of the API server. It is started on first use, an informer is started for each
+// kind on its first read.
+func (h *Harness) Cache() (client.Reader, error) {
+	h.cacheLock.Lock()
+	defer h.cacheLock.Unlock()
+
+	if h.cache != nil {
+		return h.cache, nil
+	}
+
+	// the client creates the RESTMapper shared with the cache
+	cl, err := h.Client(false)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := h.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	informers, err := cache.New(cfg, cache.Options{
+		Scheme: testutils.Scheme(),
+		Mapper: h.mapper,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	h.cacheStopCh = make(chan struct{})
+	go func(stopCh chan struct{}) {
+		if err := informers.Start(stopCh); err != nil {
+			h.T.Log("error running informer cache", err)
+		}
+	}(h.cacheStopCh)
+
+	h.cache = &cacheReader{cache: informers, client: cl}
+	return h.cache, nil
+}
+
+// stopCache stops the informers of the cache.
+func (h *Harness) stopCache() {
+	h.cacheLock.Lock()
+	defer h.cacheLock.Unlock()
+
+	if h.cacheStopCh != nil {
+		close(h.cacheStopCh)
+		h.cacheStopCh = nil
+	}
+}
+
+// cacheReader reads objects from an informer cache, it reads them from the API server until the cache is started.
+type cacheReader struct {
+	cache  client.Reader
+	client client.Reader
+}
+
+// Get retrieves an object from the cache.
+func (r *cacheReader) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	err := r.cache.Get(ctx, key, obj)
+	if _, ok := err.(*cache.ErrCacheNotStarted); ok {
+		return r.client.Get(ctx, key, obj)
+	}
+	return err
+}
+
+// List retrieves a list of objects from the cache.
+func (r *cacheReader) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	err := r.cache.List(ctx, list, opts...)
+	if _, ok := err.(*cache.ErrCacheNotStarted); ok {
+		return r.client.List(ctx, list, opts...)
+	}
+	return err
+}
+
+// cachesKind checks if the objects of a kind are read from the informer cache, see TestSuite.CachedKinds.
+func cachesKind(cachedKinds []string, gvk schema.GroupVersionKind) bool {
+	for _, kind := range cachedKinds {
+		if strings.EqualFold(kind, gvk.Kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// reader returns the reader of the objects of the kind of an object, the informer cache of the harness for the cached
+// kinds. The objects in the clusters of TestSuite.Clusters are not cached.
+func (s *Step) reader(cl client.Client, obj runtime.Object) (client.Reader, error) {
+	if s.Cache == nil || !cachesKind(s.CachedKinds, obj.GetObjectKind().GroupVersionKind()) || s.objectCluster(obj) != "" {
+		return cl, nil
+	}
+	return s.Cache()
+}
@@ -0,0 +1,71 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestCachesKind(t *testing.T) {
+	pod := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	deployment := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+
+	assert.False(t, cachesKind(nil, pod))
+	assert.True(t, cachesKind([]string{"Pod"}, pod))
+	assert.True(t, cachesKind([]string{"pod"}, pod))
+	assert.False(t, cachesKind([]string{"Pod"}, deployment))
+}
+
+func TestCheckResourceCache(t *testing.T) {
+	// the pod only exists in the cache
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme)
+	informers := fake.NewFakeClientWithScheme(scheme.Scheme, testutils.NewPod("hello", testNamespace))
+
+	step := Step{
+		Logger:          testutils.NewTestLogger(t, ""),
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+		Cache:           func() (client.Reader, error) { return informers, nil },
+	}
+
+	assert.NotEmpty(t, step.CheckResource(context.TODO(), testutils.NewPod("hello", ""), testNamespace))
+
+	step.CachedKinds = []string{"Pod"}
+	assert.Empty(t, step.CheckResource(context.TODO(), testutils.NewPod("hello", ""), testNamespace))
+}
+
+// notStartedCache is an informer cache which was not started yet.
+type notStartedCache struct{}
+
+func (notStartedCache) Get(context.Context, client.ObjectKey, runtime.Object) error {
+	return &cache.ErrCacheNotStarted{}
+}
+
+func (notStartedCache) List(context.Context, runtime.Object, ...client.ListOption) error {
+	return &cache.ErrCacheNotStarted{}
+}
+
+func TestCacheReaderNotStarted(t *testing.T) {
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "hello", Namespace: testNamespace}})
+	reader := &cacheReader{cache: notStartedCache{}, client: cl}
+
+	// the objects are read from the API server until the cache is started
+	assert.NoError(t, reader.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: "hello"}, &corev1.Pod{}))
+
+	pods := &corev1.PodList{}
+	assert.NoError(t, reader.List(context.TODO(), pods))
+	assert.Equal(t, 1, len(pods.Items))
+}
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// Capture extracts the values of the TestStep capture list into the variables of the test case.
+func (s *Step) Capture(ctx context.Context, namespace string) []error {
+	if s.Step == nil || len(s.Step.Capture) == 0 {
+		return nil
+	}
+
+	cl, err := s.Client(false)
+	if err != nil {
+		return []error{err}
+	}
+
+	dClient, err := s.DiscoveryClient()
+	if err != nil {
+		return []error{err}
+	}
+
+	errs := []error{}
+
+	for _, capture := range s.Step.Capture {
+		objs, err := s.objectsFromRef(ctx, cl, dClient, capture.ObjectReference, namespace)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(objs) != 1 {
+			errs = append(errs, fmt.Errorf("capture %s: expected 1 object, found %d", capture.Variable, len(objs)))
+			continue
+		}
+
+		// named references are not fetched by objectsFromRef
+		if capture.Name != "" {
+			if err := cl.Get(ctx, testutils.ObjectKey(objs[0]), objs[0]); err != nil {
+				errs = append(errs, fmt.Errorf("capture %s: %w", capture.Variable, err))
+				continue
+			}
+		}
+
+		value, err := captureValue(capture, objs[0])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		s.Logger.Logf("captured %s=%s", capture.Variable, value)
+		s.variables[capture.Variable] = value
+	}
+
+	return errs
+}
+
+// captureValue evaluates the JSONPath expression of a capture on an object.
+func captureValue(capture harness.Capture, obj runtime.Object) (string, error) {
+	if capture.Variable == "" {
+		return "", fmt.Errorf("capture with JSONPath %q has no variable name", capture.JSONPath)
+	}
+
+	expression := capture.JSONPath
+	if !strings.HasPrefix(expression, "{") {
+		expression = "{" + expression + "}"
+	}
+
+	path := jsonpath.New(capture.Variable)
+	if err := path.Parse(expression); err != nil {
+		return "", fmt.Errorf("capture %s: %w", capture.Variable, err)
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return "", fmt.Errorf("capture %s: %w", capture.Variable, err)
+	}
+
+	value := &bytes.Buffer{}
+	if err := path.Execute(value, content); err != nil {
+		return "", fmt.Errorf("capture %s: %w", capture.Variable, err)
+	}
+
+	return value.String(), nil
+}
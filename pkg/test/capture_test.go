@@ -0,0 +1,108 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestStepCapture(t *testing.T) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello", Namespace: testNamespace},
+		Spec:       corev1.ServiceSpec{ClusterIP: "10.0.0.1"},
+	}
+
+	serviceRef := func(name string) harness.ObjectReference {
+		return harness.ObjectReference{ObjectReference: corev1.ObjectReference{APIVersion: "v1", Kind: "Service", Name: name}}
+	}
+
+	for _, test := range []struct {
+		name      string
+		capture   []harness.Capture
+		variables map[string]string
+		errors    int
+	}{
+		{
+			name: "capture values",
+			capture: []harness.Capture{
+				{ObjectReference: serviceRef("hello"), Variable: "IP", JSONPath: "{.spec.clusterIP}"},
+				{ObjectReference: serviceRef("hello"), Variable: "NAME", JSONPath: ".metadata.name"},
+			},
+			variables: map[string]string{"IP": "10.0.0.1", "NAME": "hello"},
+		},
+		{
+			name: "missing object",
+			capture: []harness.Capture{
+				{ObjectReference: serviceRef("missing"), Variable: "IP", JSONPath: "{.spec.clusterIP}"},
+			},
+			variables: map[string]string{},
+			errors:    1,
+		},
+		{
+			name: "invalid JSONPath",
+			capture: []harness.Capture{
+				{ObjectReference: serviceRef("hello"), Variable: "IP", JSONPath: "{.spec.clusterIP"},
+			},
+			variables: map[string]string{},
+			errors:    1,
+		},
+		{
+			name: "missing variable name",
+			capture: []harness.Capture{
+				{ObjectReference: serviceRef("hello"), JSONPath: "{.spec.clusterIP}"},
+			},
+			variables: map[string]string{},
+			errors:    1,
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			cl := fake.NewFakeClientWithScheme(scheme.Scheme, service.DeepCopy())
+
+			step := Step{
+				Logger: testutils.NewTestLogger(t, ""),
+				Step:   &harness.TestStep{Capture: test.capture},
+				Client: func(bool) (client.Client, error) {
+					return cl, nil
+				},
+				DiscoveryClient: func() (discovery.DiscoveryInterface, error) {
+					return testutils.FakeDiscoveryClient(), nil
+				},
+				variables: map[string]string{},
+			}
+
+			assert.Equal(t, test.errors, len(step.Capture(context.TODO(), testNamespace)))
+			assert.Equal(t, test.variables, step.variables)
+
+			// the variables are injected into the commands of the step
+			env, err := step.commandEnv(context.TODO(), testNamespace)
+			assert.Nil(t, err)
+			assert.Equal(t, len(test.variables), len(env))
+		})
+	}
+}
+
+func TestTemplateDataVars(t *testing.T) {
+	vars := map[string]string{}
+	data := NewTemplateData(testNamespace, nil, vars)
+
+	// variables captured after the template data is created are rendered
+	vars["IP"] = "10.0.0.1"
+	rendered, err := data.Render("service.yaml", []byte("ip: {{ .Vars.IP }}"))
+	assert.Nil(t, err)
+	assert.Equal(t, "ip: 10.0.0.1", string(rendered))
+
+	_, err = data.Render("service.yaml", []byte("ip: {{ .Vars.MISSING }}"))
+	assert.Error(t, err)
+}
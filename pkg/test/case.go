@@ -4,13 +4,15 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"regexp"
+	goruntime "runtime"
 	"sort"
 	"strconv"
 	"testing"
+	"time"
 
-	petname "github.com/dustinkirkland/golang-petname"
 	"github.com/thoas/go-funk"
 	corev1 "k8s.io/api/core/v1"
 	eventsbeta1 "k8s.io/api/events/v1beta1"
@@ -19,30 +21,121 @@ import (
 	"k8s.io/apimachinery/pkg/conversion"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
 	"github.com/kudobuilder/kuttl/pkg/report"
 	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
 )
 
 var testStepRegex = regexp.MustCompile(`^(\d+)-([^.]+)(.yaml)?$`)
 
+// The hooks directory of a test suite has the steps which are shared by all of its test cases: the steps of its
+// "before" directory run before the steps of every test case, the steps of its "after" directory after them.
+const (
+	hooksDir    = "_hooks"
+	beforeHooks = "before"
+	afterHooks  = "after"
+)
+
 // Case contains all of the test steps and the Kubernetes client and other global configuration
 // for a test.
 type Case struct {
-	Steps              []*Step
-	Name               string
-	Dir                string
-	SkipDelete         bool
+	Steps      []*Step
+	Name       string
+	Dir        string
+	SkipDelete bool
+	// KeepOnFailure skips the deletion of the objects and namespace of the test case if it failed.
+	KeepOnFailure bool
+	// PauseOnFailure is the time in seconds a failed test case pauses before it is cleaned up.
+	PauseOnFailure int
+	// DebugShell starts an interactive shell in the namespace of a failed test case before it is cleaned up.
+	DebugShell         bool
 	Timeout            int
 	PreferredNamespace string
+	// NamespaceTemplate is the template of the name of the generated namespace, see generateNamespace.
+	NamespaceTemplate string
+	// NamespaceLabels and NamespaceAnnotations are added to the namespace if it is created for the test case.
+	NamespaceLabels      map[string]string
+	NamespaceAnnotations map[string]string
+	// Serial indicates that the test case must not run in parallel with other test cases.
+	Serial bool
+	// Tags of the test case from its kuttl-case.yaml file.
+	Tags []string
+	// HooksDir is the hooks directory of the test suite with the steps which run before and after the steps
+	// of the test case.
+	HooksDir string
+	// DependsOn are the names of the test case directories of the test cases which must pass before the test case
+	// starts, from its kuttl-case.yaml file.
+	DependsOn []string
+	// Fixtures are the names of the fixtures of the test suite the test case uses, from its kuttl-case.yaml file.
+	Fixtures []string
+	// Requires are the requirements of the test case from its kuttl-case.yaml file, it is skipped if they are not met.
+	Requires *harness.Requirements
 
 	Client          func(forceNew bool) (client.Client, error)
 	DiscoveryClient func() (discovery.DiscoveryInterface, error)
+	Config          func() (*rest.Config, error)
+	// Clusters returns the clients of the clusters of the test suite by name, see Harness.ClusterClients.
+	Clusters func(name string) (*ClusterClients, error)
+	// ClusterNames are the names of the clusters of the test suite, the namespace of the test case is also created
+	// in each of them.
+	ClusterNames []string
+	// Cache is the informer cache of the harness the asserts on the CachedKinds read from, see Harness.Cache.
+	Cache func() (client.Reader, error)
 
 	Logger testutils.Logger
 	// Suppress is used to suppress logs
 	Suppress []string
+	// Env is the environment of the test suite injected into the commands of the test steps.
+	Env map[string]string
+	// IgnoredFields of the test suite which are not compared by asserts.
+	IgnoredFields []string
+	// CachedKinds of the test suite whose objects are read from the Cache by asserts.
+	CachedKinds []string
+	// Backoff of the retries of the asserts of the test suite.
+	Backoff *harness.Backoff
+	// UpdateGolden rewrites the golden files of the asserts instead of comparing them.
+	UpdateGolden bool
+	// Record writes the objects applied by the test steps as assert files.
+	Record bool
+	// ValidateManifests validates the objects of the test steps with a dry run before they are applied.
+	ValidateManifests bool
+	// StrictApply fails the test steps which would update objects managed by others.
+	StrictApply bool
+	// Tracking of the objects created by the test case which are deleted after it, see startTracking.
+	Tracking *harness.Tracking
+	// NoColor disables the colorized diffs of failed asserts.
+	NoColor bool
+	// TestTimeout is the time in seconds the whole test case may take, 0 means no limit.
+	TestTimeout int
+	// FromStep and ToStep limit the test steps which are run to a range of indexes, see runsStep.
+	FromStep *int
+	ToStep   *int
+	// Template enables rendering the test files as go templates with the test suite Values.
+	Template bool
+	Values   map[string]string
+
+	// ns is the namespace of the test case, it is determined once.
+	ns *namespace
+	// variables captured by the test steps, see Step.Capture.
+	variables map[string]string
+	// restarts of pods when the test case started, see Step.RecordRestarts.
+	restarts restartCounts
+	// snapshots taken by the test steps, see Step.Snapshot.
+	snapshots map[string]*snapshot
+	// applied are the objects applied by the test steps by their resource ID, see Step.Prune.
+	applied map[string]runtime.Object
+	// tracking of the objects created by the test case, nil if they are not tracked.
+	tracking *tracking
+	// dependencies are the test cases of the run the test case depends on, see orderByDependencies.
+	dependencies []*Case
+	// finished is closed when the test case finished running, passed is set before. notRun is set if the test
+	// case finished without running, e.g. because it did not match --test, see runTestCase.
+	finished chan struct{}
+	passed   bool
+	notRun   bool
 }
 
 type namespace struct {
@@ -59,19 +152,27 @@ func (t *Case) DeleteNamespace(ns *namespace) error {
 
 	t.Logger.Log("Deleting namespace:", ns.Name)
 
-	cl, err := t.Client(false)
+	clients, err := t.namespaceClients()
 	if err != nil {
 		return err
 	}
 
-	return cl.Delete(context.TODO(), &corev1.Namespace{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: ns.Name,
-		},
-		TypeMeta: metav1.TypeMeta{
-			Kind: "Namespace",
-		},
-	})
+	// the namespace is deleted in all clusters even if the deletion in one of them fails
+	var deleteErr error
+	for _, cl := range clients {
+		err := cl.Delete(context.TODO(), &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: ns.Name,
+			},
+			TypeMeta: metav1.TypeMeta{
+				Kind: "Namespace",
+			},
+		})
+		if err != nil && deleteErr == nil {
+			deleteErr = err
+		}
+	}
+	return deleteErr
 }
 
 // CreateNamespace creates a namespace in Kubernetes to use for a test.
@@ -82,19 +183,46 @@ func (t *Case) CreateNamespace(ns *namespace) error {
 	}
 	t.Logger.Log("Creating namespace:", ns.Name)
 
-	cl, err := t.Client(false)
+	clients, err := t.namespaceClients()
 	if err != nil {
 		return err
 	}
 
-	return cl.Create(context.TODO(), &corev1.Namespace{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: ns.Name,
-		},
-		TypeMeta: metav1.TypeMeta{
-			Kind: "Namespace",
-		},
-	})
+	for _, cl := range clients {
+		err := cl.Create(context.TODO(), &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        ns.Name,
+				Labels:      t.NamespaceLabels,
+				Annotations: t.NamespaceAnnotations,
+			},
+			TypeMeta: metav1.TypeMeta{
+				Kind: "Namespace",
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// namespaceClients returns the clients of the clusters the namespace of the test case is created in: the cluster of
+// the test suite and its ClusterNames.
+func (t *Case) namespaceClients() ([]client.Client, error) {
+	cl, err := t.Client(false)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := []client.Client{cl}
+	for _, name := range t.ClusterNames {
+		cluster, err := t.Clusters(name)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, cluster.Client)
+	}
+	return clients, nil
 }
 
 // NamespaceExists gets namespace and returns true if it exists
@@ -156,8 +284,21 @@ func printEvents(events []eventsbeta1.Event, logger conversion.DebugLogger) {
 }
 
 // Run runs a test case including all of its steps.
-func (t *Case) Run(test *testing.T, tc *report.Testcase) {
-	test.Parallel()
+// The caller is responsible for marking the test as parallel (see Harness.RunTests).
+// If the context is done or the TestTimeout of the test case expires, the running step is cancelled
+// and the test case fails as timed out. The steps are cleaned up regardless.
+func (t *Case) Run(ctx context.Context, test TestReporter, tc *report.Testcase) {
+	if t.TestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(t.TestTimeout)*time.Second)
+		defer cancel()
+	}
+
+	// e.g. the deadline of the test suite expired or the tests were interrupted before the test case started
+	if ctx.Err() != nil {
+		t.cancelled(ctx, test, tc, "before the first step", nil)
+		return
+	}
 
 	ns, err := t.determineNamespace()
 	if err != nil {
@@ -170,53 +311,385 @@ func (t *Case) Run(test *testing.T, tc *report.Testcase) {
 
 	if !t.SkipDelete {
 		defer func() {
+			if t.keptOnFailure(tc) {
+				return
+			}
 			if err := t.DeleteNamespace(ns); err != nil {
 				test.Error(err)
 			}
 		}()
 	}
 
+	if t.Tracking != nil {
+		if t.tracking, err = t.startTracking(ns); err != nil {
+			test.Fatal(err)
+		}
+		if !t.SkipDelete {
+			// registered after the deletion of the namespace to run before it
+			defer func() {
+				if t.keptOnFailure(tc) {
+					return
+				}
+				if err := t.deleteTracked(ns); err != nil {
+					test.Error(err)
+				}
+			}()
+		}
+	}
+
+	if t.variables == nil {
+		t.variables = map[string]string{}
+	}
+	if t.snapshots == nil {
+		t.snapshots = map[string]*snapshot{}
+	}
+	if t.applied == nil {
+		t.applied = map[string]runtime.Object{}
+	}
+	if t.restarts == nil {
+		t.restarts = restartCounts{}
+		t.recordTestRestarts(ctx, ns.Name)
+	}
+
+	// failedStep is the step which failed, the following steps are reported as skipped
+	var failedStep *Step
 	for _, testStep := range t.Steps {
-		testStep.Client = t.Client
-		testStep.DiscoveryClient = t.DiscoveryClient
-		testStep.Logger = t.Logger.WithPrefix(testStep.String())
-		tc.Assertions += len(testStep.Asserts)
-		tc.Assertions += len(testStep.Errors)
+		stepCase := tc.NewStep(testStep.String())
+
+		if !t.runsStep(testStep) {
+			t.Logger.Logf("skipping step %s", testStep.String())
+			stepCase.Skip("not in the range of steps to run")
+			continue
+		}
+
+		if failedStep != nil {
+			stepCase.Skip(fmt.Sprintf("step %s failed", failedStep.String()))
+			continue
+		}
+
+		// the deferred cleanups below need their own copy of the loop variable
+		testStep := testStep
+		testStep.withCluster(t.Client, t.DiscoveryClient, t.Config)
+		testStep.Clusters = t.Clusters
+		// the logs of the step including the events and collector output of a failure are added to the report
+		stepLogger := testutils.NewCaptureLogger(t.Logger.WithPrefix(testStep.String()))
+		testStep.Logger = stepLogger
+		testStep.Suppress = t.Suppress
+		testStep.Env = t.Env
+		testStep.IgnoredFields = t.IgnoredFields
+		testStep.CachedKinds = t.CachedKinds
+		testStep.Cache = t.Cache
+		testStep.Backoff = t.Backoff
+		testStep.UpdateGolden = t.UpdateGolden
+		testStep.Record = t.Record
+		testStep.ValidateManifests = t.ValidateManifests
+		testStep.StrictApply = t.StrictApply
+		testStep.tracking = t.tracking
+		testStep.NoColor = t.NoColor
+		testStep.variables = t.variables
+		testStep.testRestarts = t.restarts
+		testStep.snapshots = t.snapshots
+		testStep.applied = t.applied
+
+		// background processes of the step run until the end of the test case
+		defer testStep.StopProcesses()
 
 		if !t.SkipDelete {
+			// registered before Clean to run after all objects of the step are cleaned up
+			defer func() {
+				if t.keptOnFailure(tc) {
+					return
+				}
+				for _, err := range testStep.Teardown(ns.Name) {
+					test.Error(err)
+				}
+			}()
 			defer func() {
+				if t.keptOnFailure(tc) {
+					return
+				}
 				if err := testStep.Clean(ns.Name); err != nil {
 					test.Error(err)
 				}
 			}()
 		}
 
-		if errs := testStep.Run(ns.Name); len(errs) > 0 {
+		var errs []error
+		if err := testStep.loadTemplates(); err != nil {
+			errs = []error{err}
+		} else if reason, err := t.unmetRequirement(ctx, testStep.requirements(), ns.Name); err != nil {
+			errs = []error{err}
+		} else if reason != "" {
+			t.Logger.Logf("skipping step %s: %s", testStep.String(), reason)
+			stepCase.Skip(reason)
+			continue
+		} else {
+			tc.Assertions += len(testStep.Asserts)
+			tc.Assertions += len(testStep.Errors)
+			stepCase.Assertions = len(testStep.Asserts) + len(testStep.Errors)
+			errs = t.runStep(ctx, testStep, ns.Name, tc)
+		}
+
+		stepCase.End()
+		stepCase.Output = stepLogger.Captured()
+		for _, artifact := range testStep.artifacts {
+			stepCase.AddAttachment(artifact)
+		}
+
+		if len(errs) > 0 && ctx.Err() != nil {
+			t.cancelled(ctx, test, tc, "in step "+testStep.String(), errs)
+			stepCase.Failure = report.NewStepFailure(tc.Failure.Message, errs)
+			stepCase.Failure.Type = tc.Failure.Type
+			failedStep = testStep
+			continue
+		}
+
+		if len(errs) > 0 {
 			caseErr := fmt.Errorf("failed in step %s", testStep.String())
 			tc.Failure = report.NewFailure(caseErr.Error(), errs)
+			stepCase.Failure = report.NewStepFailure(caseErr.Error(), errs)
 
 			test.Error(caseErr)
 			for _, err := range errs {
 				test.Error(err)
 			}
-			break
+			failedStep = testStep
 		}
 	}
 
+	// a failed step already logged the namespace events
 	if funk.Contains(t.Suppress, "events") {
 		t.Logger.Logf("skipping kubernetes event logging")
-	} else {
+	} else if failedStep == nil {
 		t.CollectEvents(ns.Name)
 	}
+
+	if tc.Failure != nil {
+		t.pauseOnFailure(ctx, ns.Name)
+	}
+}
+
+// keptOnFailure checks if the objects and namespace of a failed test case are kept for debugging.
+func (t *Case) keptOnFailure(tc *report.Testcase) bool {
+	return t.KeepOnFailure && tc.Failure != nil
+}
+
+// runsStep checks if the index of a test step is in the range of the steps to run, the steps of hooks always run.
+func (t *Case) runsStep(testStep *Step) bool {
+	if testStep.Hook {
+		return true
+	}
+	if t.FromStep != nil && testStep.Index < *t.FromStep {
+		return false
+	}
+	if t.ToStep != nil && testStep.Index > *t.ToStep {
+		return false
+	}
+	return true
+}
+
+// cancelled marks a test case as timed out or, if the context was cancelled otherwise, as interrupted.
+func (t *Case) cancelled(ctx context.Context, test TestReporter, tc *report.Testcase, where string, errs []error) {
+	timedOut := ctx.Err() == context.DeadlineExceeded
+
+	caseErr := fmt.Errorf("interrupted %s", where)
+	if timedOut {
+		caseErr = fmt.Errorf("timed out %s", where)
+	}
+
+	tc.Failure = report.NewFailure(caseErr.Error(), errs)
+	if timedOut {
+		tc.Failure.Type = report.TimeoutFailure
+	}
+
+	test.Error(caseErr)
+	for _, err := range errs {
+		test.Error(err)
+	}
+}
+
+// TestReporter is the part of testing.T test cases report their failures to.
+type TestReporter interface {
+	Error(args ...interface{})
+	Fatal(args ...interface{})
+}
+
+// attemptReporter is a TestReporter which records the failures of an attempt of a test case instead of failing the test.
+type attemptReporter struct {
+	errors []string
+}
+
+func (a *attemptReporter) Error(args ...interface{}) {
+	a.errors = append(a.errors, fmt.Sprint(args...))
+}
+
+// Fatal records the failure and stops the goroutine of the attempt like testing.T.Fatal, see runAttempt.
+func (a *attemptReporter) Fatal(args ...interface{}) {
+	a.Error(args...)
+	goruntime.Goexit()
+}
+
+// loggerReporter is a TestReporter which reports the failures of a test case through a logger which logs errors
+// itself, e.g. as JSON lines, and fails the test without the go test operator logging them again.
+type loggerReporter struct {
+	logger testutils.ErrorLogger
+	test   *testing.T
+}
+
+func (l *loggerReporter) Error(args ...interface{}) {
+	l.logger.LogError(args...)
+	l.test.Fail()
+}
+
+func (l *loggerReporter) Fatal(args ...interface{}) {
+	l.logger.LogError(args...)
+	l.test.FailNow()
+}
+
+// reporter returns the TestReporter of the test case, the failures are logged by its logger if it is an ErrorLogger.
+func (t *Case) reporter(test *testing.T) TestReporter {
+	if logger, ok := t.Logger.(testutils.ErrorLogger); ok {
+		return &loggerReporter{logger: logger, test: test}
+	}
+	return test
+}
+
+// RunWithRetries runs a test case like Run and reruns it up to retries times while it fails. The failures of the
+// attempts before the last one are reported to test only if it is not quarantined, they are recorded as retries of
+// the report testcase, a test case passing on a retry is flaky. It returns the report testcase of the last attempt.
+func (t *Case) RunWithRetries(ctx context.Context, test *testing.T, retries int, quarantined bool) *report.Testcase {
+	failures := []*report.Failure{}
+
+	for attempt := 1; ; attempt++ {
+		tc := report.NewCase(t.Name)
+		for _, failure := range failures {
+			tc.AddRetry(failure)
+		}
+
+		if attempt > retries && !quarantined {
+			t.Run(ctx, t.reporter(test), tc)
+			return tc
+		}
+
+		errs := t.runAttempt(ctx, tc)
+		if len(errs) == 0 {
+			return tc
+		}
+
+		failure := tc.Failure
+		if failure == nil {
+			failure = report.NewFailure("failed", nil)
+		}
+		msg := fmt.Sprintf("%s (attempt %d of %d)", failure.Message, attempt, retries+1)
+
+		// a cancelled test case is not retried
+		if ctx.Err() != nil && !quarantined {
+			reporter := t.reporter(test)
+			for _, err := range errs {
+				reporter.Error(err)
+			}
+			return tc
+		}
+
+		if attempt > retries {
+			// the failures of a quarantined test case are logged but do not fail the test
+			t.Logger.Logf("quarantined test case %s", msg)
+			for _, err := range errs {
+				t.Logger.Log(err)
+			}
+			return tc
+		}
+
+		t.Logger.Logf("test case %s, retrying", msg)
+		for _, err := range errs {
+			t.Logger.Log(err)
+		}
+		failures = append(failures, &report.Failure{Message: msg, Text: failure.Text, Type: failure.Type})
+		t.reset()
+	}
+}
+
+// runAttempt runs an attempt of the test case in its own goroutine, like testing.T does, which is stopped by
+// attemptReporter.Fatal. It returns the failures of the attempt.
+func (t *Case) runAttempt(ctx context.Context, tc *report.Testcase) []string {
+	reporter := &attemptReporter{}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		t.Run(ctx, reporter, tc)
+	}()
+	<-done
+
+	return reporter.errors
+}
+
+// continueFrom lets the test case continue from the state a previous test case left, the variables captured, the
+// snapshots taken and the objects applied by its steps are available to the steps of the test case. It is called
+// after the steps are loaded.
+func (t *Case) continueFrom(previous *Case) {
+	if previous == nil {
+		return
+	}
+
+	// the map is shared with the template data of the steps
+	for name, value := range previous.variables {
+		t.variables[name] = value
+	}
+	t.snapshots = previous.snapshots
+	t.applied = previous.applied
+}
+
+// reset prepares the test case for another attempt, an auto-created namespace is created with a new name.
+func (t *Case) reset() {
+	if t.ns != nil && t.ns.AutoCreated {
+		t.ns = nil
+	}
+	t.variables = nil
+	t.restarts = nil
+	t.snapshots = nil
+	t.applied = nil
+	t.tracking = nil
+}
+
+// runStep runs a test step, retrying it as configured in the TestStep.
+// The failures of the retried attempts are recorded in the report testcase.
+func (t *Case) runStep(ctx context.Context, testStep *Step, namespace string, tc *report.Testcase) []error {
+	retries, delay := 0, 0
+	if testStep.Step != nil {
+		retries, delay = testStep.Step.Retries, testStep.Step.RetryDelay
+	}
+
+	for attempt := 1; ; attempt++ {
+		errs := testStep.Run(ctx, namespace)
+		if len(errs) == 0 || attempt > retries || ctx.Err() != nil {
+			return errs
+		}
+
+		msg := fmt.Sprintf("failed in step %s (attempt %d of %d)", testStep.String(), attempt, retries+1)
+		tc.AddRetry(report.NewFailure(msg, errs))
+		testStep.Logger.Logf("%s, retrying in %d seconds", msg, delay)
+		if err := sleep(ctx, time.Duration(delay)*time.Second); err != nil {
+			return append(errs, err)
+		}
+	}
 }
 
 func (t *Case) determineNamespace() (*namespace, error) {
+	if t.ns != nil {
+		return t.ns, nil
+	}
+
 	ns := &namespace{
 		Name:        t.PreferredNamespace,
 		AutoCreated: false,
 	}
 	if t.PreferredNamespace == "" {
-		ns.Name = fmt.Sprintf("kudo-test-%s", petname.Generate(2, "-"))
+		name, err := t.generateNamespace()
+		if err != nil {
+			return nil, err
+		}
+		ns.Name = name
 		ns.AutoCreated = true
 	} else {
 		exists, err := t.NamespaceExists(t.PreferredNamespace)
@@ -225,15 +698,30 @@ func (t *Case) determineNamespace() (*namespace, error) {
 		}
 		ns.AutoCreated = !exists
 	}
+	t.ns = ns
 	return ns, nil
 }
 
 // CollectTestStepFiles collects a map of test steps and their associated files
 // from a directory.
 func (t *Case) CollectTestStepFiles() (map[int64][]string, error) {
+	return t.collectStepFiles(t.Dir)
+}
+
+// stepFiles returns the files of a test step of the test case, the step may be one of the hooks.
+func (t *Case) stepFiles(testStep *Step) ([]string, error) {
+	files, err := t.collectStepFiles(testStep.Dir)
+	if err != nil {
+		return nil, err
+	}
+	return files[int64(testStep.Index)], nil
+}
+
+// collectStepFiles collects the files of the test steps in a directory by their index.
+func (t *Case) collectStepFiles(dir string) (map[int64][]string, error) {
 	testStepFiles := map[int64][]string{}
 
-	files, err := ioutil.ReadDir(t.Dir)
+	files, err := ioutil.ReadDir(dir)
 	if err != nil {
 		return nil, err
 	}
@@ -255,9 +743,12 @@ func (t *Case) CollectTestStepFiles() (map[int64][]string, error) {
 			testStepFiles[index] = []string{}
 		}
 
-		testStepPath := filepath.Join(t.Dir, file.Name())
+		testStepPath := filepath.Join(dir, file.Name())
 
-		if file.IsDir() {
+		// a kustomization directory is a single step file which is built
+		if file.IsDir() && isKustomization(testStepPath) {
+			testStepFiles[index] = append(testStepFiles[index], testStepPath)
+		} else if file.IsDir() {
 			testStepDir, err := ioutil.ReadDir(testStepPath)
 			if err != nil {
 				return nil, err
@@ -276,28 +767,83 @@ func (t *Case) CollectTestStepFiles() (map[int64][]string, error) {
 	return testStepFiles, nil
 }
 
+// stepName returns the name of a test step from the names of its files, like Step.LoadYAML.
+func stepName(files []string) string {
+	for _, file := range files {
+		matches := fileNameRegex.FindStringSubmatch(filepath.Base(file))
+		if matches[2] != "assert" && matches[2] != "errors" {
+			return matches[2]
+		}
+	}
+	return ""
+}
+
 // LoadTestSteps loads all of the test steps for a test case.
 func (t *Case) LoadTestSteps() error {
-	testStepFiles, err := t.CollectTestStepFiles()
+	t.variables = map[string]string{}
+
+	// the test files are rendered for the namespace of the test case
+	var templateData *TemplateData
+	if t.Template {
+		ns, err := t.determineNamespace()
+		if err != nil {
+			return err
+		}
+		templateData = NewTemplateData(ns.Name, t.Values, t.variables)
+	}
+
+	testSteps, err := t.loadSteps(t.Dir, templateData)
 	if err != nil {
 		return err
 	}
 
+	// the steps of the hooks of the test suite run before and after the steps of every test case
+	if t.HooksDir != "" {
+		before, err := t.loadHookSteps(filepath.Join(t.HooksDir, beforeHooks), templateData)
+		if err != nil {
+			return err
+		}
+		after, err := t.loadHookSteps(filepath.Join(t.HooksDir, afterHooks), templateData)
+		if err != nil {
+			return err
+		}
+		testSteps = append(append(before, testSteps...), after...)
+	}
+
+	t.Steps = testSteps
+	return nil
+}
+
+// loadSteps loads the test steps of a directory ordered by their index.
+func (t *Case) loadSteps(dir string, templateData *TemplateData) ([]*Step, error) {
+	testStepFiles, err := t.collectStepFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
 	testSteps := []*Step{}
 
 	for index, files := range testStepFiles {
 		testStep := &Step{
-			Timeout: t.Timeout,
-			Index:   int(index),
-			Dir:     t.Dir,
-			Asserts: []runtime.Object{},
-			Apply:   []runtime.Object{},
-			Errors:  []runtime.Object{},
+			Timeout:  t.Timeout,
+			Index:    int(index),
+			Dir:      dir,
+			Asserts:  []runtime.Object{},
+			Apply:    []runtime.Object{},
+			Errors:   []runtime.Object{},
+			template: templateData,
+			values:   t.Values,
 		}
 
-		for _, file := range files {
-			if err := testStep.LoadYAML(file); err != nil {
-				return err
+		// templates are loaded before the step runs, see Step.loadTemplates
+		if t.Template {
+			testStep.Name = stepName(files)
+			testStep.files = files
+		} else {
+			for _, file := range files {
+				if err := testStep.LoadYAML(file); err != nil {
+					return nil, err
+				}
 			}
 		}
 
@@ -308,6 +854,24 @@ func (t *Case) LoadTestSteps() error {
 		return testSteps[i].Index < testSteps[j].Index
 	})
 
-	t.Steps = testSteps
-	return nil
+	return testSteps, nil
+}
+
+// loadHookSteps loads the test steps of a hooks directory, there are none if it does not exist. The names of the
+// steps are prefixed with the name of the directory, e.g. "before-install", to tell them from the test case steps.
+func (t *Case) loadHookSteps(dir string, templateData *TemplateData) ([]*Step, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	testSteps, err := t.loadSteps(dir, templateData)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, testStep := range testSteps {
+		testStep.Hook = true
+		testStep.Name = fmt.Sprintf("%s-%s", filepath.Base(dir), testStep.Name)
+	}
+	return testSteps, nil
 }
@@ -1,15 +1,27 @@
 package test
 
 import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	"github.com/kudobuilder/kuttl/pkg/report"
 	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
 )
 
@@ -297,3 +309,197 @@ func TestCollectTestStepFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadTestStepsTemplate(t *testing.T) {
+	test := &Case{
+		Dir:                "test_data/template",
+		PreferredNamespace: testNamespace,
+		Template:           true,
+		Values:             map[string]string{"name": "hello", "tag": "1.7.9"},
+		Client: func(bool) (client.Client, error) {
+			return fake.NewFakeClientWithScheme(scheme.Scheme), nil
+		},
+		Logger: testutils.NewTestLogger(t, "template"),
+	}
+
+	assert.Nil(t, test.LoadTestSteps())
+	assert.Equal(t, 1, len(test.Steps))
+	assert.Equal(t, "pod", test.Steps[0].Name)
+
+	// templates are rendered when the step runs with the variables captured by the previous steps
+	assert.Equal(t, 0, len(test.Steps[0].Apply))
+	assert.Nil(t, test.Steps[0].loadTemplates())
+
+	pod := test.Steps[0].Apply[0].(*unstructured.Unstructured)
+	assert.Equal(t, "hello", pod.GetName())
+	assert.Equal(t, testNamespace, pod.GetNamespace())
+	containers, _, _ := unstructured.NestedSlice(pod.Object, "spec", "containers")
+	assert.Equal(t, "nginx:1.7.9", containers[0].(map[string]interface{})["image"])
+
+	assertPod := test.Steps[0].Asserts[0].(*unstructured.Unstructured)
+	assert.Equal(t, "hello", assertPod.GetName())
+
+	// a missing value is an error
+	test.Values = map[string]string{"name": "hello"}
+	assert.Nil(t, test.LoadTestSteps())
+	assert.Error(t, test.Steps[0].loadTemplates())
+}
+
+func TestLoadTestStepsHooks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hooks")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, file := range []string{
+		"_hooks/before/00-install.yaml",
+		"_hooks/before/01-configure.yaml",
+		"_hooks/after/00-uninstall.yaml",
+		"example/00-create.yaml",
+		"example/01-update.yaml",
+	} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(file)), 0755))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, file), []byte("apiVersion: kuttl.dev/v1beta1\nkind: TestStep\n"), 0644))
+	}
+
+	h := Harness{T: t}
+	tests, err := h.LoadTests(dir)
+	assert.NoError(t, err)
+	// the hooks directory is not a test case
+	assert.Equal(t, 1, len(tests))
+
+	test := tests[0]
+	test.Logger = testutils.NewTestLogger(t, "example")
+	assert.NoError(t, test.LoadTestSteps())
+
+	names := []string{}
+	for _, step := range test.Steps {
+		names = append(names, step.String())
+	}
+	assert.Equal(t, []string{"0-before-install", "1-before-configure", "0-create", "1-update", "0-after-uninstall"}, names)
+
+	// the hooks run regardless of the range of steps to run
+	from := 1
+	test.FromStep = &from
+	assert.True(t, test.runsStep(test.Steps[0]))
+	assert.False(t, test.runsStep(test.Steps[2]))
+
+	files, err := test.stepFiles(test.Steps[4])
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "_hooks/after/00-uninstall.yaml")}, files)
+}
+
+func TestRunsStep(t *testing.T) {
+	one, three := 1, 3
+
+	for _, tt := range []struct {
+		name     string
+		fromStep *int
+		toStep   *int
+		expected []bool
+	}{
+		{name: "all steps", expected: []bool{true, true, true, true, true}},
+		{name: "from step", fromStep: &three, expected: []bool{false, false, false, true, true}},
+		{name: "to step", toStep: &one, expected: []bool{true, true, false, false, false}},
+		{name: "step range", fromStep: &one, toStep: &three, expected: []bool{false, true, true, true, false}},
+		{name: "single step", fromStep: &three, toStep: &three, expected: []bool{false, false, false, true, false}},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := &Case{FromStep: tt.fromStep, ToStep: tt.toStep}
+			for index, expected := range tt.expected {
+				assert.Equal(t, expected, test.runsStep(&Step{Index: index}), "step %d", index)
+			}
+		})
+	}
+}
+
+func TestRunWithRetries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "retries")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// the step fails on its first attempt only
+	newCase := func() *Case {
+		cl := fake.NewFakeClientWithScheme(scheme.Scheme)
+		return &Case{
+			Name:               "flaky",
+			Dir:                dir,
+			PreferredNamespace: testNamespace,
+			SkipDelete:         true,
+			Suppress:           []string{"events"},
+			Steps: []*Step{
+				{
+					Name:  "create",
+					Index: 1,
+					Dir:   dir,
+					Step: &harness.TestStep{
+						Commands: []harness.Command{{Script: "test -f marker || { touch marker; exit 1; }"}},
+					},
+				},
+			},
+			Client:          func(bool) (client.Client, error) { return cl, nil },
+			DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+			Logger:          testutils.NewTestLogger(t, "flaky"),
+		}
+	}
+
+	tc := newCase().RunWithRetries(context.TODO(), t, 1, false)
+	assert.Nil(t, tc.Failure)
+	assert.Equal(t, 1, len(report.NewJSONReport(suiteOf(tc)).Suites[0].Cases[0].Retries))
+
+	// without retries, a quarantined test case fails without failing the test
+	assert.NoError(t, os.Remove(filepath.Join(dir, "marker")))
+	tc = newCase().RunWithRetries(context.TODO(), t, 0, true)
+	assert.NotNil(t, tc.Failure)
+	assert.Equal(t, "failed in step 1-create", tc.Failure.Message)
+}
+
+// suiteOf returns the closed testsuites of a testcase.
+func suiteOf(tc *report.Testcase) *report.Testsuites {
+	suites := report.NewSuiteCollection("")
+	suites.NewSuite("suite").AddTestcase(tc)
+	suites.Close()
+	return suites
+}
+
+func TestRunKeepOnFailure(t *testing.T) {
+	for _, keep := range []bool{false, true} {
+		keep := keep
+		t.Run(fmt.Sprintf("keep %t", keep), func(t *testing.T) {
+			cl := fake.NewFakeClientWithScheme(scheme.Scheme)
+			c := &Case{
+				Name:              "failed",
+				NamespaceTemplate: "failed",
+				KeepOnFailure:     keep,
+				Suppress:          []string{"events"},
+				Steps: []*Step{
+					{Name: "create", Index: 1, Apply: []runtime.Object{testutils.NewPod("test", "")}},
+					{Name: "fail", Index: 2, Step: &harness.TestStep{Commands: []harness.Command{{Command: "false"}}}},
+				},
+				Client:          func(bool) (client.Client, error) { return cl, nil },
+				DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+				Logger:          testutils.NewTestLogger(t, "failed"),
+			}
+
+			tc := report.NewCase(c.Name)
+			errs := c.runAttempt(context.TODO(), tc)
+			assert.NotEmpty(t, errs)
+
+			// the objects and namespace of the failed test case are only kept with KeepOnFailure
+			err := cl.Get(context.TODO(), types.NamespacedName{Namespace: "failed", Name: "test"}, &corev1.Pod{})
+			assert.Equal(t, !keep, k8serrors.IsNotFound(err))
+			err = cl.Get(context.TODO(), types.NamespacedName{Name: "failed"}, &corev1.Namespace{})
+			assert.Equal(t, !keep, k8serrors.IsNotFound(err))
+		})
+	}
+}
+
+func TestCaseReporter(t *testing.T) {
+	// the failures are logged by the go test operator
+	c := &Case{Logger: testutils.NewTestLogger(t, "")}
+	assert.Equal(t, t, c.reporter(t))
+
+	// the failures are logged as JSON lines instead
+	c.Logger = testutils.NewJSONLogger(ioutil.Discard, "./test/e2e", "hello")
+	assert.Equal(t, &loggerReporter{logger: c.Logger.(testutils.ErrorLogger), test: t}, c.reporter(t))
+}
@@ -0,0 +1,327 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// mirrorPodAnnotation is the annotation of the mirror pods of static pods, they can not be evicted.
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+// RunChaos causes the disruptions of the TestStep.Chaos list in order. If a disruption fails, the following ones
+// are skipped. Waiting for a disruption stops if the context is done.
+func (s *Step) RunChaos(ctx context.Context, namespace string) []error {
+	if s.Step == nil || len(s.Step.Chaos) == 0 {
+		return nil
+	}
+
+	cl, err := s.Client(false)
+	if err != nil {
+		return []error{err}
+	}
+
+	for i, chaos := range s.Step.Chaos {
+		if err := s.runChaos(ctx, cl, chaos, i, namespace); err != nil {
+			return []error{err}
+		}
+	}
+
+	return nil
+}
+
+func (s *Step) runChaos(ctx context.Context, cl client.Client, chaos harness.Chaos, index int, namespace string) error {
+	set := 0
+	for _, isSet := range []bool{chaos.KillPods != nil, chaos.EvictPods != nil, chaos.IsolatePods != nil, chaos.Cordon != "", chaos.Uncordon != "", chaos.Drain != "", chaos.Bounce != ""} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("chaos requires exactly one of killPods, evictPods, isolatePods, cordon, uncordon, drain and bounce")
+	}
+
+	if chaos.Namespace != "" {
+		namespace = chaos.Namespace
+	}
+
+	timeout := s.GetTimeout()
+	if chaos.Timeout > 0 {
+		timeout = chaos.Timeout
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	switch {
+	case chaos.KillPods != nil:
+		pods, err := s.chaosPods(ctx, cl, chaos, chaos.KillPods, namespace)
+		if err != nil {
+			return err
+		}
+		for i := range pods {
+			opts := []client.DeleteOption{}
+			if chaos.GracePeriodSeconds != nil {
+				opts = append(opts, client.GracePeriodSeconds(*chaos.GracePeriodSeconds))
+			}
+			if err := cl.Delete(ctx, &pods[i], opts...); err != nil && !k8serrors.IsNotFound(err) {
+				return fmt.Errorf("killing pod %s/%s: %w", pods[i].Namespace, pods[i].Name, err)
+			}
+			s.Logger.Logf("pod %s/%s killed", pods[i].Namespace, pods[i].Name)
+		}
+		return s.waitForPodsGone(waitCtx, ctx, cl, pods, timeout)
+	case chaos.EvictPods != nil:
+		pods, err := s.chaosPods(ctx, cl, chaos, chaos.EvictPods, namespace)
+		if err != nil {
+			return err
+		}
+		return s.evictPods(waitCtx, ctx, cl, pods, chaos.GracePeriodSeconds, timeout)
+	case chaos.IsolatePods != nil:
+		return s.isolatePods(ctx, cl, chaos.IsolatePods, index, namespace)
+	case chaos.Cordon != "":
+		return s.cordon(ctx, cl, chaos.Cordon, true)
+	case chaos.Uncordon != "":
+		return s.cordon(ctx, cl, chaos.Uncordon, false)
+	case chaos.Drain != "":
+		if err := s.cordon(ctx, cl, chaos.Drain, true); err != nil {
+			return err
+		}
+		pods, err := nodePods(ctx, cl, chaos.Drain)
+		if err != nil {
+			return err
+		}
+		return s.evictPods(waitCtx, ctx, cl, pods, chaos.GracePeriodSeconds, timeout)
+	default:
+		return s.bounce(ctx, cl, types.NamespacedName{Namespace: namespace, Name: chaos.Bounce}, timeout)
+	}
+}
+
+// chaosPods lists the pods with labels in a namespace ordered by name, at most Chaos.Count of them if it is set.
+func (s *Step) chaosPods(ctx context.Context, cl client.Client, chaos harness.Chaos, selector map[string]string, namespace string) ([]corev1.Pod, error) {
+	pods := &corev1.PodList{}
+	if err := cl.List(ctx, pods, client.InNamespace(namespace), client.MatchingLabels(selector)); err != nil {
+		return nil, fmt.Errorf("listing the pods of namespace %s: %w", namespace, err)
+	}
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("no pods with labels %s in namespace %s", labels.Set(selector).String(), namespace)
+	}
+
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+	if chaos.Count > 0 && chaos.Count < len(pods.Items) {
+		return pods.Items[:chaos.Count], nil
+	}
+	return pods.Items, nil
+}
+
+// nodePods lists the pods of a node which are evicted when it is drained, the pods of DaemonSets and mirror pods are
+// skipped like by `kubectl drain --ignore-daemonsets`.
+func nodePods(ctx context.Context, cl client.Client, node string) ([]corev1.Pod, error) {
+	pods := &corev1.PodList{}
+	if err := cl.List(ctx, pods); err != nil {
+		return nil, fmt.Errorf("listing the pods of node %s: %w", node, err)
+	}
+
+	evicted := []corev1.Pod{}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != node || pod.Annotations[mirrorPodAnnotation] != "" {
+			continue
+		}
+		if controller := metav1.GetControllerOf(&pod); controller != nil && controller.Kind == "DaemonSet" {
+			continue
+		}
+		evicted = append(evicted, pod)
+	}
+	return evicted, nil
+}
+
+// evictPods evicts pods and waits for them to be gone. An eviction denied by a PodDisruptionBudget is retried until
+// the context is done.
+func (s *Step) evictPods(waitCtx, ctx context.Context, cl client.Client, pods []corev1.Pod, gracePeriod *int64, timeout int) error {
+	clientset, err := s.kubernetesClientset()
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods {
+		eviction := &policyv1beta1.Eviction{
+			ObjectMeta:    metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+			DeleteOptions: &metav1.DeleteOptions{GracePeriodSeconds: gracePeriod},
+		}
+
+		var evictErr error
+		err := wait.PollImmediateUntil(time.Second, func() (bool, error) {
+			evictErr = clientset.PolicyV1beta1().Evictions(pod.Namespace).Evict(ctx, eviction)
+			if k8serrors.IsTooManyRequests(evictErr) {
+				return false, nil
+			}
+			return true, nil
+		}, waitCtx.Done())
+		if err == wait.ErrWaitTimeout && ctx.Err() != nil {
+			return fmt.Errorf("evicting pod %s/%s: %w", pod.Namespace, pod.Name, ctx.Err())
+		}
+		if err == wait.ErrWaitTimeout {
+			return fmt.Errorf("pod %s/%s is not evicted within %d sec timeout: %v", pod.Namespace, pod.Name, timeout, evictErr)
+		}
+		if evictErr != nil && !k8serrors.IsNotFound(evictErr) {
+			return fmt.Errorf("evicting pod %s/%s: %w", pod.Namespace, pod.Name, evictErr)
+		}
+		s.Logger.Logf("pod %s/%s evicted", pod.Namespace, pod.Name)
+	}
+
+	return s.waitForPodsGone(waitCtx, ctx, cl, pods, timeout)
+}
+
+// waitForPodsGone waits until the pods are deleted. A pod recreated with the same name, e.g. by a StatefulSet, is a
+// different pod.
+func (s *Step) waitForPodsGone(waitCtx, ctx context.Context, cl client.Client, pods []corev1.Pod, timeout int) error {
+	remaining := []string{}
+	err := wait.PollImmediateUntil(time.Second, func() (bool, error) {
+		remaining = []string{}
+		for _, pod := range pods {
+			current := &corev1.Pod{}
+			err := cl.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}, current)
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return false, err
+			}
+			if current.UID == pod.UID {
+				remaining = append(remaining, pod.Namespace+"/"+pod.Name)
+			}
+		}
+		return len(remaining) == 0, nil
+	}, waitCtx.Done())
+
+	if err == wait.ErrWaitTimeout && ctx.Err() != nil {
+		return fmt.Errorf("waiting for pods to be gone: %w", ctx.Err())
+	}
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("pods %s are not gone within %d sec timeout", strings.Join(remaining, ", "), timeout)
+	}
+	return err
+}
+
+// isolatePods creates a NetworkPolicy which denies the ingress and egress traffic of the pods with labels, it is
+// deleted on Clean.
+func (s *Step) isolatePods(ctx context.Context, cl client.Client, selector map[string]string, index int, namespace string) error {
+	policy := &networkingv1.NetworkPolicy{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "NetworkPolicy"},
+		ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("kuttl-isolate-%d-%d", s.Index, index), Namespace: namespace},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: selector},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		},
+	}
+	if _, err := testutils.CreateOrUpdate(ctx, cl, policy, true); err != nil {
+		return fmt.Errorf("isolating the pods with labels %s: %w", labels.Set(selector).String(), err)
+	}
+	s.Logger.Logf("pods with labels %s isolated by %s", labels.Set(selector).String(), testutils.ResourceID(policy))
+
+	for _, obj := range s.chaosObjects {
+		if testutils.ResourceID(obj) == testutils.ResourceID(policy) {
+			return nil
+		}
+	}
+	s.chaosObjects = append(s.chaosObjects, policy)
+	return nil
+}
+
+// cordon marks a node unschedulable or schedulable. The nodes cordoned by the step are uncordoned on Clean.
+func (s *Step) cordon(ctx context.Context, cl client.Client, name string, unschedulable bool) error {
+	node := &corev1.Node{}
+	if err := cl.Get(ctx, types.NamespacedName{Name: name}, node); err != nil {
+		return fmt.Errorf("getting node %s: %w", name, err)
+	}
+
+	if node.Spec.Unschedulable != unschedulable {
+		patch := client.MergeFrom(node.DeepCopy())
+		node.Spec.Unschedulable = unschedulable
+		if err := cl.Patch(ctx, node, patch); err != nil {
+			return fmt.Errorf("updating node %s: %w", name, err)
+		}
+	}
+
+	cordoned := []string{}
+	for _, node := range s.cordoned {
+		if node != name {
+			cordoned = append(cordoned, node)
+		}
+	}
+	if unschedulable {
+		s.Logger.Logf("node %s cordoned", name)
+		cordoned = append(cordoned, name)
+	} else {
+		s.Logger.Logf("node %s uncordoned", name)
+	}
+	s.cordoned = cordoned
+	return nil
+}
+
+// bounce scales a deployment to 0 replicas and back, it waits for the rollouts of both.
+func (s *Step) bounce(ctx context.Context, cl client.Client, key types.NamespacedName, timeout int) error {
+	deployment := &appsv1.Deployment{}
+	if err := cl.Get(ctx, key, deployment); err != nil {
+		return fmt.Errorf("getting deployment %s: %w", key, err)
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	if err := s.scaleWorkload(ctx, cl, "Deployment", key, 0, timeout); err != nil {
+		return err
+	}
+	return s.scaleWorkload(ctx, cl, "Deployment", key, replicas, timeout)
+}
+
+// cleanChaos deletes the network policies of the step and uncordons the nodes it cordoned.
+func (s *Step) cleanChaos(cl client.Client) error {
+	for _, obj := range s.chaosObjects {
+		if err := cl.Delete(context.TODO(), obj); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	s.chaosObjects = nil
+
+	for _, node := range s.cordoned {
+		if err := s.cordon(context.TODO(), cl, node, false); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// kubernetesClientset returns the clientset of the requests which are not supported by the Client, e.g. evictions.
+func (s *Step) kubernetesClientset() (kubernetes.Interface, error) {
+	if s.clientset != nil {
+		return s.clientset()
+	}
+
+	if s.Config == nil {
+		return nil, errors.New("a cluster configuration is required")
+	}
+	cfg, err := s.Config()
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(cfg)
+}
@@ -0,0 +1,160 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	ktesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func chaosPod(name, node string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, Labels: labels, UID: types.UID(name)},
+		Spec:       corev1.PodSpec{NodeName: node},
+	}
+}
+
+// evictingClientset returns a clientset whose evictions delete the pods of a client, the first eviction of each pod
+// is denied like by a PodDisruptionBudget.
+func evictingClientset(cl client.Client) *kubefake.Clientset {
+	denied := map[string]bool{}
+	clientset := kubefake.NewSimpleClientset()
+	clientset.PrependReactor("create", "pods", func(action ktesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(ktesting.CreateAction).GetObject().(*policyv1beta1.Eviction)
+		if !denied[eviction.Name] {
+			denied[eviction.Name] = true
+			return true, nil, k8serrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+		}
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: eviction.Name, Namespace: eviction.Namespace}}
+		return true, nil, cl.Delete(context.TODO(), pod)
+	})
+	return clientset
+}
+
+func chaosTestStep(t *testing.T, cl client.Client, chaos ...harness.Chaos) *Step {
+	return &Step{
+		Name:    "chaos",
+		Index:   1,
+		Timeout: 10,
+		Logger:  testutils.NewTestLogger(t, ""),
+		Step: &harness.TestStep{
+			Chaos: chaos,
+		},
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+		clientset:       func() (kubernetes.Interface, error) { return evictingClientset(cl), nil },
+	}
+}
+
+func podNames(t *testing.T, cl client.Client) []string {
+	pods := &corev1.PodList{}
+	require.NoError(t, cl.List(context.TODO(), pods))
+	names := []string{}
+	for _, pod := range pods.Items {
+		names = append(names, pod.Name)
+	}
+	return names
+}
+
+func TestRunChaosPods(t *testing.T) {
+	db := map[string]string{"app": "db"}
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme,
+		chaosPod("db-0", "node-1", db), chaosPod("db-1", "node-1", db), chaosPod("db-2", "node-2", db),
+		chaosPod("web-0", "node-1", map[string]string{"app": "web"}),
+	)
+
+	step := chaosTestStep(t, cl,
+		harness.Chaos{KillPods: db, Count: 1},
+		harness.Chaos{EvictPods: db, Count: 1},
+		harness.Chaos{IsolatePods: map[string]string{"app": "web"}},
+	)
+	require.Empty(t, step.RunChaos(context.TODO(), testNamespace))
+	assert.Equal(t, []string{"db-2", "web-0"}, podNames(t, cl))
+
+	policy := &networkingv1.NetworkPolicy{}
+	require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: "kuttl-isolate-1-2"}, policy))
+	assert.Equal(t, map[string]string{"app": "web"}, policy.Spec.PodSelector.MatchLabels)
+	assert.Empty(t, policy.Spec.Ingress)
+	assert.Empty(t, policy.Spec.Egress)
+
+	// the network policy is deleted on clean
+	require.NoError(t, step.Clean(testNamespace))
+	err := cl.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: "kuttl-isolate-1-2"}, policy)
+	assert.True(t, k8serrors.IsNotFound(err))
+
+	errs := chaosTestStep(t, cl, harness.Chaos{KillPods: map[string]string{"app": "cache"}}).RunChaos(context.TODO(), testNamespace)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "no pods with labels app=cache in namespace "+testNamespace)
+
+	errs = chaosTestStep(t, cl, harness.Chaos{KillPods: db, Cordon: "node-1"}).RunChaos(context.TODO(), testNamespace)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "chaos requires exactly one of killPods, evictPods, isolatePods, cordon, uncordon, drain and bounce")
+}
+
+func TestRunChaosDrain(t *testing.T) {
+	daemon := chaosPod("logs-0", "node-1", nil)
+	controller := true
+	daemon.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "logs", Controller: &controller}}
+	mirror := chaosPod("etcd-node-1", "node-1", nil)
+	mirror.Annotations = map[string]string{mirrorPodAnnotation: "checksum"}
+
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme,
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		chaosPod("db-0", "node-1", nil), chaosPod("db-1", "node-2", nil), daemon, mirror,
+	)
+
+	step := chaosTestStep(t, cl, harness.Chaos{Drain: "node-1"})
+	require.Empty(t, step.RunChaos(context.TODO(), testNamespace))
+	assert.ElementsMatch(t, []string{"db-1", "logs-0", "etcd-node-1"}, podNames(t, cl))
+
+	node := &corev1.Node{}
+	require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Name: "node-1"}, node))
+	assert.True(t, node.Spec.Unschedulable)
+
+	// the drained node is uncordoned on clean
+	require.NoError(t, step.Clean(testNamespace))
+	node = &corev1.Node{}
+	require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Name: "node-1"}, node))
+	assert.False(t, node.Spec.Unschedulable)
+}
+
+func TestRunChaosBounce(t *testing.T) {
+	replicas := int32(2)
+	cl := generationClient{fake.NewFakeClientWithScheme(scheme.Scheme, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "operator", Namespace: testNamespace, Generation: 1},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+	})}
+
+	stop := rollOutDeployment(cl, types.NamespacedName{Namespace: testNamespace, Name: "operator"})
+
+	step := chaosTestStep(t, cl, harness.Chaos{Bounce: "operator"})
+	require.Empty(t, step.RunChaos(context.TODO(), testNamespace))
+	assert.Equal(t, []int32{0, 2}, stop())
+
+	errs := chaosTestStep(t, cl, harness.Chaos{Bounce: "missing"}).RunChaos(context.TODO(), testNamespace)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "getting deployment "+testNamespace+"/missing")
+}
@@ -0,0 +1,176 @@
+package test
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// clusterAnnotation selects the cluster of TestSuite.Clusters an object of a test step is applied to or asserted in.
+const clusterAnnotation = "kuttl.dev/cluster"
+
+// ClusterClients are the clients of a cluster of TestSuite.Clusters.
+type ClusterClients struct {
+	Config          *rest.Config
+	Client          client.Client
+	DiscoveryClient discovery.CachedDiscoveryInterface
+}
+
+// ClusterClients returns the clients of a cluster of the test suite, they are created on first use and shared by the
+// test cases.
+func (h *Harness) ClusterClients(name string) (*ClusterClients, error) {
+	h.clustersLock.Lock()
+	defer h.clustersLock.Unlock()
+
+	if clients, ok := h.clusters[name]; ok {
+		return clients, nil
+	}
+
+	cluster, ok := h.TestSuite.Clusters[name]
+	if !ok {
+		return nil, fmt.Errorf("cluster %q is not defined in the test suite", name)
+	}
+
+	cfg, err := clusterConfig(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("loading the configuration of cluster %q: %w", name, err)
+	}
+	h.limitRate(cfg)
+
+	dClient, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cached := testutils.NewCachedDiscoveryClient(dClient)
+
+	cl, err := testutils.NewRetryClient(cfg, client.Options{
+		Scheme: testutils.Scheme(),
+		Mapper: restmapper.NewDeferredDiscoveryRESTMapper(cached),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if h.clusters == nil {
+		h.clusters = map[string]*ClusterClients{}
+	}
+	h.clusters[name] = &ClusterClients{Config: cfg, Client: cl, DiscoveryClient: cached}
+	return h.clusters[name], nil
+}
+
+// clusterNames returns the sorted names of the clusters of the test suite.
+func (h *Harness) clusterNames() []string {
+	names := []string{}
+	for name := range h.TestSuite.Clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// clusterConfig loads the configuration of a cluster from its kubeconfig.
+func clusterConfig(cluster harness.Cluster) (*rest.Config, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if cluster.Kubeconfig != "" {
+		rules.ExplicitPath = cluster.Kubeconfig
+	}
+
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: cluster.Context}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+}
+
+// cluster returns the cluster of TestSuite.Clusters the step targets, the cluster of the test suite if empty.
+func (s *Step) cluster() string {
+	if s.Step == nil {
+		return ""
+	}
+	return s.Step.Cluster
+}
+
+// objectCluster returns the cluster of an object set by the cluster annotation, or the cluster of the step.
+func (s *Step) objectCluster(obj runtime.Object) string {
+	if m, err := meta.Accessor(obj); err == nil {
+		if cluster, ok := m.GetAnnotations()[clusterAnnotation]; ok {
+			return cluster
+		}
+	}
+	return s.cluster()
+}
+
+// objectClients returns the clients of the cluster of the cluster annotation of an object, or the clients of the step
+// if it does not have the annotation.
+func (s *Step) objectClients(cl client.Client, dClient discovery.DiscoveryInterface, obj runtime.Object) (client.Client, discovery.DiscoveryInterface, error) {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cluster, ok := m.GetAnnotations()[clusterAnnotation]
+	if !ok {
+		return cl, dClient, nil
+	}
+
+	clients, err := s.clusterClients(cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+	return clients.Client, clients.DiscoveryClient, nil
+}
+
+// clusterClients returns the clients of a cluster of the test suite.
+func (s *Step) clusterClients(name string) (*ClusterClients, error) {
+	if s.Clusters == nil {
+		return nil, fmt.Errorf("cluster %q is not defined in the test suite", name)
+	}
+	return s.Clusters(name)
+}
+
+// withCluster sets the clients of the step to the clients of the cluster of the step, they are resolved when they
+// are used as the TestStep may be rendered right before the step runs.
+func (s *Step) withCluster(cl func(forceNew bool) (client.Client, error), dClient func() (discovery.DiscoveryInterface, error), cfg func() (*rest.Config, error)) {
+	s.Client = func(forceNew bool) (client.Client, error) {
+		if s.cluster() == "" {
+			return cl(forceNew)
+		}
+		clients, err := s.clusterClients(s.cluster())
+		if err != nil {
+			return nil, err
+		}
+		return clients.Client, nil
+	}
+	s.DiscoveryClient = func() (discovery.DiscoveryInterface, error) {
+		if s.cluster() == "" {
+			return dClient()
+		}
+		clients, err := s.clusterClients(s.cluster())
+		if err != nil {
+			return nil, err
+		}
+		return clients.DiscoveryClient, nil
+	}
+	// requests which need the configuration fail without it, see RunExec
+	if cfg == nil {
+		s.Config = nil
+		return
+	}
+	s.Config = func() (*rest.Config, error) {
+		if s.cluster() == "" {
+			return cfg()
+		}
+		clients, err := s.clusterClients(s.cluster())
+		if err != nil {
+			return nil, err
+		}
+		return clients.Config, nil
+	}
+}
@@ -0,0 +1,155 @@
+package test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+const clusterKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: east
+  cluster:
+    server: https://east.example.com
+- name: west
+  cluster:
+    server: https://west.example.com
+contexts:
+- name: east
+  context:
+    cluster: east
+    user: user
+- name: west
+  context:
+    cluster: west
+    user: user
+current-context: east
+users:
+- name: user
+  user:
+    token: token
+`
+
+func TestClusterConfig(t *testing.T) {
+	f, err := ioutil.TempFile("", "kubeconfig")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(clusterKubeconfig)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	cfg, err := clusterConfig(harness.Cluster{Kubeconfig: f.Name()})
+	require.NoError(t, err)
+	assert.Equal(t, "https://east.example.com", cfg.Host)
+
+	cfg, err = clusterConfig(harness.Cluster{Kubeconfig: f.Name(), Context: "west"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://west.example.com", cfg.Host)
+
+	_, err = clusterConfig(harness.Cluster{Kubeconfig: f.Name(), Context: "north"})
+	assert.Error(t, err)
+}
+
+// clusterStep returns a step with a default cluster and the clusters of the test suite.
+func clusterStep(t *testing.T, cl client.Client, clusters map[string]client.Client) *Step {
+	step := &Step{
+		Logger: testutils.NewTestLogger(t, ""),
+		Clusters: func(name string) (*ClusterClients, error) {
+			return &ClusterClients{
+				Client:          clusters[name],
+				DiscoveryClient: testutils.NewCachedDiscoveryClient(testutils.FakeDiscoveryClient()),
+			}, nil
+		},
+	}
+	step.withCluster(
+		func(bool) (client.Client, error) { return cl, nil },
+		func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+		nil,
+	)
+	return step
+}
+
+func TestObjectCluster(t *testing.T) {
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme)
+	west := fake.NewFakeClientWithScheme(scheme.Scheme)
+	step := clusterStep(t, cl, map[string]client.Client{"west": west})
+
+	pod := testutils.NewPod("hello", "")
+	westPod := testutils.WithAnnotations(testutils.NewPod("hello", ""), map[string]string{clusterAnnotation: "west"})
+
+	// the object of the cluster is created in it, the assert does not compare the annotation
+	step.Apply = []runtime.Object{westPod}
+	assert.Empty(t, step.Create(context.TODO(), testNamespace))
+	assert.NoError(t, west.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: "hello"}, &corev1.Pod{}))
+	assert.NotEmpty(t, step.CheckResource(context.TODO(), pod, testNamespace))
+	assert.Empty(t, step.CheckResource(context.TODO(), westPod, testNamespace))
+
+	// the objects of a step of the cluster are in it unless they are annotated
+	step.Step = &harness.TestStep{Cluster: "west"}
+	assert.Empty(t, step.CheckResource(context.TODO(), pod, testNamespace))
+	assert.Equal(t, "west", step.objectCluster(pod))
+
+	assert.NoError(t, step.Clean(testNamespace))
+	assert.NoError(t, step.CheckResourceAbsent(context.TODO(), pod, testNamespace))
+}
+
+func TestObjectClusterRecordGolden(t *testing.T) {
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme)
+	west := fake.NewFakeClientWithScheme(scheme.Scheme, testutils.NewPod("hello", testNamespace))
+	step := clusterStep(t, cl, map[string]client.Client{"west": west})
+	dClient := testutils.FakeDiscoveryClient()
+
+	// the objects of the cluster are recorded and compared from it
+	westPod := testutils.WithAnnotations(testutils.NewPod("hello", ""), map[string]string{clusterAnnotation: "west"})
+	recorded, err := step.recordObject(context.TODO(), cl, dClient, westPod, testNamespace)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", recorded.GetName())
+	_, err = step.recordObject(context.TODO(), cl, dClient, testutils.NewPod("hello", ""), testNamespace)
+	assert.Error(t, err)
+
+	golden := harness.Golden{ObjectReference: corev1.ObjectReference{APIVersion: "v1", Kind: "Pod", Name: "hello"}, File: "pod.yaml"}
+	_, err = step.goldenObject(context.TODO(), testNamespace, golden)
+	assert.Error(t, err)
+	golden.Cluster = "west"
+	actual, err := step.goldenObject(context.TODO(), testNamespace, golden)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", actual.GetName())
+}
+
+func TestCreateNamespaceClusters(t *testing.T) {
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme)
+	west := fake.NewFakeClientWithScheme(scheme.Scheme)
+	c := &Case{
+		Name:         "example",
+		Client:       func(bool) (client.Client, error) { return cl, nil },
+		Clusters:     func(string) (*ClusterClients, error) { return &ClusterClients{Client: west}, nil },
+		ClusterNames: []string{"west"},
+		Logger:       testutils.NewTestLogger(t, "example"),
+	}
+
+	ns := &namespace{Name: "e2e-example", AutoCreated: true}
+	require.NoError(t, c.CreateNamespace(ns))
+	for _, cl := range []client.Client{cl, west} {
+		assert.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Name: ns.Name}, &corev1.Namespace{}))
+	}
+
+	require.NoError(t, c.DeleteNamespace(ns))
+	for _, cl := range []client.Client{cl, west} {
+		assert.Error(t, cl.Get(context.TODO(), types.NamespacedName{Name: ns.Name}, &corev1.Namespace{}))
+	}
+}
@@ -0,0 +1,115 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// CheckConditions checks the condition asserts of the TestAssert: each object of a condition assert must have the
+// condition with the expected status, reason and message.
+func (s *Step) CheckConditions(ctx context.Context, namespace string) []error {
+	if s.Assert == nil || len(s.Assert.Conditions) == 0 {
+		return nil
+	}
+
+	cl, err := s.Client(false)
+	if err != nil {
+		return []error{err}
+	}
+
+	dClient, err := s.DiscoveryClient()
+	if err != nil {
+		return []error{err}
+	}
+
+	errs := []error{}
+
+	for _, expected := range s.Assert.Conditions {
+		if expected.Type == "" {
+			errs = append(errs, fmt.Errorf("condition assert of kind %s requires a type", expected.Kind))
+			continue
+		}
+
+		var message *regexp.Regexp
+		if expected.Message != "" {
+			if message, err = regexp.Compile(expected.Message); err != nil {
+				errs = append(errs, fmt.Errorf("invalid message %q of condition assert %s: %w", expected.Message, expected.Type, err))
+				continue
+			}
+		}
+
+		objs, err := s.objectsFromRef(ctx, cl, dClient, expected.ObjectReference, namespace)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(objs) == 0 {
+			errs = append(errs, fmt.Errorf("no objects of kind %s match the labels of condition assert %s", expected.Kind, expected.Type))
+			continue
+		}
+
+		for _, obj := range objs {
+			// named references are not fetched by objectsFromRef
+			if expected.Name != "" {
+				if err := cl.Get(ctx, testutils.ObjectKey(obj), obj); k8serrors.IsNotFound(err) {
+					errs = append(errs, fmt.Errorf("%s not found for condition assert %s", testutils.ResourceID(obj), expected.Type))
+					continue
+				} else if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+			}
+
+			if err := checkCondition(obj.(*unstructured.Unstructured), expected, message); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+// checkCondition checks that an object has the condition of a condition assert, message is the compiled message of
+// the condition assert if it is set.
+func checkCondition(obj *unstructured.Unstructured, expected harness.ConditionAssert, message *regexp.Regexp) error {
+	status := expected.Status
+	if status == "" {
+		status = "True"
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, condition := range conditions {
+		c, ok := condition.(map[string]interface{})
+		if !ok || c["type"] != expected.Type {
+			continue
+		}
+
+		actualStatus, _ := c["status"].(string)
+		actualReason, _ := c["reason"].(string)
+		actualMessage, _ := c["message"].(string)
+
+		if actualStatus == status && (expected.Reason == "" || actualReason == expected.Reason) &&
+			(message == nil || message.MatchString(actualMessage)) {
+			return nil
+		}
+
+		want := status
+		if expected.Reason != "" {
+			want += ", reason " + expected.Reason
+		}
+		if message != nil {
+			want += fmt.Sprintf(", message matching %q", expected.Message)
+		}
+		return fmt.Errorf("condition %s of %s is %s, reason %s, message %q, expected %s",
+			expected.Type, testutils.ResourceID(obj), actualStatus, actualReason, actualMessage, want)
+	}
+
+	return fmt.Errorf("%s has no condition %s", testutils.ResourceID(obj), expected.Type)
+}
@@ -0,0 +1,101 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestStepCheckConditions(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "operator", Namespace: testNamespace},
+		Status: appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: "NewReplicaSetAvailable", LastUpdateTime: metav1.Now()},
+			{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse, Reason: "MinimumReplicasUnavailable", Message: "Deployment does not have minimum availability."},
+		}},
+	}
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme, deployment)
+
+	condition := func(name, conditionType, status, reason, message string) harness.ConditionAssert {
+		return harness.ConditionAssert{
+			ObjectReference: harness.ObjectReference{ObjectReference: driftRef("Deployment", "apps/v1", name)},
+			Type:            conditionType,
+			Status:          status,
+			Reason:          reason,
+			Message:         message,
+		}
+	}
+
+	for _, test := range []struct {
+		name       string
+		conditions []harness.ConditionAssert
+		errors     []string
+	}{
+		{
+			name: "conditions match regardless of their order",
+			conditions: []harness.ConditionAssert{
+				condition("operator", "Available", "False", "MinimumReplicasUnavailable", "minimum availability"),
+				condition("operator", "Progressing", "", "", ""),
+			},
+		},
+		{
+			name:       "status does not match",
+			conditions: []harness.ConditionAssert{condition("operator", "Available", "", "", "")},
+			errors: []string{
+				`condition Available of Deployment:world/operator is False, reason MinimumReplicasUnavailable, message "Deployment does not have minimum availability.", expected True`,
+			},
+		},
+		{
+			name:       "reason and message do not match",
+			conditions: []harness.ConditionAssert{condition("operator", "Progressing", "True", "ReplicaSetUpdated", "^updated")},
+			errors: []string{
+				`condition Progressing of Deployment:world/operator is True, reason NewReplicaSetAvailable, message "", expected True, reason ReplicaSetUpdated, message matching "^updated"`,
+			},
+		},
+		{
+			name: "missing condition and object",
+			conditions: []harness.ConditionAssert{
+				condition("operator", "ReplicaFailure", "", "", ""),
+				condition("missing", "Available", "", "", ""),
+			},
+			errors: []string{
+				"Deployment:world/operator has no condition ReplicaFailure",
+				"Deployment:world/missing not found for condition assert Available",
+			},
+		},
+		{
+			name: "invalid condition asserts",
+			conditions: []harness.ConditionAssert{
+				condition("operator", "", "", "", ""),
+				condition("operator", "Available", "", "", "("),
+			},
+			errors: []string{
+				"condition assert of kind Deployment requires a type",
+				"invalid message \"(\" of condition assert Available: error parsing regexp: missing closing ): `(`",
+			},
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			step := &Step{
+				Logger:          testutils.NewTestLogger(t, ""),
+				Assert:          &harness.TestAssert{Conditions: test.conditions},
+				Client:          func(bool) (client.Client, error) { return cl, nil },
+				DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+			}
+			assert.Equal(t, test.errors, errorStrings(step.CheckConditions(context.TODO(), testNamespace)))
+		})
+	}
+}
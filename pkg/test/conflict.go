@@ -0,0 +1,74 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// applyFieldManager is the field manager of the objects applied by test steps in strict apply mode, the fields of
+// the objects managed by other managers are not overwritten, see applyConflict.
+const applyFieldManager = "kuttl"
+
+// fieldOwnerClient sets the field manager of the objects it creates and patches.
+type fieldOwnerClient struct {
+	client.Client
+	owner client.FieldOwner
+}
+
+func (c fieldOwnerClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	return c.Client.Create(ctx, obj, append(opts, c.owner)...)
+}
+
+func (c fieldOwnerClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return c.Client.Patch(ctx, obj, patch, append(opts, c.owner)...)
+}
+
+// applyConflict checks if an object to apply already exists with different content which is managed by another
+// field manager than applyFieldManager, e.g. a leftover of a previous run or an object of another test. The object
+// must be namespaced.
+func applyConflict(ctx context.Context, cl client.Client, obj runtime.Object) error {
+	actual := &unstructured.Unstructured{}
+	actual.SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+	if err := cl.Get(ctx, testutils.ObjectKey(obj), actual); k8serrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// the expected object is copied, the content of unstructured objects is shared by the converter
+	expected, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj.DeepCopyObject())
+	if err != nil {
+		return err
+	}
+
+	differs := testutils.IsSubset(expected, actual.UnstructuredContent())
+	if differs == nil {
+		return nil
+	}
+
+	managers := map[string]bool{}
+	for _, entry := range actual.GetManagedFields() {
+		if entry.Manager != applyFieldManager {
+			managers[entry.Manager] = true
+		}
+	}
+	if len(managers) == 0 {
+		return nil
+	}
+
+	names := []string{}
+	for name := range managers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("%s already exists with different content managed by %s: %v", testutils.ResourceID(obj), strings.Join(names, ", "), differs)
+}
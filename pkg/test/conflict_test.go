@@ -0,0 +1,96 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestStepCreateStrictApply(t *testing.T) {
+	pod := func(name, value string, managers ...string) *corev1.Pod {
+		p := &corev1.Pod{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, Labels: map[string]string{"value": value}},
+		}
+		for _, manager := range managers {
+			p.ManagedFields = append(p.ManagedFields, metav1.ManagedFieldsEntry{Manager: manager, Operation: metav1.ManagedFieldsOperationUpdate})
+		}
+		return p
+	}
+	apply := func(name, value string) runtime.Object {
+		obj := pod(name, value)
+		obj.Namespace = ""
+		return obj
+	}
+
+	for _, test := range []struct {
+		name     string
+		existing *corev1.Pod
+		apply    runtime.Object
+		errors   []string
+		value    string
+	}{
+		{
+			name:  "object does not exist",
+			apply: apply("hello", "new"),
+			value: "new",
+		},
+		{
+			name:     "object of another manager with the same content",
+			existing: pod("hello", "same", "helm"),
+			apply:    apply("hello", "same"),
+			value:    "same",
+		},
+		{
+			name:     "object of kuttl with different content",
+			existing: pod("hello", "old", applyFieldManager),
+			apply:    apply("hello", "new"),
+			value:    "new",
+		},
+		{
+			name:     "object of other managers with different content",
+			existing: pod("hello", "old", applyFieldManager, "kubectl", "helm"),
+			apply:    apply("hello", "new"),
+			errors: []string{
+				"Pod:world/hello already exists with different content managed by helm, kubectl: " +
+					".metadata.labels.value: value mismatch, expected: new != actual: old",
+			},
+			value: "old",
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			objs := []runtime.Object{}
+			if test.existing != nil {
+				objs = append(objs, test.existing)
+			}
+			cl := fake.NewFakeClientWithScheme(scheme.Scheme, objs...)
+
+			step := Step{
+				Logger:          testutils.NewTestLogger(t, ""),
+				Apply:           []runtime.Object{test.apply},
+				StrictApply:     true,
+				Client:          func(bool) (client.Client, error) { return cl, nil },
+				DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+			}
+			assert.Equal(t, test.errors, errorStrings(step.Create(context.TODO(), testNamespace)))
+
+			actual := &corev1.Pod{}
+			require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: "hello"}, actual))
+			assert.Equal(t, test.value, actual.Labels["value"])
+		})
+	}
+}
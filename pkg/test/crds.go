@@ -0,0 +1,152 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kudobuilder/kuttl/pkg/http"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// installCRDs installs the CRDs of TestSuite.CRDs and waits until they are established.
+func (h *Harness) installCRDs(ctx context.Context, cl client.Client) error {
+	if len(h.TestSuite.CRDs) == 0 {
+		return nil
+	}
+
+	crds, err := loadCRDs(h.TestSuite.CRDs)
+	if err != nil {
+		return err
+	}
+
+	for _, crd := range crds {
+		if _, err := testutils.CreateOrUpdate(ctx, cl, crd, true); err != nil {
+			return fmt.Errorf("error creating resource %s: %w", testutils.ResourceID(crd), err)
+		}
+		h.T.Logf("CustomResourceDefinition %s installed", testutils.ResourceID(crd))
+	}
+
+	return waitForCRDsEstablished(ctx, cl, crds, time.Duration(h.GetTimeout())*time.Second)
+}
+
+// loadCRDs loads the CRDs of local paths, glob patterns, URLs and OCI artifacts, other objects are ignored.
+func loadCRDs(sources []string) ([]runtime.Object, error) {
+	objects := []runtime.Object{}
+
+	for _, source := range sources {
+		var objs []runtime.Object
+		var err error
+
+		switch {
+		case http.IsOCIReference(source):
+			objs, err = http.OCIToRuntimeObjects(source)
+		case http.IsURL(source):
+			objs, err = http.ToRuntimeObjects(source)
+		default:
+			objs, err = pathObjects(source)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("loading CRDs from %s: %w", source, err)
+		}
+
+		for _, obj := range objs {
+			if isCRD(obj) {
+				objects = append(objects, obj)
+			}
+		}
+	}
+
+	return objects, nil
+}
+
+// isCRD checks if an object is a CustomResourceDefinition of any version.
+func isCRD(obj runtime.Object) bool {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	return gvk.Group == "apiextensions.k8s.io" && gvk.Kind == "CustomResourceDefinition"
+}
+
+// pathObjects loads the objects of the manifests of a file, a directory or the files and directories matching a
+// glob pattern.
+func pathObjects(pattern string) ([]runtime.Object, error) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no such file or directory")
+	}
+
+	objects := []runtime.Object{}
+	for _, path := range paths {
+		err := filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || !isManifest(path) {
+				return err
+			}
+
+			objs, err := testutils.LoadYAMLFromFile(path)
+			if err != nil {
+				return err
+			}
+			objects = append(objects, objs...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return objects, nil
+}
+
+// isManifest checks if a file is a YAML or JSON manifest by its extension.
+func isManifest(path string) bool {
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// waitForCRDsEstablished waits until the Established condition of the CRDs is true.
+func waitForCRDsEstablished(ctx context.Context, cl client.Client, crds []runtime.Object, timeout time.Duration) error {
+	pending := append([]runtime.Object{}, crds...)
+
+	err := wait.PollImmediate(100*time.Millisecond, timeout, func() (bool, error) {
+		remaining := []runtime.Object{}
+		for _, crd := range pending {
+			actual := &unstructured.Unstructured{}
+			actual.SetGroupVersionKind(crd.GetObjectKind().GroupVersionKind())
+			if err := cl.Get(ctx, testutils.ObjectKey(crd), actual); err != nil {
+				return false, err
+			}
+			if !conditionTrue(actual, "Established") {
+				remaining = append(remaining, crd)
+			}
+		}
+		pending = remaining
+		return len(pending) == 0, nil
+	})
+	if err != nil && len(pending) > 0 {
+		return fmt.Errorf("%s is not established: %w", testutils.ResourceID(pending[0]), err)
+	}
+	return err
+}
+
+// conditionTrue checks if a condition of the status of an object is true, e.g. the Established condition of a CRD.
+func conditionTrue(obj *unstructured.Unstructured, conditionType string) bool {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, condition := range conditions {
+		c, ok := condition.(map[string]interface{})
+		if ok && c["type"] == conditionType && c["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func testCRD(name string) string {
+	return fmt.Sprintf("apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: %s\n", name)
+}
+
+func resourceIDs(objs []runtime.Object) []string {
+	ids := []string{}
+	for _, obj := range objs {
+		ids = append(ids, testutils.ResourceID(obj))
+	}
+	return ids
+}
+
+func TestLoadCRDs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-crds")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	configMap := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n"
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "chart", "crds"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "chart", "crds", "a.yaml"), []byte(testCRD("a.example.com")+"---\n"+configMap), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "chart", "crds", "README.md"), []byte("# CRDs"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "b-crd.yaml"), []byte(testCRD("b.example.com")), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "c-crd.yaml"), []byte(testCRD("c.example.com")), 0644))
+
+	crds, err := loadCRDs([]string{filepath.Join(dir, "chart"), filepath.Join(dir, "*-crd.yaml")})
+	require.NoError(t, err)
+	// the config map and the README are ignored
+	assert.Equal(t, []string{
+		"CustomResourceDefinition:/a.example.com",
+		"CustomResourceDefinition:/b.example.com",
+		"CustomResourceDefinition:/c.example.com",
+	}, resourceIDs(crds))
+
+	_, err = loadCRDs([]string{filepath.Join(dir, "missing")})
+	assert.EqualError(t, err, fmt.Sprintf("loading CRDs from %s: no such file or directory", filepath.Join(dir, "missing")))
+}
+
+func TestWaitForCRDsEstablished(t *testing.T) {
+	established, err := testutils.LoadYAML("crd.yaml", strings.NewReader(testCRD("a.example.com")+`status:
+  conditions:
+  - type: NamesAccepted
+    status: "True"
+  - type: Established
+    status: "True"
+`))
+	require.NoError(t, err)
+	pending, err := testutils.LoadYAML("crd.yaml", strings.NewReader(testCRD("b.example.com")))
+	require.NoError(t, err)
+
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme, established[0], pending[0])
+
+	assert.NoError(t, waitForCRDsEstablished(context.TODO(), cl, established, time.Second))
+
+	err = waitForCRDsEstablished(context.TODO(), cl, append(established, pending...), 200*time.Millisecond)
+	assert.EqualError(t, err, "CustomResourceDefinition:/b.example.com is not established: timed out waiting for the condition")
+}
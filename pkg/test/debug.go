@@ -0,0 +1,122 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var (
+	// debugLock serializes the debugging of failed test cases, the test cases running in parallel share the terminal.
+	debugLock sync.Mutex
+
+	// interactive checks if the input is an interactive terminal, it is replaced by the tests.
+	interactive = stdinIsTerminal
+	// inputLines returns the lines entered on the terminal, it is replaced by the tests.
+	inputLines = stdinLines
+
+	stdinOnce  sync.Once
+	stdinInput chan string
+)
+
+// pauseOnFailure logs the namespace of a failed test case and pauses it before it is cleaned up. The pause ends early
+// when the test case is cancelled or, on an interactive terminal, when enter is pressed. With DebugShell an
+// interactive shell is started instead and the pause ends when it exits.
+func (t *Case) pauseOnFailure(ctx context.Context, namespace string) {
+	if t.KeepOnFailure {
+		t.Logger.Logf("KEEPING NAMESPACE %s of the failed test, inspect it with: kubectl get all --namespace %s", namespace, namespace)
+	}
+
+	if t.DebugShell && !interactive() {
+		t.Logger.Log("not starting the debug shell, the input is not an interactive terminal")
+	}
+	shell := t.DebugShell && interactive()
+	if !shell && t.PauseOnFailure <= 0 {
+		return
+	}
+
+	debugLock.Lock()
+	defer debugLock.Unlock()
+
+	kubeconfig := kubeconfigFile()
+	t.Logger.Logf("the failed test ran in namespace %s, to connect to the cluster, run: export KUBECONFIG=%q", namespace, kubeconfig)
+
+	if shell {
+		t.Logger.Log("starting debug shell, exit it to continue the test")
+		if err := debugShellCommand(ctx, namespace, kubeconfig).Run(); err != nil {
+			t.Logger.Log("debug shell exited", err)
+		}
+		return
+	}
+
+	var input <-chan string
+	if interactive() {
+		t.Logger.Logf("pausing the failed test for %d seconds before it is cleaned up, press enter to continue", t.PauseOnFailure)
+		input = inputLines()
+	} else {
+		t.Logger.Logf("pausing the failed test for %d seconds before it is cleaned up", t.PauseOnFailure)
+	}
+
+	select {
+	case <-time.After(time.Duration(t.PauseOnFailure) * time.Second):
+	case <-input:
+	case <-ctx.Done():
+	}
+}
+
+// debugShellCommand returns the command of an interactive shell with KUBECONFIG and NAMESPACE set to the cluster and
+// namespace of a test case. The shell is $SHELL or /bin/sh.
+func debugShellCommand(ctx context.Context, namespace, kubeconfig string) *exec.Cmd {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+
+	cmd := exec.CommandContext(ctx, shell)
+	cmd.Env = append(os.Environ(), "KUBECONFIG="+kubeconfig, "NAMESPACE="+namespace)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+// kubeconfigFile returns the path of the kubeconfig the harness wrote to the working directory, or $KUBECONFIG if
+// there is none, e.g. when running in a cluster.
+func kubeconfigFile() string {
+	cwd, err := os.Getwd()
+	if err == nil {
+		kubeconfig := filepath.Join(cwd, "kubeconfig")
+		if _, err := os.Stat(kubeconfig); err == nil {
+			return kubeconfig
+		}
+	}
+	return os.Getenv("KUBECONFIG")
+}
+
+// stdinIsTerminal checks if stdin is a character device, i.e. an interactive terminal.
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// stdinLines returns the lines read from stdin. Stdin is read by a single goroutine for the whole run, a line entered
+// after a pause ended is received by the next pause.
+func stdinLines() <-chan string {
+	stdinOnce.Do(func() {
+		stdinInput = make(chan string)
+		go func() {
+			scanner := bufio.NewScanner(os.Stdin)
+			for scanner.Scan() {
+				stdinInput <- scanner.Text()
+			}
+		}()
+	})
+	return stdinInput
+}
@@ -0,0 +1,79 @@
+package test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestPauseOnFailure(t *testing.T) {
+	defer func() {
+		interactive = stdinIsTerminal
+		inputLines = stdinLines
+	}()
+
+	input := make(chan string, 1)
+	inputLines = func() <-chan string { return input }
+
+	for _, tt := range []struct {
+		name        string
+		interactive bool
+		enter       bool
+		cancel      bool
+		expected    time.Duration
+	}{
+		{name: "timeout", expected: time.Second},
+		{name: "cancelled", cancel: true},
+		{name: "enter", interactive: true, enter: true},
+		// the input is only read on an interactive terminal
+		{name: "not interactive", enter: true, expected: time.Second},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			interactive = func() bool { return tt.interactive }
+			if tt.enter {
+				input <- ""
+				defer func() {
+					select {
+					case <-input:
+					default:
+					}
+				}()
+			}
+
+			ctx, cancel := context.WithCancel(context.TODO())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+
+			c := &Case{Name: "failed", PauseOnFailure: 1, Logger: testutils.NewTestLogger(t, "failed")}
+			start := time.Now()
+			c.pauseOnFailure(ctx, "failed")
+			elapsed := time.Since(start)
+
+			assert.True(t, elapsed >= tt.expected, "paused for %v, expected %v", elapsed, tt.expected)
+			assert.True(t, elapsed < tt.expected+500*time.Millisecond, "paused for %v, expected %v", elapsed, tt.expected)
+		})
+	}
+}
+
+func TestDebugShellCommand(t *testing.T) {
+	shell := os.Getenv("SHELL")
+	defer os.Setenv("SHELL", shell)
+
+	os.Setenv("SHELL", "")
+	cmd := debugShellCommand(context.TODO(), "test", "/tmp/kubeconfig")
+	assert.Equal(t, "/bin/sh", cmd.Path)
+	assert.Contains(t, cmd.Env, "KUBECONFIG=/tmp/kubeconfig")
+	assert.Contains(t, cmd.Env, "NAMESPACE=test")
+
+	os.Setenv("SHELL", "/bin/bash")
+	cmd = debugShellCommand(context.TODO(), "test", "/tmp/kubeconfig")
+	assert.Equal(t, []string{"/bin/bash"}, cmd.Args)
+}
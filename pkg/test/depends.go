@@ -0,0 +1,106 @@
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// orderByDependencies orders test cases so that every test case follows the test cases it depends on, the order of
+// independent test cases is kept. The dependencies which are not part of the test cases are ignored, e.g. because
+// they were not selected by their tags. Serial test cases run before the parallel ones, so they can not depend on
+// parallel test cases.
+func orderByDependencies(tests []*Case) ([]*Case, error) {
+	// the test cases by their directory, there is one per combination of the matrix
+	byDir := map[string][]*Case{}
+	for _, test := range tests {
+		dir := filepath.Base(test.Dir)
+		byDir[dir] = append(byDir[dir], test)
+	}
+
+	for _, test := range tests {
+		test.dependencies = nil
+		for _, name := range test.DependsOn {
+			for _, dependency := range byDir[name] {
+				if test.Serial && !dependency.Serial {
+					return nil, fmt.Errorf("serial test %s can not depend on parallel test %s", test.Name, dependency.Name)
+				}
+				test.dependencies = append(test.dependencies, dependency)
+			}
+		}
+	}
+
+	ordered := []*Case{}
+	visited := map[*Case]bool{}
+	// path are the test cases whose dependencies are being visited, a test case on it is a cycle
+	path := []*Case{}
+
+	var visit func(test *Case) error
+	visit = func(test *Case) error {
+		for i, t := range path {
+			if t == test {
+				names := []string{}
+				for _, t := range append(path[i:], test) {
+					names = append(names, t.Name)
+				}
+				return fmt.Errorf("test dependencies form a cycle: %s", strings.Join(names, " -> "))
+			}
+		}
+		if visited[test] {
+			return nil
+		}
+
+		path = append(path, test)
+		for _, dependency := range test.dependencies {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+
+		visited[test] = true
+		ordered = append(ordered, test)
+		return nil
+	}
+
+	for _, test := range tests {
+		if err := visit(test); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// runTestCase runs a test case as a subtest of t and closes its finished channel when the subtest finished. The
+// subtest of a test case which does not match the -test.run filter, e.g. of --test, is not run, the test case finishes
+// right away without passing so the test cases depending on it are skipped instead of waiting for it.
+func runTestCase(t *testing.T, test *Case, f func(t *testing.T)) {
+	// the subtest runs until it finished or, if it is parallel, until it calls t.Parallel before t.Run returns
+	started := false
+	t.Run(test.Name, func(t *testing.T) {
+		started = true
+		defer close(test.finished)
+		f(t)
+	})
+
+	if !started {
+		test.notRun = true
+		close(test.finished)
+	}
+}
+
+// awaitDependencies waits until the test cases the test case depends on finished. It returns the reason to skip the
+// test case if any of them did not run or pass, it is empty if all of them passed.
+func (t *Case) awaitDependencies() string {
+	for _, dependency := range t.dependencies {
+		<-dependency.finished
+		if dependency.notRun {
+			return fmt.Sprintf("dependency %s did not run, it does not match the tests to run", dependency.Name)
+		}
+		if !dependency.passed {
+			return fmt.Sprintf("dependency %s did not pass", dependency.Name)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,137 @@
+package test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderByDependencies(t *testing.T) {
+	newCase := func(name string, serial bool, dependsOn ...string) *Case {
+		return &Case{Name: name, Dir: filepath.Join("suite", name), Serial: serial, DependsOn: dependsOn}
+	}
+
+	for _, tt := range []struct {
+		name     string
+		tests    []*Case
+		expected []string
+		err      string
+	}{
+		{
+			name:     "no dependencies",
+			tests:    []*Case{newCase("a", false), newCase("b", false)},
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "dependencies first",
+			tests:    []*Case{newCase("a", false, "c"), newCase("b", false), newCase("c", false, "b")},
+			expected: []string{"b", "c", "a"},
+		},
+		{
+			name:     "ignored dependency",
+			tests:    []*Case{newCase("a", false, "unknown"), newCase("b", false)},
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "serial dependency",
+			tests:    []*Case{newCase("a", true, "b"), newCase("b", true)},
+			expected: []string{"b", "a"},
+		},
+		{
+			name:  "serial on parallel",
+			tests: []*Case{newCase("a", true, "b"), newCase("b", false)},
+			err:   "serial test a can not depend on parallel test b",
+		},
+		{
+			name:  "cycle",
+			tests: []*Case{newCase("a", false, "b"), newCase("b", false, "c"), newCase("c", false, "a")},
+			err:   "test dependencies form a cycle: a -> b -> c -> a",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ordered, err := orderByDependencies(tt.tests)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+
+			names := []string{}
+			for _, test := range ordered {
+				names = append(names, test.Name)
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestAwaitDependencies(t *testing.T) {
+	a := &Case{Name: "a", finished: make(chan struct{})}
+	b := &Case{Name: "b", finished: make(chan struct{})}
+	test := &Case{Name: "c", dependencies: []*Case{a, b}}
+
+	a.passed = true
+	close(a.finished)
+	go func() {
+		close(b.finished)
+	}()
+	assert.Equal(t, "dependency b did not pass", test.awaitDependencies())
+
+	b.passed = true
+	assert.Equal(t, "", test.awaitDependencies())
+}
+
+func TestLoadTestsDependsOn(t *testing.T) {
+	dir, err := ioutil.TempDir("", "depends")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "install"), 0755))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "upgrade"), 0755))
+	testCase := "apiVersion: kuttl.dev/v1beta1\nkind: TestCase\ndependsOn: [install]\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "upgrade", testCaseFile), []byte(testCase), 0644))
+
+	h := Harness{T: t}
+	tests, err := h.LoadTests(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"install"}, tests[1].DependsOn)
+
+	testCase = "apiVersion: kuttl.dev/v1beta1\nkind: TestCase\ndependsOn: [instal]\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "upgrade", testCaseFile), []byte(testCase), 0644))
+	_, err = h.LoadTests(dir)
+	assert.EqualError(t, err, "test upgrade depends on unknown test instal")
+}
+
+func TestRunTestCaseFiltered(t *testing.T) {
+	// the test binary runs itself with a -test.run filter which only matches the dependent test case
+	if os.Getenv("KUTTL_TEST_RUN_FILTERED") == "1" {
+		dependency := &Case{Name: "dependency", finished: make(chan struct{})}
+		dependent := &Case{Name: "dependent", finished: make(chan struct{}), dependencies: []*Case{dependency}}
+
+		runTestCase(t, dependency, func(t *testing.T) {
+			dependency.passed = true
+		})
+		runTestCase(t, dependent, func(t *testing.T) {
+			t.Log(dependent.awaitDependencies())
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, os.Args[0], "-test.run=^TestRunTestCaseFiltered$/^dependent$", "-test.v")
+	cmd.Env = append(os.Environ(), "KUTTL_TEST_RUN_FILTERED=1")
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.Contains(t, string(out), "dependency dependency did not run")
+	assert.NotContains(t, string(out), "=== RUN   TestRunTestCaseFiltered/dependency\n")
+}
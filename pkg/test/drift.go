@@ -0,0 +1,212 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// driftWatcher watches the objects of the drift asserts of a test step while the step runs and records the first
+// change of each of them. A nil driftWatcher never detects drift.
+type driftWatcher struct {
+	stop     chan struct{}
+	stopOnce sync.Once
+	// done is closed when the watcher stopped.
+	done chan struct{}
+
+	mu sync.Mutex
+	// errs are the drift of the objects by the index of their drift assert.
+	errs []error
+}
+
+// watchDrift reads the objects of the drift asserts of the step and starts a driftWatcher comparing every change of
+// them to their state when the step started, so changes which are reverted right away are detected too. Objects which
+// do not exist yet are compared to their state when they are created.
+func (s *Step) watchDrift(ctx context.Context, namespace string) *driftWatcher {
+	if s.Assert == nil || len(s.Assert.Drift) == 0 {
+		return nil
+	}
+
+	w := &driftWatcher{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+		errs: make([]error, len(s.Assert.Drift)),
+	}
+
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for i, expected := range s.Assert.Drift {
+		i, expected := i, expected
+
+		// the objects are read before the step runs its commands and applies its objects, the watch starts after
+		// the version which was read
+		var baseline *unstructured.Unstructured
+		resourceVersion := ""
+		if cl, obj, err := s.driftObject(namespace, expected); err == nil {
+			if err := cl.Get(ctx, testutils.ObjectKey(obj), obj); err == nil {
+				baseline, resourceVersion = driftFields(obj, expected), obj.GetResourceVersion()
+			}
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.watchDriftObject(ctx, namespace, expected, baseline, resourceVersion, start, w, i); err != nil {
+				w.record(i, err)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(w.done)
+	}()
+
+	return w
+}
+
+// watchDriftObject watches the object of a drift assert until the watcher is stopped or the object drifted from its
+// baseline, the baseline is its first state if it is nil.
+func (s *Step) watchDriftObject(ctx context.Context, namespace string, expected harness.DriftAssert, baseline *unstructured.Unstructured, resourceVersion string, start time.Time, w *driftWatcher, i int) error {
+	// the kind of the object may not be installed yet
+	var cl client.Client
+	var obj *unstructured.Unstructured
+	for {
+		var err error
+		if cl, obj, err = s.driftObject(namespace, expected); err == nil {
+			break
+		}
+		select {
+		case <-w.stop:
+			return nil
+		case <-ctx.Done():
+			return nil
+		case <-time.After(errorWatchInterval):
+		}
+	}
+
+	return watchResources(ctx, cl, obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName(), resourceVersion, w.stop, func(event watch.Event) bool {
+		actual, ok := event.Object.(*unstructured.Unstructured)
+		if !ok {
+			return true
+		}
+
+		switch event.Type {
+		case watch.Deleted:
+			if baseline == nil {
+				return true
+			}
+			w.record(i, fmt.Errorf("%s was deleted %s after the step started", testutils.ResourceID(baseline), time.Since(start).Round(time.Millisecond)))
+			return false
+		case watch.Added, watch.Modified:
+			projected := driftFields(actual, expected)
+			if baseline == nil {
+				baseline = projected
+				return true
+			}
+			if reflect.DeepEqual(baseline.Object, projected.Object) {
+				return true
+			}
+
+			diff, err := testutils.Diff(baseline, projected, testutils.DiffOptions{Color: !s.NoColor})
+			if err != nil {
+				diff = err.Error()
+			}
+			w.record(i, fmt.Errorf("%s drifted %s after the step started:\n%s", testutils.ResourceID(projected), time.Since(start).Round(time.Millisecond), diff))
+			return false
+		}
+		return true
+	})
+}
+
+// driftObject returns the client of the object of a drift assert and the object with its name and namespace.
+func (s *Step) driftObject(namespace string, expected harness.DriftAssert) (client.Client, *unstructured.Unstructured, error) {
+	cl, err := s.Client(false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dClient, err := s.DiscoveryClient()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if expected.Namespace != "" {
+		namespace = expected.Namespace
+	}
+
+	obj := testutils.NewResource(expected.APIVersion, expected.Kind, expected.Name, "")
+	if _, _, err := testutils.Namespaced(dClient, obj, namespace); err != nil {
+		return nil, nil, err
+	}
+	return cl, obj.(*unstructured.Unstructured), nil
+}
+
+// driftFields returns an object of a drift assert with only the fields which must not change.
+func driftFields(actual *unstructured.Unstructured, expected harness.DriftAssert) *unstructured.Unstructured {
+	fields := expected.Fields
+	if len(fields) == 0 {
+		fields = []string{"spec"}
+	}
+
+	projected := testutils.NewResource(actual.GetAPIVersion(), actual.GetKind(), actual.GetName(), actual.GetNamespace()).(*unstructured.Unstructured)
+	for _, field := range fields {
+		path := strings.Split(strings.TrimPrefix(field, "."), ".")
+		value, found, err := unstructured.NestedFieldNoCopy(actual.Object, path...)
+		if err != nil || !found {
+			continue
+		}
+		// the parents of the path are maps as the value was found at the path, setting it does not fail
+		_ = unstructured.SetNestedField(projected.Object, runtime.DeepCopyJSONValue(value), path...)
+	}
+	return projected
+}
+
+// record adds the drift of the object of the drift assert at an index, only the first one is kept.
+func (w *driftWatcher) record(i int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.errs[i] == nil {
+		w.errs[i] = err
+	}
+}
+
+// Drifted returns the drift of the objects in the order of the drift asserts without stopping the watcher.
+func (w *driftWatcher) Drifted() []error {
+	if w == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	errs := []error{}
+	for _, err := range w.errs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Stop stops the watcher and returns the drift of the objects.
+func (w *driftWatcher) Stop() []error {
+	if w == nil {
+		return nil
+	}
+
+	w.stopOnce.Do(func() { close(w.stop) })
+	<-w.done
+	return w.Drifted()
+}
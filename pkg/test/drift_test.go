@@ -0,0 +1,90 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func driftDeployment(name string, replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+}
+
+// updateDeployment changes a deployment of the test namespace.
+func updateDeployment(t *testing.T, cl client.Client, name string, update func(*appsv1.Deployment)) {
+	deployment := &appsv1.Deployment{}
+	require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: name}, deployment))
+	update(deployment)
+	require.NoError(t, cl.Update(context.TODO(), deployment))
+}
+
+func driftRef(kind, apiVersion, name string) corev1.ObjectReference {
+	return corev1.ObjectReference{Kind: kind, APIVersion: apiVersion, Name: name}
+}
+
+func TestStepWatchDrift(t *testing.T) {
+	cl := newFakeWatchClient(fake.NewFakeClientWithScheme(scheme.Scheme, driftDeployment("operator", 1), driftDeployment("fought", 1)), "operator", "fought", "created")
+
+	step := Step{
+		Assert: &harness.TestAssert{Drift: []harness.DriftAssert{
+			{ObjectReference: driftRef("Deployment", "apps/v1", "operator"), Fields: []string{"spec.replicas"}},
+			{ObjectReference: driftRef("Deployment", "apps/v1", "fought")},
+			{ObjectReference: driftRef("Deployment", "apps/v1", "created")},
+		}},
+		Logger:          testutils.NewTestLogger(t, ""),
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+	}
+
+	watcher := step.watchDrift(context.TODO(), testNamespace)
+	require.NotNil(t, watcher)
+	cl.awaitWatches(3)
+	assert.Empty(t, watcher.Drifted())
+
+	// changes of fields which are not watched are no drift
+	paused := driftDeployment("operator", 1)
+	paused.Spec.Paused = true
+	cl.send(t, watch.Modified, paused)
+	// the baseline of objects which do not exist yet is their state when they are created
+	cl.send(t, watch.Added, driftDeployment("created", 1))
+	assert.Empty(t, watcher.Drifted())
+
+	// the deletion is sent first, the drift is still reported in the order of the drift asserts
+	cl.send(t, watch.Deleted, driftDeployment("created", 1))
+	// a change is drift even if it is reverted before the step ends, the object of the client never changes
+	fought := driftDeployment("fought", 1)
+	fought.Spec.Paused = true
+	cl.send(t, watch.Modified, fought)
+
+	errs := watcher.Stop()
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Error(), "Deployment:world/fought drifted")
+	assert.Contains(t, errs[0].Error(), "+  paused: true")
+	assert.Contains(t, errs[1].Error(), "Deployment:world/created was deleted")
+	assert.Equal(t, errs, watcher.Stop())
+
+	// the watcher is not started without drift asserts
+	step.Assert.Drift = nil
+	watcher = step.watchDrift(context.TODO(), testNamespace)
+	assert.Nil(t, watcher)
+	assert.Nil(t, watcher.Drifted())
+	assert.Nil(t, watcher.Stop())
+}
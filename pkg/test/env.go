@@ -0,0 +1,92 @@
+package test
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// resolveEnv builds the environment variables of commands from an env map and a list of secrets and config maps.
+// Like in a Kubernetes container, the variables of the env map take precedence over the variables of envFrom.
+// Variables of base are overridden by both.
+func resolveEnv(ctx context.Context, cl client.Client, namespace string, base, env map[string]string, envFrom []corev1.EnvFromSource) (map[string]string, error) {
+	resolved := map[string]string{}
+	for key, value := range base {
+		resolved[key] = value
+	}
+
+	for _, source := range envFrom {
+		switch {
+		case source.ConfigMapRef != nil:
+			configMap := &corev1.ConfigMap{}
+			err := cl.Get(ctx, types.NamespacedName{Namespace: namespace, Name: source.ConfigMapRef.Name}, configMap)
+			if k8serrors.IsNotFound(err) && source.ConfigMapRef.Optional != nil && *source.ConfigMapRef.Optional {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("env from config map %s: %w", source.ConfigMapRef.Name, err)
+			}
+			for key, value := range configMap.Data {
+				resolved[source.Prefix+key] = value
+			}
+		case source.SecretRef != nil:
+			secret := &corev1.Secret{}
+			err := cl.Get(ctx, types.NamespacedName{Namespace: namespace, Name: source.SecretRef.Name}, secret)
+			if k8serrors.IsNotFound(err) && source.SecretRef.Optional != nil && *source.SecretRef.Optional {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("env from secret %s: %w", source.SecretRef.Name, err)
+			}
+			for key, value := range secret.Data {
+				resolved[source.Prefix+key] = string(value)
+			}
+		}
+	}
+
+	for key, value := range env {
+		resolved[key] = value
+	}
+
+	return resolved, nil
+}
+
+// commandEnv returns the environment variables of the commands of the test step.
+// The variables captured by previous steps, the addresses of the port forwards of the step and the
+// tracking label of the test case override the test suite env.
+func (s *Step) commandEnv(ctx context.Context, namespace string) (map[string]string, error) {
+	base := s.Env
+	if len(s.variables) > 0 || len(s.forwardEnv) > 0 || s.tracking != nil {
+		base = map[string]string{}
+		for key, value := range s.Env {
+			base[key] = value
+		}
+		for key, value := range s.variables {
+			base[key] = value
+		}
+		for key, value := range s.forwardEnv {
+			base[key] = value
+		}
+		if s.tracking != nil {
+			base[trackingLabelEnv] = s.tracking.label()
+		}
+	}
+
+	if s.Step == nil || (len(s.Step.Env) == 0 && len(s.Step.EnvFrom) == 0) {
+		return base, nil
+	}
+
+	var cl client.Client
+	if len(s.Step.EnvFrom) > 0 {
+		var err error
+		if cl, err = s.Client(false); err != nil {
+			return nil, err
+		}
+	}
+
+	return resolveEnv(ctx, cl, namespace, base, s.Step.Env, s.Step.EnvFrom)
+}
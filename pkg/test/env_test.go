@@ -0,0 +1,78 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestResolveEnv(t *testing.T) {
+	optional := true
+
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme,
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: testNamespace},
+			Data:       map[string]string{"HOST": "example.com", "PORT": "80"},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: testNamespace},
+			Data:       map[string][]byte{"TOKEN": []byte("hunter2")},
+		},
+	)
+
+	for _, test := range []struct {
+		name        string
+		base        map[string]string
+		env         map[string]string
+		envFrom     []corev1.EnvFromSource
+		expected    map[string]string
+		shouldError bool
+	}{
+		{
+			name:     "env overrides base",
+			base:     map[string]string{"HOST": "suite", "SUITE": "true"},
+			env:      map[string]string{"HOST": "step"},
+			expected: map[string]string{"HOST": "step", "SUITE": "true"},
+		},
+		{
+			name: "env from config map and secret",
+			envFrom: []corev1.EnvFromSource{
+				{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "config"}}},
+				{Prefix: "APP_", SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "secret"}}},
+			},
+			env:      map[string]string{"PORT": "8080"},
+			expected: map[string]string{"HOST": "example.com", "PORT": "8080", "APP_TOKEN": "hunter2"},
+		},
+		{
+			name: "missing optional config map",
+			envFrom: []corev1.EnvFromSource{
+				{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "missing"}, Optional: &optional}},
+			},
+			expected: map[string]string{},
+		},
+		{
+			name: "missing secret",
+			envFrom: []corev1.EnvFromSource{
+				{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "missing"}}},
+			},
+			shouldError: true,
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			env, err := resolveEnv(context.TODO(), cl, testNamespace, test.base, test.env, test.envFrom)
+			if test.shouldError {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, env)
+		})
+	}
+}
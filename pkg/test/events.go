@@ -0,0 +1,111 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+)
+
+// CheckEvents checks the event asserts of the TestAssert against the events of the namespace.
+func (s *Step) CheckEvents(ctx context.Context, namespace string) []error {
+	if s.Assert == nil || len(s.Assert.Events) == 0 {
+		return nil
+	}
+
+	cl, err := s.Client(false)
+	if err != nil {
+		return []error{err}
+	}
+
+	eventList := &corev1.EventList{}
+	if err := cl.List(ctx, eventList, client.InNamespace(namespace)); err != nil {
+		return []error{err}
+	}
+
+	errs := []error{}
+
+	for _, expected := range s.Assert.Events {
+		matched, err := matchingEvents(expected, eventList.Items)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if expected.Absent && len(matched) > 0 {
+			errs = append(errs, fmt.Errorf("found unexpected event %s: %s", describeEventAssert(expected), matched[0].Message))
+		} else if !expected.Absent && len(matched) == 0 {
+			errs = append(errs, fmt.Errorf("no event %s found", describeEventAssert(expected)))
+		}
+	}
+
+	return errs
+}
+
+// matchingEvents returns the events matching the event assert. Unset fields of the assert match any value.
+func matchingEvents(expected harness.EventAssert, events []corev1.Event) ([]corev1.Event, error) {
+	var message *regexp.Regexp
+	if expected.Message != "" {
+		var err error
+		if message, err = regexp.Compile(expected.Message); err != nil {
+			return nil, fmt.Errorf("invalid message regular expression of event %s: %w", describeEventAssert(expected), err)
+		}
+	}
+
+	matched := []corev1.Event{}
+
+	for _, event := range events {
+		if !matchesInvolvedObject(expected.InvolvedObject, event.InvolvedObject) {
+			continue
+		}
+		if expected.Reason != "" && expected.Reason != event.Reason {
+			continue
+		}
+		if expected.Type != "" && expected.Type != event.Type {
+			continue
+		}
+		if message != nil && !message.MatchString(event.Message) {
+			continue
+		}
+		matched = append(matched, event)
+	}
+
+	return matched, nil
+}
+
+// matchesInvolvedObject checks the set fields of the expected reference against the involved object of an event.
+func matchesInvolvedObject(expected, actual corev1.ObjectReference) bool {
+	return (expected.APIVersion == "" || expected.APIVersion == actual.APIVersion) &&
+		(expected.Kind == "" || expected.Kind == actual.Kind) &&
+		(expected.Namespace == "" || expected.Namespace == actual.Namespace) &&
+		(expected.Name == "" || expected.Name == actual.Name) &&
+		(expected.UID == "" || expected.UID == actual.UID) &&
+		(expected.FieldPath == "" || expected.FieldPath == actual.FieldPath)
+}
+
+// describeEventAssert returns a readable description of an event assert for error messages.
+func describeEventAssert(expected harness.EventAssert) string {
+	description := "involving "
+	if expected.InvolvedObject.Kind != "" {
+		description += expected.InvolvedObject.Kind
+	} else {
+		description += "any object"
+	}
+	if expected.InvolvedObject.Name != "" {
+		description += "/" + expected.InvolvedObject.Name
+	}
+	if expected.Type != "" {
+		description += fmt.Sprintf(" with type %s", expected.Type)
+	}
+	if expected.Reason != "" {
+		description += fmt.Sprintf(" with reason %s", expected.Reason)
+	}
+	if expected.Message != "" {
+		description += fmt.Sprintf(" with message matching %q", expected.Message)
+	}
+	return description
+}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestStepCheckEvents(t *testing.T) {
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello.16a5b3c4d5e6f7a8", Namespace: testNamespace},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Name:       "hello",
+			Namespace:  testNamespace,
+		},
+		Reason:  "FailedScheduling",
+		Type:    corev1.EventTypeWarning,
+		Message: "0/3 nodes are available: 3 Insufficient cpu.",
+	}
+
+	pod := func(name string) corev1.ObjectReference {
+		return corev1.ObjectReference{Kind: "Pod", Name: name}
+	}
+
+	for _, test := range []struct {
+		name   string
+		events []harness.EventAssert
+		errors int
+	}{
+		{
+			name: "matching event",
+			events: []harness.EventAssert{
+				{InvolvedObject: pod("hello"), Reason: "FailedScheduling"},
+				{InvolvedObject: pod(""), Type: corev1.EventTypeWarning, Message: "Insufficient cpu"},
+			},
+		},
+		{
+			name: "missing event",
+			events: []harness.EventAssert{
+				{InvolvedObject: pod("hello"), Reason: "Scheduled"},
+				{InvolvedObject: pod("other"), Reason: "FailedScheduling"},
+				{InvolvedObject: pod("hello"), Message: "^Insufficient memory"},
+			},
+			errors: 3,
+		},
+		{
+			name: "absent event",
+			events: []harness.EventAssert{
+				{InvolvedObject: pod("other"), Type: corev1.EventTypeWarning, Absent: true},
+			},
+		},
+		{
+			name: "unexpected event",
+			events: []harness.EventAssert{
+				{InvolvedObject: pod("hello"), Type: corev1.EventTypeWarning, Absent: true},
+			},
+			errors: 1,
+		},
+		{
+			name: "invalid message regular expression",
+			events: []harness.EventAssert{
+				{InvolvedObject: pod("hello"), Message: "("},
+			},
+			errors: 1,
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			cl := fake.NewFakeClientWithScheme(scheme.Scheme, event.DeepCopy())
+
+			step := Step{
+				Logger: testutils.NewTestLogger(t, ""),
+				Assert: &harness.TestAssert{Events: test.events},
+				Client: func(bool) (client.Client, error) {
+					return cl, nil
+				},
+			}
+
+			assert.Equal(t, test.errors, len(step.CheckEvents(context.TODO(), testNamespace)))
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/thoas/go-funk"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// RunExec runs commands in containers of pods. It stops at the first failed command.
+func (s *Step) RunExec(ctx context.Context, namespace string, execs []harness.Exec) []error {
+	for _, exec := range execs {
+		if err := s.runExec(ctx, namespace, exec); err != nil {
+			return []error{err}
+		}
+	}
+	return nil
+}
+
+// runExec runs a command in a container with the exec subresource of the pod and checks its exit code and output.
+func (s *Step) runExec(ctx context.Context, namespace string, exec harness.Exec) error {
+	if len(exec.Command) == 0 {
+		return errors.New("exec requires a command")
+	}
+
+	pod, err := s.execPod(ctx, namespace, exec)
+	if err != nil {
+		return err
+	}
+
+	if s.Config == nil {
+		return errors.New("exec requires a cluster configuration")
+	}
+	cfg, err := s.Config()
+	if err != nil {
+		return err
+	}
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	req := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: exec.Container,
+			Command:   exec.Command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(cfg, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+
+	s.Logger.Logf("running command in pod %s: %v", pod.Name, exec.Command)
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	err = executor.Stream(remotecommand.StreamOptions{
+		Stdout: io.MultiWriter(stdout, s.Logger),
+		Stderr: io.MultiWriter(stderr, s.Logger),
+	})
+	s.Logger.Flush()
+
+	return checkExecResult(pod.Name, exec, err, stdout.String(), stderr.String())
+}
+
+// checkExecResult checks the exit code and the output of a command run in a pod.
+func checkExecResult(pod string, exec harness.Exec, err error, stdout, stderr string) error {
+	exitCode := 0
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitStatus()
+	} else if err != nil {
+		return fmt.Errorf("command %v in pod %s: %w", exec.Command, pod, err)
+	}
+
+	exitCodes := exec.ExitCodes
+	if len(exitCodes) == 0 {
+		exitCodes = []int{0}
+	}
+	if !funk.ContainsInt(exitCodes, exitCode) {
+		return fmt.Errorf("command %v in pod %s exited with %d, expected one of %v", exec.Command, pod, exitCode, exitCodes)
+	}
+
+	if err := testutils.CheckOutput("stdout", exec.Stdout, stdout); err != nil {
+		return fmt.Errorf("command %v in pod %s: %w", exec.Command, pod, err)
+	}
+	if err := testutils.CheckOutput("stderr", exec.Stderr, stderr); err != nil {
+		return fmt.Errorf("command %v in pod %s: %w", exec.Command, pod, err)
+	}
+	return nil
+}
+
+// execPod returns the pod to run the command of an exec in, either the named pod or the first
+// running pod matching the selector.
+func (s *Step) execPod(ctx context.Context, namespace string, exec harness.Exec) (*corev1.Pod, error) {
+	if exec.Pod == "" && exec.Selector == "" {
+		return nil, errors.New("exec requires a pod or selector")
+	}
+
+	if exec.Namespace != "" {
+		namespace = exec.Namespace
+	}
+
+	cl, err := s.Client(false)
+	if err != nil {
+		return nil, err
+	}
+
+	if exec.Pod != "" {
+		pod := &corev1.Pod{}
+		if err := cl.Get(ctx, client.ObjectKey{Namespace: namespace, Name: exec.Pod}, pod); err != nil {
+			return nil, fmt.Errorf("exec: %w", err)
+		}
+		return pod, nil
+	}
+
+	selector, err := labels.Parse(exec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("exec: invalid selector %q: %w", exec.Selector, err)
+	}
+
+	pods := &corev1.PodList{}
+	if err := cl.List(ctx, pods, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("exec: %w", err)
+	}
+
+	for index := range pods.Items {
+		if pods.Items[index].Status.Phase == corev1.PodRunning {
+			return &pods.Items[index], nil
+		}
+	}
+	return nil, fmt.Errorf("exec: no running pod matching %q found", exec.Selector)
+}
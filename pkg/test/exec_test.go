@@ -0,0 +1,135 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	utilexec "k8s.io/client-go/util/exec"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestExecPod(t *testing.T) {
+	pod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, Labels: map[string]string{"app": "db"}},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	for _, test := range []struct {
+		name    string
+		exec    harness.Exec
+		pod     string
+		wantErr bool
+	}{
+		{
+			name: "named pod",
+			exec: harness.Exec{Pod: "db-0"},
+			pod:  "db-0",
+		},
+		{
+			name: "first running pod of selector",
+			exec: harness.Exec{Selector: "app=db"},
+			pod:  "db-1",
+		},
+		{
+			name:    "missing pod",
+			exec:    harness.Exec{Pod: "missing"},
+			wantErr: true,
+		},
+		{
+			name:    "no running pod",
+			exec:    harness.Exec{Selector: "app=web"},
+			wantErr: true,
+		},
+		{
+			name:    "no pod or selector",
+			exec:    harness.Exec{},
+			wantErr: true,
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			cl := fake.NewFakeClientWithScheme(scheme.Scheme, pod("db-0", corev1.PodPending), pod("db-1", corev1.PodRunning))
+
+			step := Step{
+				Logger: testutils.NewTestLogger(t, ""),
+				Client: func(bool) (client.Client, error) {
+					return cl, nil
+				},
+			}
+
+			actual, err := step.execPod(context.TODO(), testNamespace, test.exec)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.pod, actual.Name)
+		})
+	}
+}
+
+func TestCheckExecResult(t *testing.T) {
+	exitError := func(code int) error {
+		return utilexec.CodeExitError{Err: errors.New("command terminated with non-zero exit code"), Code: code}
+	}
+
+	for _, test := range []struct {
+		name    string
+		exec    harness.Exec
+		err     error
+		stdout  string
+		wantErr bool
+	}{
+		{
+			name:   "success",
+			exec:   harness.Exec{Command: []string{"cat", "/etc/config"}, Stdout: &harness.CommandOutput{Match: []string{"^debug: true$"}}},
+			stdout: "debug: true\n",
+		},
+		{
+			name:    "output mismatch",
+			exec:    harness.Exec{Command: []string{"cat", "/etc/config"}, Stdout: &harness.CommandOutput{Match: []string{"^debug: false$"}}},
+			stdout:  "debug: true\n",
+			wantErr: true,
+		},
+		{
+			name:    "non-zero exit code",
+			exec:    harness.Exec{Command: []string{"false"}},
+			err:     exitError(1),
+			wantErr: true,
+		},
+		{
+			name: "expected exit code",
+			exec: harness.Exec{Command: []string{"test", "-f", "/tmp/missing"}, ExitCodes: []int{1}},
+			err:  exitError(1),
+		},
+		{
+			name:    "stream error",
+			exec:    harness.Exec{Command: []string{"true"}},
+			err:     errors.New("container not found"),
+			wantErr: true,
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			err := checkExecResult("db-0", test.exec, test.err, test.stdout, "")
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// Terminate deletes the objects of the TestStep.Terminate list without waiting for them to be deleted, their
+// finalizers are asserted with finalizer asserts.
+func (s *Step) Terminate(ctx context.Context, namespace string) error {
+	if s.Step == nil || len(s.Step.Terminate) == 0 {
+		return nil
+	}
+
+	cl, err := s.Client(false)
+	if err != nil {
+		return err
+	}
+
+	dClient, err := s.DiscoveryClient()
+	if err != nil {
+		return err
+	}
+
+	// the objects are looked up by the test suite, they are deleted by the user the step acts as
+	actingClient, err := s.actingClient(namespace)
+	if err != nil {
+		return err
+	}
+
+	for _, ref := range s.Step.Terminate {
+		objs, err := s.objectsFromRef(ctx, cl, dClient, ref, namespace)
+		if err != nil {
+			return err
+		}
+
+		for _, obj := range objs {
+			if err := actingClient.Delete(ctx, obj); err != nil && !k8serrors.IsNotFound(err) {
+				return fmt.Errorf("failed to terminate %s: %w", testutils.ResourceID(obj), err)
+			}
+			testutils.LogResource(s.Logger, testutils.ResourceID(obj), "terminating")
+		}
+	}
+
+	return nil
+}
+
+// CheckFinalizers checks the finalizer asserts of the TestAssert: the objects must be terminating with the finalizer
+// or, if deleted is set, the objects must not exist anymore.
+func (s *Step) CheckFinalizers(ctx context.Context, namespace string) []error {
+	if s.Assert == nil || len(s.Assert.Finalizers) == 0 {
+		return nil
+	}
+
+	cl, err := s.Client(false)
+	if err != nil {
+		return []error{err}
+	}
+
+	dClient, err := s.DiscoveryClient()
+	if err != nil {
+		return []error{err}
+	}
+
+	errs := []error{}
+
+	for _, expected := range s.Assert.Finalizers {
+		if expected.Finalizer == "" && !expected.Deleted {
+			errs = append(errs, fmt.Errorf("finalizer assert of kind %s requires a finalizer unless deleted is set", expected.Kind))
+			continue
+		}
+
+		objs, err := s.existingObjects(ctx, cl, dClient, expected.ObjectReference, namespace)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if expected.Deleted {
+			for _, obj := range objs {
+				errs = append(errs, fmt.Errorf("%s is not deleted, %s", testutils.ResourceID(obj), finalizers(obj)))
+			}
+			continue
+		}
+
+		if len(objs) == 0 {
+			errs = append(errs, fmt.Errorf("no objects of kind %s found for finalizer assert %s", expected.Kind, expected.Finalizer))
+			continue
+		}
+
+		for _, obj := range objs {
+			if obj.GetDeletionTimestamp() == nil {
+				errs = append(errs, fmt.Errorf("%s is not terminating", testutils.ResourceID(obj)))
+				continue
+			}
+
+			found := false
+			for _, finalizer := range obj.GetFinalizers() {
+				if finalizer == expected.Finalizer {
+					found = true
+					break
+				}
+			}
+			if !found {
+				errs = append(errs, fmt.Errorf("%s is terminating without finalizer %s, %s", testutils.ResourceID(obj), expected.Finalizer, finalizers(obj)))
+			}
+		}
+	}
+
+	return errs
+}
+
+// finalizers describes the finalizers of an object for the errors of finalizer asserts.
+func finalizers(obj *unstructured.Unstructured) string {
+	if len(obj.GetFinalizers()) == 0 {
+		return "it has no finalizers"
+	}
+	return "its finalizers are " + strings.Join(obj.GetFinalizers(), ", ")
+}
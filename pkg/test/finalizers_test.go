@@ -0,0 +1,125 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestStepTerminate(t *testing.T) {
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "hello", Namespace: testNamespace}})
+
+	step := &Step{
+		Logger: testutils.NewTestLogger(t, ""),
+		Step: &harness.TestStep{Terminate: []harness.ObjectReference{
+			{ObjectReference: driftRef("Pod", "v1", "hello")},
+			// objects which are already deleted are ignored
+			{ObjectReference: driftRef("Pod", "v1", "missing")},
+		}},
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+	}
+
+	assert.NoError(t, step.Terminate(context.TODO(), testNamespace))
+
+	err := cl.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: "hello"}, &corev1.Pod{})
+	assert.True(t, k8serrors.IsNotFound(err))
+}
+
+func TestStepCheckFinalizers(t *testing.T) {
+	now := metav1.Now()
+
+	pod := func(name string, deletionTimestamp *metav1.Time, finalizers ...string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         testNamespace,
+			DeletionTimestamp: deletionTimestamp,
+			Finalizers:        finalizers,
+		}}
+	}
+
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme,
+		pod("terminating", &now, "kuttl.dev/cleanup", "kuttl.dev/backup"),
+		pod("released", &now),
+		pod("running", nil, "kuttl.dev/cleanup"),
+	)
+
+	finalizer := func(name, finalizer string, deleted bool) harness.FinalizerAssert {
+		return harness.FinalizerAssert{
+			ObjectReference: harness.ObjectReference{ObjectReference: driftRef("Pod", "v1", name)},
+			Finalizer:       finalizer,
+			Deleted:         deleted,
+		}
+	}
+
+	for _, test := range []struct {
+		name       string
+		finalizers []harness.FinalizerAssert
+		errors     []string
+	}{
+		{
+			name: "objects are terminating with the finalizer or deleted",
+			finalizers: []harness.FinalizerAssert{
+				finalizer("terminating", "kuttl.dev/backup", false),
+				finalizer("missing", "", true),
+			},
+		},
+		{
+			name: "objects are not terminating with the finalizer",
+			finalizers: []harness.FinalizerAssert{
+				finalizer("running", "kuttl.dev/cleanup", false),
+				finalizer("terminating", "kuttl.dev/other", false),
+				finalizer("released", "kuttl.dev/cleanup", false),
+				finalizer("missing", "kuttl.dev/cleanup", false),
+			},
+			errors: []string{
+				"Pod:world/running is not terminating",
+				"Pod:world/terminating is terminating without finalizer kuttl.dev/other, its finalizers are kuttl.dev/cleanup, kuttl.dev/backup",
+				"Pod:world/released is terminating without finalizer kuttl.dev/cleanup, it has no finalizers",
+				"no objects of kind Pod found for finalizer assert kuttl.dev/cleanup",
+			},
+		},
+		{
+			name: "objects are not deleted",
+			finalizers: []harness.FinalizerAssert{
+				finalizer("terminating", "", true),
+				finalizer("released", "", true),
+			},
+			errors: []string{
+				"Pod:world/terminating is not deleted, its finalizers are kuttl.dev/cleanup, kuttl.dev/backup",
+				"Pod:world/released is not deleted, it has no finalizers",
+			},
+		},
+		{
+			name:       "invalid finalizer assert",
+			finalizers: []harness.FinalizerAssert{finalizer("terminating", "", false)},
+			errors: []string{
+				"finalizer assert of kind Pod requires a finalizer unless deleted is set",
+			},
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			step := &Step{
+				Logger:          testutils.NewTestLogger(t, ""),
+				Assert:          &harness.TestAssert{Finalizers: test.finalizers},
+				Client:          func(bool) (client.Client, error) { return cl, nil },
+				DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+			}
+			assert.Equal(t, test.errors, errorStrings(step.CheckFinalizers(context.TODO(), testNamespace)))
+		})
+	}
+}
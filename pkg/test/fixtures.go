@@ -0,0 +1,136 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/kudobuilder/kuttl/pkg/report"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// fixturesDir is the directory of a test suite with the fixtures the test cases can use, a directory of test steps
+// per fixture, e.g. "_fixtures/postgres".
+const fixturesDir = "_fixtures"
+
+// fixture is an expensive resource shared by test cases, e.g. a database operator. Its test steps are run by the first
+// test case using it and its objects are deleted after the last test case using it finished.
+type fixture struct {
+	lock sync.Mutex
+	test *Case
+	// remaining is the number of test cases of the run using the fixture which did not finish yet.
+	remaining int
+	// created is set once the steps ran, the objects they applied are deleted by teardown.
+	created bool
+	// err is the failure of the steps, the test cases using the fixture fail with it.
+	err error
+}
+
+// fixturePath returns the directory of a fixture of a test case.
+func fixturePath(test *Case, name string) string {
+	return filepath.Join(filepath.Dir(test.Dir), fixturesDir, name)
+}
+
+// initFixtures counts the test cases of the run using each fixture.
+func (h *Harness) initFixtures(tests map[string][]*Case) {
+	h.fixtures = map[string]*fixture{}
+	for _, cases := range tests {
+		for _, test := range cases {
+			for _, name := range test.Fixtures {
+				path := fixturePath(test, name)
+				if h.fixtures[path] == nil {
+					h.fixtures[path] = &fixture{test: h.suiteCase(name, path)}
+				}
+				h.fixtures[path].remaining++
+			}
+		}
+	}
+}
+
+// acquireFixtures creates the fixtures of a test case which were not created yet.
+func (h *Harness) acquireFixtures(ctx context.Context, test *Case) error {
+	for _, name := range test.Fixtures {
+		f := h.fixtures[fixturePath(test, name)]
+
+		f.lock.Lock()
+		if !f.created {
+			f.err = f.create(ctx, h.GetLogger().WithPrefix(fixturesDir+"/"+name))
+		}
+		err := f.err
+		f.lock.Unlock()
+
+		if err != nil {
+			return fmt.Errorf("fixture %s failed: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// releaseFixtures tears down the fixtures of a finished test case if no other test case of the run uses them.
+func (h *Harness) releaseFixtures(test *Case) {
+	for _, name := range test.Fixtures {
+		f := h.fixtures[fixturePath(test, name)]
+
+		f.lock.Lock()
+		f.remaining--
+		if f.remaining == 0 {
+			f.teardown()
+		}
+		f.lock.Unlock()
+	}
+}
+
+// teardownFixtures tears down the fixtures which are still created after the run, e.g. because it was interrupted.
+func (h *Harness) teardownFixtures() {
+	for _, f := range h.fixtures {
+		f.lock.Lock()
+		f.teardown()
+		f.lock.Unlock()
+	}
+}
+
+// create runs the steps of the fixture, it must be called with the lock held. The steps log to the logger of the
+// harness, the test cases they are run by may finish before the fixture is torn down.
+func (f *fixture) create(ctx context.Context, logger testutils.Logger) error {
+	f.test.Logger = logger
+	if err := f.test.LoadTestSteps(); err != nil {
+		return err
+	}
+
+	// the objects of failed steps are torn down too
+	f.created = true
+
+	errs := f.test.runAttempt(ctx, report.NewCase(f.test.Name))
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// teardown deletes the objects of the steps of the fixture in reverse order and runs their cleanup commands, it must
+// be called with the lock held.
+func (f *fixture) teardown() {
+	if !f.created || f.test.ns == nil {
+		return
+	}
+	f.created = false
+
+	f.test.Logger.Log("tearing down fixture")
+	for i := len(f.test.Steps) - 1; i >= 0; i-- {
+		step := f.test.Steps[i]
+		// the step did not run
+		if step.Client == nil {
+			continue
+		}
+
+		if err := step.Clean(f.test.ns.Name); err != nil {
+			f.test.Logger.Log("error cleaning up fixture step", step.String(), err)
+		}
+		for _, err := range step.Teardown(f.test.ns.Name) {
+			f.test.Logger.Log("error tearing down fixture step", step.String(), err)
+		}
+	}
+}
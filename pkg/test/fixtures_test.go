@@ -0,0 +1,79 @@
+package test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestFixtures(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fixtures")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	pod := "apiVersion: v1\nkind: Pod\nmetadata:\n  name: db\nspec:\n  containers:\n  - name: db\n    image: postgres\n"
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, fixturesDir, "db"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, fixturesDir, "db", "00-create.yaml"), []byte(pod), 0644))
+
+	testCase := "apiVersion: kuttl.dev/v1beta1\nkind: TestCase\nfixtures: [db]\n"
+	for _, name := range []string{"a", "b"} {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, name), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name, testCaseFile), []byte(testCase), 0644))
+	}
+
+	h := Harness{T: t}
+	tests, err := h.LoadTests(dir)
+	require.NoError(t, err)
+	// the fixtures directory is not a test case
+	require.Equal(t, 2, len(tests))
+
+	h.initFixtures(map[string][]*Case{dir: tests})
+	f := h.fixtures[filepath.Join(dir, fixturesDir, "db")]
+	require.NotNil(t, f)
+	assert.Equal(t, 2, f.remaining)
+
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme)
+	f.test.Client = func(bool) (client.Client, error) { return cl, nil }
+	f.test.DiscoveryClient = func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil }
+
+	key := types.NamespacedName{Namespace: "default", Name: "db"}
+
+	// the fixture is created by the first test case using it
+	require.NoError(t, h.acquireFixtures(context.TODO(), tests[0]))
+	assert.NoError(t, cl.Get(context.TODO(), key, &corev1.Pod{}))
+	require.NoError(t, h.acquireFixtures(context.TODO(), tests[1]))
+
+	// and deleted after the last one finished
+	h.releaseFixtures(tests[0])
+	assert.NoError(t, cl.Get(context.TODO(), key, &corev1.Pod{}))
+	h.releaseFixtures(tests[1])
+	assert.True(t, k8serrors.IsNotFound(cl.Get(context.TODO(), key, &corev1.Pod{})))
+}
+
+func TestLoadTestsUnknownFixture(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fixtures")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "a"), 0755))
+	testCase := "apiVersion: kuttl.dev/v1beta1\nkind: TestCase\nfixtures: [db]\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a", testCaseFile), []byte(testCase), 0644))
+
+	h := Harness{T: t}
+	_, err = h.LoadTests(dir)
+	assert.EqualError(t, err, "test a uses unknown fixture db")
+}
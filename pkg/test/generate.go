@@ -0,0 +1,70 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	kfile "github.com/kudobuilder/kuttl/pkg/file"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// generateData is the data of the templates of TestStep.Generate.
+type generateData struct {
+	*TemplateData
+	// Index is the index of the copy.
+	Index int
+}
+
+// generateObjects renders the templates of the paths of a generator for every index of its range.
+func (s *Step) generateObjects(generate harness.Generate, paths []string) ([]runtime.Object, error) {
+	if generate.Count <= 0 {
+		return nil, fmt.Errorf("generate requires a positive count, got %d", generate.Count)
+	}
+
+	data := s.template
+	if data == nil {
+		data = NewTemplateData("", s.values, s.variables)
+	}
+
+	objects := []runtime.Object{}
+	for _, path := range paths {
+		files, err := kfile.FromPath(filepath.Join(s.Dir, path), "*.yaml")
+		if err != nil {
+			return nil, fmt.Errorf("failed to find YAML files in %s: %w", filepath.Join(s.Dir, path), err)
+		}
+
+		for _, file := range files {
+			contents, err := ioutil.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+
+			// the template is parsed once for all copies
+			tmpl, err := template.New(filepath.Base(file)).Option("missingkey=error").Parse(string(contents))
+			if err != nil {
+				return nil, fmt.Errorf("parsing template %s: %w", file, err)
+			}
+
+			for index := generate.Start; index < generate.Start+generate.Count; index++ {
+				rendered := &bytes.Buffer{}
+				if err := tmpl.Execute(rendered, generateData{TemplateData: data, Index: index}); err != nil {
+					return nil, fmt.Errorf("rendering template %s with index %d: %w", file, index, err)
+				}
+
+				objs, err := testutils.LoadYAML(file, rendered)
+				if err != nil {
+					return nil, err
+				}
+				objects = append(objects, objs...)
+			}
+		}
+	}
+
+	return objects, nil
+}
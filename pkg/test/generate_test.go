@@ -0,0 +1,81 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-generate")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"00-step.yaml": `apiVersion: kuttl.dev/v1beta1
+kind: TestStep
+generate:
+- count: 3
+  start: 1
+  apply: [templates/crontab.yaml]
+  assert: [templates/crontab-assert.yaml]
+`,
+		"templates/crontab.yaml": `apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  name: crontab-{{ .Index }}
+spec:
+  cronSpec: "*/{{ .Index }} * * * *"
+  image: {{ .Values.image }}
+`,
+		"templates/crontab-assert.yaml": `apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  name: crontab-{{ .Index }}
+status:
+  processed: true
+`,
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	step := &Step{Dir: dir, values: map[string]string{"image": "busybox"}}
+	require.NoError(t, step.LoadYAML(filepath.Join(dir, "00-step.yaml")))
+
+	names := func(objs []runtime.Object) []string {
+		ids := []string{}
+		for _, obj := range objs {
+			ids = append(ids, testutils.ResourceID(obj))
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"CronTab:/crontab-1", "CronTab:/crontab-2", "CronTab:/crontab-3"}, names(step.Apply))
+	assert.Equal(t, []string{"CronTab:/crontab-1", "CronTab:/crontab-2", "CronTab:/crontab-3"}, names(step.Asserts))
+
+	spec, _, _ := unstructured.NestedStringMap(step.Apply[1].(*unstructured.Unstructured).Object, "spec")
+	assert.Equal(t, map[string]string{"cronSpec": "*/2 * * * *", "image": "busybox"}, spec)
+
+	// the templates are rendered with the template data of the test case
+	step = &Step{Dir: dir, template: NewTemplateData("kuttl-test", map[string]string{"image": "nginx"}, nil)}
+	require.NoError(t, step.LoadYAML(filepath.Join(dir, "00-step.yaml")))
+	spec, _, _ = unstructured.NestedStringMap(step.Apply[0].(*unstructured.Unstructured).Object, "spec")
+	assert.Equal(t, map[string]string{"cronSpec": "*/1 * * * *", "image": "nginx"}, spec)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "01-step.yaml"), []byte(`apiVersion: kuttl.dev/v1beta1
+kind: TestStep
+generate:
+- apply: [templates/crontab.yaml]
+`), 0644))
+	step = &Step{Dir: dir}
+	assert.EqualError(t, step.LoadYAML(filepath.Join(dir, "01-step.yaml")), `step "step" generate: generate requires a positive count, got 0`)
+}
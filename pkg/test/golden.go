@@ -0,0 +1,135 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// goldenFields are removed from objects before they are compared with golden files, they differ between test runs.
+var goldenFields = []string{"metadata.namespace", "metadata.managedFields", "metadata.ownerReferences[*].uid"}
+
+// CheckGolden compares the objects of the golden asserts of the TestAssert with their golden files.
+// If UpdateGolden is set, the golden files are rewritten from the objects instead.
+func (s *Step) CheckGolden(ctx context.Context, namespace string) []error {
+	if s.Assert == nil || len(s.Assert.Golden) == 0 {
+		return nil
+	}
+
+	errs := []error{}
+
+	for _, golden := range s.Assert.Golden {
+		if err := s.checkGolden(ctx, namespace, golden); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// checkGolden compares an object with its golden file or rewrites the golden file if UpdateGolden is set.
+func (s *Step) checkGolden(ctx context.Context, namespace string, golden harness.Golden) error {
+	if golden.Name == "" || golden.File == "" {
+		return fmt.Errorf("golden assert of kind %s requires a name and file", golden.Kind)
+	}
+
+	actual, err := s.goldenObject(ctx, namespace, golden)
+	if err != nil {
+		return err
+	}
+
+	actualYAML := &bytes.Buffer{}
+	if err := testutils.MarshalObject(actual, actualYAML); err != nil {
+		return err
+	}
+
+	path := filepath.Join(s.Dir, golden.File)
+
+	if s.UpdateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, actualYAML.Bytes(), 0644); err != nil {
+			return err
+		}
+		s.addArtifact(path)
+		s.Logger.Logf("updated golden file %s from %s", golden.File, testutils.ResourceID(actual))
+		return nil
+	}
+
+	objects, err := testutils.LoadYAMLFromFile(path)
+	if err != nil {
+		return fmt.Errorf("golden file %s: %w", golden.File, err)
+	}
+	if len(objects) != 1 {
+		return fmt.Errorf("golden file %s: expected 1 object, found %d", golden.File, len(objects))
+	}
+
+	expected, ok := objects[0].(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("golden file %s: unexpected object of type %T", golden.File, objects[0])
+	}
+	testutils.RemoveFields(expected.Object, append(goldenFields, s.ignoredFields()...))
+
+	expectedYAML := &bytes.Buffer{}
+	if err := testutils.MarshalObject(expected, expectedYAML); err != nil {
+		return err
+	}
+
+	if !bytes.Equal(expectedYAML.Bytes(), actualYAML.Bytes()) {
+		diff, err := testutils.Diff(expected, actual, testutils.DiffOptions{Color: !s.NoColor})
+		if err != nil {
+			diff = err.Error()
+		}
+		return fmt.Errorf("%s does not match golden file %s (run with --update-golden to update it):\n%s", testutils.ResourceID(actual), golden.File, diff)
+	}
+
+	return nil
+}
+
+// goldenObject fetches the object of a golden assert without the fields which are not compared.
+func (s *Step) goldenObject(ctx context.Context, namespace string, golden harness.Golden) (*unstructured.Unstructured, error) {
+	cl, err := s.Client(false)
+	if err != nil {
+		return nil, err
+	}
+
+	dClient, err := s.DiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if golden.Namespace != "" {
+		namespace = golden.Namespace
+	}
+
+	obj := testutils.NewResource(golden.APIVersion, golden.Kind, golden.Name, "")
+	if golden.Cluster != "" {
+		obj = testutils.WithAnnotations(obj, map[string]string{clusterAnnotation: golden.Cluster})
+	}
+
+	cl, dClient, err = s.objectClients(cl, dClient, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, _, err := testutils.Namespaced(dClient, obj, namespace); err != nil {
+		return nil, err
+	}
+
+	actual := obj.(*unstructured.Unstructured)
+	if err := cl.Get(ctx, testutils.ObjectKey(actual), actual); err != nil {
+		return nil, fmt.Errorf("golden file %s: %w", golden.File, err)
+	}
+
+	testutils.RemoveFields(actual.Object, append(goldenFields, s.ignoredFields()...))
+	return actual, nil
+}
@@ -0,0 +1,82 @@
+package test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestStepCheckGolden(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-golden")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello", Namespace: testNamespace, UID: "1234"},
+		Spec:       corev1.ServiceSpec{ClusterIP: "10.0.0.1"},
+	}
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme, service)
+
+	step := Step{
+		Dir:    dir,
+		Logger: testutils.NewTestLogger(t, ""),
+		Assert: &harness.TestAssert{
+			Golden: []harness.Golden{
+				{
+					ObjectReference: corev1.ObjectReference{APIVersion: "v1", Kind: "Service", Name: "hello"},
+					File:            "golden/service.yaml",
+				},
+			},
+		},
+		Client: func(bool) (client.Client, error) {
+			return cl, nil
+		},
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) {
+			return testutils.FakeDiscoveryClient(), nil
+		},
+	}
+
+	// the golden file does not exist yet
+	assert.Equal(t, 1, len(step.CheckGolden(context.TODO(), testNamespace)))
+
+	step.UpdateGolden = true
+	assert.Equal(t, 0, len(step.CheckGolden(context.TODO(), testNamespace)))
+
+	golden, err := ioutil.ReadFile(filepath.Join(dir, "golden/service.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: hello
+spec:
+  clusterIP: 10.0.0.1
+status:
+  loadBalancer: {}
+`, string(golden))
+
+	step.UpdateGolden = false
+	assert.Equal(t, 0, len(step.CheckGolden(context.TODO(), testNamespace)))
+
+	// golden files must match exactly, not only be a subset
+	service.Spec.Selector = map[string]string{"app": "hello"}
+	require.NoError(t, cl.Update(context.TODO(), service))
+	assert.Equal(t, 1, len(step.CheckGolden(context.TODO(), testNamespace)))
+
+	// ignored fields are not compared
+	step.IgnoredFields = []string{"spec.selector"}
+	assert.Equal(t, 0, len(step.CheckGolden(context.TODO(), testNamespace)))
+}
@@ -11,21 +11,27 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"syscall"
 	"testing"
 	"time"
 
 	volumetypes "github.com/docker/docker/api/types/volume"
 	docker "github.com/docker/docker/client"
+	"github.com/thoas/go-funk"
 	"gopkg.in/yaml.v2"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/flowcontrol"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
-	kindConfig "sigs.k8s.io/kind/pkg/apis/config/v1alpha3"
+	kindV1Alpha3 "sigs.k8s.io/kind/pkg/apis/config/v1alpha3"
+	kindConfig "sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
 
 	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
 	"github.com/kudobuilder/kuttl/pkg/file"
@@ -44,15 +50,48 @@ type Harness struct {
 	config        *rest.Config
 	docker        testutils.DockerClient
 	client        client.Client
-	dclient       discovery.DiscoveryInterface
+	dclient       discovery.CachedDiscoveryInterface
+	mapper        *restmapper.DeferredDiscoveryRESTMapper
 	env           *envtest.Environment
-	kind          *kind
+	provider      clusterProvider
 	tempPath      string
 	clientLock    sync.Mutex
 	configLock    sync.Mutex
 	stopping      bool
 	bgProcesses   []*exec.Cmd
 	report        *report.Testsuites
+	// commandEnv is the resolved environment of the test suite commands.
+	commandEnv map[string]string
+	// parallelism limits the number of concurrently running test cases, see GetParallel.
+	parallelism chan struct{}
+	// failed test cases of the run which are saved for --rerun-failed, see saveFailed.
+	failed     []failedTest
+	failedLock sync.Mutex
+	// history of the test case results including the results of the run, see addResult.
+	history testHistory
+	// flaky test cases of the run which passed on a retry.
+	flaky       []failedTest
+	historyLock sync.Mutex
+	// namespacePool are the namespaces of the pool which are not used by a test case, see usesNamespacePool.
+	namespacePool chan string
+	// fixtures used by the test cases of the run by their directory, see acquireFixtures.
+	fixtures map[string]*fixture
+	// progress of the run which is saved for --resume, see saveProgress.
+	progress     progress
+	progressLock sync.Mutex
+	// interrupted is set if the run was cancelled while its progress was saved, see keepsKIND.
+	interrupted bool
+	// cache is the informer cache of the cached kinds, see Cache.
+	cache       client.Reader
+	cacheStopCh chan struct{}
+	cacheLock   sync.Mutex
+	// clusters are the clients of the clusters of the test suite by name, see ClusterClients.
+	clusters     map[string]*ClusterClients
+	clustersLock sync.Mutex
+	// inCluster is set if the harness uses the service account of the pod it runs in, see Config.
+	inCluster bool
+	// kubernetesVersion is the version of KubernetesVersions the harness runs against, see RunKubernetesVersions.
+	kubernetesVersion string
 }
 
 // LoadTests loads all of the tests in a given directory.
@@ -70,36 +109,160 @@ func (h *Harness) LoadTests(dir string) ([]*Case, error) {
 	var tests []*Case
 
 	timeout := h.GetTimeout()
-	h.T.Logf("going to run test suite with timeout of %d seconds for each step", timeout)
+
+	hooks := ""
+	if _, err := os.Stat(filepath.Join(dir, hooksDir)); err == nil {
+		hooks = filepath.Join(dir, hooksDir)
+	}
 
 	for _, file := range files {
-		if !file.IsDir() {
+		if !file.IsDir() || file.Name() == hooksDir || file.Name() == fixturesDir {
+			continue
+		}
+		// the setup and teardown of the test suite may be in a test directory, they are not test cases
+		if isSuiteDir(h.TestSuite, filepath.Join(dir, file.Name())) {
 			continue
 		}
 
-		tests = append(tests, &Case{
-			Timeout:            timeout,
-			Steps:              []*Step{},
-			Name:               file.Name(),
-			PreferredNamespace: h.TestSuite.Namespace,
-			Dir:                filepath.Join(dir, file.Name()),
-			SkipDelete:         h.TestSuite.SkipDelete,
-			Suppress:           h.TestSuite.Suppress,
-		})
+		metadata, err := loadTestCase(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, dependency := range metadata.DependsOn {
+			if info, err := os.Stat(filepath.Join(dir, dependency)); err != nil || !info.IsDir() {
+				return nil, fmt.Errorf("test %s depends on unknown test %s", file.Name(), dependency)
+			}
+		}
+		for _, name := range metadata.Fixtures {
+			if info, err := os.Stat(filepath.Join(dir, fixturesDir, name)); err != nil || !info.IsDir() {
+				return nil, fmt.Errorf("test %s uses unknown fixture %s", file.Name(), name)
+			}
+		}
+		if !h.selectsTags(metadata.Tags) {
+			continue
+		}
+
+		// each test case is run once for every combination of the matrix
+		for _, entry := range matrixEntries(h.TestSuite.Matrix, h.TestSuite.Values) {
+			tests = append(tests, &Case{
+				Timeout:              timeout,
+				Steps:                []*Step{},
+				Name:                 file.Name() + entry.suffix(),
+				PreferredNamespace:   h.TestSuite.Namespace,
+				NamespaceTemplate:    h.TestSuite.NamespaceTemplate,
+				NamespaceLabels:      h.TestSuite.NamespaceLabels,
+				NamespaceAnnotations: h.TestSuite.NamespaceAnnotations,
+				Dir:                  filepath.Join(dir, file.Name()),
+				SkipDelete:           h.TestSuite.SkipDelete,
+				KeepOnFailure:        h.TestSuite.KeepOnFailure,
+				PauseOnFailure:       h.TestSuite.PauseOnFailure,
+				DebugShell:           h.TestSuite.DebugShell,
+				Suppress:             h.TestSuite.Suppress,
+				Env:                  h.commandEnv,
+				IgnoredFields:        h.TestSuite.IgnoredFields,
+				CachedKinds:          h.TestSuite.CachedKinds,
+				Backoff:              h.TestSuite.Backoff,
+				UpdateGolden:         h.TestSuite.UpdateGolden,
+				Record:               h.TestSuite.Record,
+				ValidateManifests:    h.TestSuite.ValidateManifests,
+				StrictApply:          h.TestSuite.StrictApply,
+				Tracking:             h.TestSuite.Tracking,
+				NoColor:              h.TestSuite.NoColor || h.TestSuite.LogFormat == harness.LogFormatJSON,
+				TestTimeout:          h.TestSuite.TestTimeout,
+				FromStep:             h.TestSuite.FromStep,
+				ToStep:               h.TestSuite.ToStep,
+				Template:             h.TestSuite.Template,
+				Values:               entry.values,
+				Serial:               funk.ContainsString(h.TestSuite.Serial, file.Name()),
+				Tags:                 metadata.Tags,
+				Requires:             metadata.Requires,
+				HooksDir:             hooks,
+				DependsOn:            metadata.DependsOn,
+				Fixtures:             metadata.Fixtures,
+				ClusterNames:         h.clusterNames(),
+				finished:             make(chan struct{}),
+			})
+		}
 	}
 
 	return tests, nil
 }
 
+// matrixEntry is a combination of the values of a test suite matrix.
+type matrixEntry struct {
+	// pairs are the key=value pairs of the combination.
+	pairs  []string
+	values map[string]string
+}
+
+// suffix returns the suffix of the test case name for the combination, e.g. "[tag=1.0,version=2]".
+func (e matrixEntry) suffix() string {
+	if len(e.pairs) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(e.pairs, ",") + "]"
+}
+
+// matrixEntries returns all combinations of the matrix values merged into the test suite values.
+// The combinations are ordered by the sorted matrix keys, an empty matrix results in a single entry.
+func matrixEntries(matrix map[string][]string, values map[string]string) []matrixEntry {
+	keys := []string{}
+	for key, matrixValues := range matrix {
+		if len(matrixValues) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	entries := []matrixEntry{{values: values}}
+	for _, key := range keys {
+		expanded := []matrixEntry{}
+		for _, entry := range entries {
+			for _, value := range matrix[key] {
+				entryValues := map[string]string{}
+				for k, v := range entry.values {
+					entryValues[k] = v
+				}
+				entryValues[key] = value
+
+				pairs := append(append([]string{}, entry.pairs...), fmt.Sprintf("%s=%s", key, value))
+				expanded = append(expanded, matrixEntry{pairs: pairs, values: entryValues})
+			}
+		}
+		entries = expanded
+	}
+
+	return entries
+}
+
 // GetLogger returns an initialized test logger.
 func (h *Harness) GetLogger() testutils.Logger {
 	if h.logger == nil {
-		h.logger = testutils.NewTestLogger(h.T, "")
+		switch {
+		case h.TestSuite.LogFormat == harness.LogFormatJSON:
+			h.logger = testutils.NewJSONLogger(os.Stdout, "", "")
+		case h.TestSuite.StreamLogs:
+			h.logger = testutils.NewStreamLogger(os.Stdout, "")
+		default:
+			h.logger = testutils.NewTestLogger(h.T, "")
+		}
 	}
 
 	return h.logger
 }
 
+// newLogger returns a logger of a test of a test suite in the configured log format.
+func (h *Harness) newLogger(t *testing.T, suite, test string) testutils.Logger {
+	switch logger := h.GetLogger().(type) {
+	case *testutils.JSONLogger:
+		return logger.WithTest(suite, test)
+	case *testutils.StreamLogger:
+		return logger.WithPrefix(test)
+	default:
+		return testutils.NewTestLogger(t, test)
+	}
+}
+
 // GetTimeout returns the configured timeout for the test suite.
 func (h *Harness) GetTimeout() int {
 	timeout := 30
@@ -109,9 +272,52 @@ func (h *Harness) GetTimeout() int {
 	return timeout
 }
 
-// RunKIND starts a KIND cluster.
+// GetParallel returns the maximum number of test cases to run at once.
+func (h *Harness) GetParallel() int {
+	parallel := 8
+	if h.TestSuite.Parallel > 0 {
+		parallel = h.TestSuite.Parallel
+	}
+	return parallel
+}
+
+// GetQPS returns the maximum number of requests per second to the API server.
+func (h *Harness) GetQPS() int {
+	qps := 20
+	if h.TestSuite.QPS > 0 {
+		qps = h.TestSuite.QPS
+	}
+	return qps
+}
+
+// GetBurst returns the maximum number of requests to the API server sent at once above the QPS.
+func (h *Harness) GetBurst() int {
+	burst := 30
+	if h.TestSuite.Burst > 0 {
+		burst = h.TestSuite.Burst
+	}
+	return burst
+}
+
+// limitRate sets the rate limit of a config, the clients created from it share its rate limiter.
+func (h *Harness) limitRate(cfg *rest.Config) {
+	cfg.QPS = float32(h.GetQPS())
+	cfg.Burst = h.GetBurst()
+	cfg.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(cfg.QPS, cfg.Burst)
+}
+
+// GetMaxFailures returns the number of failed test cases after which the remaining test cases are skipped,
+// 0 means no limit.
+func (h *Harness) GetMaxFailures() int {
+	if h.TestSuite.FailFast {
+		return 1
+	}
+	return h.TestSuite.MaxFailures
+}
+
+// RunKIND starts a local cluster with the cluster provider of the test suite, a KIND cluster by default.
 func (h *Harness) RunKIND() (*rest.Config, error) {
-	if h.kind == nil {
+	if h.provider == nil {
 		var err error
 
 		err = h.initTempPath()
@@ -119,50 +325,69 @@ func (h *Harness) RunKIND() (*rest.Config, error) {
 			return nil, err
 		}
 
-		kind := newKind(h.TestSuite.KINDContext, h.kubeconfigPath(), h.GetLogger())
-		h.kind = &kind
+		h.provider, err = h.newClusterProvider()
+		if err != nil {
+			return nil, err
+		}
+
+		if h.provider.IsRunning() {
+			if h.resumesKIND() {
+				h.T.Logf("re-attaching to the %s cluster %s of the previous run", h.provider.Name(), h.TestSuite.KINDContext)
+				if err := h.provider.ExportKubeconfig(); err != nil {
+					return nil, err
+				}
+				if err := h.addKINDImages(); err != nil {
+					return nil, err
+				}
+				h.setProgressKINDContext()
+				return clientcmd.BuildConfigFromFlags("", h.kubeconfigPath())
+			}
 
-		if h.kind.IsRunning() {
 			// we don't take over an existing kind cluster for --start-kind
 			// which means we do not stop that cluster.  User will either need to switch to existing cluster or stop it.
-			h.kind = nil
-			msg := "KIND is already running, unable to start"
+			msg := fmt.Sprintf("%s is already running, unable to start", h.provider.Name())
+			h.provider = nil
 			h.T.Log(msg)
 			return nil, errors.New(msg)
 		}
 
-		kindCfg := &kindConfig.Cluster{}
+		// the images are built before the cluster is started so that broken builds fail fast
+		if err := h.buildImages(context.TODO()); err != nil {
+			return nil, err
+		}
 
-		if h.TestSuite.KINDConfig != "" {
-			h.T.Logf("Loading KIND config from %s", h.TestSuite.KINDConfig)
-			var err error
-			kindCfg, err = loadKindConfig(h.TestSuite.KINDConfig)
-			if err != nil {
-				return nil, err
-			}
+		h.T.Logf("Starting %s cluster", h.provider.Name())
+		if err := h.provider.Start(); err != nil {
+			return nil, err
 		}
 
-		dockerClient, err := h.DockerClient()
-		if err != nil {
+		if err := h.provider.LoadImages(h.kindImages()); err != nil {
 			return nil, err
 		}
 
-		// Determine the correct API version to use with the user's Docker client.
-		dockerClient.NegotiateAPIVersion(context.TODO())
+		h.setProgressKINDContext()
+	}
 
-		h.addNodeCaches(dockerClient, kindCfg)
+	return clientcmd.BuildConfigFromFlags("", h.kubeconfigPath())
+}
 
-		h.T.Log("Starting KIND cluster")
-		if err := h.kind.Run(kindCfg); err != nil {
-			return nil, err
-		}
+// kindConfig returns the KIND configuration of the KINDConfig file and the KINDCluster of the test suite.
+func (h *Harness) kindConfig() (*kindConfig.Cluster, error) {
+	kindCfg := &kindConfig.Cluster{}
 
-		if err := h.kind.AddContainers(dockerClient, h.TestSuite.KINDContainers, h.T); err != nil {
+	if h.TestSuite.KINDConfig != "" {
+		h.T.Logf("Loading KIND config from %s", h.TestSuite.KINDConfig)
+		var err error
+		kindCfg, err = loadKindConfig(h.TestSuite.KINDConfig)
+		if err != nil {
 			return nil, err
 		}
 	}
 
-	return clientcmd.BuildConfigFromFlags("", h.kubeconfigPath())
+	if err := applyKINDCluster(kindCfg, h.TestSuite.KINDCluster); err != nil {
+		return nil, err
+	}
+	return kindCfg, nil
 }
 
 // initTempPath creates the temp folder if needed.
@@ -212,6 +437,18 @@ func (h *Harness) addNodeCaches(dockerClient testutils.DockerClient, kindCfg *ki
 func (h *Harness) RunTestEnv() (*rest.Config, error) {
 	started := time.Now()
 
+	if h.kubernetesVersion != "" {
+		h.T.Logf("using kube-apiserver and etcd %s", h.kubernetesVersion)
+		assets, err := envtestAssets(h.kubernetesVersion)
+		if err != nil {
+			return nil, err
+		}
+		// the binaries are looked up by the test environment when it starts
+		if err := os.Setenv("KUBEBUILDER_ASSETS", assets); err != nil {
+			return nil, err
+		}
+	}
+
 	testenv, err := testutils.StartTestEnvironment(h.TestSuite.ControlPlaneArgs)
 	if err != nil {
 		return nil, err
@@ -241,23 +478,27 @@ func (h *Harness) Config() (*rest.Config, error) {
 		h.T.Log("running tests with a mocked control plane (kube-apiserver and etcd).")
 		h.config, err = h.RunTestEnv()
 	} else if h.TestSuite.StartKIND {
-		h.T.Log("running tests with KIND.")
+		h.T.Logf("running tests with %s.", h.clusterProviderName())
 		h.config, err = h.RunKIND()
+	} else if h.TestSuite.Provision != nil {
+		h.T.Log("running tests with a provisioned cluster.")
+		h.config, err = h.RunKIND()
+	} else if testutils.UsesInClusterConfig() {
+		h.T.Log("running tests in the cluster with the service account of the pod.")
+		h.config, err = config.GetConfig()
+		h.inCluster = true
 	} else {
 		h.T.Log("running tests using configured kubeconfig.")
 		h.config, err = config.GetConfig()
-		inCluster, _ := testutils.InClusterConfig()
-		if err == nil && inCluster {
-			return h.config, nil
-		}
 	}
 
 	if err != nil {
 		return h.config, err
 	}
+	h.limitRate(h.config)
 
 	// if not the mocked control plane
-	if !h.TestSuite.StartControlPlane {
+	if !h.TestSuite.StartControlPlane && !h.inCluster {
 		// newly started clusters aren't ready until default service account is ready
 		// fixes: error looking up service account <namespace>/default: serviceaccount "default" not found
 		// we avoid this with "inCluster" as the cluster must be already be up since we're running on it
@@ -267,7 +508,8 @@ func (h *Harness) Config() (*rest.Config, error) {
 		}
 	}
 
-	// The creation of the "kubeconfig" is necessary for out of cluster execution of kubectl
+	// The creation of the "kubeconfig" is necessary for out of cluster execution of kubectl, in the cluster
+	// it refers to the token file of the service account
 	f, err := os.Create("kubeconfig")
 	if err != nil {
 		return h.config, err
@@ -278,6 +520,21 @@ func (h *Harness) Config() (*rest.Config, error) {
 	return h.config, testutils.Kubeconfig(h.config, f)
 }
 
+// suiteNamespace returns the namespace of the test suite commands, the namespace of the service account when the
+// harness runs in the cluster.
+func (h *Harness) suiteNamespace() string {
+	if !h.inCluster {
+		return "default"
+	}
+
+	namespace, err := testutils.InClusterNamespace()
+	if err != nil {
+		h.T.Log("error reading the namespace of the service account", err)
+		return "default"
+	}
+	return namespace
+}
+
 // Client returns the current Kubernetes client for the test harness.
 func (h *Harness) Client(forceNew bool) (client.Client, error) {
 	h.clientLock.Lock()
@@ -292,8 +549,21 @@ func (h *Harness) Client(forceNew bool) (client.Client, error) {
 		return nil, err
 	}
 
+	dClient, err := h.discoveryClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// the new client uses the API resources installed since the client was created, e.g. CRDs
+	if h.mapper == nil {
+		h.mapper = restmapper.NewDeferredDiscoveryRESTMapper(dClient)
+	} else if forceNew {
+		h.mapper.Reset()
+	}
+
 	h.client, err = testutils.NewRetryClient(cfg, client.Options{
 		Scheme: testutils.Scheme(),
+		Mapper: h.mapper,
 	})
 	return h.client, err
 }
@@ -303,6 +573,12 @@ func (h *Harness) DiscoveryClient() (discovery.DiscoveryInterface, error) {
 	h.clientLock.Lock()
 	defer h.clientLock.Unlock()
 
+	return h.discoveryClient()
+}
+
+// discoveryClient returns the discovery client shared by the test cases, the API resources of the server are cached
+// by it. It must be called with the client lock held.
+func (h *Harness) discoveryClient() (discovery.CachedDiscoveryInterface, error) {
 	if h.dclient != nil {
 		return h.dclient, nil
 	}
@@ -312,8 +588,13 @@ func (h *Harness) DiscoveryClient() (discovery.DiscoveryInterface, error) {
 		return nil, err
 	}
 
-	h.dclient, err = discovery.NewDiscoveryClientForConfig(cfg)
-	return h.dclient, err
+	dClient, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	h.dclient = testutils.NewCachedDiscoveryClient(dClient)
+	return h.dclient, nil
 }
 
 // DockerClient returns the Docker client to use for the test harness.
@@ -328,26 +609,113 @@ func (h *Harness) DockerClient() (testutils.DockerClient, error) {
 }
 
 // RunTests should be called from within a Go test (t) and launches all of the KUTTL integration
-// tests at dir.
-func (h *Harness) RunTests() {
+// tests at dir. If the context is cancelled, the running tests are cancelled and the remaining tests fail.
+func (h *Harness) RunTests(ctx context.Context) {
 	// cleanup after running tests
 	defer h.Stop()
 	h.T.Log("running tests")
 
 	testDirs := h.testPreProcessing()
+	h.T.Logf("going to run test suite with timeout of %d seconds for each step", h.GetTimeout())
 
 	//todo: testsuite + testsuites (extend case to have what we need (need testdir here)
 	// TestSuite is a TestSuiteCollection and should be renamed for v1beta2
 	realTestSuite := make(map[string][]*Case)
+	if h.TestSuite.Resume {
+		previous, err := loadProgress(progressPath)
+		if err != nil {
+			h.T.Fatal(err)
+		}
+		h.progressLock.Lock()
+		h.progress.Passed = previous.Passed
+		h.progressLock.Unlock()
+	}
 	for _, testDir := range testDirs {
 		tempTests, err := h.LoadTests(testDir)
 		if err != nil {
 			h.T.Fatal(err)
 		}
+		if h.TestSuite.ShardCount > 1 {
+			count := len(tempTests)
+			tempTests = filterShard(tempTests, testDir, h.TestSuite.ShardIndex, h.TestSuite.ShardCount)
+			h.T.Logf("running %d of %d tests of %s in shard %d of %d", len(tempTests), count, testDir, h.TestSuite.ShardIndex, h.TestSuite.ShardCount)
+		}
+		if h.TestSuite.RerunFailed {
+			failed, err := loadFailed(h.TestSuite.FailedFile)
+			if err != nil {
+				h.T.Fatal(err)
+			}
+			tempTests = filterFailed(tempTests, testDir, failed)
+			h.T.Logf("rerunning %d failed tests of %s", len(tempTests), testDir)
+		}
+		if h.TestSuite.Resume {
+			count := len(tempTests)
+			tempTests = filterPassed(tempTests, testDir, h.progress.Passed)
+			h.T.Logf("resuming %s, skipping %d tests which passed in the previous run", testDir, count-len(tempTests))
+		}
+		// the test cases follow the test cases they depend on
+		tempTests, err = orderByDependencies(tempTests)
+		if err != nil {
+			h.T.Fatal(err)
+		}
 		// array of test cases tied to testsuite (by testdir)
 		realTestSuite[testDir] = tempTests
 	}
 
+	// the reports of the shards are told apart when they are merged
+	if h.TestSuite.ShardCount > 1 && h.report != nil {
+		h.report.AddProperty(report.Property{Name: "shard", Value: fmt.Sprintf("%d/%d", h.TestSuite.ShardIndex, h.TestSuite.ShardCount)})
+	}
+
+	if h.TestSuite.HistoryFile != "" {
+		history, err := loadHistory(h.TestSuite.HistoryFile)
+		if err != nil {
+			h.T.Fatal(err)
+		}
+		h.history = history
+	}
+
+	// when embedded in `go test` the -test.parallel flag is not set by kuttl,
+	// the semaphore ensures the TestSuite parallel setting is honored in both cases.
+	h.parallelism = make(chan struct{}, h.GetParallel())
+
+	// the teardown runs after all test cases, also if the setup failed or the run was interrupted
+	if h.TestSuite.Teardown != "" {
+		defer h.runTeardown()
+	}
+	if h.TestSuite.Setup != "" && !h.runSuiteCase(ctx, setupCase, h.TestSuite.Setup) {
+		h.T.Log("setup failed, skipping all tests")
+		h.skipTests(realTestSuite, "setup failed")
+		return
+	}
+	if h.TestSuite.Upgrade != nil && !h.runUpgrade(ctx) {
+		h.T.Log("upgrade failed, skipping all tests")
+		h.skipTests(realTestSuite, "upgrade failed")
+		return
+	}
+
+	// the fixtures are torn down before the teardown of the test suite
+	h.initFixtures(realTestSuite)
+	defer h.teardownFixtures()
+
+	if h.usesNamespacePool() {
+		// the namespaces are deleted even if the creation of the pool fails
+		defer h.deleteNamespacePool(context.Background())
+		if err := h.createNamespacePool(ctx); err != nil {
+			h.T.Fatal(err)
+		}
+	}
+
+	// the running test cases are cancelled when the test suite times out
+	if h.TestSuite.SuiteTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(h.TestSuite.SuiteTimeout)*time.Second)
+		defer cancel()
+	}
+
+	// the loggers of the tests are derived from the harness logger, it is initialized before the tests run in parallel
+	h.GetLogger()
+
 	h.T.Run("harness", func(t *testing.T) {
 		for testDir, tests := range realTestSuite {
 
@@ -357,22 +725,119 @@ func (h *Harness) RunTests() {
 
 				test.Client = h.Client
 				test.DiscoveryClient = h.DiscoveryClient
+				test.Config = h.Config
+				test.Clusters = h.ClusterClients
+				if len(h.TestSuite.CachedKinds) > 0 {
+					test.Cache = h.Cache
+				}
+				testDir := testDir
+
+				// the test cases depending on this test case wait until it finished
+				runTestCase(t, test, func(t *testing.T) {
+					defer h.releaseFixtures(test)
+
+					// failed tests are recorded on Goexit too, e.g. after t.Fatal
+					defer func() {
+						if t.Failed() {
+							h.addFailed(testDir, test.Name)
+						}
+					}()
+
+					// serial tests run inline, parallel tests are paused until all serial tests are finished.
+					if !test.Serial {
+						t.Parallel()
+					}
 
-				t.Run(test.Name, func(t *testing.T) {
-					test.Logger = testutils.NewTestLogger(t, test.Name)
+					// the dependencies are awaited before a slot of the parallelism is taken, they may need it
+					if reason := test.awaitDependencies(); reason != "" {
+						suite.AddTestcase(skippedCase(test, reason))
+						t.Skipf("skipping test: %s", reason)
+					}
+
+					h.parallelism <- struct{}{}
+					defer func() { <-h.parallelism }()
+
+					if h.failuresExceeded() {
+						t.Skipf("skipping test after %d failed tests", h.GetMaxFailures())
+					}
+
+					// the test case runs in a namespace of the pool, its objects are deleted when it is returned
+					if h.namespacePool != nil {
+						test.PreferredNamespace = h.takeNamespace()
+						defer func() {
+							h.returnNamespace(context.Background(), test.PreferredNamespace, h.TestSuite.KeepOnFailure && t.Failed())
+						}()
+					}
+
+					test.Logger = h.newLogger(t, testDir, test.Name)
 
 					if err := test.LoadTestSteps(); err != nil {
-						t.Fatal(err)
+						test.reporter(t).Fatal(err)
+					}
+
+					// a test case whose requirements are not met by the cluster is skipped
+					reason, err := test.unmetRequirement(ctx, test.Requires, test.PreferredNamespace)
+					if err != nil {
+						test.reporter(t).Fatal(err)
+					}
+					if reason != "" {
+						suite.AddTestcase(skippedCase(test, reason))
+						t.Skipf("skipping test: %s", reason)
+					}
+
+					if err := h.acquireFixtures(ctx, test); err != nil {
+						test.reporter(t).Fatal(err)
 					}
 
-					tc := report.NewCase(test.Name)
-					test.Run(t, tc)
+					quarantined := h.quarantined(testDir, test.Name)
+					if quarantined {
+						test.Logger.Log("test is quarantined, it was flaky in recent runs and its failures are ignored")
+					}
+
+					tc := test.RunWithRetries(ctx, t, h.TestSuite.Retries, quarantined)
+					tc.Tags = test.Tags
 					suite.AddTestcase(tc)
+
+					test.passed = !t.Failed() && tc.Failure == nil
+					if test.passed {
+						h.addPassed(testDir, test.Name)
+					}
+
+					switch {
+					case t.Failed() || tc.Failure != nil:
+						h.addResult(testDir, test.Name, resultFailed)
+					case len(tc.FlakyFailures) > 0:
+						h.addResult(testDir, test.Name, resultFlaky)
+					default:
+						h.addResult(testDir, test.Name, resultPassed)
+					}
 				})
 			}
 		}
 	})
 
+	if err := h.saveFailed(); err != nil {
+		h.T.Log("error saving failed tests", err)
+	}
+
+	if err := h.saveHistory(); err != nil {
+		h.T.Log("error saving test history", err)
+	}
+
+	// an interrupted run is resumed by --resume, the progress of a completed run is discarded
+	if h.TestSuite.Resume {
+		h.progressLock.Lock()
+		h.interrupted = ctx.Err() != nil
+		h.progressLock.Unlock()
+
+		if !h.interrupted {
+			if err := os.Remove(progressPath); err != nil && !os.IsNotExist(err) {
+				h.T.Log("error removing progress", err)
+			}
+		}
+	}
+	h.logFlaky()
+
 	h.T.Log("run tests finished")
 }
 
@@ -410,30 +875,53 @@ func (h *Harness) testPreProcessing() []string {
 }
 
 // Run the test harness - start the control plane and then run the tests.
+// On SIGINT or SIGTERM the running tests are cancelled, which deletes their namespaces and stops their
+// background processes, before the harness is stopped as usual. A second signal stops the harness immediately.
 func (h *Harness) Run() {
+	ctx, stop := h.signalContext()
+	defer stop()
+
+	h.Setup(ctx)
+	h.RunTests(ctx)
+	h.Report()
+}
+
+// signalContext returns a context which is cancelled on SIGINT or SIGTERM, a second signal stops the harness and
+// exits. The returned function stops capturing the signals.
+func (h *Harness) signalContext() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
 
 	// capture ctrl+c and provide clean up
+	sigchan := make(chan os.Signal, 2)
+	signal.Notify(sigchan, os.Interrupt, syscall.SIGTERM)
 	go func() {
-		sigchan := make(chan os.Signal, 1)
-		signal.Notify(sigchan, os.Interrupt)
 		sig := <-sigchan
+		h.T.Logf("received %s, cancelling the running tests", sig)
+		cancel()
+
+		sig = <-sigchan
 		h.Stop()
 		h.T.Log("failed with", sig)
 		os.Exit(-1)
 	}()
 
-	h.Setup()
-	h.RunTests()
-	h.Report()
+	return ctx, func() {
+		signal.Stop(sigchan)
+		cancel()
+	}
 }
 
 // Setup spins up the test env based on configuration
 // It can be used to start env which can than be modified prior to running tests, otherwise use Run().
-func (h *Harness) Setup() {
+// If the context is cancelled, the installation of the CRDs and manifests and the commands of the test suite stop.
+func (h *Harness) Setup(ctx context.Context) {
 	rand.Seed(time.Now().UTC().UnixNano())
 	h.report = report.NewSuiteCollection(h.TestSuite.Name)
 	h.T.Log("starting setup")
 
+	// the CRDs and manifests of the test suite may be encrypted as well as the test steps
+	testutils.DecryptSops = h.TestSuite.Sops
+
 	cl, err := h.Client(false)
 	if err != nil {
 		h.fatal(fmt.Errorf("fatal error getting client: %v", err))
@@ -446,7 +934,7 @@ func (h *Harness) Setup() {
 
 	// Install CRDs
 	crdKind := testutils.NewResource("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "", "")
-	crds, err := testutils.InstallManifests(context.TODO(), cl, dClient, h.TestSuite.CRDDir, crdKind)
+	crds, err := testutils.InstallManifests(ctx, cl, dClient, h.TestSuite.CRDDir, crdKind)
 	if err != nil {
 		h.fatal(fmt.Errorf("fatal error installing crds: %v", err))
 	}
@@ -455,6 +943,10 @@ func (h *Harness) Setup() {
 		h.fatal(fmt.Errorf("fatal error waiting for crds: %v", err))
 	}
 
+	if err := h.installCRDs(ctx, cl); err != nil {
+		h.fatal(fmt.Errorf("fatal error installing crds: %v", err))
+	}
+
 	// Create a new client to bust the client's CRD cache.
 	cl, err = h.Client(true)
 	if err != nil {
@@ -463,11 +955,16 @@ func (h *Harness) Setup() {
 
 	// Install required manifests.
 	for _, manifestDir := range h.TestSuite.ManifestDirs {
-		if _, err := testutils.InstallManifests(context.TODO(), cl, dClient, manifestDir); err != nil {
+		if _, err := testutils.InstallManifests(ctx, cl, dClient, manifestDir); err != nil {
 			h.fatal(fmt.Errorf("fatal error installing manifests: %v", err))
 		}
 	}
-	bgs, err := testutils.RunCommands(h.GetLogger(), "default", h.TestSuite.Commands, "", h.TestSuite.Timeout)
+	h.commandEnv, err = resolveEnv(ctx, cl, h.suiteNamespace(), nil, h.TestSuite.Env, h.TestSuite.EnvFrom)
+	if err != nil {
+		h.fatal(fmt.Errorf("fatal error resolving env: %v", err))
+	}
+
+	bgs, err := testutils.RunCommands(ctx, h.GetLogger(), h.suiteNamespace(), h.TestSuite.Commands, "", h.TestSuite.Timeout, h.commandEnv)
 	// assign any background processes first for cleanup in case of any errors
 	h.bgProcesses = append(h.bgProcesses, bgs...)
 	if err != nil {
@@ -482,14 +979,15 @@ func (h *Harness) Stop() {
 		close(h.managerStopCh)
 		h.managerStopCh = nil
 	}
+	h.stopCache()
 
-	if h.kind != nil {
-		logDir := filepath.Join(h.TestSuite.ArtifactsDir, fmt.Sprintf("kind-logs-%d", time.Now().Unix()))
+	if h.provider != nil {
+		logDir := filepath.Join(h.TestSuite.ArtifactsDir, fmt.Sprintf("%s-logs-%d", strings.ToLower(h.provider.Name()), time.Now().Unix()))
 
 		h.T.Log("collecting cluster logs to", logDir)
 
-		if err := h.kind.CollectLogs(logDir); err != nil {
-			h.T.Log("error collecting kind cluster logs", err)
+		if err := h.provider.CollectLogs(logDir); err != nil {
+			h.T.Logf("error collecting %s cluster logs %v", h.provider.Name(), err)
 		}
 	}
 
@@ -510,7 +1008,11 @@ func (h *Harness) Stop() {
 		}
 	}
 
-	if h.TestSuite.SkipClusterDelete {
+	if h.keepsKIND() {
+		h.T.Logf("keeping the %s cluster of the interrupted run, run with --resume again to resume it", h.TestSuite.KINDContext)
+	}
+
+	if h.TestSuite.SkipClusterDelete || (h.TestSuite.KeepOnFailure && h.hasFailed()) || h.keepsKIND() {
 		cwd, _ := os.Getwd()
 		kubeconfig := filepath.Join(cwd, "kubeconfig")
 
@@ -529,19 +1031,20 @@ func (h *Harness) Stop() {
 		h.env = nil
 	}
 
+	// the cluster is torn down first, the delete commands of a provisioned cluster may use its kubeconfig
+	if h.provider != nil {
+		h.T.Logf("tearing down %s cluster", h.provider.Name())
+		if err := h.provider.Stop(); err != nil {
+			h.T.Logf("error tearing down %s cluster %v", h.provider.Name(), err)
+		}
+
+		h.provider = nil
+	}
+
 	h.T.Logf("removing temp folder: %q", h.tempPath)
 	if err := os.RemoveAll(h.tempPath); err != nil {
 		h.T.Log("error removing temporary directory", err)
 	}
-
-	if h.kind != nil {
-		h.T.Log("tearing down kind cluster")
-		if err := h.kind.Stop(); err != nil {
-			h.T.Log("error tearing down kind cluster", err)
-		}
-
-		h.kind = nil
-	}
 }
 
 // wraps Test.Fatal in order to clean up harness
@@ -571,17 +1074,30 @@ func (h *Harness) Report() {
 	}
 }
 
+// loadKindConfig loads a KIND configuration file, v1alpha3 configurations are converted to v1alpha4.
 func loadKindConfig(path string) (*kindConfig.Cluster, error) {
 	raw, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
-	cluster := &kindConfig.Cluster{}
+	typeMeta := kindConfig.TypeMeta{}
+	if err := yaml.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, err
+	}
 
 	decoder := yaml.NewDecoder(bytes.NewReader(raw))
 	decoder.SetStrict(true)
 
+	if typeMeta.APIVersion == "" || typeMeta.APIVersion == kindV1Alpha3APIVersion {
+		cluster := &kindV1Alpha3.Cluster{}
+		if err := decoder.Decode(cluster); err != nil {
+			return nil, err
+		}
+		return kindConfigFromV1Alpha3(cluster), nil
+	}
+
+	cluster := &kindConfig.Cluster{}
 	if err := decoder.Decode(cluster); err != nil {
 		return nil, err
 	}
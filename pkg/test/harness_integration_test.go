@@ -3,6 +3,7 @@
 package test
 
 import (
+	"context"
 	"syscall"
 	"testing"
 
@@ -18,6 +19,9 @@ func TestHarnessRunIntegration(t *testing.T) {
 				"./test_data/",
 			},
 			StartControlPlane: true,
+			// the test_data/template test case is rendered with the values
+			Template: true,
+			Values:   map[string]string{"name": "hello", "tag": "1.7.9"},
 		},
 		T: t,
 	}
@@ -36,7 +40,7 @@ func TestRunBackgroundCommands(t *testing.T) {
 	}}
 	h.TestSuite.Commands = commands
 
-	h.Setup()
+	h.Setup(context.TODO())
 	defer h.Stop()
 
 	// setup creates bg processes
@@ -4,12 +4,16 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"testing"
 
 	dockertypes "github.com/docker/docker/api/types"
 	volumetypes "github.com/docker/docker/api/types/volume"
 	"github.com/stretchr/testify/assert"
-	kindConfig "sigs.k8s.io/kind/pkg/apis/config/v1alpha3"
+	"k8s.io/client-go/rest"
+	kindConfig "sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
 )
 
 func TestGetTimeout(t *testing.T) {
@@ -20,6 +24,61 @@ func TestGetTimeout(t *testing.T) {
 	assert.Equal(t, 45, h.GetTimeout())
 }
 
+func TestGetParallel(t *testing.T) {
+	h := Harness{}
+	assert.Equal(t, 8, h.GetParallel())
+
+	h.TestSuite.Parallel = 2
+	assert.Equal(t, 2, h.GetParallel())
+}
+
+func TestLimitRate(t *testing.T) {
+	h := Harness{}
+	assert.Equal(t, 20, h.GetQPS())
+	assert.Equal(t, 30, h.GetBurst())
+
+	h.TestSuite.QPS = 100
+	h.TestSuite.Burst = 200
+	cfg := &rest.Config{}
+	h.limitRate(cfg)
+	assert.Equal(t, float32(100), cfg.QPS)
+	assert.Equal(t, 200, cfg.Burst)
+	// the clients created from the config share the rate limiter
+	assert.Equal(t, float32(100), cfg.RateLimiter.QPS())
+}
+
+func TestGetMaxFailures(t *testing.T) {
+	h := Harness{}
+	assert.Equal(t, 0, h.GetMaxFailures())
+	h.addFailed("suite", "test-1")
+	assert.False(t, h.failuresExceeded())
+
+	h.TestSuite.MaxFailures = 2
+	assert.Equal(t, 2, h.GetMaxFailures())
+	assert.False(t, h.failuresExceeded())
+	h.addFailed("suite", "test-2")
+	assert.True(t, h.failuresExceeded())
+
+	h.TestSuite.FailFast = true
+	assert.Equal(t, 1, h.GetMaxFailures())
+}
+
+func TestLoadTestsSerial(t *testing.T) {
+	h := Harness{
+		T: t,
+		TestSuite: harness.TestSuite{
+			Serial: []string{"cli-test"},
+		},
+	}
+
+	tests, err := h.LoadTests("test_data")
+	assert.Nil(t, err)
+
+	for _, test := range tests {
+		assert.Equal(t, test.Name == "cli-test", test.Serial, test.Name)
+	}
+}
+
 type dockerMock struct {
 	ImageWriter *io.PipeWriter
 	imageReader *io.PipeReader
@@ -46,6 +105,10 @@ func (d *dockerMock) ImageSave(ctx context.Context, imageIDs []string) (io.ReadC
 	return d.imageReader, nil
 }
 
+func (d *dockerMock) ImageInspectWithRaw(ctx context.Context, imageID string) (dockertypes.ImageInspect, []byte, error) {
+	return dockertypes.ImageInspect{ID: "sha256:" + imageID}, nil, nil
+}
+
 func TestAddNodeCaches(t *testing.T) {
 	h := Harness{
 		T:      t,
@@ -81,3 +144,45 @@ func TestAddNodeCaches(t *testing.T) {
 	assert.Equal(t, "/var/lib/docker/data/kind-0", kindCfg.Nodes[0].ExtraMounts[0].HostPath)
 	assert.Equal(t, "/var/lib/docker/data/kind-1", kindCfg.Nodes[1].ExtraMounts[0].HostPath)
 }
+
+func TestMatrixEntries(t *testing.T) {
+	entries := matrixEntries(map[string][]string{
+		"version": {"1", "2"},
+		"tag":     {"a", "b"},
+		"empty":   {},
+	}, map[string]string{"name": "suite", "tag": "latest"})
+
+	suffixes := []string{}
+	for _, entry := range entries {
+		suffixes = append(suffixes, entry.suffix())
+	}
+	assert.Equal(t, []string{"[tag=a,version=1]", "[tag=a,version=2]", "[tag=b,version=1]", "[tag=b,version=2]"}, suffixes)
+	assert.Equal(t, map[string]string{"name": "suite", "tag": "b", "version": "1"}, entries[2].values)
+
+	entries = matrixEntries(nil, map[string]string{"name": "suite"})
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "", entries[0].suffix())
+	assert.Equal(t, map[string]string{"name": "suite"}, entries[0].values)
+}
+
+func TestLoadTestsMatrix(t *testing.T) {
+	h := Harness{
+		T: t,
+		TestSuite: harness.TestSuite{
+			Matrix: map[string][]string{"tag": {"1.0", "2.0"}},
+		},
+	}
+
+	tests, err := h.LoadTests("test_data")
+	assert.Nil(t, err)
+
+	names := []string{}
+	for _, test := range tests {
+		if strings.HasPrefix(test.Name, "cli-test") {
+			names = append(names, test.Name)
+			assert.True(t, strings.HasSuffix(test.Dir, "cli-test"))
+			assert.Equal(t, strings.TrimSuffix(strings.TrimPrefix(test.Name, "cli-test[tag="), "]"), test.Values["tag"])
+		}
+	}
+	assert.Equal(t, []string{"cli-test[tag=1.0]", "cli-test[tag=2.0]"}, names)
+}
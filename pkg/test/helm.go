@@ -0,0 +1,312 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/yaml"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	"github.com/kudobuilder/kuttl/pkg/http"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// helmRelease is a Helm release installed by a test step, it is uninstalled on Clean.
+type helmRelease struct {
+	name      string
+	namespace string
+}
+
+// RunHelm installs, upgrades and uninstalls the releases of the TestStep.Helm list in order.
+// If an operation fails, the following ones are skipped.
+func (s *Step) RunHelm(ctx context.Context, namespace string) []error {
+	if s.Step == nil || len(s.Step.Helm) == 0 {
+		return nil
+	}
+
+	// the rendered objects of the releases are collected again by every attempt of the step
+	s.releaseObjects = nil
+
+	for _, helm := range s.Step.Helm {
+		if err := s.runHelm(ctx, helm, namespace); err != nil {
+			return []error{err}
+		}
+	}
+
+	return nil
+}
+
+func (s *Step) runHelm(ctx context.Context, helm harness.Helm, namespace string) error {
+	if helm.Release == "" {
+		return errors.New("helm requires the name of the release")
+	}
+	if helm.Namespace != "" {
+		namespace = helm.Namespace
+	}
+
+	cfg, err := s.helmConfiguration(namespace)
+	if err != nil {
+		return err
+	}
+
+	timeout := s.GetTimeout()
+	if helm.Timeout > 0 {
+		timeout = helm.Timeout
+	}
+
+	if helm.Uninstall {
+		uninstall := action.NewUninstall(cfg)
+		uninstall.Timeout = time.Duration(timeout) * time.Second
+		if _, err := uninstall.Run(helm.Release); err != nil {
+			return fmt.Errorf("uninstalling helm release %s: %w", helm.Release, err)
+		}
+		s.Logger.Logf("helm release %s uninstalled", helm.Release)
+		s.removeRelease(helmRelease{name: helm.Release, namespace: namespace})
+		return nil
+	}
+
+	if helm.Chart == "" {
+		return fmt.Errorf("helm release %s requires a chart", helm.Release)
+	}
+
+	settings := cli.New()
+	pathOptions := action.ChartPathOptions{RepoURL: helm.Repo, Version: helm.Version}
+
+	chrt, err := s.loadHelmChart(helm, pathOptions, settings)
+	if err != nil {
+		return fmt.Errorf("loading chart %s of helm release %s: %w", helm.Chart, helm.Release, err)
+	}
+
+	vals, err := s.helmValues(helm, settings)
+	if err != nil {
+		return fmt.Errorf("values of helm release %s: %w", helm.Release, err)
+	}
+
+	// a release installed by a previous attempt of the step is upgraded
+	rls := helmRelease{name: helm.Release, namespace: namespace}
+	upgrade := helm.Upgrade || s.hasRelease(rls)
+	if upgrade {
+		history := action.NewHistory(cfg)
+		history.Max = 1
+		if _, err := history.Run(helm.Release); err == driver.ErrReleaseNotFound {
+			upgrade = false
+		}
+	}
+
+	var installed *release.Release
+	if upgrade {
+		client := action.NewUpgrade(cfg)
+		client.ChartPathOptions = pathOptions
+		client.Namespace = namespace
+		client.Wait = helm.Wait
+		client.Timeout = time.Duration(timeout) * time.Second
+		if installed, err = client.Run(helm.Release, chrt, vals); err != nil {
+			return fmt.Errorf("upgrading helm release %s: %w", helm.Release, err)
+		}
+		s.Logger.Logf("helm release %s upgraded to revision %d", helm.Release, installed.Version)
+	} else {
+		client := action.NewInstall(cfg)
+		client.ChartPathOptions = pathOptions
+		client.ReleaseName = helm.Release
+		client.Namespace = namespace
+		client.CreateNamespace = true
+		client.Wait = helm.Wait
+		client.Timeout = time.Duration(timeout) * time.Second
+		if installed, err = client.Run(chrt, vals); err != nil {
+			return fmt.Errorf("installing helm release %s: %w", helm.Release, err)
+		}
+		s.Logger.Logf("helm release %s installed", helm.Release)
+	}
+
+	if !s.hasRelease(rls) {
+		s.releases = append(s.releases, rls)
+	}
+
+	if helm.Assert {
+		if err := s.addReleaseObjects(installed, namespace); err != nil {
+			return fmt.Errorf("rendered manifest of helm release %s: %w", helm.Release, err)
+		}
+	}
+
+	return nil
+}
+
+// loadHelmChart loads the chart of a release, local charts are relative to the test case directory.
+func (s *Step) loadHelmChart(helm harness.Helm, pathOptions action.ChartPathOptions, settings *cli.EnvSettings) (*chart.Chart, error) {
+	name := helm.Chart
+	if helm.Repo == "" && !http.IsURL(name) && !filepath.IsAbs(name) {
+		name = filepath.Join(s.Dir, name)
+	}
+
+	path, err := pathOptions.LocateChart(name, settings)
+	if err != nil {
+		return nil, err
+	}
+	return loader.Load(path)
+}
+
+// helmValues merges the values files and the values of a release.
+func (s *Step) helmValues(helm harness.Helm, settings *cli.EnvSettings) (map[string]interface{}, error) {
+	files := []string{}
+	for _, file := range helm.ValuesFiles {
+		if !http.IsURL(file) && !filepath.IsAbs(file) {
+			file = filepath.Join(s.Dir, file)
+		}
+		files = append(files, file)
+	}
+
+	vals, err := (&values.Options{ValueFiles: files}).MergeValues(getter.All(settings))
+	if err != nil {
+		return nil, err
+	}
+
+	inline := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(helm.Values), &inline); err != nil {
+		return nil, err
+	}
+	return chartutil.CoalesceTables(inline, vals), nil
+}
+
+// addReleaseObjects adds the objects of the rendered manifest of a release to the objects asserted by the step.
+func (s *Step) addReleaseObjects(installed *release.Release, namespace string) error {
+	objs, err := testutils.LoadYAML(installed.Name, strings.NewReader(installed.Manifest))
+	if err != nil {
+		return err
+	}
+
+	dClient, err := s.DiscoveryClient()
+	if err != nil {
+		return err
+	}
+
+	// the namespace of the release may be different from the test namespace the asserts default to
+	for _, obj := range objs {
+		if _, _, err := testutils.Namespaced(dClient, obj, namespace); err != nil {
+			return err
+		}
+	}
+
+	s.releaseObjects = append(s.releaseObjects, objs...)
+	return nil
+}
+
+// CheckReleases checks the objects of the rendered manifests of the releases with TestStep.Helm.Assert set.
+func (s *Step) CheckReleases(ctx context.Context, namespace string) []error {
+	errs := concurrently(len(s.releaseObjects), func(i int) []error {
+		return s.CheckResource(ctx, s.releaseObjects[i], namespace)
+	})
+
+	testErrors := []error{}
+	for _, err := range errs {
+		testErrors = append(testErrors, err...)
+	}
+	return testErrors
+}
+
+// uninstallReleases uninstalls the releases installed by the step.
+func (s *Step) uninstallReleases() error {
+	for _, rls := range s.releases {
+		cfg, err := s.helmConfiguration(rls.namespace)
+		if err != nil {
+			return err
+		}
+		if _, err := action.NewUninstall(cfg).Run(rls.name); err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+			return fmt.Errorf("uninstalling helm release %s: %w", rls.name, err)
+		}
+		s.Logger.Logf("helm release %s uninstalled", rls.name)
+	}
+	s.releases = nil
+	return nil
+}
+
+func (s *Step) hasRelease(rls helmRelease) bool {
+	for _, installed := range s.releases {
+		if installed == rls {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Step) removeRelease(rls helmRelease) {
+	releases := []helmRelease{}
+	for _, installed := range s.releases {
+		if installed != rls {
+			releases = append(releases, installed)
+		}
+	}
+	s.releases = releases
+}
+
+// helmConfiguration returns the configuration of the Helm actions of a namespace. The releases are stored in
+// secrets unless the HELM_DRIVER environment variable overrides it.
+func (s *Step) helmConfiguration(namespace string) (*action.Configuration, error) {
+	if s.helmConfig != nil {
+		return s.helmConfig(namespace)
+	}
+
+	config, err := s.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &action.Configuration{}
+	if err := cfg.Init(&helmRESTClientGetter{config: config, namespace: namespace}, namespace, os.Getenv("HELM_DRIVER"), s.Logger.Logf); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// helmRESTClientGetter provides the clients of the Helm actions for the configuration of the test suite.
+type helmRESTClientGetter struct {
+	config    *rest.Config
+	namespace string
+}
+
+func (g *helmRESTClientGetter) ToRESTConfig() (*rest.Config, error) {
+	return rest.CopyConfig(g.config), nil
+}
+
+func (g *helmRESTClientGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	dClient, err := discovery.NewDiscoveryClientForConfig(g.config)
+	if err != nil {
+		return nil, err
+	}
+	return memory.NewMemCacheClient(dClient), nil
+}
+
+func (g *helmRESTClientGetter) ToRESTMapper() (meta.RESTMapper, error) {
+	dClient, err := g.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	return restmapper.NewShortcutExpander(restmapper.NewDeferredDiscoveryRESTMapper(dClient), dClient), nil
+}
+
+// ToRawKubeConfigLoader returns a client config of the namespace, the Helm actions only use its namespace.
+func (g *helmRESTClientGetter) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	return clientcmd.NewDefaultClientConfig(clientcmdapi.Config{}, &clientcmd.ConfigOverrides{
+		Context: clientcmdapi.Context{Namespace: g.namespace},
+	})
+}
@@ -0,0 +1,171 @@
+package test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// writeTestChart writes a chart of a service whose port is a value to a directory.
+func writeTestChart(t *testing.T, dir string) {
+	files := map[string]string{
+		"Chart.yaml":             "apiVersion: v2\nname: db\nversion: 1.0.0\n",
+		"values.yaml":            "port: 5432\n",
+		"templates/service.yaml": "apiVersion: v1\nkind: Service\nmetadata:\n  name: {{ .Release.Name }}\nspec:\n  ports:\n  - port: {{ .Values.port }}\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+}
+
+func helmTestStep(t *testing.T, dir string, cl client.Client, cfg *action.Configuration, helm ...harness.Helm) *Step {
+	return &Step{
+		Name:    "install",
+		Index:   1,
+		Dir:     dir,
+		Timeout: 10,
+		Logger:  testutils.NewTestLogger(t, ""),
+		Step: &harness.TestStep{
+			Helm: helm,
+		},
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+		helmConfig:      func(string) (*action.Configuration, error) { return cfg, nil },
+	}
+}
+
+func TestRunHelm(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-helm")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTestChart(t, filepath.Join(dir, "chart"))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "values.yaml"), []byte("port: 3306\n"), 0644))
+
+	cfg := &action.Configuration{
+		Releases:     storage.Init(driver.NewMemory()),
+		KubeClient:   &kubefake.PrintingKubeClient{Out: ioutil.Discard},
+		Capabilities: chartutil.DefaultCapabilities,
+		Log:          t.Logf,
+	}
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme, &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: testNamespace},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 6379}}},
+	})
+
+	step := helmTestStep(t, dir, cl, cfg, harness.Helm{
+		Release:     "db",
+		Chart:       "chart",
+		ValuesFiles: []string{"values.yaml"},
+		Values:      "port: 6379",
+		Assert:      true,
+	})
+
+	require.Empty(t, step.RunHelm(context.TODO(), testNamespace))
+	installed, err := cfg.Releases.Last("db")
+	require.NoError(t, err)
+	assert.Equal(t, 1, installed.Version)
+	// the inline values override the values files
+	assert.Equal(t, map[string]interface{}{"port": float64(6379)}, installed.Config)
+
+	// the rendered objects are asserted in the namespace of the release
+	require.Len(t, step.releaseObjects, 1)
+	assert.Equal(t, "Service:"+testNamespace+"/db", testutils.ResourceID(step.releaseObjects[0]))
+	assert.Empty(t, step.CheckReleases(context.TODO(), testNamespace))
+
+	// another attempt of the step upgrades the release
+	require.Empty(t, step.RunHelm(context.TODO(), testNamespace))
+	upgraded, err := cfg.Releases.Last("db")
+	require.NoError(t, err)
+	assert.Equal(t, 2, upgraded.Version)
+	assert.Len(t, step.releaseObjects, 1)
+
+	// a release is not installed twice
+	errs := helmTestStep(t, dir, cl, cfg, harness.Helm{Release: "db", Chart: "chart"}).RunHelm(context.TODO(), testNamespace)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "installing helm release db: cannot re-use a name that is still in use")
+
+	// the releases installed by the step are uninstalled on clean
+	require.NoError(t, step.Clean(testNamespace))
+	_, err = cfg.Releases.Last("db")
+	assert.Error(t, err)
+}
+
+func TestRunHelmAssertRetried(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-helm")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTestChart(t, filepath.Join(dir, "chart"))
+
+	cfg := &action.Configuration{
+		Releases:     storage.Init(driver.NewMemory()),
+		KubeClient:   &kubefake.PrintingKubeClient{Out: ioutil.Discard},
+		Capabilities: chartutil.DefaultCapabilities,
+		Log:          t.Logf,
+	}
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme)
+
+	// the objects of the release only become ready after the first check
+	go func() {
+		time.Sleep(2 * time.Second)
+		assert.NoError(t, cl.Create(context.TODO(), &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: testNamespace},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 5432}}},
+		}))
+	}()
+
+	step := helmTestStep(t, dir, cl, cfg, harness.Helm{Release: "db", Chart: "chart", Assert: true})
+	assert.Empty(t, step.Run(context.TODO(), testNamespace))
+}
+
+func TestRunHelmUninstall(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-helm")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTestChart(t, filepath.Join(dir, "chart"))
+
+	cfg := &action.Configuration{
+		Releases:     storage.Init(driver.NewMemory()),
+		KubeClient:   &kubefake.PrintingKubeClient{Out: ioutil.Discard},
+		Capabilities: chartutil.DefaultCapabilities,
+		Log:          t.Logf,
+	}
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme)
+
+	step := helmTestStep(t, dir, cl, cfg,
+		harness.Helm{Release: "db", Chart: "chart", Upgrade: true},
+		harness.Helm{Release: "db", Uninstall: true},
+	)
+	require.Empty(t, step.RunHelm(context.TODO(), testNamespace))
+	_, err = cfg.Releases.Last("db")
+	assert.Error(t, err)
+	// the uninstalled release is not uninstalled again on clean
+	assert.Empty(t, step.releases)
+
+	errs := helmTestStep(t, dir, cl, cfg, harness.Helm{Release: "cache"}).RunHelm(context.TODO(), testNamespace)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "helm release cache requires a chart")
+}
@@ -0,0 +1,146 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// historyLength is the number of recent results kept per test case in the history file.
+const historyLength = 10
+
+// Results of the test cases in the history file.
+const (
+	resultPassed = "passed"
+	resultFailed = "failed"
+	// resultFlaky is the result of a test case which failed and passed on a retry.
+	resultFlaky = "flaky"
+)
+
+// testHistory are the recent results of the test cases by test directory and test case name, the latest result last.
+type testHistory map[string]map[string][]string
+
+// loadHistory reads the results of the previous runs, there are none if the file does not exist.
+func loadHistory(path string) (testHistory, error) {
+	history := testHistory{}
+
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(contents, &history); err != nil {
+		return nil, fmt.Errorf("reading test history from %s: %w", path, err)
+	}
+	return history, nil
+}
+
+// add records the result of a test case, only the latest historyLength results are kept.
+func (h testHistory) add(suite, name, result string) {
+	if h[suite] == nil {
+		h[suite] = map[string][]string{}
+	}
+
+	results := append(h[suite][name], result)
+	if len(results) > historyLength {
+		results = results[len(results)-historyLength:]
+	}
+	h[suite][name] = results
+}
+
+// isFlaky checks if a test case was flaky in its recent results: it passed on a retry, or it both passed and failed.
+func (h testHistory) isFlaky(suite, name string) bool {
+	passed, failed := false, false
+	for _, result := range h[suite][name] {
+		switch result {
+		case resultFlaky:
+			return true
+		case resultPassed:
+			passed = true
+		case resultFailed:
+			failed = true
+		}
+	}
+	return passed && failed
+}
+
+// save writes the history to a file.
+func (h testHistory) save(path string) error {
+	contents, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, contents, 0644)
+}
+
+// addResult records the result of a test case of the run in the history and in the flaky test cases.
+func (h *Harness) addResult(suite, name, result string) {
+	h.historyLock.Lock()
+	defer h.historyLock.Unlock()
+
+	if h.history == nil {
+		h.history = testHistory{}
+	}
+	h.history.add(suite, name, result)
+
+	if result == resultFlaky {
+		h.flaky = append(h.flaky, failedTest{Suite: suite, Name: name})
+	}
+}
+
+// quarantined checks if a test case is quarantined because it was flaky in the recent runs.
+func (h *Harness) quarantined(suite, name string) bool {
+	if !h.TestSuite.Quarantine {
+		return false
+	}
+
+	h.historyLock.Lock()
+	defer h.historyLock.Unlock()
+
+	return h.history.isFlaky(suite, name)
+}
+
+// saveHistory writes the history with the results of the run to the history file if one is configured.
+func (h *Harness) saveHistory() error {
+	if h.TestSuite.HistoryFile == "" {
+		return nil
+	}
+
+	h.historyLock.Lock()
+	defer h.historyLock.Unlock()
+
+	return h.history.save(h.TestSuite.HistoryFile)
+}
+
+// logFlaky logs the summary of the test cases which passed on a retry.
+func (h *Harness) logFlaky() {
+	h.historyLock.Lock()
+	defer h.historyLock.Unlock()
+
+	if len(h.flaky) == 0 {
+		return
+	}
+
+	flaky := append([]failedTest{}, h.flaky...)
+	sort.Slice(flaky, func(i, j int) bool {
+		if flaky[i].Suite == flaky[j].Suite {
+			return flaky[i].Name < flaky[j].Name
+		}
+		return flaky[i].Suite < flaky[j].Suite
+	})
+
+	h.T.Logf("%d flaky tests passed on a retry:", len(flaky))
+	for _, test := range flaky {
+		h.T.Logf("  %s/%s", test.Suite, test.Name)
+	}
+}
@@ -0,0 +1,47 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "history.json")
+
+	// there is no history before the first run
+	history, err := loadHistory(path)
+	assert.NoError(t, err)
+	assert.Equal(t, testHistory{}, history)
+
+	history.add("e2e", "stable", resultPassed)
+	history.add("e2e", "stable", resultPassed)
+	history.add("e2e", "retried", resultFlaky)
+	history.add("e2e", "unstable", resultFailed)
+	history.add("e2e", "unstable", resultPassed)
+	history.add("e2e", "broken", resultFailed)
+
+	assert.False(t, history.isFlaky("e2e", "stable"))
+	assert.True(t, history.isFlaky("e2e", "retried"))
+	assert.True(t, history.isFlaky("e2e", "unstable"))
+	assert.False(t, history.isFlaky("e2e", "broken"))
+	assert.False(t, history.isFlaky("e2e", "unknown"))
+
+	// only the recent results are kept
+	for i := 0; i < historyLength; i++ {
+		history.add("e2e", "retried", resultPassed)
+	}
+	assert.False(t, history.isFlaky("e2e", "retried"))
+
+	assert.NoError(t, history.save(path))
+	loaded, err := loadHistory(path)
+	assert.NoError(t, err)
+	assert.Equal(t, history, loaded)
+}
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// buildImages builds the container images of KINDImages, pre-built images are skipped.
+func (h *Harness) buildImages(ctx context.Context) error {
+	for _, image := range h.TestSuite.KINDImages {
+		switch {
+		case image.Command != "":
+			h.T.Logf("Building image %s with %q", image.Image, image.Command)
+			command := harness.Command{Command: image.Command}
+			if _, err := testutils.RunCommands(ctx, h.GetLogger(), "", []harness.Command{command}, "", 0, h.TestSuite.Env); err != nil {
+				return fmt.Errorf("building image %s: %w", image.Image, err)
+			}
+		case image.Context != "":
+			h.T.Logf("Building image %s from %s", image.Image, image.Context)
+			cmd := exec.CommandContext(ctx, "docker", dockerBuildArgs(image)...)
+			cmd.Stdout = h.GetLogger()
+			cmd.Stderr = h.GetLogger()
+			if err := cmd.Run(); err != nil {
+				return fmt.Errorf("building image %s: %w", image.Image, err)
+			}
+		}
+	}
+	return nil
+}
+
+// addKINDImages builds the images of KINDImages and loads them and KINDContainers into the running local cluster.
+func (h *Harness) addKINDImages() error {
+	if err := h.buildImages(context.TODO()); err != nil {
+		return err
+	}
+	return h.provider.LoadImages(h.kindImages())
+}
+
+// kindImages returns the images which are loaded into the KIND nodes, KINDContainers and KINDImages.
+func (h *Harness) kindImages() []string {
+	images := append([]string{}, h.TestSuite.KINDContainers...)
+	for _, image := range h.TestSuite.KINDImages {
+		images = append(images, image.Image)
+	}
+	return images
+}
+
+// dockerBuildArgs returns the arguments of the docker build of an image.
+func dockerBuildArgs(image harness.ContainerImage) []string {
+	args := []string{"build", "--tag", image.Image}
+	if image.Dockerfile != "" {
+		args = append(args, "--file", filepath.Join(image.Context, image.Dockerfile))
+	}
+
+	// the build arguments are sorted so the command is the same on every run
+	names := []string{}
+	for name := range image.BuildArgs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, "--build-arg", fmt.Sprintf("%s=%s", name, image.BuildArgs[name]))
+	}
+
+	return append(args, image.Context)
+}
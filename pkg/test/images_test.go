@@ -0,0 +1,47 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+)
+
+func TestDockerBuildArgs(t *testing.T) {
+	assert.Equal(t, []string{"build", "--tag", "operator:test", "."}, dockerBuildArgs(harness.ContainerImage{
+		Image:   "operator:test",
+		Context: ".",
+	}))
+
+	assert.Equal(t, []string{
+		"build", "--tag", "operator:test", "--file", "build/Dockerfile.test",
+		"--build-arg", "GOARCH=amd64", "--build-arg", "VERSION=test", "build",
+	}, dockerBuildArgs(harness.ContainerImage{
+		Image:      "operator:test",
+		Context:    "build",
+		Dockerfile: "Dockerfile.test",
+		BuildArgs:  map[string]string{"VERSION": "test", "GOARCH": "amd64"},
+	}))
+}
+
+func TestBuildImages(t *testing.T) {
+	h := Harness{
+		T: t,
+		TestSuite: harness.TestSuite{
+			KINDContainers: []string{"nginx:1.19"},
+			KINDImages: []harness.ContainerImage{
+				{Image: "operator:test", Command: "echo building $IMAGE", Context: "ignored"},
+				{Image: "webhook:test"},
+			},
+			Env: map[string]string{"IMAGE": "operator:test"},
+		},
+	}
+
+	assert.NoError(t, h.buildImages(context.TODO()))
+	assert.Equal(t, []string{"nginx:1.19", "operator:test", "webhook:test"}, h.kindImages())
+
+	h.TestSuite.KINDImages = []harness.ContainerImage{{Image: "operator:test", Command: "false"}}
+	assert.Error(t, h.buildImages(context.TODO()))
+}
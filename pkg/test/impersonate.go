@@ -0,0 +1,105 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// actsAs checks if the step applies, patches and deletes its objects as another user than the test suite, see
+// TestStep.Kubeconfig, TestStep.Context and TestStep.Impersonate.
+func (s *Step) actsAs() bool {
+	return s.Step != nil && (s.Step.Kubeconfig != "" || s.Step.Context != "" || s.Step.Impersonate != nil)
+}
+
+// actingClient returns the client the objects of the step are applied, patched and deleted with, the client of the
+// test suite unless the step acts as another user. It is created on first use.
+func (s *Step) actingClient(namespace string) (client.Client, error) {
+	if !s.actsAs() {
+		return s.Client(false)
+	}
+	if s.acting != nil {
+		return s.acting, nil
+	}
+
+	cfg, err := s.actingConfig(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	cl, err := testutils.NewRetryClient(cfg, client.Options{Scheme: testutils.Scheme()})
+	if err != nil {
+		return nil, err
+	}
+	s.acting = cl
+	return s.acting, nil
+}
+
+// actingConfig returns the configuration of the user the step acts as.
+func (s *Step) actingConfig(namespace string) (*rest.Config, error) {
+	var cfg *rest.Config
+
+	if s.Step.Kubeconfig != "" || s.Step.Context != "" {
+		kubeconfig := s.Step.Kubeconfig
+		if kubeconfig != "" && !filepath.IsAbs(kubeconfig) {
+			kubeconfig = filepath.Join(s.Dir, kubeconfig)
+		}
+
+		var err error
+		if cfg, err = clusterConfig(harness.Cluster{Kubeconfig: kubeconfig, Context: s.Step.Context}); err != nil {
+			return nil, fmt.Errorf("loading kubeconfig of step %s: %w", s.String(), err)
+		}
+	} else {
+		if s.Config == nil {
+			return nil, errors.New("impersonate requires a cluster configuration")
+		}
+		base, err := s.Config()
+		if err != nil {
+			return nil, err
+		}
+		cfg = rest.CopyConfig(base)
+	}
+
+	if s.Step.Impersonate != nil {
+		impersonate, err := impersonationConfig(*s.Step.Impersonate, namespace)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Impersonate = impersonate
+	}
+	return cfg, nil
+}
+
+// impersonationConfig returns the impersonation of a user or a service account, a service account without a
+// namespace is in the test namespace.
+func impersonationConfig(impersonate harness.Impersonate, namespace string) (rest.ImpersonationConfig, error) {
+	if impersonate.ServiceAccount == "" {
+		if impersonate.User == "" {
+			return rest.ImpersonationConfig{}, errors.New("impersonate requires a user or a service account")
+		}
+		return rest.ImpersonationConfig{UserName: impersonate.User, Groups: impersonate.Groups}, nil
+	}
+
+	if impersonate.User != "" {
+		return rest.ImpersonationConfig{}, errors.New("impersonate can not set both a user and a service account")
+	}
+
+	name := impersonate.ServiceAccount
+	if parts := strings.SplitN(name, "/", 2); len(parts) == 2 {
+		namespace, name = parts[0], parts[1]
+	}
+
+	// the user and groups of a service account, see k8s.io/apiserver/pkg/authentication/serviceaccount
+	groups := []string{"system:serviceaccounts", "system:serviceaccounts:" + namespace}
+	return rest.ImpersonationConfig{
+		UserName: fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name),
+		Groups:   append(groups, impersonate.Groups...),
+	}, nil
+}
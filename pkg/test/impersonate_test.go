@@ -0,0 +1,97 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+)
+
+func TestImpersonationConfig(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		impersonate harness.Impersonate
+		expected    rest.ImpersonationConfig
+		err         string
+	}{
+		{
+			name:        "user",
+			impersonate: harness.Impersonate{User: "jane", Groups: []string{"developers"}},
+			expected:    rest.ImpersonationConfig{UserName: "jane", Groups: []string{"developers"}},
+		},
+		{
+			name:        "service account in the test namespace",
+			impersonate: harness.Impersonate{ServiceAccount: "operator"},
+			expected: rest.ImpersonationConfig{
+				UserName: "system:serviceaccount:world:operator",
+				Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:world"},
+			},
+		},
+		{
+			name:        "service account in another namespace",
+			impersonate: harness.Impersonate{ServiceAccount: "system/operator"},
+			expected: rest.ImpersonationConfig{
+				UserName: "system:serviceaccount:system:operator",
+				Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:system"},
+			},
+		},
+		{
+			name: "none",
+			err:  "impersonate requires a user or a service account",
+		},
+		{
+			name:        "user and service account",
+			impersonate: harness.Impersonate{User: "jane", ServiceAccount: "operator"},
+			err:         "impersonate can not set both a user and a service account",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			impersonate, err := impersonationConfig(tt.impersonate, testNamespace)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, impersonate)
+		})
+	}
+}
+
+func TestActingConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-acting")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "kubeconfig"), []byte(clusterKubeconfig), 0644))
+
+	base := &rest.Config{Host: "https://suite.example.com", BearerToken: "admin"}
+	step := &Step{
+		Dir:    dir,
+		Config: func() (*rest.Config, error) { return base, nil },
+		Step:   &harness.TestStep{Impersonate: &harness.Impersonate{User: "jane"}},
+	}
+	assert.True(t, step.actsAs())
+
+	// the configuration of the test suite is not modified
+	cfg, err := step.actingConfig(testNamespace)
+	require.NoError(t, err)
+	assert.Equal(t, "https://suite.example.com", cfg.Host)
+	assert.Equal(t, "jane", cfg.Impersonate.UserName)
+	assert.Equal(t, "", base.Impersonate.UserName)
+
+	// the kubeconfig is relative to the test case directory
+	step.Step = &harness.TestStep{Kubeconfig: "kubeconfig", Context: "west"}
+	cfg, err = step.actingConfig(testNamespace)
+	require.NoError(t, err)
+	assert.Equal(t, "https://west.example.com", cfg.Host)
+	assert.Equal(t, "", cfg.Impersonate.UserName)
+
+	step.Step = &harness.TestStep{}
+	assert.False(t, step.actsAs())
+}
@@ -0,0 +1,227 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// jobTimeout returns the timeout for a job, the step timeout is used unless the job overrides it.
+func (s *Step) jobTimeout(job harness.Job) int {
+	if job.Timeout > 0 {
+		return job.Timeout
+	}
+	return s.GetTimeout()
+}
+
+// newJob creates the Kubernetes Job for a TestStep job.
+func (s *Step) newJob(job harness.Job, index int, namespace string) *batchv1.Job {
+	name := job.Name
+	if name == "" {
+		name = defaultJobName(s.String(), index)
+	}
+
+	// the job is run exactly once, a retry would hide a failure of the job.
+	backoffLimit := int32(0)
+	spec := *job.Spec.DeepCopy()
+	if spec.RestartPolicy == "" {
+		spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				Spec: spec,
+			},
+		},
+	}
+}
+
+// defaultJobName returns the name of a job of a test step without a name, the test step name with the index of the
+// job converted to a valid DNS-1123 label. The test step name is truncated so that the name is also a valid value
+// of the job-name label of the job pods.
+func defaultJobName(step string, index int) string {
+	suffix := fmt.Sprintf("-job-%d", index)
+	name := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(step), "-"), "-")
+	if max := validation.DNS1123LabelMaxLength - len(suffix); len(name) > max {
+		name = strings.TrimRight(name[:max], "-")
+	}
+	return name + suffix
+}
+
+// RunJobs runs all jobs of the TestStep.Jobs list to completion in order.
+// The logs of each job are written to the step log. If a job fails, the following jobs are skipped.
+// Waiting for a job stops if the context is done.
+func (s *Step) RunJobs(ctx context.Context, namespace string) []error {
+	if s.Step == nil || len(s.Step.Jobs) == 0 {
+		return nil
+	}
+
+	cl, err := s.Client(false)
+	if err != nil {
+		return []error{err}
+	}
+
+	for i, job := range s.Step.Jobs {
+		if err := s.runJob(ctx, cl, job, i, namespace); err != nil {
+			if job.IgnoreFailure {
+				s.Logger.Logf("ignoring job failure: %v", err)
+				continue
+			}
+			return []error{err}
+		}
+	}
+
+	return nil
+}
+
+func (s *Step) runJob(ctx context.Context, cl client.Client, job harness.Job, index int, namespace string) error {
+	k8sJob := s.newJob(job, index, namespace)
+	timeout := s.jobTimeout(job)
+
+	// a job of a previous attempt of the step is replaced
+	if err := s.deleteJob(ctx, cl, k8sJob, timeout); err != nil {
+		return err
+	}
+
+	if err := cl.Create(ctx, k8sJob); err != nil {
+		return fmt.Errorf("creating job %s: %w", testutils.ResourceID(k8sJob), err)
+	}
+	testutils.LogResource(s.Logger, testutils.ResourceID(k8sJob), "created")
+	s.jobs = append(s.jobs, k8sJob)
+
+	waitCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+	err := wait.PollImmediateUntil(time.Second, func() (bool, error) {
+		if err := cl.Get(ctx, testutils.ObjectKey(k8sJob), k8sJob); err != nil {
+			return false, err
+		}
+		return k8sJob.Status.Succeeded > 0 || k8sJob.Status.Failed > 0, nil
+	}, waitCtx.Done())
+
+	s.jobLogs(ctx, cl, k8sJob)
+
+	if err == wait.ErrWaitTimeout && ctx.Err() != nil {
+		return fmt.Errorf("job %s: %w", testutils.ResourceID(k8sJob), ctx.Err())
+	}
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("job %s did not complete within %d sec timeout", testutils.ResourceID(k8sJob), timeout)
+	}
+	if err != nil {
+		return err
+	}
+
+	if k8sJob.Status.Failed > 0 {
+		return fmt.Errorf("job %s failed: %s", testutils.ResourceID(k8sJob), s.jobExitStatus(ctx, cl, k8sJob))
+	}
+
+	testutils.LogResource(s.Logger, testutils.ResourceID(k8sJob), "completed")
+	return nil
+}
+
+// deleteJob deletes an existing job with the same name and waits for it to be gone. The job is deleted in the
+// foreground, so it is only gone after its pods, they would otherwise be mixed with the pods of the new job.
+func (s *Step) deleteJob(ctx context.Context, cl client.Client, job *batchv1.Job, timeout int) error {
+	existing := job.DeepCopy()
+	err := cl.Delete(ctx, existing, client.PropagationPolicy(metav1.DeletePropagationForeground))
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return wait.PollImmediate(100*time.Millisecond, time.Duration(timeout)*time.Second, func() (bool, error) {
+		err := cl.Get(ctx, testutils.ObjectKey(existing), existing)
+		if k8serrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+}
+
+// jobLogs writes the logs of all containers of the job pods to the step log, prefixed with the pod and container
+// like `kubectl logs --prefix`.
+func (s *Step) jobLogs(ctx context.Context, cl client.Client, job *batchv1.Job) {
+	clientset, err := s.kubernetesClientset()
+	if err != nil {
+		s.Logger.Logf("failed to collect logs of job %s: %v", job.Name, err)
+		return
+	}
+
+	pods := &corev1.PodList{}
+	if err := cl.List(ctx, pods, client.InNamespace(job.Namespace), client.MatchingLabels{"job-name": job.Name}); err != nil {
+		s.Logger.Logf("failed to collect logs of job %s: %v", job.Name, err)
+		return
+	}
+
+	for _, pod := range pods.Items {
+		for _, container := range append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+			if err := s.containerLogs(ctx, clientset, pod, container.Name); err != nil {
+				s.Logger.Logf("failed to collect logs of job %s: %v", job.Name, err)
+			}
+		}
+	}
+}
+
+// containerLogs writes the logs of a container of a pod to the step log.
+func (s *Step) containerLogs(ctx context.Context, clientset kubernetes.Interface, pod corev1.Pod, container string) error {
+	logs, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Container: container}).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("streaming logs of pod %s container %s: %w", pod.Name, container, err)
+	}
+	defer logs.Close()
+
+	scanner := bufio.NewScanner(logs)
+	for scanner.Scan() {
+		s.Logger.Logf("[pod/%s/%s] %s", pod.Name, container, scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// jobExitStatus describes the exit codes of the terminated containers of the job pods.
+func (s *Step) jobExitStatus(ctx context.Context, cl client.Client, job *batchv1.Job) string {
+	pods := &corev1.PodList{}
+	if err := cl.List(ctx, pods, client.InNamespace(job.Namespace), client.MatchingLabels{"job-name": job.Name}); err != nil {
+		return err.Error()
+	}
+
+	status := ""
+	for _, pod := range pods.Items {
+		for _, container := range pod.Status.ContainerStatuses {
+			if container.State.Terminated == nil || container.State.Terminated.ExitCode == 0 {
+				continue
+			}
+			status += fmt.Sprintf("container %s/%s exited with code %d (%s) ", pod.Name, container.Name,
+				container.State.Terminated.ExitCode, container.State.Terminated.Reason)
+		}
+	}
+
+	if status == "" {
+		return "no terminated containers found"
+	}
+	return status
+}
@@ -0,0 +1,183 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestRunJobs(t *testing.T) {
+	for _, test := range []struct {
+		name          string
+		status        batchv1.JobStatus
+		ignoreFailure bool
+		shouldError   bool
+	}{
+		{
+			name:   "job succeeded",
+			status: batchv1.JobStatus{Succeeded: 1},
+		},
+		{
+			name:        "job failed",
+			status:      batchv1.JobStatus{Failed: 1},
+			shouldError: true,
+		},
+		{
+			name:          "job failure ignored",
+			status:        batchv1.JobStatus{Failed: 1},
+			ignoreFailure: true,
+		},
+		{
+			name:        "job timed out",
+			shouldError: true,
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			cl := fake.NewFakeClientWithScheme(scheme.Scheme)
+
+			step := Step{
+				Name:    "migrate",
+				Index:   1,
+				Timeout: 2,
+				Logger:  testutils.NewTestLogger(t, ""),
+				Step: &harness.TestStep{
+					Jobs: []harness.Job{
+						{
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{{Name: "migrate", Image: "busybox"}},
+							},
+							IgnoreFailure: test.ignoreFailure,
+						},
+					},
+				},
+				Client:          func(bool) (client.Client, error) { return cl, nil },
+				DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+			}
+
+			go func() {
+				time.Sleep(500 * time.Millisecond)
+				job := &batchv1.Job{}
+				if err := cl.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: "1-migrate-job-0"}, job); err != nil {
+					return
+				}
+				job.Status = test.status
+				assert.Nil(t, cl.Update(context.TODO(), job))
+			}()
+
+			errs := step.RunJobs(context.TODO(), testNamespace)
+			if test.shouldError {
+				assert.Equal(t, 1, len(errs))
+			} else {
+				assert.Equal(t, 0, len(errs))
+			}
+
+			job := &batchv1.Job{}
+			assert.Nil(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: "1-migrate-job-0"}, job))
+			assert.Equal(t, corev1.RestartPolicyNever, job.Spec.Template.Spec.RestartPolicy)
+		})
+	}
+}
+
+// foregroundClient deletes the pods of a job deleted in the foreground before the job like the garbage collector.
+type foregroundClient struct {
+	client.Client
+}
+
+func (c foregroundClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOption) error {
+	options := &client.DeleteOptions{}
+	options.ApplyOptions(opts)
+	if job, ok := obj.(*batchv1.Job); ok && options.PropagationPolicy != nil && *options.PropagationPolicy == metav1.DeletePropagationForeground {
+		err := c.Client.DeleteAllOf(ctx, &corev1.Pod{}, client.InNamespace(job.Namespace), client.MatchingLabels{"job-name": job.Name})
+		if err != nil {
+			return err
+		}
+	}
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func TestDeleteJob(t *testing.T) {
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "1-migrate-job-0", Namespace: testNamespace}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "1-migrate-job-0-abcde", Namespace: testNamespace, Labels: map[string]string{"job-name": job.Name},
+	}}
+	cl := foregroundClient{fake.NewFakeClientWithScheme(scheme.Scheme, job.DeepCopy(), pod)}
+
+	step := Step{Logger: testutils.NewTestLogger(t, "")}
+	assert.NoError(t, step.deleteJob(context.TODO(), cl, job, 2))
+
+	// the pods of the previous attempt are gone with the job
+	pods := &corev1.PodList{}
+	assert.NoError(t, cl.List(context.TODO(), pods, client.InNamespace(testNamespace)))
+	assert.Empty(t, pods.Items)
+	assert.True(t, k8serrors.IsNotFound(cl.Get(context.TODO(), testutils.ObjectKey(job), &batchv1.Job{})))
+
+	// a job which does not exist is not deleted
+	assert.NoError(t, step.deleteJob(context.TODO(), cl, job, 2))
+}
+
+func TestDefaultJobName(t *testing.T) {
+	for _, test := range []struct {
+		step     string
+		expected string
+	}{
+		{step: "1-migrate", expected: "1-migrate-job-0"},
+		{step: "0-Setup_DB", expected: "0-setup-db-job-0"},
+		{step: "2-setup.db_", expected: "2-setup-db-job-0"},
+		{step: "3-" + strings.Repeat("a", 70), expected: "3-" + strings.Repeat("a", 55) + "-job-0"},
+	} {
+		name := defaultJobName(test.step, 0)
+		assert.Equal(t, test.expected, name)
+		assert.Empty(t, validation.IsDNS1123Label(name))
+	}
+}
+
+func TestJobLogs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1/namespaces/world/pods/1-migrate-job-0-abcde/log" && r.URL.Query().Get("container") == "migrate":
+			fmt.Fprint(w, "migrating\ndone\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "1-migrate-job-0", Namespace: testNamespace}}
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "1-migrate-job-0-abcde", Namespace: testNamespace, Labels: map[string]string{"job-name": job.Name}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "migrate", Image: "busybox"}}},
+	})
+
+	logger := testutils.NewCaptureLogger(testutils.NewTestLogger(t, ""))
+	step := Step{
+		Logger: logger,
+		Config: func() (*rest.Config, error) { return &rest.Config{Host: server.URL}, nil },
+	}
+
+	step.jobLogs(context.TODO(), cl, job)
+	assert.Contains(t, logger.Captured(), "[pod/1-migrate-job-0-abcde/migrate] migrating\n")
+	assert.Contains(t, logger.Captured(), "[pod/1-migrate-job-0-abcde/migrate] done\n")
+}
@@ -2,16 +2,23 @@ package test
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	"sigs.k8s.io/kind/pkg/apis/config/v1alpha3"
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
 	"sigs.k8s.io/kind/pkg/cluster"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
 	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
 )
 
+// kindV1Alpha3APIVersion is the API version of v1alpha3 KIND configurations, they are converted to v1alpha4.
+const kindV1Alpha3APIVersion = "kind.x-k8s.io/v1alpha3"
+
 // kind provides a thin abstraction layer for a KIND cluster.
 type kind struct {
 	Provider     *cluster.Provider
@@ -31,15 +38,20 @@ func newKind(kindContext string, explicitPath string, logger testutils.Logger) k
 }
 
 // Run starts a KIND cluster from a given configuration.
-func (k *kind) Run(config *v1alpha3.Cluster) error {
+func (k *kind) Run(config *v1alpha4.Cluster) error {
 	return k.Provider.Create(
 		k.context,
-		cluster.CreateWithV1Alpha3Config(config),
+		cluster.CreateWithV1Alpha4Config(config),
 		cluster.CreateWithKubeconfigPath(k.explicitPath),
 		cluster.CreateWithRetain(true),
 	)
 }
 
+// ExportKubeconfig writes the kubeconfig of an already running KIND cluster.
+func (k *kind) ExportKubeconfig() error {
+	return k.Provider.ExportKubeConfig(k.context, k.explicitPath)
+}
+
 // IsRunning checks if a KIND cluster is already running for the current context.
 func (k *kind) IsRunning() bool {
 	contexts, err := k.Provider.List()
@@ -70,8 +82,19 @@ func (k *kind) AddContainers(docker testutils.DockerClient, containers []string,
 		return err
 	}
 
-	for _, node := range nodes {
-		for _, container := range containers {
+	for _, container := range containers {
+		// the image is not loaded into nodes which have the same image already, e.g. of a previous run
+		var id string
+		if image, _, err := docker.ImageInspectWithRaw(context.TODO(), container); err == nil {
+			id = image.ID
+		}
+
+		for _, node := range nodes {
+			if nodeID, err := nodeutils.ImageID(node, container); err == nil && id != "" && nodeID == id {
+				t.Logf("Image %s is up to date on node %s\n", container, node.String())
+				continue
+			}
+
 			t.Logf("Add image %s to node %s\n", container, node.String())
 			if err := loadContainer(docker, node, container); err != nil {
 				return err
@@ -106,3 +129,193 @@ func loadContainer(docker testutils.DockerClient, node nodes.Node, container str
 
 	return nil
 }
+
+// kindProvider is the KIND cluster provider, the default cluster provider.
+type kindProvider struct {
+	kind
+	harness *Harness
+	config  *v1alpha4.Cluster
+}
+
+func (k *kindProvider) Name() string {
+	return k.harness.clusterProviderName()
+}
+
+// Start starts the KIND cluster with the node caches of the test suite.
+func (k *kindProvider) Start() error {
+	dockerClient, err := k.harness.DockerClient()
+	if err != nil {
+		return err
+	}
+
+	// Determine the correct API version to use with the user's Docker client.
+	dockerClient.NegotiateAPIVersion(context.TODO())
+
+	k.harness.addNodeCaches(dockerClient, k.config)
+	return k.Run(k.config)
+}
+
+// LoadImages loads the images into each KIND node.
+func (k *kindProvider) LoadImages(images []string) error {
+	dockerClient, err := k.harness.DockerClient()
+	if err != nil {
+		return err
+	}
+	dockerClient.NegotiateAPIVersion(context.TODO())
+
+	return k.AddContainers(dockerClient, images, k.harness.T)
+}
+
+// applyKINDCluster applies the KIND cluster configuration of the test suite over a KIND configuration.
+func applyKINDCluster(config *v1alpha4.Cluster, cluster *harness.KINDCluster) error {
+	if cluster == nil {
+		return nil
+	}
+
+	if len(cluster.Nodes) > 0 {
+		config.Nodes = []v1alpha4.Node{}
+		for _, node := range cluster.Nodes {
+			kindNode, err := kindNode(node)
+			if err != nil {
+				return err
+			}
+			config.Nodes = append(config.Nodes, kindNode)
+		}
+	}
+
+	if cluster.Workers > 0 && len(config.Nodes) == 0 {
+		config.Nodes = append(config.Nodes, v1alpha4.Node{Role: v1alpha4.ControlPlaneRole})
+	}
+	for i := 0; i < cluster.Workers; i++ {
+		config.Nodes = append(config.Nodes, v1alpha4.Node{Role: v1alpha4.WorkerRole})
+	}
+
+	if cluster.NodeImage != "" {
+		if len(config.Nodes) == 0 {
+			config.Nodes = append(config.Nodes, v1alpha4.Node{Role: v1alpha4.ControlPlaneRole})
+		}
+		for i := range config.Nodes {
+			if config.Nodes[i].Image == "" {
+				config.Nodes[i].Image = cluster.NodeImage
+			}
+		}
+	}
+
+	for gate, enabled := range cluster.FeatureGates {
+		if config.FeatureGates == nil {
+			config.FeatureGates = map[string]bool{}
+		}
+		config.FeatureGates[gate] = enabled
+	}
+
+	if cluster.Networking != nil {
+		config.Networking = v1alpha4.Networking{
+			IPFamily:          v1alpha4.ClusterIPFamily(cluster.Networking.IPFamily),
+			APIServerAddress:  cluster.Networking.APIServerAddress,
+			APIServerPort:     int32(cluster.Networking.APIServerPort),
+			PodSubnet:         cluster.Networking.PodSubnet,
+			ServiceSubnet:     cluster.Networking.ServiceSubnet,
+			DisableDefaultCNI: cluster.Networking.DisableDefaultCNI,
+		}
+	}
+
+	config.KubeadmConfigPatches = append(config.KubeadmConfigPatches, cluster.KubeadmConfigPatches...)
+	config.ContainerdConfigPatches = append(config.ContainerdConfigPatches, cluster.ContainerdConfigPatches...)
+	return nil
+}
+
+// kindNode converts a node of the KIND cluster configuration of the test suite.
+func kindNode(node harness.KINDNode) (v1alpha4.Node, error) {
+	kindNode := v1alpha4.Node{
+		Role:                 v1alpha4.NodeRole(node.Role),
+		Image:                node.Image,
+		KubeadmConfigPatches: node.KubeadmConfigPatches,
+	}
+
+	switch kindNode.Role {
+	case "":
+		kindNode.Role = v1alpha4.ControlPlaneRole
+	case v1alpha4.ControlPlaneRole, v1alpha4.WorkerRole:
+	default:
+		return v1alpha4.Node{}, fmt.Errorf("invalid KIND node role %q: must be %s or %s", node.Role, v1alpha4.ControlPlaneRole, v1alpha4.WorkerRole)
+	}
+
+	for _, mapping := range node.ExtraPortMappings {
+		protocol := v1alpha4.PortMappingProtocol(strings.ToUpper(mapping.Protocol))
+		switch protocol {
+		case "":
+			protocol = v1alpha4.PortMappingProtocolTCP
+		case v1alpha4.PortMappingProtocolTCP, v1alpha4.PortMappingProtocolUDP, v1alpha4.PortMappingProtocolSCTP:
+		default:
+			return v1alpha4.Node{}, fmt.Errorf("invalid protocol %q of KIND port mapping %d: must be TCP, UDP or SCTP", mapping.Protocol, mapping.ContainerPort)
+		}
+
+		kindNode.ExtraPortMappings = append(kindNode.ExtraPortMappings, v1alpha4.PortMapping{
+			ContainerPort: int32(mapping.ContainerPort),
+			HostPort:      int32(mapping.HostPort),
+			ListenAddress: mapping.ListenAddress,
+			Protocol:      protocol,
+		})
+	}
+
+	for _, mount := range node.ExtraMounts {
+		kindNode.ExtraMounts = append(kindNode.ExtraMounts, v1alpha4.Mount{
+			ContainerPath: mount.ContainerPath,
+			HostPath:      mount.HostPath,
+			Readonly:      mount.ReadOnly,
+		})
+	}
+
+	return kindNode, nil
+}
+
+// kindConfigFromV1Alpha3 converts a v1alpha3 KIND configuration to v1alpha4, whose fields are a superset of it.
+func kindConfigFromV1Alpha3(config *v1alpha3.Cluster) *v1alpha4.Cluster {
+	converted := &v1alpha4.Cluster{
+		Networking: v1alpha4.Networking{
+			IPFamily:          v1alpha4.ClusterIPFamily(config.Networking.IPFamily),
+			APIServerPort:     config.Networking.APIServerPort,
+			APIServerAddress:  config.Networking.APIServerAddress,
+			PodSubnet:         config.Networking.PodSubnet,
+			ServiceSubnet:     config.Networking.ServiceSubnet,
+			DisableDefaultCNI: config.Networking.DisableDefaultCNI,
+		},
+		KubeadmConfigPatches: config.KubeadmConfigPatches,
+	}
+
+	for _, patch := range config.KubeadmConfigPatchesJSON6902 {
+		converted.KubeadmConfigPatchesJSON6902 = append(converted.KubeadmConfigPatchesJSON6902, v1alpha4.PatchJSON6902{
+			Group:   patch.Group,
+			Version: patch.Version,
+			Kind:    patch.Kind,
+			Patch:   patch.Patch,
+		})
+	}
+
+	for _, node := range config.Nodes {
+		convertedNode := v1alpha4.Node{
+			Role:  v1alpha4.NodeRole(node.Role),
+			Image: node.Image,
+		}
+		for _, mount := range node.ExtraMounts {
+			convertedNode.ExtraMounts = append(convertedNode.ExtraMounts, v1alpha4.Mount{
+				ContainerPath:  mount.ContainerPath,
+				HostPath:       mount.HostPath,
+				Readonly:       mount.Readonly,
+				SelinuxRelabel: mount.SelinuxRelabel,
+				Propagation:    v1alpha4.MountPropagation(v1alpha3.MountPropagationValueToName[mount.Propagation]),
+			})
+		}
+		for _, mapping := range node.ExtraPortMappings {
+			convertedNode.ExtraPortMappings = append(convertedNode.ExtraPortMappings, v1alpha4.PortMapping{
+				ContainerPort: mapping.ContainerPort,
+				HostPort:      mapping.HostPort,
+				ListenAddress: mapping.ListenAddress,
+				Protocol:      v1alpha4.PortMappingProtocol(v1alpha3.PortMappingProtocolValueToName[mapping.Protocol]),
+			})
+		}
+		converted.Nodes = append(converted.Nodes, convertedNode)
+	}
+
+	return converted
+}
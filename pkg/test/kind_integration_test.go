@@ -12,7 +12,7 @@ import (
 	"github.com/docker/docker/api/types"
 	dockerClient "github.com/docker/docker/client"
 	"github.com/thoas/go-funk"
-	"sigs.k8s.io/kind/pkg/apis/config/v1alpha3"
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 
 	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
@@ -30,7 +30,7 @@ func TestAddContainers(t *testing.T) {
 
 	kind := newKind(kindTestContext, "kubeconfig", testutils.NewTestLogger(t, ""))
 
-	config := v1alpha3.Cluster{}
+	config := v1alpha4.Cluster{}
 
 	if err := kind.Run(&config); err != nil {
 		t.Fatalf("failed to start KIND cluster: %v", err)
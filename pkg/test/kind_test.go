@@ -0,0 +1,117 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+)
+
+func TestLoadKindConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-kind")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	v1alpha3 := `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha3
+nodes:
+- role: control-plane
+  extraPortMappings:
+  - containerPort: 53
+    hostPort: 5353
+    protocol: udp
+  extraMounts:
+  - containerPath: /data
+    hostPath: /tmp/data
+    propagation: HostToContainer
+kubeadmConfigPatchesJson6902:
+- group: kubeadm.k8s.io
+  version: v1beta2
+  kind: ClusterConfiguration
+  patch: "[]"
+`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "v1alpha3.yaml"), []byte(v1alpha3), 0644))
+
+	cfg, err := loadKindConfig(filepath.Join(dir, "v1alpha3.yaml"))
+	require.NoError(t, err)
+	require.Len(t, cfg.Nodes, 1)
+	assert.Equal(t, v1alpha4.ControlPlaneRole, cfg.Nodes[0].Role)
+	assert.Equal(t, v1alpha4.PortMappingProtocolUDP, cfg.Nodes[0].ExtraPortMappings[0].Protocol)
+	assert.Equal(t, int32(5353), cfg.Nodes[0].ExtraPortMappings[0].HostPort)
+	assert.Equal(t, v1alpha4.MountPropagationHostToContainer, cfg.Nodes[0].ExtraMounts[0].Propagation)
+	assert.Equal(t, "ClusterConfiguration", cfg.KubeadmConfigPatchesJSON6902[0].Kind)
+
+	v1alpha4Config := `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+featureGates:
+  EphemeralContainers: true
+containerdConfigPatches:
+- |-
+  [plugins."io.containerd.grpc.v1.cri".registry.mirrors."localhost:5000"]
+    endpoint = ["http://kind-registry:5000"]
+`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "v1alpha4.yaml"), []byte(v1alpha4Config), 0644))
+
+	cfg, err = loadKindConfig(filepath.Join(dir, "v1alpha4.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"EphemeralContainers": true}, cfg.FeatureGates)
+	assert.Len(t, cfg.ContainerdConfigPatches, 1)
+
+	// v1alpha4 fields are unknown to v1alpha3 configurations
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "invalid.yaml"), []byte("featureGates:\n  EphemeralContainers: true\n"), 0644))
+	_, err = loadKindConfig(filepath.Join(dir, "invalid.yaml"))
+	assert.Error(t, err)
+}
+
+func TestApplyKINDCluster(t *testing.T) {
+	cfg := &v1alpha4.Cluster{
+		Nodes:                []v1alpha4.Node{{Role: v1alpha4.ControlPlaneRole, Image: "kindest/node:v1.17.5"}},
+		FeatureGates:         map[string]bool{"EphemeralContainers": true},
+		KubeadmConfigPatches: []string{"file"},
+	}
+
+	require.NoError(t, applyKINDCluster(cfg, nil))
+	assert.Len(t, cfg.Nodes, 1)
+
+	require.NoError(t, applyKINDCluster(cfg, &harness.KINDCluster{
+		Workers:              2,
+		NodeImage:            "kindest/node:v1.18.2",
+		FeatureGates:         map[string]bool{"CSIStorageCapacity": true},
+		Networking:           &harness.KINDNetworking{APIServerPort: 6443, DisableDefaultCNI: true},
+		KubeadmConfigPatches: []string{"suite"},
+	}))
+
+	require.Len(t, cfg.Nodes, 3)
+	assert.Equal(t, "kindest/node:v1.17.5", cfg.Nodes[0].Image)
+	assert.Equal(t, v1alpha4.WorkerRole, cfg.Nodes[1].Role)
+	assert.Equal(t, "kindest/node:v1.18.2", cfg.Nodes[2].Image)
+	assert.Equal(t, map[string]bool{"EphemeralContainers": true, "CSIStorageCapacity": true}, cfg.FeatureGates)
+	assert.Equal(t, int32(6443), cfg.Networking.APIServerPort)
+	assert.True(t, cfg.Networking.DisableDefaultCNI)
+	assert.Equal(t, []string{"file", "suite"}, cfg.KubeadmConfigPatches)
+
+	// the nodes replace the nodes of the configuration file
+	cfg = &v1alpha4.Cluster{}
+	require.NoError(t, applyKINDCluster(cfg, &harness.KINDCluster{
+		Nodes: []harness.KINDNode{
+			{ExtraPortMappings: []harness.KINDPortMapping{{ContainerPort: 80, HostPort: 8080}}},
+			{Role: "worker", ExtraMounts: []harness.KINDMount{{ContainerPath: "/data", HostPath: "/tmp/data", ReadOnly: true}}},
+		},
+	}))
+	require.Len(t, cfg.Nodes, 2)
+	assert.Equal(t, v1alpha4.ControlPlaneRole, cfg.Nodes[0].Role)
+	assert.Equal(t, v1alpha4.PortMapping{ContainerPort: 80, HostPort: 8080, Protocol: v1alpha4.PortMappingProtocolTCP}, cfg.Nodes[0].ExtraPortMappings[0])
+	assert.True(t, cfg.Nodes[1].ExtraMounts[0].Readonly)
+
+	assert.EqualError(t, applyKINDCluster(cfg, &harness.KINDCluster{Nodes: []harness.KINDNode{{Role: "etcd"}}}),
+		`invalid KIND node role "etcd": must be control-plane or worker`)
+	assert.EqualError(t, applyKINDCluster(cfg, &harness.KINDCluster{Nodes: []harness.KINDNode{
+		{ExtraPortMappings: []harness.KINDPortMapping{{ContainerPort: 80, Protocol: "HTTP"}}},
+	}}), `invalid protocol "HTTP" of KIND port mapping 80: must be TCP, UDP or SCTP`)
+}
@@ -0,0 +1,40 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// isKustomization checks if a path is a directory with a kustomization file.
+func isKustomization(path string) bool {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		if info, err := os.Stat(filepath.Join(path, name)); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// kustomizeObjects builds a kustomization directory like `kustomize build` and returns the objects of the output.
+func kustomizeObjects(dir string) ([]runtime.Object, error) {
+	resources, err := krusty.MakeKustomizer(filesys.MakeFsOnDisk(), krusty.MakeDefaultOptions()).Run(dir)
+	if err != nil {
+		return nil, fmt.Errorf("building kustomization %s: %w", dir, err)
+	}
+
+	manifest, err := resources.AsYaml()
+	if err != nil {
+		return nil, fmt.Errorf("building kustomization %s: %w", dir, err)
+	}
+
+	return testutils.LoadYAML(dir, bytes.NewReader(manifest))
+}
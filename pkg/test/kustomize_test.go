@@ -0,0 +1,54 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestLoadTestStepsKustomize(t *testing.T) {
+	test := &Case{
+		Dir:                "test_data/kustomize",
+		PreferredNamespace: testNamespace,
+		Client: func(bool) (client.Client, error) {
+			return fake.NewFakeClientWithScheme(scheme.Scheme), nil
+		},
+		Logger: testutils.NewTestLogger(t, "kustomize"),
+	}
+
+	require.NoError(t, test.LoadTestSteps())
+	require.Equal(t, 2, len(test.Steps))
+
+	// the kustomization directories of a step are built
+	install := test.Steps[0]
+	assert.Equal(t, "install", install.Name)
+	require.Equal(t, 1, len(install.Apply))
+	pod := install.Apply[0].(*unstructured.Unstructured)
+	assert.Equal(t, "dev-web", pod.GetName())
+	assert.Equal(t, map[string]string{"app": "web"}, pod.GetLabels())
+	require.Equal(t, 1, len(install.Asserts))
+	assert.Equal(t, "dev-web", install.Asserts[0].(*unstructured.Unstructured).GetName())
+
+	// and the kustomization directories of the apply and assert paths of a TestStep
+	base := test.Steps[1]
+	require.Equal(t, 1, len(base.Apply))
+	assert.Equal(t, "web", base.Apply[0].(*unstructured.Unstructured).GetName())
+	require.Equal(t, 1, len(base.Asserts))
+	assert.Equal(t, "web", base.Asserts[0].(*unstructured.Unstructured).GetName())
+}
+
+func TestKustomizeObjects(t *testing.T) {
+	assert.True(t, isKustomization("test_data/kustomize/base"))
+	assert.False(t, isKustomization("test_data/kustomize"))
+	assert.False(t, isKustomization("test_data/kustomize/base/pod.yaml"))
+
+	_, err := kustomizeObjects("test_data/kustomize")
+	assert.Error(t, err)
+}
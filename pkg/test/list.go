@@ -0,0 +1,125 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// ListFormat is the output format of a test listing.
+type ListFormat string
+
+const (
+	// ListTable lists a test step per line in aligned columns.
+	ListTable ListFormat = "table"
+	// ListJSON lists the test cases as a JSON array.
+	ListJSON ListFormat = "json"
+)
+
+// TestListing is a discovered test case in a test listing.
+type TestListing struct {
+	// Suite is the test directory of the test case.
+	Suite string `json:"suite"`
+	Name  string `json:"name"`
+	Dir   string `json:"dir"`
+	// Serial indicates that the test case does not run in parallel with other test cases.
+	Serial bool     `json:"serial,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	// DependsOn are the test cases which must pass before the test case starts.
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// Fixtures are the shared fixtures the test case uses.
+	Fixtures []string          `json:"fixtures,omitempty"`
+	Steps    []TestStepListing `json:"steps"`
+}
+
+// TestStepListing is a test step of a test case in a test listing.
+type TestStepListing struct {
+	Index int    `json:"index"`
+	Name  string `json:"name"`
+	// Files of the test step relative to the test case directory.
+	Files []string `json:"files"`
+}
+
+// ListTests returns the test cases of all local test directories with the steps which are run, see DiscoverTests.
+func (h *Harness) ListTests(testToRun string) ([]TestListing, error) {
+	cases, err := h.DiscoverTests(testToRun)
+	if err != nil {
+		return nil, err
+	}
+
+	listings := []TestListing{}
+
+	for _, test := range cases {
+		listing := TestListing{
+			Suite:     h.testSuiteDir(test),
+			Name:      test.Name,
+			Dir:       test.Dir,
+			Serial:    test.Serial,
+			Tags:      test.Tags,
+			DependsOn: test.DependsOn,
+			Fixtures:  test.Fixtures,
+			Steps:     []TestStepListing{},
+		}
+
+		for _, step := range test.Steps {
+			// like in Plan, the steps which are skipped by FromStep and ToStep are not listed
+			if !test.runsStep(step) {
+				continue
+			}
+
+			files, err := test.stepFiles(step)
+			if err != nil {
+				return nil, err
+			}
+
+			stepListing := TestStepListing{Index: step.Index, Name: step.Name, Files: []string{}}
+			for _, file := range files {
+				stepListing.Files = append(stepListing.Files, relativePath(test.Dir, file))
+			}
+			listing.Steps = append(listing.Steps, stepListing)
+		}
+
+		listings = append(listings, listing)
+	}
+
+	return listings, nil
+}
+
+// List writes the test cases of all local test directories with their steps to w in the given format.
+func (h *Harness) List(w io.Writer, testToRun string, format ListFormat) error {
+	listings, err := h.ListTests(testToRun)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case ListJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(listings)
+	case ListTable, "":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "SUITE\tTEST\tSTEP\tFILES")
+		for _, listing := range listings {
+			for _, step := range listing.Steps {
+				fmt.Fprintf(tw, "%s\t%s\t%d-%s\t%s\n", listing.Suite, listing.Name, step.Index, step.Name, strings.Join(step.Files, ","))
+			}
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown list format %q, must be one of %s, %s", format, ListTable, ListJSON)
+	}
+}
+
+// testSuiteDir returns the test directory of the test suite as configured which contains a test case.
+func (h *Harness) testSuiteDir(test *Case) string {
+	for _, dir := range h.TestSuite.TestDirs {
+		if absDir, err := filepath.Abs(dir); err == nil && absDir == filepath.Dir(test.Dir) {
+			return dir
+		}
+	}
+	return filepath.Dir(test.Dir)
+}
@@ -0,0 +1,101 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+)
+
+func TestList(t *testing.T) {
+	h := Harness{
+		TestSuite: harness.TestSuite{
+			TestDirs: []string{"test_data"},
+			Serial:   []string{"cli-test"},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	require.NoError(t, h.List(out, "cli-test", ListJSON))
+
+	listings := []TestListing{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &listings))
+	require.Equal(t, 1, len(listings))
+	assert.Equal(t, "test_data", listings[0].Suite)
+	assert.Equal(t, "cli-test", listings[0].Name)
+	assert.True(t, listings[0].Serial)
+	assert.Equal(t, []TestStepListing{
+		{Index: 0, Name: "create-pod", Files: []string{"00-assert.yaml", "00-create-pod.yaml"}},
+		{Index: 1, Name: "patch", Files: []string{"01-assert.yaml", "01-patch.yaml"}},
+	}, listings[0].Steps)
+
+	out.Reset()
+	require.NoError(t, h.List(out, "cli-test", ListTable))
+	assert.Equal(t, `SUITE      TEST      STEP          FILES
+test_data  cli-test  0-create-pod  00-assert.yaml,00-create-pod.yaml
+test_data  cli-test  1-patch       01-assert.yaml,01-patch.yaml
+`, out.String())
+
+	assert.Error(t, h.List(out, "cli-test", "yaml"))
+
+	// the steps which are not run are not listed
+	fromStep := 1
+	h.TestSuite.FromStep = &fromStep
+	listings, err := h.ListTests("cli-test")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(listings))
+	assert.Equal(t, []TestStepListing{{Index: 1, Name: "patch", Files: []string{"01-assert.yaml", "01-patch.yaml"}}}, listings[0].Steps)
+
+	// only the failed tests are listed with --rerun-failed
+	dir, err := ioutil.TempDir("", "kuttl-list")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	h = Harness{TestSuite: harness.TestSuite{TestDirs: []string{"test_data"}, RerunFailed: true, FailedFile: filepath.Join(dir, "failed.json")}}
+	listings, err = h.ListTests("")
+	require.NoError(t, err)
+	assert.Empty(t, listings)
+
+	h.addFailed("test_data", "cli-test")
+	require.NoError(t, h.saveFailed())
+	listings, err = h.ListTests("")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(listings))
+	assert.Equal(t, "cli-test", listings[0].Name)
+
+	// only the tests of the shard are listed
+	for i := 0; i < 8; i++ {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "suite", fmt.Sprintf("test-%d", i)), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "suite", fmt.Sprintf("test-%d", i), "00-assert.yaml"), []byte("apiVersion: v1\nkind: Pod\nmetadata:\n  name: hello\n"), 0644))
+	}
+
+	h = Harness{TestSuite: harness.TestSuite{TestDirs: []string{filepath.Join(dir, "suite")}}}
+	all, err := h.ListTests("")
+	require.NoError(t, err)
+	require.Equal(t, 8, len(all))
+
+	names := []string{}
+	for index := 0; index < 2; index++ {
+		h.TestSuite.ShardCount, h.TestSuite.ShardIndex = 2, index
+		listings, err = h.ListTests("")
+		require.NoError(t, err)
+		assert.True(t, len(listings) < len(all))
+		for _, listing := range listings {
+			names = append(names, listing.Name)
+		}
+	}
+
+	allNames := []string{}
+	for _, listing := range all {
+		allNames = append(allNames, listing.Name)
+	}
+	assert.ElementsMatch(t, allNames, names)
+}
@@ -0,0 +1,213 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// CheckMetrics scrapes the metrics endpoints of the metrics asserts of the TestAssert and checks the metric values.
+func (s *Step) CheckMetrics(ctx context.Context, namespace string) []error {
+	if s.Assert == nil || len(s.Assert.Metrics) == 0 {
+		return nil
+	}
+
+	errs := []error{}
+
+	for _, expected := range s.Assert.Metrics {
+		if expected.Service == "" || expected.Metric == "" || expected.Value == "" {
+			errs = append(errs, errors.New("metrics assert requires a service, metric and value"))
+			continue
+		}
+
+		metrics, err := s.scrapeMetrics(ctx, expected, namespace)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to scrape metrics of service %s: %w", expected.Service, err))
+			continue
+		}
+
+		if err := checkMetric(expected, metrics); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// scrapeMetrics requests the metrics endpoint of a metrics assert through the API server service proxy.
+func (s *Step) scrapeMetrics(ctx context.Context, expected harness.MetricsAssert, namespace string) (string, error) {
+	if s.Config == nil {
+		return "", errors.New("metrics assert requires a cluster configuration")
+	}
+
+	if expected.Namespace != "" {
+		namespace = expected.Namespace
+	}
+
+	path := expected.Path
+	if path == "" {
+		path = "/metrics"
+	}
+
+	httpClient, url, err := s.serviceProxy(namespace, expected.Service, expected.Port, expected.Scheme, path)
+	if err != nil {
+		return "", err
+	}
+	httpClient.Timeout = defaultHTTPTimeout
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return string(body), nil
+}
+
+// checkMetric checks the value of the metric in the scraped metrics in the Prometheus text format.
+func checkMetric(expected harness.MetricsAssert, metrics string) error {
+	parser := expfmt.TextParser{}
+	families, err := parser.TextToMetricFamilies(strings.NewReader(metrics))
+	if err != nil {
+		return fmt.Errorf("failed to parse metrics of service %s: %w", expected.Service, err)
+	}
+
+	value, found, err := metricValue(families, expected.Metric, expected.Labels)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("metric %s%s not found", expected.Metric, formatLabels(expected.Labels))
+	}
+
+	if err := compareMetric(expected.Value, value); err != nil {
+		return fmt.Errorf("metric %s%s: %w", expected.Metric, formatLabels(expected.Labels), err)
+	}
+	return nil
+}
+
+// metricValue returns the sum of the values of the series of the metric with the labels.
+func metricValue(families map[string]*dto.MetricFamily, name string, labels map[string]string) (float64, bool, error) {
+	family, suffix := families[name], ""
+	if family == nil {
+		for _, s := range []string{"_sum", "_count"} {
+			if base := strings.TrimSuffix(name, s); base != name && families[base] != nil {
+				family, suffix = families[base], s
+			}
+		}
+	}
+	if family == nil {
+		return 0, false, nil
+	}
+
+	sum, found := 0.0, false
+
+	for _, metric := range family.Metric {
+		if !hasLabels(metric, labels) {
+			continue
+		}
+
+		var value float64
+		switch {
+		case metric.Counter != nil && suffix == "":
+			value = metric.Counter.GetValue()
+		case metric.Gauge != nil && suffix == "":
+			value = metric.Gauge.GetValue()
+		case metric.Untyped != nil && suffix == "":
+			value = metric.Untyped.GetValue()
+		case metric.Histogram != nil && suffix == "_sum":
+			value = metric.Histogram.GetSampleSum()
+		case metric.Histogram != nil && suffix == "_count":
+			value = float64(metric.Histogram.GetSampleCount())
+		case metric.Summary != nil && suffix == "_sum":
+			value = metric.Summary.GetSampleSum()
+		case metric.Summary != nil && suffix == "_count":
+			value = float64(metric.Summary.GetSampleCount())
+		default:
+			return 0, false, fmt.Errorf("metric %s of type %s can only be asserted by its _sum and _count", name, family.GetType())
+		}
+
+		sum += value
+		found = true
+	}
+
+	return sum, found, nil
+}
+
+// hasLabels checks if the series of a metric has all the labels.
+func hasLabels(metric *dto.Metric, labels map[string]string) bool {
+	matched := 0
+	for _, pair := range metric.Label {
+		if value, ok := labels[pair.GetName()]; ok {
+			if value != pair.GetValue() {
+				return false
+			}
+			matched++
+		}
+	}
+	return matched == len(labels)
+}
+
+// compareMetric compares the value of a metric with the expected value, which is either a number
+// or a comparison operator like the inline operators of assert objects.
+func compareMetric(expected string, actual float64) error {
+	// the value is formatted without an exponent to parse it as a quantity
+	actualString := strconv.FormatFloat(actual, 'f', -1, 64)
+
+	if strings.HasPrefix(expected, "kuttl.") {
+		return testutils.IsSubset(expected, actualString)
+	}
+
+	expectedQuantity, err := resource.ParseQuantity(expected)
+	if err != nil {
+		return fmt.Errorf("invalid expected value %q: %w", expected, err)
+	}
+	actualQuantity, err := resource.ParseQuantity(actualString)
+	if err != nil {
+		return fmt.Errorf("value %v is not comparable: %w", actual, err)
+	}
+
+	if expectedQuantity.Cmp(actualQuantity) != 0 {
+		return fmt.Errorf("value mismatch, expected: %s != actual: %v", expected, actual)
+	}
+	return nil
+}
+
+// formatLabels formats labels like the Prometheus text format for error messages.
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	pairs := []string{}
+	for name, value := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", name, value))
+	}
+	sort.Strings(pairs)
+	return "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,129 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/rest"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+const testMetrics = `# HELP controller_runtime_reconcile_errors_total Total number of reconciliation errors per controller
+# TYPE controller_runtime_reconcile_errors_total counter
+controller_runtime_reconcile_errors_total{controller="foo"} 0
+controller_runtime_reconcile_errors_total{controller="bar"} 2
+# HELP workqueue_depth Current depth of workqueue
+# TYPE workqueue_depth gauge
+workqueue_depth{name="foo"} 3
+# HELP controller_runtime_reconcile_time_seconds Length of time per reconciliation per controller
+# TYPE controller_runtime_reconcile_time_seconds histogram
+controller_runtime_reconcile_time_seconds_bucket{controller="foo",le="0.5"} 4
+controller_runtime_reconcile_time_seconds_bucket{controller="foo",le="+Inf"} 5
+controller_runtime_reconcile_time_seconds_sum{controller="foo"} 1.25
+controller_runtime_reconcile_time_seconds_count{controller="foo"} 5
+# TYPE process_resident_memory_bytes gauge
+process_resident_memory_bytes 4.2598e+07
+`
+
+func TestCheckMetric(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		expected harness.MetricsAssert
+		wantErr  bool
+	}{
+		{
+			name:     "counter with labels",
+			expected: harness.MetricsAssert{Metric: "controller_runtime_reconcile_errors_total", Labels: map[string]string{"controller": "foo"}, Value: "0"},
+		},
+		{
+			name:     "series are summed",
+			expected: harness.MetricsAssert{Metric: "controller_runtime_reconcile_errors_total", Value: "2"},
+		},
+		{
+			name:     "value mismatch",
+			expected: harness.MetricsAssert{Metric: "controller_runtime_reconcile_errors_total", Labels: map[string]string{"controller": "bar"}, Value: "0"},
+			wantErr:  true,
+		},
+		{
+			name:     "comparison",
+			expected: harness.MetricsAssert{Metric: "workqueue_depth", Value: "kuttl.lt(5)"},
+		},
+		{
+			name:     "failed comparison",
+			expected: harness.MetricsAssert{Metric: "workqueue_depth", Value: "kuttl.gt(5)"},
+			wantErr:  true,
+		},
+		{
+			name:     "large value",
+			expected: harness.MetricsAssert{Metric: "process_resident_memory_bytes", Value: "kuttl.lt(100Mi)"},
+		},
+		{
+			name:     "histogram count",
+			expected: harness.MetricsAssert{Metric: "controller_runtime_reconcile_time_seconds_count", Value: "5"},
+		},
+		{
+			name:     "histogram sum",
+			expected: harness.MetricsAssert{Metric: "controller_runtime_reconcile_time_seconds_sum", Value: "1.25"},
+		},
+		{
+			name:     "histogram without suffix",
+			expected: harness.MetricsAssert{Metric: "controller_runtime_reconcile_time_seconds", Value: "5"},
+			wantErr:  true,
+		},
+		{
+			name:     "missing metric",
+			expected: harness.MetricsAssert{Metric: "missing", Value: "0"},
+			wantErr:  true,
+		},
+		{
+			name:     "missing labels",
+			expected: harness.MetricsAssert{Metric: "workqueue_depth", Labels: map[string]string{"name": "bar"}, Value: "0"},
+			wantErr:  true,
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			err := checkMetric(test.expected, testMetrics)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestStepCheckMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/namespaces/world/services/foo-metrics/proxy/metrics", "/api/v1/namespaces/system/services/https:foo-metrics:8443/proxy/custom":
+			fmt.Fprint(w, testMetrics)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	step := Step{
+		Logger: testutils.NewTestLogger(t, ""),
+		Assert: &harness.TestAssert{Metrics: []harness.MetricsAssert{
+			{Service: "foo-metrics", Metric: "workqueue_depth", Value: "3"},
+			{Service: "foo-metrics", Namespace: "system", Scheme: "https", Port: "8443", Path: "/custom", Metric: "workqueue_depth", Value: "3"},
+			{Service: "foo-metrics", Metric: "workqueue_depth", Value: "kuttl.lt(3)"},
+			{Service: "bar-metrics", Metric: "workqueue_depth", Value: "3"},
+		}},
+		Config: func() (*rest.Config, error) {
+			return &rest.Config{Host: server.URL}, nil
+		},
+	}
+
+	errs := step.CheckMetrics(context.TODO(), testNamespace)
+	assert.Equal(t, 2, len(errs), errs)
+}
@@ -0,0 +1,61 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	petname "github.com/dustinkirkland/golang-petname"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// defaultNamespaceTemplate is the template of the generated namespace names if the test suite does not set one.
+const defaultNamespaceTemplate = "kudo-test-{{ .Random }}"
+
+// invalidNameChars are the characters of a test case or test step name which are not allowed in a DNS-1123 label,
+// e.g. a namespace or job name.
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// namespaceTemplateData is the data of a NamespaceTemplate.
+type namespaceTemplateData struct {
+	// Test is the name of the test case converted to a valid namespace name.
+	Test string
+	// Random is a random name, e.g. "ample-goose".
+	Random string
+}
+
+// generateNamespace renders the NamespaceTemplate of the test case to the name of a new namespace. The name is
+// truncated to the maximum length of a namespace name.
+func (t *Case) generateNamespace() (string, error) {
+	text := t.NamespaceTemplate
+	if text == "" {
+		text = defaultNamespaceTemplate
+	}
+
+	tmpl, err := template.New("namespace").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parsing namespace template %q: %w", text, err)
+	}
+
+	data := namespaceTemplateData{
+		Test:   strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(t.Name), "-"), "-"),
+		Random: petname.Generate(2, "-"),
+	}
+
+	rendered := &bytes.Buffer{}
+	if err := tmpl.Execute(rendered, data); err != nil {
+		return "", fmt.Errorf("rendering namespace template %q: %w", text, err)
+	}
+
+	name := rendered.String()
+	if len(name) > validation.DNS1123LabelMaxLength {
+		name = strings.TrimRight(name[:validation.DNS1123LabelMaxLength], "-")
+	}
+
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return "", fmt.Errorf("invalid namespace name %q of namespace template %q: %s", name, text, strings.Join(errs, ", "))
+	}
+	return name, nil
+}
@@ -0,0 +1,215 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	petname "github.com/dustinkirkland/golang-petname"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// poolNamespacePrefix is the prefix of the names of the namespaces of the namespace pool.
+const poolNamespacePrefix = "kuttl-pool-"
+
+// usesNamespacePool checks if the test cases run in the namespaces of a pool, see TestSuite.NamespacePool.
+func (h *Harness) usesNamespacePool() bool {
+	return h.TestSuite.NamespacePool > 0 && h.TestSuite.Namespace == "" && !h.TestSuite.SkipDelete
+}
+
+// createNamespacePool creates the namespaces of the pool.
+func (h *Harness) createNamespacePool(ctx context.Context) error {
+	h.namespacePool = make(chan string, h.TestSuite.NamespacePool)
+	for i := 0; i < h.TestSuite.NamespacePool; i++ {
+		name, err := h.createPoolNamespace(ctx)
+		if err != nil {
+			return err
+		}
+		h.namespacePool <- name
+	}
+	return nil
+}
+
+// createPoolNamespace creates a namespace for the pool.
+func (h *Harness) createPoolNamespace(ctx context.Context) (string, error) {
+	cl, err := h.Client(false)
+	if err != nil {
+		return "", err
+	}
+
+	name := poolNamespacePrefix + petname.Generate(2, "-")
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      h.TestSuite.NamespaceLabels,
+			Annotations: h.TestSuite.NamespaceAnnotations,
+		},
+	}
+	if err := cl.Create(ctx, namespace); err != nil {
+		return "", fmt.Errorf("creating pool namespace %s: %w", name, err)
+	}
+	return name, nil
+}
+
+// takeNamespace waits for a namespace of the pool which is not used by another test case.
+func (h *Harness) takeNamespace() string {
+	return <-h.namespacePool
+}
+
+// returnNamespace deletes the objects of a namespace a test case ran in and returns it to the pool. If its objects
+// are not deleted within the timeout of the test suite, the namespace is replaced by a new one. A kept namespace,
+// e.g. of a failed test case with KeepOnFailure, is replaced by a new one too.
+func (h *Harness) returnNamespace(ctx context.Context, name string, keep bool) {
+	if !keep {
+		err := h.cleanNamespace(ctx, name)
+		if err == nil {
+			h.namespacePool <- name
+			return
+		}
+
+		h.T.Logf("error cleaning pool namespace %s, replacing it: %v", name, err)
+		h.deleteNamespace(ctx, name)
+	}
+
+	replacement, err := h.createPoolNamespace(ctx)
+	if err != nil {
+		// the pool shrinks, the test cases wait for the remaining namespaces
+		h.T.Log("error replacing pool namespace", err)
+		return
+	}
+	h.namespacePool <- replacement
+}
+
+// deleteNamespacePool deletes the namespaces of the pool, it is called after all test cases returned theirs.
+func (h *Harness) deleteNamespacePool(ctx context.Context) {
+	for {
+		select {
+		case name := <-h.namespacePool:
+			h.deleteNamespace(ctx, name)
+		default:
+			return
+		}
+	}
+}
+
+// deleteNamespace deletes a namespace without waiting for its deletion.
+func (h *Harness) deleteNamespace(ctx context.Context, name string) {
+	cl, err := h.Client(false)
+	if err == nil {
+		err = cl.Delete(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	if err != nil {
+		h.T.Logf("error deleting pool namespace %s: %v", name, err)
+	}
+}
+
+// cleanNamespace deletes the objects of all namespaced resources in a namespace, except the ones kubernetes creates
+// in every namespace, and waits until they are gone.
+func (h *Harness) cleanNamespace(ctx context.Context, name string) error {
+	cl, err := h.Client(false)
+	if err != nil {
+		return err
+	}
+	dClient, err := h.DiscoveryClient()
+	if err != nil {
+		return err
+	}
+
+	resources, err := namespacedResources(dClient)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(time.Duration(h.GetTimeout()) * time.Second)
+	for {
+		remaining, err := deleteNamespaceObjects(ctx, cl, resources, name, keptInNamespace)
+		if err != nil {
+			return err
+		}
+		if len(remaining) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("objects were not deleted: %s", strings.Join(remaining, ", "))
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// namespacedResources returns the kinds of the namespaced resources whose objects can be listed and deleted.
+func namespacedResources(dClient discovery.DiscoveryInterface) ([]schema.GroupVersionKind, error) {
+	lists, err := discovery.ServerPreferredNamespacedResources(dClient)
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+	return deletableKinds(lists)
+}
+
+// deletableKinds returns the kinds of the resources of API resource lists whose objects can be listed and deleted.
+func deletableKinds(lists []*metav1.APIResourceList) ([]schema.GroupVersionKind, error) {
+	kinds := []schema.GroupVersionKind{}
+	for _, list := range discovery.FilteredBy(discovery.SupportsAllVerbs{Verbs: []string{"list", "delete"}}, lists) {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range list.APIResources {
+			// events are not created by the tests and expire
+			if resource.Name == "events" {
+				continue
+			}
+			kinds = append(kinds, gv.WithKind(resource.Kind))
+		}
+	}
+	return kinds, nil
+}
+
+// deleteNamespaceObjects deletes the objects in a namespace which are not kept, it returns the objects which were
+// not yet gone.
+func deleteNamespaceObjects(ctx context.Context, cl client.Client, kinds []schema.GroupVersionKind, namespace string, kept func(kind string, obj *unstructured.Unstructured) bool) ([]string, error) {
+	remaining := []string{}
+
+	for _, gvk := range kinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := cl.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("listing %s: %w", gvk.Kind, err)
+		}
+
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if kept(gvk.Kind, obj) {
+				continue
+			}
+
+			remaining = append(remaining, fmt.Sprintf("%s/%s", gvk.Kind, obj.GetName()))
+			if obj.GetDeletionTimestamp() != nil {
+				continue
+			}
+			if err := cl.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+				return nil, fmt.Errorf("deleting %s/%s: %w", gvk.Kind, obj.GetName(), err)
+			}
+		}
+	}
+
+	return remaining, nil
+}
+
+// keptInNamespace checks if an object is created by kubernetes in every namespace, e.g. the default service account.
+func keptInNamespace(kind string, obj *unstructured.Unstructured) bool {
+	switch kind {
+	case "ServiceAccount":
+		return obj.GetName() == "default"
+	case "ConfigMap":
+		return obj.GetName() == "kube-root-ca.crt"
+	case "Secret":
+		return obj.GetAnnotations()[corev1.ServiceAccountNameKey] == "default"
+	}
+	return false
+}
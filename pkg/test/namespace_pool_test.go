@@ -0,0 +1,102 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	coretesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestUsesNamespacePool(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		testSuite harness.TestSuite
+		expected  bool
+	}{
+		{name: "no pool", testSuite: harness.TestSuite{}},
+		{name: "pool", testSuite: harness.TestSuite{NamespacePool: 2}, expected: true},
+		{name: "namespace", testSuite: harness.TestSuite{NamespacePool: 2, Namespace: "test"}},
+		{name: "skip delete", testSuite: harness.TestSuite{NamespacePool: 2, SkipDelete: true}},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			h := Harness{TestSuite: tt.testSuite}
+			assert.Equal(t, tt.expected, h.usesNamespacePool())
+		})
+	}
+}
+
+func TestNamespacePool(t *testing.T) {
+	verbs := metav1.Verbs{"create", "delete", "get", "list"}
+	dClient := &fakediscovery.FakeDiscovery{
+		Fake: &coretesting.Fake{
+			Resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "v1",
+					APIResources: []metav1.APIResource{
+						{Name: "pods", Namespaced: true, Kind: "Pod", Verbs: verbs},
+						{Name: "serviceaccounts", Namespaced: true, Kind: "ServiceAccount", Verbs: verbs},
+						{Name: "events", Namespaced: true, Kind: "Event", Verbs: verbs},
+						{Name: "namespaces", Namespaced: false, Kind: "Namespace", Verbs: verbs},
+						{Name: "bindings", Namespaced: true, Kind: "Binding", Verbs: metav1.Verbs{"create"}},
+					},
+				},
+			},
+		},
+	}
+
+	kinds, err := namespacedResources(dClient)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []schema.GroupVersionKind{
+		{Version: "v1", Kind: "Pod"},
+		{Version: "v1", Kind: "ServiceAccount"},
+	}, kinds)
+
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme)
+	h := Harness{
+		T:         t,
+		TestSuite: harness.TestSuite{NamespacePool: 2, Timeout: 1},
+		client:    cl,
+		dclient:   testutils.NewCachedDiscoveryClient(dClient),
+	}
+	require.NoError(t, h.createNamespacePool(context.TODO()))
+
+	name := h.takeNamespace()
+	assert.True(t, strings.HasPrefix(name, poolNamespacePrefix))
+
+	for _, obj := range []runtime.Object{
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: name}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: name}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: name}},
+	} {
+		require.NoError(t, cl.Create(context.TODO(), obj))
+	}
+
+	// the objects of the test case are deleted when the namespace is returned to the pool
+	h.returnNamespace(context.TODO(), name, false)
+	assert.Equal(t, 2, len(h.namespacePool))
+
+	pods := &corev1.PodList{}
+	require.NoError(t, cl.List(context.TODO(), pods))
+	assert.Equal(t, 0, len(pods.Items))
+	assert.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: name, Name: "default"}, &corev1.ServiceAccount{}))
+
+	h.deleteNamespacePool(context.TODO())
+	namespaces := &corev1.NamespaceList{}
+	require.NoError(t, cl.List(context.TODO(), namespaces))
+	assert.Equal(t, 0, len(namespaces.Items))
+}
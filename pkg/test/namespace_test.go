@@ -0,0 +1,83 @@
+package test
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestGenerateNamespace(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		test     string
+		template string
+		expected string
+		err      string
+	}{
+		{name: "default", test: "example", expected: `^kudo-test-[a-z]+-[a-z]+$`},
+		{name: "test name", test: "example", template: "e2e-{{ .Test }}", expected: `^e2e-example$`},
+		{name: "matrix test name", test: "example[tag=1.0,version=2]", template: "{{ .Test }}", expected: `^example-tag-1-0-version-2$`},
+		{
+			name:     "truncated",
+			test:     strings.Repeat("long-", 20),
+			template: "{{ .Test }}-{{ .Random }}",
+			expected: `^(long-){12}lon$`,
+		},
+		{
+			name:     "unknown field",
+			test:     "example",
+			template: "{{ .Namespace }}",
+			err:      `rendering namespace template "{{ .Namespace }}": template: namespace:1:3: executing "namespace" at <.Namespace>: can't evaluate field Namespace in type test.namespaceTemplateData`,
+		},
+		{
+			name:     "invalid name",
+			test:     "example",
+			template: "E2E-{{ .Test }}",
+			err:      `invalid namespace name "E2E-example" of namespace template "E2E-{{ .Test }}": a DNS-1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Case{Name: tt.test, NamespaceTemplate: tt.template}
+			name, err := c.generateNamespace()
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Regexp(t, regexp.MustCompile(tt.expected), name)
+		})
+	}
+}
+
+func TestCreateNamespaceLabels(t *testing.T) {
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme)
+	c := &Case{
+		Name:                 "example",
+		NamespaceTemplate:    "e2e-{{ .Test }}",
+		NamespaceLabels:      map[string]string{"pod-security.kubernetes.io/enforce": "baseline"},
+		NamespaceAnnotations: map[string]string{"owner": "kuttl"},
+		Client:               func(bool) (client.Client, error) { return cl, nil },
+		Logger:               testutils.NewTestLogger(t, "example"),
+	}
+
+	ns, err := c.determineNamespace()
+	require.NoError(t, err)
+	require.NoError(t, c.CreateNamespace(ns))
+
+	namespace := &corev1.Namespace{}
+	require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Name: "e2e-example"}, namespace))
+	assert.Equal(t, c.NamespaceLabels, namespace.Labels)
+	assert.Equal(t, c.NamespaceAnnotations, namespace.Annotations)
+}
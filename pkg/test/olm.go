@@ -0,0 +1,291 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// olmRegistryImage is the image of the registry pods which serve bundle images, see OLM.BundleImage.
+var olmRegistryImage = "quay.io/operator-framework/upstream-opm-builder:latest"
+
+// olmRegistryPort is the gRPC port of the registry pods.
+const olmRegistryPort = 50051
+
+// RunOLM installs the operators of the TestStep.OLM list in order. If an installation fails, the following ones
+// are skipped. Waiting for an operator stops if the context is done.
+func (s *Step) RunOLM(ctx context.Context, namespace string) []error {
+	if s.Step == nil || len(s.Step.OLM) == 0 {
+		return nil
+	}
+
+	cl, err := s.Client(false)
+	if err != nil {
+		return []error{err}
+	}
+
+	for _, olm := range s.Step.OLM {
+		if err := s.runOLM(ctx, cl, olm, namespace); err != nil {
+			return []error{err}
+		}
+	}
+
+	return nil
+}
+
+func (s *Step) runOLM(ctx context.Context, cl client.Client, olm harness.OLM, namespace string) error {
+	if olm.Package == "" {
+		return errors.New("olm requires the name of the package")
+	}
+	set := 0
+	for _, source := range []string{olm.BundleImage, olm.IndexImage, olm.CatalogSource} {
+		if source != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("olm package %s requires exactly one of bundleImage, indexImage and catalogSource", olm.Package)
+	}
+
+	if olm.Namespace != "" {
+		namespace = olm.Namespace
+	}
+
+	if err := s.ensureOperatorGroup(ctx, cl, olm, namespace); err != nil {
+		return err
+	}
+
+	catalog := types.NamespacedName{Namespace: olm.CatalogSourceNamespace, Name: olm.CatalogSource}
+	if catalog.Namespace == "" {
+		catalog.Namespace = namespace
+	}
+	if olm.CatalogSource == "" {
+		catalog = types.NamespacedName{Namespace: namespace, Name: olm.Package + "-catalog"}
+		if err := s.createCatalogSource(ctx, cl, olm, catalog); err != nil {
+			return err
+		}
+	}
+
+	subscription := newOLMObject("Subscription", namespace, olm.Package)
+	spec := map[string]interface{}{
+		"name":                olm.Package,
+		"source":              catalog.Name,
+		"sourceNamespace":     catalog.Namespace,
+		"installPlanApproval": "Automatic",
+	}
+	if olm.Channel != "" {
+		spec["channel"] = olm.Channel
+	}
+	if olm.StartingCSV != "" {
+		spec["startingCSV"] = olm.StartingCSV
+	}
+	subscription.Object["spec"] = spec
+	if err := s.createOLMObject(ctx, cl, subscription); err != nil {
+		return err
+	}
+
+	timeout := s.GetTimeout()
+	if olm.Timeout > 0 {
+		timeout = olm.Timeout
+	}
+
+	s.Logger.Logf("waiting for the ClusterServiceVersion of package %s to succeed", olm.Package)
+
+	reason := ""
+	waitCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+	err := wait.PollImmediateUntil(time.Second, func() (bool, error) {
+		var err error
+		reason, err = s.olmReason(ctx, cl, testutils.ObjectKey(subscription))
+		return reason == "", err
+	}, waitCtx.Done())
+
+	if err == wait.ErrWaitTimeout && ctx.Err() != nil {
+		return fmt.Errorf("installing olm package %s: %w", olm.Package, ctx.Err())
+	}
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("olm package %s is not installed within %d sec timeout: %s", olm.Package, timeout, reason)
+	}
+	if err != nil {
+		return fmt.Errorf("installing olm package %s: %w", olm.Package, err)
+	}
+
+	s.Logger.Logf("olm package %s installed", olm.Package)
+	return nil
+}
+
+// olmReason checks if the ClusterServiceVersion installed by a subscription succeeded, it returns why it did not
+// or an error if it failed.
+func (s *Step) olmReason(ctx context.Context, cl client.Client, key types.NamespacedName) (string, error) {
+	subscription, found, err := getWaitForObject(ctx, cl, "operators.coreos.com/v1alpha1", "Subscription", key)
+	if err != nil || !found {
+		return "subscription not found", err
+	}
+
+	csvName, _, _ := unstructured.NestedString(subscription.Object, "status", "installedCSV")
+	if csvName == "" {
+		state, _, _ := unstructured.NestedString(subscription.Object, "status", "state")
+		return fmt.Sprintf("no ClusterServiceVersion is installed by the subscription (state %q)", state), nil
+	}
+
+	csv, found, err := getWaitForObject(ctx, cl, "operators.coreos.com/v1alpha1", "ClusterServiceVersion", types.NamespacedName{Namespace: key.Namespace, Name: csvName})
+	if err != nil || !found {
+		return fmt.Sprintf("ClusterServiceVersion %s not found", csvName), err
+	}
+
+	// the ClusterServiceVersion is deleted with the subscription
+	if !s.hasOLMObject(csv) {
+		s.olmObjects = append(s.olmObjects, csv)
+	}
+
+	phase, _, _ := unstructured.NestedString(csv.Object, "status", "phase")
+	switch phase {
+	case "Succeeded":
+		return "", nil
+	case "Failed":
+		message, _, _ := unstructured.NestedString(csv.Object, "status", "message")
+		return "", fmt.Errorf("ClusterServiceVersion %s failed: %s", csvName, message)
+	default:
+		return fmt.Sprintf("ClusterServiceVersion %s is in phase %q", csvName, phase), nil
+	}
+}
+
+// ensureOperatorGroup creates an OperatorGroup in the namespace of an operator if it has none.
+func (s *Step) ensureOperatorGroup(ctx context.Context, cl client.Client, olm harness.OLM, namespace string) error {
+	groups := &unstructured.UnstructuredList{}
+	groups.SetAPIVersion("operators.coreos.com/v1")
+	groups.SetKind("OperatorGroupList")
+	if err := cl.List(ctx, groups, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("listing the operator groups of namespace %s: %w", namespace, err)
+	}
+	if len(groups.Items) > 0 {
+		return nil
+	}
+
+	group := newOLMObject("OperatorGroup", namespace, "kuttl-operator-group")
+	group.SetAPIVersion("operators.coreos.com/v1")
+	if len(olm.TargetNamespaces) > 0 {
+		targets := []interface{}{}
+		for _, target := range olm.TargetNamespaces {
+			targets = append(targets, target)
+		}
+		group.Object["spec"] = map[string]interface{}{"targetNamespaces": targets}
+	}
+	return s.createOLMObject(ctx, cl, group)
+}
+
+// createCatalogSource creates the CatalogSource of an index image or of the registry pod of a bundle image.
+func (s *Step) createCatalogSource(ctx context.Context, cl client.Client, olm harness.OLM, key types.NamespacedName) error {
+	spec := map[string]interface{}{
+		"sourceType":  "grpc",
+		"displayName": olm.Package,
+	}
+
+	if olm.IndexImage != "" {
+		spec["image"] = olm.IndexImage
+	} else {
+		service, err := s.createBundleRegistry(ctx, cl, olm, key)
+		if err != nil {
+			return err
+		}
+		spec["address"] = fmt.Sprintf("%s.%s.svc:%d", service.Name, service.Namespace, olmRegistryPort)
+	}
+
+	catalog := newOLMObject("CatalogSource", key.Namespace, key.Name)
+	catalog.Object["spec"] = spec
+	return s.createOLMObject(ctx, cl, catalog)
+}
+
+// createBundleRegistry creates a pod which serves a registry of a bundle image like `operator-sdk run bundle`
+// and its service.
+func (s *Step) createBundleRegistry(ctx context.Context, cl client.Client, olm harness.OLM, key types.NamespacedName) (*corev1.Service, error) {
+	name := key.Name + "-registry"
+	labels := map[string]string{"kuttl.dev/olm-registry": key.Name}
+
+	command := fmt.Sprintf("mkdir -p /database && /bin/opm registry add -d /database/index.db -b %s && /bin/opm registry serve -d /database/index.db -p %d", olm.BundleImage, olmRegistryPort)
+	pod := &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: key.Namespace, Labels: labels},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:    "registry",
+				Image:   olmRegistryImage,
+				Command: []string{"/bin/sh", "-c", command},
+				Ports:   []corev1.ContainerPort{{Name: "grpc", ContainerPort: olmRegistryPort}},
+			}},
+		},
+	}
+	if err := s.createOLMObject(ctx, cl, pod); err != nil {
+		return nil, err
+	}
+
+	service := &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: key.Namespace},
+		Spec: corev1.ServiceSpec{
+			Selector: labels,
+			Ports:    []corev1.ServicePort{{Name: "grpc", Port: olmRegistryPort, TargetPort: intstr.FromInt(olmRegistryPort)}},
+		},
+	}
+	if err := s.createOLMObject(ctx, cl, service); err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
+// newOLMObject returns an object of a kind of the operators.coreos.com/v1alpha1 API.
+func newOLMObject(kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetAPIVersion("operators.coreos.com/v1alpha1")
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+// createOLMObject creates or updates an object to install an operator, it is deleted on Clean.
+func (s *Step) createOLMObject(ctx context.Context, cl client.Client, obj runtime.Object) error {
+	if _, err := testutils.CreateOrUpdate(ctx, cl, obj, true); err != nil {
+		return fmt.Errorf("creating %s: %w", testutils.ResourceID(obj), err)
+	}
+	testutils.LogResource(s.Logger, testutils.ResourceID(obj), "created")
+
+	if !s.hasOLMObject(obj) {
+		s.olmObjects = append(s.olmObjects, obj)
+	}
+	return nil
+}
+
+func (s *Step) hasOLMObject(obj runtime.Object) bool {
+	for _, created := range s.olmObjects {
+		if testutils.ResourceID(created) == testutils.ResourceID(obj) {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteOLMObjects deletes the objects created to install operators in the reverse order of their creation.
+func (s *Step) deleteOLMObjects(cl client.Client) error {
+	for i := len(s.olmObjects) - 1; i >= 0; i-- {
+		if err := cl.Delete(context.TODO(), s.olmObjects[i]); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	s.olmObjects = nil
+	return nil
+}
@@ -0,0 +1,142 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// installCSV acts like OLM, it installs the ClusterServiceVersion of a subscription in a phase.
+func installCSV(t *testing.T, cl client.Client, subscription types.NamespacedName, phase string) {
+	time.Sleep(500 * time.Millisecond)
+
+	sub := newOLMObject("Subscription", subscription.Namespace, subscription.Name)
+	if !assert.NoError(t, cl.Get(context.TODO(), subscription, sub)) {
+		return
+	}
+	assert.NoError(t, unstructured.SetNestedField(sub.Object, "operator.v1.0.0", "status", "installedCSV"))
+	assert.NoError(t, cl.Update(context.TODO(), sub))
+
+	csv := newOLMObject("ClusterServiceVersion", subscription.Namespace, "operator.v1.0.0")
+	csv.Object["status"] = map[string]interface{}{"phase": phase, "message": "install strategy failed"}
+	assert.NoError(t, cl.Create(context.TODO(), csv))
+}
+
+// olmScheme returns a scheme of the fake client which lists the operator groups as unstructured objects.
+func olmScheme() *runtime.Scheme {
+	olmScheme := runtime.NewScheme()
+	utilruntime.Must(scheme.AddToScheme(olmScheme))
+
+	groupVersion := schema.GroupVersion{Group: "operators.coreos.com", Version: "v1"}
+	olmScheme.AddKnownTypeWithName(groupVersion.WithKind("OperatorGroup"), &unstructured.Unstructured{})
+	olmScheme.AddKnownTypeWithName(groupVersion.WithKind("OperatorGroupList"), &unstructured.UnstructuredList{})
+	return olmScheme
+}
+
+func olmTestStep(t *testing.T, cl client.Client, olm harness.OLM) *Step {
+	return &Step{
+		Name:    "install",
+		Index:   1,
+		Timeout: 10,
+		Logger:  testutils.NewTestLogger(t, ""),
+		Step: &harness.TestStep{
+			OLM: []harness.OLM{olm},
+		},
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+	}
+}
+
+func TestRunOLM(t *testing.T) {
+	cl := fake.NewFakeClientWithScheme(olmScheme())
+	step := olmTestStep(t, cl, harness.OLM{
+		Package:    "operator",
+		IndexImage: "quay.io/example/operator-index:v1.0.0",
+		Channel:    "stable",
+	})
+
+	go installCSV(t, cl, types.NamespacedName{Namespace: testNamespace, Name: "operator"}, "Succeeded")
+	require.Empty(t, step.RunOLM(context.TODO(), testNamespace))
+
+	group := newOLMObject("OperatorGroup", testNamespace, "kuttl-operator-group")
+	group.SetAPIVersion("operators.coreos.com/v1")
+	assert.NoError(t, cl.Get(context.TODO(), testutils.ObjectKey(group), group))
+
+	catalog := newOLMObject("CatalogSource", testNamespace, "operator-catalog")
+	require.NoError(t, cl.Get(context.TODO(), testutils.ObjectKey(catalog), catalog))
+	image, _, _ := unstructured.NestedString(catalog.Object, "spec", "image")
+	assert.Equal(t, "quay.io/example/operator-index:v1.0.0", image)
+
+	subscription := newOLMObject("Subscription", testNamespace, "operator")
+	require.NoError(t, cl.Get(context.TODO(), testutils.ObjectKey(subscription), subscription))
+	spec, _, _ := unstructured.NestedStringMap(subscription.Object, "spec")
+	assert.Equal(t, map[string]string{
+		"name":                "operator",
+		"channel":             "stable",
+		"source":              "operator-catalog",
+		"sourceNamespace":     testNamespace,
+		"installPlanApproval": "Automatic",
+	}, spec)
+
+	// the objects created to install the operator and its ClusterServiceVersion are deleted on clean
+	require.NoError(t, step.Clean(testNamespace))
+	for _, obj := range []*unstructured.Unstructured{group, catalog, subscription, newOLMObject("ClusterServiceVersion", testNamespace, "operator.v1.0.0")} {
+		err := cl.Get(context.TODO(), testutils.ObjectKey(obj), obj)
+		assert.True(t, k8serrors.IsNotFound(err), "%s is not deleted", testutils.ResourceID(obj))
+	}
+}
+
+func TestRunOLMBundle(t *testing.T) {
+	cl := fake.NewFakeClientWithScheme(olmScheme())
+	step := olmTestStep(t, cl, harness.OLM{
+		Package:     "operator",
+		BundleImage: "quay.io/example/operator-bundle:v1.0.0",
+		Namespace:   "operators",
+	})
+
+	go installCSV(t, cl, types.NamespacedName{Namespace: "operators", Name: "operator"}, "Failed")
+	errs := step.RunOLM(context.TODO(), testNamespace)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "installing olm package operator: ClusterServiceVersion operator.v1.0.0 failed: install strategy failed")
+
+	// the bundle is served by a registry pod
+	pod := &corev1.Pod{}
+	require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: "operators", Name: "operator-catalog-registry"}, pod))
+	assert.Contains(t, pod.Spec.Containers[0].Command[2], "-b quay.io/example/operator-bundle:v1.0.0")
+	assert.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: "operators", Name: "operator-catalog-registry"}, &corev1.Service{}))
+
+	catalog := newOLMObject("CatalogSource", "operators", "operator-catalog")
+	require.NoError(t, cl.Get(context.TODO(), testutils.ObjectKey(catalog), catalog))
+	address, _, _ := unstructured.NestedString(catalog.Object, "spec", "address")
+	assert.Equal(t, "operator-catalog-registry.operators.svc:50051", address)
+}
+
+func TestRunOLMInvalid(t *testing.T) {
+	cl := fake.NewFakeClientWithScheme(olmScheme())
+	step := olmTestStep(t, cl, harness.OLM{
+		Package:       "operator",
+		IndexImage:    "quay.io/example/operator-index:v1.0.0",
+		CatalogSource: "operatorhubio-catalog",
+	})
+
+	errs := step.RunOLM(context.TODO(), testNamespace)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "olm package operator requires exactly one of bundleImage, indexImage and catalogSource")
+}
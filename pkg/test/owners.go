@@ -0,0 +1,148 @@
+package test
+
+import (
+	"context"
+	"fmt"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// CheckOwners checks the owner asserts of the TestAssert: the objects must be owned by their owner or, if
+// garbageCollected is set, the owner and the objects owned by it must be deleted.
+func (s *Step) CheckOwners(ctx context.Context, namespace string) []error {
+	if s.Assert == nil || len(s.Assert.Owners) == 0 {
+		return nil
+	}
+
+	cl, err := s.Client(false)
+	if err != nil {
+		return []error{err}
+	}
+
+	dClient, err := s.DiscoveryClient()
+	if err != nil {
+		return []error{err}
+	}
+
+	errs := []error{}
+
+	for _, expected := range s.Assert.Owners {
+		if expected.Owner.Kind == "" || expected.Owner.Name == "" {
+			errs = append(errs, fmt.Errorf("owner assert of kind %s requires the kind and name of the owner", expected.Kind))
+			continue
+		}
+
+		errs = append(errs, s.checkOwner(ctx, cl, dClient, expected, namespace)...)
+	}
+
+	return errs
+}
+
+// checkOwner checks an owner assert.
+func (s *Step) checkOwner(ctx context.Context, cl client.Client, dClient discovery.DiscoveryInterface, expected harness.OwnerAssert, namespace string) []error {
+	if expected.Namespace != "" {
+		namespace = expected.Namespace
+	}
+
+	ownerNamespace := namespace
+	if expected.Owner.Namespace != "" {
+		ownerNamespace = expected.Owner.Namespace
+	}
+	owner := testutils.NewResource(expected.Owner.APIVersion, expected.Owner.Kind, expected.Owner.Name, "").(*unstructured.Unstructured)
+	if _, _, err := testutils.Namespaced(dClient, owner, ownerNamespace); err != nil {
+		return []error{err}
+	}
+	ownerErr := cl.Get(ctx, testutils.ObjectKey(owner), owner)
+	if ownerErr != nil && !k8serrors.IsNotFound(ownerErr) {
+		return []error{ownerErr}
+	}
+
+	objs, err := s.existingObjects(ctx, cl, dClient, expected.ObjectReference, namespace)
+	if err != nil {
+		return []error{err}
+	}
+
+	if expected.GarbageCollected {
+		if ownerErr == nil {
+			return []error{fmt.Errorf("owner %s is not deleted", testutils.ResourceID(owner))}
+		}
+
+		errs := []error{}
+		for _, obj := range objs {
+			if ownerReference(obj, owner) != nil {
+				errs = append(errs, fmt.Errorf("%s is not garbage collected after its owner %s was deleted", testutils.ResourceID(obj), testutils.ResourceID(owner)))
+			}
+		}
+		return errs
+	}
+
+	if ownerErr != nil {
+		return []error{fmt.Errorf("owner %s: %w", testutils.ResourceID(owner), ownerErr)}
+	}
+	if len(objs) == 0 {
+		return []error{fmt.Errorf("no objects of kind %s owned by %s found", expected.Kind, testutils.ResourceID(owner))}
+	}
+
+	errs := []error{}
+	for _, obj := range objs {
+		ref := ownerReference(obj, owner)
+		switch {
+		case ref == nil:
+			errs = append(errs, fmt.Errorf("%s is not owned by %s", testutils.ResourceID(obj), testutils.ResourceID(owner)))
+		case expected.Controller && (ref.Controller == nil || !*ref.Controller):
+			errs = append(errs, fmt.Errorf("%s is owned but not controlled by %s", testutils.ResourceID(obj), testutils.ResourceID(owner)))
+		}
+	}
+	return errs
+}
+
+// existingObjects fetches the existing objects of the reference of an assert.
+func (s *Step) existingObjects(ctx context.Context, cl client.Client, dClient discovery.DiscoveryInterface, ref harness.ObjectReference, namespace string) ([]*unstructured.Unstructured, error) {
+	objs, err := s.objectsFromRef(ctx, cl, dClient, ref, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	owned := []*unstructured.Unstructured{}
+	for _, obj := range objs {
+		// named references are not fetched by objectsFromRef
+		if ref.Name != "" {
+			if err := cl.Get(ctx, testutils.ObjectKey(obj), obj); k8serrors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+		}
+		owned = append(owned, obj.(*unstructured.Unstructured))
+	}
+	return owned, nil
+}
+
+// ownerReference returns the owner reference of an object to an owner, it matches by the group, kind and name of the
+// owner and by its UID if the owner exists.
+func ownerReference(obj runtime.Object, owner *unstructured.Unstructured) *metav1.OwnerReference {
+	m := obj.(metav1.Object)
+	ownerGroup := owner.GroupVersionKind().Group
+
+	for _, ref := range m.GetOwnerReferences() {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil || gv.Group != ownerGroup || ref.Kind != owner.GetKind() || ref.Name != owner.GetName() {
+			continue
+		}
+		if owner.GetUID() != "" && ref.UID != owner.GetUID() {
+			continue
+		}
+		ref := ref
+		return &ref
+	}
+	return nil
+}
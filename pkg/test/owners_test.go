@@ -0,0 +1,124 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestStepCheckOwners(t *testing.T) {
+	controller := true
+
+	pod := func(name, owner string, uid types.UID, isController bool) *corev1.Pod {
+		p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace}}
+		if owner != "" {
+			ref := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "Deployment", Name: owner, UID: uid}
+			if isController {
+				ref.Controller = &controller
+			}
+			p.OwnerReferences = []metav1.OwnerReference{ref}
+		}
+		return p
+	}
+
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme,
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "operator", Namespace: testNamespace, UID: "operator-uid"}},
+		pod("controlled", "operator", "operator-uid", true),
+		pod("owned", "operator", "operator-uid", false),
+		pod("stale", "operator", "previous-uid", true),
+		pod("orphan", "", "", false),
+		pod("leftover", "deleted", "deleted-uid", true),
+	)
+
+	owner := func(name, owner string, isController, garbageCollected bool) harness.OwnerAssert {
+		return harness.OwnerAssert{
+			ObjectReference:  harness.ObjectReference{ObjectReference: driftRef("Pod", "v1", name)},
+			Owner:            driftRef("Deployment", "apps/v1", owner),
+			Controller:       isController,
+			GarbageCollected: garbageCollected,
+		}
+	}
+
+	for _, test := range []struct {
+		name   string
+		owners []harness.OwnerAssert
+		errors []string
+	}{
+		{
+			name: "objects are owned and controlled",
+			owners: []harness.OwnerAssert{
+				owner("controlled", "operator", true, false),
+				owner("owned", "operator", false, false),
+			},
+		},
+		{
+			name: "objects are not owned or controlled",
+			owners: []harness.OwnerAssert{
+				owner("owned", "operator", true, false),
+				owner("orphan", "operator", false, false),
+				owner("stale", "operator", false, false),
+				owner("missing", "operator", false, false),
+			},
+			errors: []string{
+				"Pod:world/owned is owned but not controlled by Deployment:world/operator",
+				"Pod:world/orphan is not owned by Deployment:world/operator",
+				"Pod:world/stale is not owned by Deployment:world/operator",
+				"no objects of kind Pod owned by Deployment:world/operator found",
+			},
+		},
+		{
+			name: "owner does not exist",
+			owners: []harness.OwnerAssert{
+				owner("leftover", "deleted", false, false),
+			},
+			errors: []string{
+				`owner Deployment:world/deleted: deployments.apps "deleted" not found`,
+			},
+		},
+		{
+			name: "garbage collection",
+			owners: []harness.OwnerAssert{
+				owner("missing", "deleted", false, true),
+				owner("leftover", "deleted", false, true),
+				owner("controlled", "operator", false, true),
+			},
+			errors: []string{
+				"Pod:world/leftover is not garbage collected after its owner Deployment:world/deleted was deleted",
+				"owner Deployment:world/operator is not deleted",
+			},
+		},
+		{
+			name: "invalid owner assert",
+			owners: []harness.OwnerAssert{
+				owner("controlled", "", false, false),
+			},
+			errors: []string{
+				"owner assert of kind Pod requires the kind and name of the owner",
+			},
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			step := &Step{
+				Logger:          testutils.NewTestLogger(t, ""),
+				Assert:          &harness.TestAssert{Owners: test.owners},
+				Client:          func(bool) (client.Client, error) { return cl, nil },
+				DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+			}
+			assert.Equal(t, test.errors, errorStrings(step.CheckOwners(context.TODO(), testNamespace)))
+		})
+	}
+}
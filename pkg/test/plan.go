@@ -0,0 +1,390 @@
+package test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	"github.com/kudobuilder/kuttl/pkg/http"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// planNamespace stands in for the namespace of a test case which is only determined when the test case runs.
+const planNamespace = "$NAMESPACE"
+
+// DiscoverTests loads the test cases and their steps of all local test directories without connecting to a cluster.
+// If testToRun is set, only the test cases matching it like the --test flag are returned, with ShardCount only the
+// test cases of the shard and with RerunFailed only the test cases which failed in the previous run.
+// Test files rendered as templates use the test suite namespace or $NAMESPACE as the namespace of the test case.
+func (h *Harness) DiscoverTests(testToRun string) ([]*Case, error) {
+	testutils.DecryptSops = h.TestSuite.Sops
+
+	var match *regexp.Regexp
+	if testToRun != "" {
+		var err error
+		if match, err = regexp.Compile(testToRun); err != nil {
+			return nil, fmt.Errorf("invalid test %q: %w", testToRun, err)
+		}
+	}
+
+	// like in RunTests, only the test cases which failed in the previous run are run with RerunFailed
+	var failed []failedTest
+	if h.TestSuite.RerunFailed {
+		var err error
+		if failed, err = loadFailed(h.TestSuite.FailedFile); err != nil {
+			return nil, err
+		}
+	}
+
+	cases := []*Case{}
+
+	for _, dir := range h.TestSuite.TestDirs {
+		// test suite archives are only downloaded when the tests run
+		if http.IsURL(dir) {
+			continue
+		}
+
+		tests, err := h.LoadTests(dir)
+		if err != nil {
+			return nil, err
+		}
+		// like in RunTests, only the test cases of the shard are run
+		if h.TestSuite.ShardCount > 1 {
+			tests = filterShard(tests, dir, h.TestSuite.ShardIndex, h.TestSuite.ShardCount)
+		}
+
+		for _, test := range tests {
+			if match != nil && !match.MatchString(test.Name) {
+				continue
+			}
+			if h.TestSuite.RerunFailed && !containsTest(failed, dir, test.Name) {
+				continue
+			}
+
+			test.Logger = discardLogger{}
+			test.ns = &namespace{Name: h.TestSuite.Namespace}
+			if test.ns.Name == "" {
+				test.ns.Name = planNamespace
+			}
+
+			if err := test.LoadTestSteps(); err != nil {
+				return nil, fmt.Errorf("test %s: %w", test.Name, err)
+			}
+			for _, step := range test.Steps {
+				if err := step.loadTemplates(); err != nil {
+					return nil, fmt.Errorf("test %s: %w", test.Name, err)
+				}
+			}
+
+			cases = append(cases, test)
+		}
+	}
+
+	return cases, nil
+}
+
+// Plan writes the execution plan of the test suite to w without connecting to a cluster: the commands of the
+// test suite and the test cases with the files, commands and objects of their steps in the order they run.
+// Environment variables are expanded as far as they are known before the tests run.
+func (h *Harness) Plan(w io.Writer, testToRun string) error {
+	cases, err := h.DiscoverTests(testToRun)
+	if err != nil {
+		return err
+	}
+
+	p := &planWriter{w: w, env: h.TestSuite.Env}
+
+	if h.TestSuite.CRDDir != "" {
+		p.line(0, "install CRDs from %s", h.TestSuite.CRDDir)
+	}
+	for _, crds := range h.TestSuite.CRDs {
+		p.line(0, "install CRDs from %s", crds)
+	}
+	for _, dir := range h.TestSuite.ManifestDirs {
+		p.line(0, "install manifests from %s", dir)
+	}
+	for _, command := range h.TestSuite.Commands {
+		p.line(0, "run %s", planCommand(command, p.namespaceEnv("default")))
+	}
+
+	for _, dir := range h.TestSuite.TestDirs {
+		if http.IsURL(dir) {
+			p.line(0, "test suite %s (downloaded when the tests run)", dir)
+			continue
+		}
+
+		p.line(0, "test suite %s", dir)
+		for _, test := range cases {
+			if h.testSuiteDir(test) != dir {
+				continue
+			}
+			if err := p.testCase(test); err != nil {
+				return err
+			}
+		}
+	}
+
+	return p.err
+}
+
+// planWriter writes the lines of a plan and keeps the first write error.
+type planWriter struct {
+	w io.Writer
+	// env of the test suite, the variables resolved from the cluster are not known.
+	env map[string]string
+	err error
+}
+
+// line writes an indented line to the plan.
+func (p *planWriter) line(indent int, format string, args ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = fmt.Fprintf(p.w, "%s%s\n", strings.Repeat("  ", indent), fmt.Sprintf(format, args...))
+}
+
+// namespaceEnv returns the env of the test suite for the commands run in a namespace, like in testutils.RunCommand
+// the NAMESPACE variable can not be overridden.
+func (p *planWriter) namespaceEnv(namespace string) map[string]string {
+	env := map[string]string{}
+	for key, value := range p.env {
+		env[key] = value
+	}
+	env["NAMESPACE"] = namespace
+	return env
+}
+
+// testCase writes the steps of a test case to the plan.
+func (p *planWriter) testCase(test *Case) error {
+	p.line(1, "test %s (namespace %s, timeout %ds)", test.Name, test.ns.Name, test.Timeout)
+
+	for _, step := range test.Steps {
+		if !test.runsStep(step) {
+			p.line(2, "step %s (skipped)", step.String())
+			continue
+		}
+
+		stepEnv := map[string]string{}
+		for key, value := range p.env {
+			stepEnv[key] = value
+		}
+
+		files, err := test.stepFiles(step)
+		if err != nil {
+			return err
+		}
+
+		p.line(2, "step %s (timeout %ds)", step.String(), step.GetTimeout())
+		for _, file := range files {
+			p.line(3, "file %s", relativePath(test.Dir, file))
+		}
+
+		if step.Step != nil {
+			for key, value := range step.Step.Env {
+				stepEnv[key] = planExpand(value, stepEnv)
+			}
+		}
+		stepEnv["NAMESPACE"] = test.ns.Name
+
+		if step.Step != nil {
+
+			for _, ref := range step.Step.Delete {
+				p.line(3, "delete %s %s", ref.Kind, ref.Name)
+			}
+			for _, ref := range step.Step.Terminate {
+				p.line(3, "terminate %s %s", ref.Kind, ref.Name)
+			}
+			for _, command := range step.Step.Commands {
+				p.line(3, "run %s", planCommand(command, stepEnv))
+			}
+			for _, exec := range step.Step.Exec {
+				p.line(3, "exec %s", planExec(exec))
+			}
+			for i, job := range step.Step.Jobs {
+				p.line(3, "job %s", planJob(step.newJob(job, i, test.ns.Name).Name, job))
+			}
+			for _, helm := range step.Step.Helm {
+				p.line(3, "%s", planHelm(helm))
+			}
+			for _, olm := range step.Step.OLM {
+				p.line(3, "olm install %s", olm.Package)
+			}
+			for _, chaos := range step.Step.Chaos {
+				p.line(3, "chaos %s", planChaos(chaos))
+			}
+			for _, scale := range step.Step.Scale {
+				p.line(3, "scale %s %s to %d replicas", scale.Kind, scale.Name, scale.Replicas)
+			}
+			for _, restart := range step.Step.Restart {
+				p.line(3, "restart %s %s", restart.Kind, restart.Name)
+			}
+			for _, waitFor := range step.Step.WaitFor {
+				description, _, err := waitForCondition(waitFor, test.ns.Name)
+				if err != nil {
+					return err
+				}
+				p.line(3, "wait for %s", description)
+			}
+			for _, path := range step.Step.Apply {
+				p.line(3, "apply path %s", planExpand(path, step.values))
+			}
+			if step.Step.Prune {
+				p.line(3, "prune %s", step.Step.PruneSelector)
+			}
+			for _, path := range step.Step.Assert {
+				p.line(3, "assert path %s", planExpand(path, step.values))
+			}
+			for _, path := range step.Step.Error {
+				p.line(3, "error path %s", planExpand(path, step.values))
+			}
+		}
+
+		for _, obj := range step.Apply {
+			p.line(3, "apply %s", planObject(obj))
+		}
+		for _, obj := range step.Asserts {
+			p.line(3, "assert %s", planObject(obj))
+		}
+		if step.Assert != nil {
+			for _, command := range step.Assert.Commands {
+				p.line(3, "assert run %s", planCommand(command, stepEnv))
+			}
+			for _, exec := range step.Assert.Exec {
+				p.line(3, "assert exec %s", planExec(exec))
+			}
+		}
+		for _, obj := range step.Errors {
+			p.line(3, "error %s", planObject(obj))
+		}
+	}
+
+	return p.err
+}
+
+// planHelm describes a Helm operation of the plan.
+func planHelm(helm harness.Helm) string {
+	switch {
+	case helm.Uninstall:
+		return fmt.Sprintf("helm uninstall %s", helm.Release)
+	case helm.Upgrade:
+		return fmt.Sprintf("helm upgrade %s %s", helm.Release, helm.Chart)
+	default:
+		return fmt.Sprintf("helm install %s %s", helm.Release, helm.Chart)
+	}
+}
+
+// planExec describes a command run in a container of a pod.
+func planExec(exec harness.Exec) string {
+	description := strings.Join(exec.Command, " ")
+	if exec.Pod != "" {
+		description += " in pod " + exec.Pod
+	} else {
+		description += " in a pod matching " + exec.Selector
+	}
+	if exec.Container != "" {
+		description += " container " + exec.Container
+	}
+	return description
+}
+
+// planJob describes a job of a test step by its name and the images of its containers.
+func planJob(name string, job harness.Job) string {
+	images := []string{}
+	for _, container := range job.Spec.Containers {
+		images = append(images, container.Image)
+	}
+	return fmt.Sprintf("%s (%s)", name, strings.Join(images, ", "))
+}
+
+// planCommand describes a command of the plan with its environment variables expanded.
+func planCommand(command harness.Command, env map[string]string) string {
+	if command.Script != "" {
+		return fmt.Sprintf("script %q", command.Script)
+	}
+
+	description := planExpand(command.Command, env)
+	if command.Namespaced {
+		description += " (namespaced)"
+	}
+	if command.Background {
+		description += " (background)"
+	}
+	return description
+}
+
+// planExpand expands the environment variables of a string like env.ExpandWithMap, variables which are only
+// known when the tests run (e.g. captured variables) are kept as they are.
+func planExpand(s string, env map[string]string) string {
+	return os.Expand(s, func(key string) string {
+		if key == "$" {
+			return "$"
+		}
+		if value, ok := env[key]; ok {
+			return value
+		}
+		if value, ok := os.LookupEnv(key); ok {
+			return value
+		}
+		return "${" + key + "}"
+	})
+}
+
+// planObject describes an object of the plan by its kind and name.
+func planObject(obj runtime.Object) string {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	description := fmt.Sprintf("%s %s", gvk.GroupVersion().String(), gvk.Kind)
+
+	if m, err := meta.Accessor(obj); err == nil {
+		if m.GetName() != "" {
+			description += " " + m.GetName()
+		} else if len(m.GetLabels()) > 0 {
+			description += " " + labels.SelectorFromSet(m.GetLabels()).String()
+		}
+	}
+	return description
+}
+
+// relativePath returns the path of a file relative to a directory if possible.
+func relativePath(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+// discardLogger is a testutils.Logger which drops all logs, it is used when no test is running.
+type discardLogger struct{}
+
+func (discardLogger) Log(args ...interface{})                 {}
+func (discardLogger) Logf(format string, args ...interface{}) {}
+func (l discardLogger) WithPrefix(string) testutils.Logger    { return l }
+func (discardLogger) Write(p []byte) (n int, err error)       { return len(p), nil }
+func (discardLogger) Flush()                                  {}
+
+// planChaos describes a disruption of a test step.
+func planChaos(chaos harness.Chaos) string {
+	switch {
+	case chaos.KillPods != nil:
+		return "kill pods " + labels.Set(chaos.KillPods).String()
+	case chaos.EvictPods != nil:
+		return "evict pods " + labels.Set(chaos.EvictPods).String()
+	case chaos.IsolatePods != nil:
+		return "isolate pods " + labels.Set(chaos.IsolatePods).String()
+	case chaos.Cordon != "":
+		return "cordon node " + chaos.Cordon
+	case chaos.Uncordon != "":
+		return "uncordon node " + chaos.Uncordon
+	case chaos.Drain != "":
+		return "drain node " + chaos.Drain
+	default:
+		return "bounce deployment " + chaos.Bounce
+	}
+}
@@ -0,0 +1,77 @@
+package test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+)
+
+func TestPlan(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-plan")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "hello"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "hello", "00-install.yaml"), []byte(`apiVersion: kuttl.dev/v1beta1
+kind: TestStep
+env:
+  MANIFEST: $SUITE_DIR/pod.yaml
+commands:
+- command: kubectl apply -f $MANIFEST -n $NAMESPACE
+- command: echo $CAPTURED
+exec:
+- pod: hello
+  command: [cat, /tmp/ready]
+jobs:
+- spec:
+    containers:
+    - name: migrate
+      image: busybox
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: hello
+`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "hello", "00-assert.yaml"), []byte(`apiVersion: v1
+kind: Pod
+metadata:
+  name: hello
+`), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "other"), 0755))
+
+	h := Harness{
+		TestSuite: harness.TestSuite{
+			TestDirs: []string{dir, "https://example.com/suite.tgz"},
+			Env:      map[string]string{"SUITE_DIR": "/manifests"},
+			Commands: []harness.Command{{Command: "echo $NAMESPACE"}},
+		},
+	}
+
+	plan := &bytes.Buffer{}
+	require.NoError(t, h.Plan(plan, "hel"))
+	assert.Equal(t, `run echo default
+test suite `+dir+`
+  test hello (namespace $NAMESPACE, timeout 30s)
+    step 0-install (timeout 30s)
+      file 00-assert.yaml
+      file 00-install.yaml
+      run kubectl apply -f /manifests/pod.yaml -n $NAMESPACE
+      run echo ${CAPTURED}
+      exec cat /tmp/ready in pod hello
+      job 0-install-job-0 (busybox)
+      apply v1 Pod hello
+      assert v1 Pod hello
+test suite https://example.com/suite.tgz (downloaded when the tests run)
+`, plan.String())
+
+	_, err = h.DiscoverTests("(")
+	assert.Error(t, err)
+}
@@ -0,0 +1,228 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+)
+
+// startPortForwards establishes the port forwards of the test step. The local addresses are exposed
+// to the commands of the step in the environment variables of the forwards.
+func (s *Step) startPortForwards(ctx context.Context, namespace string) error {
+	if s.Step == nil || len(s.Step.PortForward) == 0 {
+		return nil
+	}
+
+	s.forwardEnv = map[string]string{}
+
+	for _, pf := range s.Step.PortForward {
+		address, err := s.startPortForward(ctx, namespace, pf)
+		if err != nil {
+			return err
+		}
+		if pf.Env != "" {
+			s.forwardEnv[pf.Env] = address
+		}
+	}
+
+	return nil
+}
+
+// stopPortForwards tears down the port forwards of the test step.
+func (s *Step) stopPortForwards() {
+	for _, stop := range s.forwards {
+		close(stop)
+	}
+	s.forwards = nil
+	s.forwardEnv = nil
+}
+
+// startPortForward waits for the target of a port forward to be running and forwards a local port to it.
+// It returns the local address of the forward. The forwards are established before the objects of the step
+// are applied, so the target must be created by an earlier step.
+func (s *Step) startPortForward(ctx context.Context, namespace string, pf harness.PortForward) (string, error) {
+	if (pf.Pod == "") == (pf.Service == "") {
+		return "", errors.New("port forward requires either a pod or a service")
+	}
+	if pf.Namespace != "" {
+		namespace = pf.Namespace
+	}
+
+	cl, err := s.Client(false)
+	if err != nil {
+		return "", err
+	}
+
+	var pod string
+	var port int
+	var targetErr error
+	waitCtx, cancel := context.WithTimeout(ctx, time.Duration(s.GetTimeout())*time.Second)
+	defer cancel()
+	err = wait.PollImmediateUntil(time.Second, func() (bool, error) {
+		pod, port, targetErr = forwardTarget(ctx, cl, namespace, pf)
+		return targetErr == nil, nil
+	}, waitCtx.Done())
+	if err == wait.ErrWaitTimeout && ctx.Err() != nil {
+		return "", fmt.Errorf("port forward to %s: %w", forwardName(pf), ctx.Err())
+	}
+	if err != nil {
+		return "", fmt.Errorf("port forward to %s: %w", forwardName(pf), targetErr)
+	}
+
+	if s.Config == nil {
+		return "", errors.New("port forward requires a cluster configuration")
+	}
+	cfg, err := s.Config()
+	if err != nil {
+		return "", err
+	}
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return "", err
+	}
+	transport, upgrader, err := spdy.RoundTripperFor(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	req := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	stop, ready := make(chan struct{}), make(chan struct{})
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, []string{fmt.Sprintf("%d:%d", pf.LocalPort, port)}, stop, ready, s.Logger, s.Logger)
+	if err != nil {
+		return "", err
+	}
+
+	forwardErr := make(chan error, 1)
+	go func() {
+		forwardErr <- forwarder.ForwardPorts()
+	}()
+
+	select {
+	case <-ready:
+	case err := <-forwardErr:
+		return "", fmt.Errorf("port forward to %s: %w", forwardName(pf), err)
+	case <-waitCtx.Done():
+		close(stop)
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("port forward to %s: %w", forwardName(pf), ctx.Err())
+		}
+		return "", fmt.Errorf("port forward to %s: timed out", forwardName(pf))
+	}
+	s.forwards = append(s.forwards, stop)
+
+	ports, err := forwarder.GetPorts()
+	if err != nil {
+		return "", err
+	}
+
+	address := fmt.Sprintf("127.0.0.1:%d", ports[0].Local)
+	s.Logger.Logf("forwarding %s to pod %s port %d", address, pod, port)
+	return address, nil
+}
+
+// forwardTarget returns the running pod and its port number a port forward is established to.
+// The port of a service is resolved to the target port of the pod.
+func forwardTarget(ctx context.Context, cl client.Client, namespace string, pf harness.PortForward) (string, int, error) {
+	if pf.Pod != "" {
+		pod := &corev1.Pod{}
+		if err := cl.Get(ctx, client.ObjectKey{Namespace: namespace, Name: pf.Pod}, pod); err != nil {
+			return "", 0, err
+		}
+		if pod.Status.Phase != corev1.PodRunning {
+			return "", 0, fmt.Errorf("pod %s is %s", pod.Name, pod.Status.Phase)
+		}
+		port, err := containerPort(pod, intstr.Parse(pf.Port))
+		return pod.Name, port, err
+	}
+
+	service := &corev1.Service{}
+	if err := cl.Get(ctx, client.ObjectKey{Namespace: namespace, Name: pf.Service}, service); err != nil {
+		return "", 0, err
+	}
+
+	servicePort, err := findServicePort(service, pf.Port)
+	if err != nil {
+		return "", 0, err
+	}
+
+	pods := &corev1.PodList{}
+	if err := cl.List(ctx, pods, client.InNamespace(namespace), client.MatchingLabels(service.Spec.Selector)); err != nil {
+		return "", 0, err
+	}
+
+	for index := range pods.Items {
+		pod := &pods.Items[index]
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		targetPort := servicePort.TargetPort
+		if targetPort.Type == intstr.Int && targetPort.IntVal == 0 {
+			targetPort = intstr.FromInt(int(servicePort.Port))
+		}
+		port, err := containerPort(pod, targetPort)
+		return pod.Name, port, err
+	}
+
+	return "", 0, fmt.Errorf("no running pod of service %s found", service.Name)
+}
+
+// findServicePort returns the port of a service by name or number. The port can be omitted for services with one port.
+func findServicePort(service *corev1.Service, port string) (corev1.ServicePort, error) {
+	if port == "" && len(service.Spec.Ports) == 1 {
+		return service.Spec.Ports[0], nil
+	}
+
+	for _, servicePort := range service.Spec.Ports {
+		if servicePort.Name == port || strconv.Itoa(int(servicePort.Port)) == port {
+			return servicePort, nil
+		}
+	}
+	return corev1.ServicePort{}, fmt.Errorf("service %s has no port %q", service.Name, port)
+}
+
+// containerPort resolves a port number or the name of a container port of a pod to a port number.
+func containerPort(pod *corev1.Pod, port intstr.IntOrString) (int, error) {
+	if port.Type == intstr.Int {
+		if port.IntVal <= 0 {
+			return 0, fmt.Errorf("invalid port %d", port.IntVal)
+		}
+		return int(port.IntVal), nil
+	}
+
+	for _, container := range pod.Spec.Containers {
+		for _, containerPort := range container.Ports {
+			if containerPort.Name == port.StrVal {
+				return int(containerPort.ContainerPort), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("pod %s has no container port %q", pod.Name, port.StrVal)
+}
+
+// forwardName describes the target of a port forward for error messages.
+func forwardName(pf harness.PortForward) string {
+	if pf.Pod != "" {
+		return fmt.Sprintf("pod %s port %s", pf.Pod, pf.Port)
+	}
+	return fmt.Sprintf("service %s port %s", pf.Service, pf.Port)
+}
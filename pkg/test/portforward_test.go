@@ -0,0 +1,142 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestForwardTarget(t *testing.T) {
+	pod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, Labels: map[string]string{"app": "db"}},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "db", Ports: []corev1.ContainerPort{{Name: "postgres", ContainerPort: 5432}}},
+				},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: testNamespace},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": "db"},
+			Ports: []corev1.ServicePort{
+				{Name: "sql", Port: 5432, TargetPort: intstr.FromString("postgres")},
+				{Name: "metrics", Port: 80, TargetPort: intstr.FromInt(9187)},
+				{Name: "admin", Port: 8080},
+			},
+		},
+	}
+
+	for _, test := range []struct {
+		name    string
+		forward harness.PortForward
+		pod     string
+		port    int
+		wantErr bool
+	}{
+		{
+			name:    "pod port number",
+			forward: harness.PortForward{Pod: "db-1", Port: "5432"},
+			pod:     "db-1",
+			port:    5432,
+		},
+		{
+			name:    "pod port name",
+			forward: harness.PortForward{Pod: "db-1", Port: "postgres"},
+			pod:     "db-1",
+			port:    5432,
+		},
+		{
+			name:    "pod not running",
+			forward: harness.PortForward{Pod: "db-0", Port: "5432"},
+			wantErr: true,
+		},
+		{
+			name:    "service named target port",
+			forward: harness.PortForward{Service: "db", Port: "sql"},
+			pod:     "db-1",
+			port:    5432,
+		},
+		{
+			name:    "service target port number",
+			forward: harness.PortForward{Service: "db", Port: "80"},
+			pod:     "db-1",
+			port:    9187,
+		},
+		{
+			name:    "service without target port",
+			forward: harness.PortForward{Service: "db", Port: "admin"},
+			pod:     "db-1",
+			port:    8080,
+		},
+		{
+			name:    "missing service port",
+			forward: harness.PortForward{Service: "db", Port: "443"},
+			wantErr: true,
+		},
+		{
+			name:    "missing service",
+			forward: harness.PortForward{Service: "missing", Port: "80"},
+			wantErr: true,
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			cl := fake.NewFakeClientWithScheme(scheme.Scheme, pod("db-0", corev1.PodPending), pod("db-1", corev1.PodRunning), service.DeepCopy())
+
+			pod, port, err := forwardTarget(context.TODO(), cl, testNamespace, test.forward)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.pod, pod)
+			assert.Equal(t, test.port, port)
+		})
+	}
+}
+
+func TestPortForwardEnv(t *testing.T) {
+	step := Step{
+		Env:        map[string]string{"DB_ADDR": "db:5432"},
+		forwardEnv: map[string]string{"DB_ADDR": "127.0.0.1:40000"},
+	}
+
+	env, err := step.commandEnv(context.TODO(), testNamespace)
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:40000", env["DB_ADDR"])
+
+	step.stopPortForwards()
+	env, err = step.commandEnv(context.TODO(), testNamespace)
+	assert.NoError(t, err)
+	assert.Equal(t, "db:5432", env["DB_ADDR"])
+}
+
+func TestStartPortForwardCancelled(t *testing.T) {
+	step := Step{
+		Logger: testutils.NewTestLogger(t, ""),
+		Client: func(bool) (client.Client, error) { return fake.NewFakeClientWithScheme(scheme.Scheme), nil },
+	}
+
+	// waiting for the target ends with the context of the step
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	_, err := step.startPortForward(ctx, testNamespace, harness.PortForward{Pod: "db", Port: "5432"})
+	assert.True(t, errors.Is(err, context.Canceled), err)
+}
@@ -0,0 +1,206 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/jsonpath"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	kenv "github.com/kudobuilder/kuttl/pkg/env"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// defaultHTTPTimeout is the time an HTTP request of an assert may take if its timeout is not set, the assert is retried
+// until the timeout of the step.
+const defaultHTTPTimeout = 10 * time.Second
+
+// CheckHTTP performs the requests of the HTTP asserts of the TestAssert and checks the responses.
+func (s *Step) CheckHTTP(ctx context.Context, namespace string) []error {
+	if s.Assert == nil || len(s.Assert.HTTP) == 0 {
+		return nil
+	}
+
+	errs := []error{}
+
+	for _, probe := range s.Assert.HTTP {
+		if err := s.checkHTTP(ctx, probe, namespace); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// checkHTTP performs the request of an HTTP assert and checks the response.
+func (s *Step) checkHTTP(ctx context.Context, probe harness.HTTPAssert, namespace string) error {
+	httpClient, url, err := s.httpTarget(ctx, probe, namespace)
+	if err != nil {
+		return err
+	}
+	httpClient.Timeout = defaultHTTPTimeout
+	if probe.Timeout > 0 {
+		httpClient.Timeout = time.Duration(probe.Timeout) * time.Second
+	}
+
+	method := probe.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(probe.Body))
+	if err != nil {
+		return fmt.Errorf("http %s %s: %w", method, url, err)
+	}
+	for name, value := range probe.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("http %s %s: %w", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("http %s %s: %w", method, url, err)
+	}
+
+	if err := checkResponse(probe.Response, resp, body); err != nil {
+		return fmt.Errorf("http %s %s: %w", method, url, err)
+	}
+	return nil
+}
+
+// httpTarget returns the client and URL of an HTTP assert. Services are requested
+// through the service proxy of the API server with the credentials of the cluster configuration.
+func (s *Step) httpTarget(ctx context.Context, probe harness.HTTPAssert, namespace string) (*http.Client, string, error) {
+	if (probe.URL == "") == (probe.Service == "") {
+		return nil, "", errors.New("http assert requires either a url or a service")
+	}
+
+	if probe.URL != "" {
+		// the URL can refer to the addresses of port forwards and other variables of the step
+		env, err := s.commandEnv(ctx, namespace)
+		if err != nil {
+			return nil, "", err
+		}
+
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if probe.InsecureSkipVerify {
+			transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec
+		}
+		return &http.Client{Transport: transport}, kenv.ExpandWithMap(probe.URL, env), nil
+	}
+
+	if s.Config == nil {
+		return nil, "", errors.New("http assert of a service requires a cluster configuration")
+	}
+
+	if probe.Namespace != "" {
+		namespace = probe.Namespace
+	}
+	return s.serviceProxy(namespace, probe.Service, probe.Port, probe.Scheme, probe.Path)
+}
+
+// serviceProxy returns the client and URL of a path of a service which is requested through the service proxy of the
+// API server with the credentials of the cluster configuration.
+func (s *Step) serviceProxy(namespace, service, port, scheme, path string) (*http.Client, string, error) {
+	cfg, err := s.Config()
+	if err != nil {
+		return nil, "", err
+	}
+	transport, err := rest.TransportFor(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if port != "" {
+		service += ":" + port
+	}
+	if scheme != "" {
+		service = scheme + ":" + service
+	}
+
+	host := strings.TrimSuffix(cfg.Host, "/")
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+
+	url := fmt.Sprintf("%s/api/v1/namespaces/%s/services/%s/proxy/%s", host, namespace, service, strings.TrimPrefix(path, "/"))
+	return &http.Client{Transport: transport}, url, nil
+}
+
+// checkResponse checks the status code, headers and body of a response.
+func checkResponse(expected harness.HTTPResponse, resp *http.Response, body []byte) error {
+	if expected.StatusCode != 0 && resp.StatusCode != expected.StatusCode {
+		return fmt.Errorf("expected status code %d, got %d", expected.StatusCode, resp.StatusCode)
+	}
+	if expected.StatusCode == 0 && (resp.StatusCode < 200 || resp.StatusCode > 299) {
+		return fmt.Errorf("expected a 2xx status code, got %d", resp.StatusCode)
+	}
+
+	for name, match := range expected.Headers {
+		re, err := regexp.Compile(match)
+		if err != nil {
+			return fmt.Errorf("invalid header %s match %q: %w", name, match, err)
+		}
+		if value := resp.Header.Get(name); !re.MatchString(value) {
+			return fmt.Errorf("header %s: %q does not match %q", name, value, match)
+		}
+	}
+
+	if err := testutils.CheckOutput("body", expected.Body, string(body)); err != nil {
+		return err
+	}
+
+	if len(expected.JSONPath) == 0 {
+		return nil
+	}
+
+	var content interface{}
+	if err := json.Unmarshal(body, &content); err != nil {
+		return fmt.Errorf("body is not JSON: %w", err)
+	}
+
+	for expression, value := range expected.JSONPath {
+		actual, err := jsonPathValue(expression, content)
+		if err != nil {
+			return err
+		}
+		if err := testutils.IsSubset(value, actual); err != nil {
+			return fmt.Errorf("body %s: %w", expression, err)
+		}
+	}
+
+	return nil
+}
+
+// jsonPathValue evaluates a JSONPath expression on a decoded JSON document.
+func jsonPathValue(expression string, content interface{}) (string, error) {
+	if !strings.HasPrefix(expression, "{") {
+		expression = "{" + expression + "}"
+	}
+
+	path := jsonpath.New("body")
+	if err := path.Parse(expression); err != nil {
+		return "", fmt.Errorf("invalid JSONPath %s: %w", expression, err)
+	}
+
+	value := &bytes.Buffer{}
+	if err := path.Execute(value, content); err != nil {
+		return "", fmt.Errorf("body %s: %w", expression, err)
+	}
+	return value.String(), nil
+}
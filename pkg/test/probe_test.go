@@ -0,0 +1,103 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/rest"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestStepCheckHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			<-r.Context().Done()
+		case "/healthz", "/api/v1/namespaces/world/services/hello:8080/proxy/healthz":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"status": "ok", "method": %q, "token": %q, "replicas": 3}`, r.Method, r.Header.Get("X-Token"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	for _, test := range []struct {
+		name   string
+		probe  harness.HTTPAssert
+		errors int
+	}{
+		{
+			name: "url",
+			probe: harness.HTTPAssert{
+				URL:     server.URL + "/healthz",
+				Method:  http.MethodPost,
+				Headers: map[string]string{"X-Token": "secret"},
+				Response: harness.HTTPResponse{
+					StatusCode: http.StatusOK,
+					Headers:    map[string]string{"Content-Type": "^application/json"},
+					Body:       &harness.CommandOutput{Match: []string{`"status": "ok"`}, NotMatch: []string{"error"}},
+					JSONPath: map[string]string{
+						".method":   "POST",
+						".token":    "secret",
+						".replicas": "kuttl.gte(2)",
+					},
+				},
+			},
+		},
+		{
+			name:  "service proxy",
+			probe: harness.HTTPAssert{Service: "hello", Port: "8080", Path: "/healthz"},
+		},
+		{
+			name:   "unexpected status code",
+			probe:  harness.HTTPAssert{URL: server.URL + "/missing"},
+			errors: 1,
+		},
+		{
+			name:  "expected status code",
+			probe: harness.HTTPAssert{URL: server.URL + "/missing", Response: harness.HTTPResponse{StatusCode: http.StatusNotFound}},
+		},
+		{
+			name:   "header mismatch",
+			probe:  harness.HTTPAssert{URL: server.URL + "/healthz", Response: harness.HTTPResponse{Headers: map[string]string{"Content-Type": "text/html"}}},
+			errors: 1,
+		},
+		{
+			name:   "JSONPath mismatch",
+			probe:  harness.HTTPAssert{URL: server.URL + "/healthz", Response: harness.HTTPResponse{JSONPath: map[string]string{".status": "failed"}}},
+			errors: 1,
+		},
+		{
+			name:   "request timeout",
+			probe:  harness.HTTPAssert{URL: server.URL + "/slow", Timeout: 1},
+			errors: 1,
+		},
+		{
+			name:   "url and service",
+			probe:  harness.HTTPAssert{URL: server.URL + "/healthz", Service: "hello"},
+			errors: 1,
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			step := Step{
+				Logger: testutils.NewTestLogger(t, ""),
+				Assert: &harness.TestAssert{HTTP: []harness.HTTPAssert{test.probe}},
+				Config: func() (*rest.Config, error) {
+					return &rest.Config{Host: server.URL}, nil
+				},
+			}
+
+			errs := step.CheckHTTP(context.TODO(), testNamespace)
+			assert.Equal(t, test.errors, len(errs), errs)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/thoas/go-funk"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// backgroundProcess is a process started by a background command of a test step.
+type backgroundProcess struct {
+	command harness.Command
+	cmd     *exec.Cmd
+	// done is closed when the process exited, err is the result of waiting for the process.
+	done chan struct{}
+	err  error
+}
+
+func newBackgroundProcess(command harness.Command, cmd *exec.Cmd) *backgroundProcess {
+	p := &backgroundProcess{
+		command: command,
+		cmd:     cmd,
+		done:    make(chan struct{}),
+	}
+	go func() {
+		p.err = cmd.Wait()
+		close(p.done)
+	}()
+	return p
+}
+
+// exited returns true if the process is no longer running.
+func (p *backgroundProcess) exited() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// runCommands runs the commands of the test step.
+// Processes of background commands are tracked until they are stopped with StopProcesses.
+func (s *Step) runCommands(ctx context.Context, namespace string) error {
+	// processes of a previous attempt of the step are replaced
+	s.StopProcesses()
+
+	env, err := s.commandEnv(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	bgs, err := testutils.RunCommands(ctx, s.Logger, namespace, s.Step.Commands, s.Dir, s.Timeout, env)
+
+	// background processes are returned in the order of the background commands
+	background := funk.Filter(s.Step.Commands, func(command harness.Command) bool {
+		return command.Background
+	}).([]harness.Command)
+	for i, bg := range bgs {
+		s.processes = append(s.processes, newBackgroundProcess(background[i], bg))
+	}
+
+	return err
+}
+
+// CheckProcesses returns an error for each background process which is expected to be kept alive but exited.
+func (s *Step) CheckProcesses() []error {
+	errs := []error{}
+
+	for _, p := range s.processes {
+		if p.command.KeepAlive && p.exited() {
+			errs = append(errs, fmt.Errorf("background command %q exited: %v", p.cmd.Args, p.err))
+		}
+	}
+
+	return errs
+}
+
+// StopProcesses kills all background processes of the test step which are still running.
+func (s *Step) StopProcesses() {
+	for _, p := range s.processes {
+		if p.exited() {
+			continue
+		}
+
+		s.Logger.Logf("killing background process %q", p.cmd.Args)
+		if err := p.cmd.Process.Kill(); err != nil {
+			s.Logger.Logf("background process %q kill error %v", p.cmd.Args, err)
+		}
+
+		select {
+		case <-p.done:
+		case <-time.After(10 * time.Second):
+			s.Logger.Logf("background process %q did not exit", p.cmd.Args)
+		}
+	}
+
+	s.processes = nil
+}
@@ -0,0 +1,62 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestStepBackgroundProcesses(t *testing.T) {
+	step := Step{
+		Name:   "background",
+		Logger: testutils.NewTestLogger(t, ""),
+		Step: &harness.TestStep{
+			Commands: []harness.Command{
+				{Command: "echo foreground"},
+				{Command: "sleep 60", Background: true, KeepAlive: true},
+				{Command: "true", Background: true},
+			},
+		},
+	}
+
+	assert.Nil(t, step.runCommands(context.TODO(), testNamespace))
+	assert.Equal(t, 2, len(step.processes))
+	assert.True(t, step.processes[0].command.KeepAlive)
+	assert.False(t, step.processes[1].command.KeepAlive)
+
+	// the exited process is not expected to be kept alive
+	<-step.processes[1].done
+	assert.Equal(t, []error{}, step.CheckProcesses())
+
+	sleep := step.processes[0]
+	step.StopProcesses()
+	assert.True(t, sleep.exited())
+	assert.Nil(t, step.processes)
+}
+
+func TestStepBackgroundProcessExited(t *testing.T) {
+	step := Step{
+		Name:   "background",
+		Logger: testutils.NewTestLogger(t, ""),
+		Step: &harness.TestStep{
+			Commands: []harness.Command{
+				{Command: "true", Background: true, KeepAlive: true},
+			},
+		},
+	}
+
+	assert.Nil(t, step.runCommands(context.TODO(), testNamespace))
+	defer step.StopProcesses()
+
+	select {
+	case <-step.processes[0].done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("background process did not exit")
+	}
+	assert.Equal(t, 1, len(step.CheckProcesses()))
+}
@@ -0,0 +1,209 @@
+package test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// The providers of the local cluster of TestSuite.ClusterProvider.
+const (
+	kindProviderName     = "kind"
+	k3dProviderName      = "k3d"
+	minikubeProviderName = "minikube"
+)
+
+// clusterProvider starts and stops the local cluster the tests run in, see TestSuite.StartKIND.
+type clusterProvider interface {
+	// Name returns the name of the provider for log messages.
+	Name() string
+	// IsRunning checks if the cluster is already running.
+	IsRunning() bool
+	// Start starts the cluster and writes its kubeconfig.
+	Start() error
+	// ExportKubeconfig writes the kubeconfig of the running cluster.
+	ExportKubeconfig() error
+	// LoadImages loads container images of the host into the cluster.
+	LoadImages(images []string) error
+	// CollectLogs saves the logs of the cluster to a directory.
+	CollectLogs(dir string) error
+	// Stop deletes the cluster.
+	Stop() error
+}
+
+// newClusterProvider returns the cluster provider of the test suite, the provisioner of TestSuite.Provision
+// or KIND by default.
+func (h *Harness) newClusterProvider() (clusterProvider, error) {
+	if h.TestSuite.Provision != nil {
+		return &provisioner{harness: h, provision: *h.TestSuite.Provision}, nil
+	}
+
+	run := runProviderCommand(h.GetLogger())
+
+	switch h.TestSuite.ClusterProvider {
+	case "", kindProviderName:
+		config, err := h.kindConfig()
+		if err != nil {
+			return nil, err
+		}
+		return &kindProvider{
+			kind:    newKind(h.TestSuite.KINDContext, h.kubeconfigPath(), h.GetLogger()),
+			harness: h,
+			config:  config,
+		}, nil
+	case k3dProviderName:
+		// the k3s images are not named after the Kubernetes versions
+		if h.kubernetesVersion != "" {
+			return nil, fmt.Errorf("kubernetes versions are not supported by %s", k3dProviderName)
+		}
+		return &k3d{name: h.TestSuite.KINDContext, kubeconfig: h.kubeconfigPath(), run: run}, nil
+	case minikubeProviderName:
+		return &minikube{name: h.TestSuite.KINDContext, kubeconfig: h.kubeconfigPath(), kubernetesVersion: h.kubernetesVersion, run: run}, nil
+	default:
+		return nil, fmt.Errorf("unknown cluster provider %q: must be %s, %s or %s", h.TestSuite.ClusterProvider, kindProviderName, k3dProviderName, minikubeProviderName)
+	}
+}
+
+// clusterProviderName returns the name of the cluster provider of the test suite for log messages.
+func (h *Harness) clusterProviderName() string {
+	switch h.TestSuite.ClusterProvider {
+	case "", kindProviderName:
+		return "KIND"
+	default:
+		return h.TestSuite.ClusterProvider
+	}
+}
+
+// providerCommand runs a command of the CLI of a cluster provider with additional environment variables and
+// returns its output.
+type providerCommand func(env []string, name string, args ...string) ([]byte, error)
+
+// runProviderCommand returns a providerCommand which logs the commands and their errors.
+func runProviderCommand(logger testutils.Logger) providerCommand {
+	return func(env []string, name string, args ...string) ([]byte, error) {
+		logger.Logf("running command: %v", append([]string{name}, args...))
+
+		cmd := exec.Command(name, args...)
+		cmd.Env = append(os.Environ(), env...)
+		cmd.Stderr = logger
+
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("running %s %s: %w", name, strings.Join(args, " "), err)
+		}
+		return out, nil
+	}
+}
+
+// k3d is the k3d cluster provider, see https://k3d.io.
+type k3d struct {
+	name       string
+	kubeconfig string
+	run        providerCommand
+}
+
+func (k *k3d) Name() string {
+	return k3dProviderName
+}
+
+func (k *k3d) IsRunning() bool {
+	_, err := k.run(nil, "k3d", "cluster", "get", k.name)
+	return err == nil
+}
+
+func (k *k3d) Start() error {
+	if _, err := k.run(nil, "k3d", "cluster", "create", k.name, "--wait", "--kubeconfig-update-default=false"); err != nil {
+		return err
+	}
+	return k.ExportKubeconfig()
+}
+
+func (k *k3d) ExportKubeconfig() error {
+	kubeconfig, err := k.run(nil, "k3d", "kubeconfig", "get", k.name)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(k.kubeconfig, kubeconfig, 0600)
+}
+
+func (k *k3d) LoadImages(images []string) error {
+	if len(images) == 0 {
+		return nil
+	}
+	_, err := k.run(nil, "k3d", append([]string{"image", "import", "--cluster", k.name}, images...)...)
+	return err
+}
+
+// CollectLogs does nothing, k3d can not export the logs of a cluster. The logs of the nodes are the logs of
+// their containers.
+func (k *k3d) CollectLogs(dir string) error {
+	return nil
+}
+
+func (k *k3d) Stop() error {
+	_, err := k.run(nil, "k3d", "cluster", "delete", k.name)
+	return err
+}
+
+// minikube is the minikube cluster provider, see https://minikube.sigs.k8s.io. The cluster is a minikube profile,
+// minikube writes its kubeconfig to the KUBECONFIG of its commands.
+type minikube struct {
+	name              string
+	kubeconfig        string
+	kubernetesVersion string
+	run               providerCommand
+}
+
+func (m *minikube) Name() string {
+	return minikubeProviderName
+}
+
+func (m *minikube) env() []string {
+	return []string{"KUBECONFIG=" + m.kubeconfig}
+}
+
+func (m *minikube) IsRunning() bool {
+	_, err := m.run(m.env(), "minikube", "status", "--profile", m.name)
+	return err == nil
+}
+
+func (m *minikube) Start() error {
+	args := []string{"start", "--profile", m.name, "--wait", "all"}
+	if m.kubernetesVersion != "" {
+		args = append(args, "--kubernetes-version", "v"+m.kubernetesVersion)
+	}
+	_, err := m.run(m.env(), "minikube", args...)
+	return err
+}
+
+func (m *minikube) ExportKubeconfig() error {
+	_, err := m.run(m.env(), "minikube", "update-context", "--profile", m.name)
+	return err
+}
+
+func (m *minikube) LoadImages(images []string) error {
+	for _, image := range images {
+		if _, err := m.run(m.env(), "minikube", "image", "load", image, "--profile", m.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *minikube) CollectLogs(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	_, err := m.run(m.env(), "minikube", "logs", "--profile", m.name, "--file", filepath.Join(dir, "minikube.log"))
+	return err
+}
+
+func (m *minikube) Stop() error {
+	_, err := m.run(m.env(), "minikube", "delete", "--profile", m.name)
+	return err
+}
@@ -0,0 +1,101 @@
+package test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+)
+
+// recordCommands returns a providerCommand which records the commands instead of running them, the commands
+// starting with a prefix of failing fail.
+func recordCommands(commands *[]string, output string, failing ...string) providerCommand {
+	return func(env []string, name string, args ...string) ([]byte, error) {
+		command := strings.Join(append(append(env, name), args...), " ")
+		*commands = append(*commands, command)
+		for _, prefix := range failing {
+			if strings.HasPrefix(command, prefix) {
+				return nil, errors.New("exit status 1")
+			}
+		}
+		return []byte(output), nil
+	}
+}
+
+func TestNewClusterProvider(t *testing.T) {
+	h := Harness{T: t, TestSuite: harness.TestSuite{KINDContext: "e2e"}}
+
+	for name, expected := range map[string]string{"": "KIND", "kind": "KIND", "k3d": "k3d", "minikube": "minikube"} {
+		h.TestSuite.ClusterProvider = name
+		provider, err := h.newClusterProvider()
+		require.NoError(t, err)
+		assert.Equal(t, expected, provider.Name())
+	}
+
+	h.TestSuite.ClusterProvider = "kubeadm"
+	_, err := h.newClusterProvider()
+	assert.EqualError(t, err, `unknown cluster provider "kubeadm": must be kind, k3d or minikube`)
+}
+
+func TestK3d(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-k3d")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	commands := []string{}
+	k := &k3d{
+		name:       "e2e",
+		kubeconfig: filepath.Join(dir, "kubeconfig"),
+		run:        recordCommands(&commands, "apiVersion: v1\nkind: Config\n", "k3d cluster get"),
+	}
+
+	assert.False(t, k.IsRunning())
+	require.NoError(t, k.Start())
+	require.NoError(t, k.LoadImages(nil))
+	require.NoError(t, k.LoadImages([]string{"operator:test", "webhook:test"}))
+	require.NoError(t, k.Stop())
+
+	assert.Equal(t, []string{
+		"k3d cluster get e2e",
+		"k3d cluster create e2e --wait --kubeconfig-update-default=false",
+		"k3d kubeconfig get e2e",
+		"k3d image import --cluster e2e operator:test webhook:test",
+		"k3d cluster delete e2e",
+	}, commands)
+
+	kubeconfig, err := ioutil.ReadFile(filepath.Join(dir, "kubeconfig"))
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1\nkind: Config\n", string(kubeconfig))
+}
+
+func TestMinikube(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-minikube")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	commands := []string{}
+	m := &minikube{name: "e2e", kubeconfig: "/work/kubeconfig", run: recordCommands(&commands, "")}
+
+	assert.True(t, m.IsRunning())
+	require.NoError(t, m.ExportKubeconfig())
+	require.NoError(t, m.LoadImages([]string{"operator:test", "webhook:test"}))
+	require.NoError(t, m.CollectLogs(filepath.Join(dir, "logs")))
+	require.NoError(t, m.Stop())
+
+	assert.Equal(t, []string{
+		"KUBECONFIG=/work/kubeconfig minikube status --profile e2e",
+		"KUBECONFIG=/work/kubeconfig minikube update-context --profile e2e",
+		"KUBECONFIG=/work/kubeconfig minikube image load operator:test --profile e2e",
+		"KUBECONFIG=/work/kubeconfig minikube image load webhook:test --profile e2e",
+		"KUBECONFIG=/work/kubeconfig minikube logs --profile e2e --file " + filepath.Join(dir, "logs", "minikube.log"),
+		"KUBECONFIG=/work/kubeconfig minikube delete --profile e2e",
+	}, commands)
+	assert.DirExists(t, filepath.Join(dir, "logs"))
+}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// provisionKubeconfigEnv is the environment variable of the path the create commands of the provision write the
+// kubeconfig of the cluster to.
+const provisionKubeconfigEnv = "KUTTL_KUBECONFIG"
+
+// provisioner is the cluster provider of TestSuite.Provision, it creates and deletes the cluster with commands.
+type provisioner struct {
+	harness   *Harness
+	provision harness.Provision
+}
+
+func (p *provisioner) Name() string {
+	return "provisioned"
+}
+
+// IsRunning always returns false, a provisioned cluster is created by every run.
+func (p *provisioner) IsRunning() bool {
+	return false
+}
+
+// Start runs the create commands and checks they wrote the kubeconfig of the cluster.
+func (p *provisioner) Start() error {
+	if err := p.run(p.provision.Create); err != nil {
+		return fmt.Errorf("creating the cluster: %w", err)
+	}
+
+	if _, err := os.Stat(p.harness.kubeconfigPath()); err != nil {
+		return fmt.Errorf("the create commands did not write the kubeconfig of the cluster to $%s: %w", provisionKubeconfigEnv, err)
+	}
+	return nil
+}
+
+// ExportKubeconfig does nothing, the kubeconfig is written by the create commands.
+func (p *provisioner) ExportKubeconfig() error {
+	return nil
+}
+
+// LoadImages fails if there are images, they must be pushed to a registry the cluster pulls from instead.
+func (p *provisioner) LoadImages(images []string) error {
+	if len(images) > 0 {
+		return errors.New("images can not be loaded into a provisioned cluster, push them to a registry instead")
+	}
+	return nil
+}
+
+// CollectLogs does nothing, the commands of the provision do not collect logs.
+func (p *provisioner) CollectLogs(dir string) error {
+	return nil
+}
+
+// Stop runs the delete commands.
+func (p *provisioner) Stop() error {
+	if err := p.run(p.provision.Delete); err != nil {
+		return fmt.Errorf("deleting the cluster: %w", err)
+	}
+	return nil
+}
+
+// run runs commands of the provision with the environment of the test suite and the Kubernetes version of the
+// harness, see RunKubernetesVersions.
+func (p *provisioner) run(commands []harness.Command) error {
+	env := map[string]string{provisionKubeconfigEnv: p.harness.kubeconfigPath()}
+	if p.harness.kubernetesVersion != "" {
+		env[kubernetesVersionEnv] = p.harness.kubernetesVersion
+	}
+	for key, value := range p.harness.TestSuite.Env {
+		env[key] = value
+	}
+
+	_, err := testutils.RunCommands(context.TODO(), p.harness.GetLogger(), "", commands, "", p.harness.TestSuite.Timeout, env)
+	return err
+}
@@ -0,0 +1,53 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+)
+
+func TestProvisioner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-provision")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	h := &Harness{
+		T:        t,
+		tempPath: dir,
+		TestSuite: harness.TestSuite{
+			Env: map[string]string{"CLUSTER": "e2e"},
+			Provision: &harness.Provision{
+				Create: []harness.Command{{Script: "echo $CLUSTER > $KUTTL_KUBECONFIG"}},
+				Delete: []harness.Command{{Script: "rm $KUTTL_KUBECONFIG"}},
+			},
+		},
+	}
+
+	provider, err := h.newClusterProvider()
+	require.NoError(t, err)
+	assert.Equal(t, "provisioned", provider.Name())
+	assert.False(t, provider.IsRunning())
+
+	require.NoError(t, provider.Start())
+	kubeconfig, err := ioutil.ReadFile(filepath.Join(dir, "kubeconfig"))
+	require.NoError(t, err)
+	assert.Equal(t, "e2e\n", string(kubeconfig))
+
+	assert.NoError(t, provider.LoadImages(nil))
+	assert.Error(t, provider.LoadImages([]string{"operator:test"}))
+
+	require.NoError(t, provider.Stop())
+	assert.NoFileExists(t, filepath.Join(dir, "kubeconfig"))
+
+	// the create commands must write the kubeconfig
+	h.TestSuite.Provision.Create = []harness.Command{{Script: "true"}}
+	provider, err = h.newClusterProvider()
+	require.NoError(t, err)
+	assert.Error(t, provider.Start())
+}
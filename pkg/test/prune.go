@@ -0,0 +1,96 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// recordApplied records an object applied by the step in the applied objects of the test case.
+func (s *Step) recordApplied(obj runtime.Object) {
+	if s.applied != nil {
+		s.applied[testutils.ResourceID(obj)] = obj.DeepCopyObject()
+	}
+}
+
+// Prune deletes the objects applied by the previous steps of the test case which match the TestStep prune selector
+// and are not applied by the step, like `kubectl apply --prune`. It does not wait for the objects to be deleted.
+func (s *Step) Prune(ctx context.Context, namespace string) []error {
+	if s.Step == nil || !s.Step.Prune {
+		return nil
+	}
+	if s.Step.PruneSelector == "" {
+		return []error{errors.New("prune requires a pruneSelector")}
+	}
+
+	selector, err := labels.Parse(s.Step.PruneSelector)
+	if err != nil {
+		return []error{fmt.Errorf("invalid pruneSelector %q: %w", s.Step.PruneSelector, err)}
+	}
+
+	dClient, err := s.DiscoveryClient()
+	if err != nil {
+		return []error{err}
+	}
+
+	// the objects are pruned by the user the step acts as, like they were applied
+	actingClient, err := s.actingClient(namespace)
+	if err != nil {
+		return []error{err}
+	}
+
+	current := map[string]bool{}
+	for _, obj := range s.Apply {
+		current[testutils.ResourceID(obj)] = true
+	}
+
+	ids := []string{}
+	for id := range s.applied {
+		if !current[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	errs := []error{}
+	for _, id := range ids {
+		obj := s.applied[id]
+
+		cl, _, err := s.objectClients(actingClient, dClient, obj)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		actual := &unstructured.Unstructured{}
+		actual.SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+		if err := cl.Get(ctx, testutils.ObjectKey(obj), actual); k8serrors.IsNotFound(err) {
+			delete(s.applied, id)
+			continue
+		} else if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if !selector.Matches(labels.Set(actual.GetLabels())) {
+			continue
+		}
+
+		if err := cl.Delete(ctx, actual); err != nil && !k8serrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to prune %s: %w", id, err))
+			continue
+		}
+		delete(s.applied, id)
+		s.Logger.Log(id, "pruned")
+	}
+
+	return errs
+}
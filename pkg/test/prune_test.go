@@ -0,0 +1,59 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestStepPrune(t *testing.T) {
+	pod := func(name, app string) runtime.Object {
+		return testutils.WithLabels(t, testutils.NewPod(name, ""), map[string]string{"app": app})
+	}
+
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme)
+	applied := map[string]runtime.Object{}
+
+	step := func(testStep *harness.TestStep, objs ...runtime.Object) *Step {
+		return &Step{
+			Logger:          testutils.NewTestLogger(t, ""),
+			Step:            testStep,
+			Apply:           objs,
+			Client:          func(bool) (client.Client, error) { return cl, nil },
+			DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+			applied:         applied,
+		}
+	}
+
+	first := step(nil, pod("kept", "operator"), pod("removed", "operator"), pod("other", "database"))
+	assert.Equal(t, []error{}, first.Create(context.TODO(), testNamespace))
+
+	// the objects of the previous steps which match the selector and are not applied any more are pruned
+	second := step(&harness.TestStep{Prune: true, PruneSelector: "app=operator"}, pod("kept", "operator"))
+	assert.Equal(t, []error{}, second.Create(context.TODO(), testNamespace))
+
+	exists := func(name string) bool {
+		err := cl.Get(context.TODO(), testutils.ObjectKey(testutils.NewPod(name, testNamespace)), testutils.NewPod(name, testNamespace))
+		if err != nil && !k8serrors.IsNotFound(err) {
+			t.Fatal(err)
+		}
+		return err == nil
+	}
+	assert.True(t, exists("kept"))
+	assert.False(t, exists("removed"))
+	assert.True(t, exists("other"))
+	assert.Len(t, applied, 2)
+
+	missingSelector := step(&harness.TestStep{Prune: true})
+	assert.Equal(t, []string{"prune requires a pruneSelector"}, errorStrings(missingSelector.Create(context.TODO(), testNamespace)))
+}
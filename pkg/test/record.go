@@ -0,0 +1,119 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// recordFields are removed from recorded objects in addition to the golden fields, they are assigned by the cluster
+// and differ between test runs, e.g. the addresses, nodes and start times of pods, or change on every reconcile.
+var recordFields = []string{
+	"metadata.generation",
+	"spec.nodeName",
+	"spec.clusterIP",
+	"spec.clusterIPs",
+	"spec.ports[*].nodePort",
+	"status.observedGeneration",
+	"status.conditions[*].lastTransitionTime",
+	"status.conditions[*].lastUpdateTime",
+	"status.conditions[*].lastProbeTime",
+	"status.conditions[*].lastHeartbeatTime",
+	"status.hostIP",
+	"status.podIP",
+	"status.podIPs",
+	"status.startTime",
+	"status.containerStatuses[*].containerID",
+	"status.containerStatuses[*].imageID",
+	"status.containerStatuses[*].lastState",
+	"status.containerStatuses[*].restartCount",
+	"status.containerStatuses[*].started",
+	"status.containerStatuses[*].state",
+	"status.initContainerStatuses[*].containerID",
+	"status.initContainerStatuses[*].imageID",
+	"status.initContainerStatuses[*].lastState",
+	"status.initContainerStatuses[*].restartCount",
+	"status.initContainerStatuses[*].state",
+}
+
+// RecordAsserts writes the objects applied by the step, as they are in the cluster, to the assert file of the step.
+// An existing assert file is not overwritten.
+func (s *Step) RecordAsserts(ctx context.Context, namespace string) error {
+	if len(s.Apply) == 0 {
+		return nil
+	}
+
+	path := filepath.Join(s.Dir, fmt.Sprintf("%02d-assert.yaml", s.Index))
+	if _, err := os.Stat(path); err == nil {
+		s.Logger.Logf("not recording asserts, %s already exists", path)
+		return nil
+	}
+
+	cl, err := s.Client(false)
+	if err != nil {
+		return err
+	}
+
+	dClient, err := s.DiscoveryClient()
+	if err != nil {
+		return err
+	}
+
+	recorded := &bytes.Buffer{}
+
+	for index, obj := range s.Apply {
+		actual, err := s.recordObject(ctx, cl, dClient, obj, namespace)
+		if err != nil {
+			return err
+		}
+
+		if index > 0 {
+			recorded.WriteString("---\n")
+		}
+		if err := testutils.MarshalObject(actual, recorded); err != nil {
+			return err
+		}
+	}
+
+	if err := ioutil.WriteFile(path, recorded.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	s.addArtifact(path)
+	s.Logger.Logf("recorded %d objects to %s", len(s.Apply), path)
+	return nil
+}
+
+// recordObject fetches an applied object without the fields which differ between test runs.
+func (s *Step) recordObject(ctx context.Context, cl client.Client, dClient discovery.DiscoveryInterface, obj runtime.Object, namespace string) (*unstructured.Unstructured, error) {
+	cl, dClient, err := s.objectClients(cl, dClient, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	key := testutils.ObjectKey(obj)
+
+	actual := testutils.NewResource(gvk.GroupVersion().String(), gvk.Kind, key.Name, "").(*unstructured.Unstructured)
+	if _, _, err := testutils.Namespaced(dClient, actual, namespace); err != nil {
+		return nil, err
+	}
+
+	if err := cl.Get(ctx, testutils.ObjectKey(actual), actual); err != nil {
+		return nil, fmt.Errorf("recording %s: %w", testutils.ResourceID(actual), err)
+	}
+
+	fields := append(append([]string{}, goldenFields...), recordFields...)
+	testutils.RemoveFields(actual.Object, append(fields, s.ignoredFields()...))
+	return actual, nil
+}
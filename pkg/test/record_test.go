@@ -0,0 +1,107 @@
+package test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestStepRecordAsserts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-record")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme, &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello", Namespace: testNamespace, UID: "1234"},
+		Spec:       corev1.ServiceSpec{ClusterIP: "10.0.0.1", Type: corev1.ServiceTypeClusterIP},
+	}, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello", Namespace: testNamespace, Generation: 1},
+		Spec:       corev1.PodSpec{NodeName: "node-1", Containers: []corev1.Container{{Name: "hello", Image: "hello"}}},
+		Status: corev1.PodStatus{
+			Phase:     corev1.PodRunning,
+			PodIP:     "10.1.0.1",
+			HostIP:    "172.18.0.2",
+			StartTime: &metav1.Time{Time: time.Now()},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "hello",
+				Image:        "hello",
+				ImageID:      "docker.io/library/hello@sha256:1234",
+				ContainerID:  "containerd://1234",
+				Ready:        true,
+				RestartCount: 1,
+				State:        corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.Now()}},
+			}},
+		},
+	})
+
+	step := Step{
+		Index:  1,
+		Dir:    dir,
+		Logger: testutils.NewTestLogger(t, ""),
+		Apply:  []runtime.Object{testutils.NewResource("v1", "Service", "hello", ""), testutils.NewResource("v1", "Pod", "hello", "")},
+		Client: func(bool) (client.Client, error) {
+			return cl, nil
+		},
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) {
+			return testutils.FakeDiscoveryClient(), nil
+		},
+	}
+
+	require.NoError(t, step.RecordAsserts(context.TODO(), testNamespace))
+
+	path := filepath.Join(dir, "01-assert.yaml")
+	recorded, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: hello
+spec:
+  type: ClusterIP
+status:
+  loadBalancer: {}
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: hello
+spec:
+  containers:
+  - image: hello
+    name: hello
+    resources: {}
+status:
+  containerStatuses:
+  - image: hello
+    name: hello
+    ready: true
+  phase: Running
+`, string(recorded))
+
+	// the recorded assert file passes
+	require.NoError(t, step.LoadYAML(path))
+	assert.Equal(t, 2, len(step.Asserts))
+	assert.Equal(t, []error{}, step.Check(context.TODO(), testNamespace))
+
+	// existing assert files are not overwritten
+	require.NoError(t, ioutil.WriteFile(path, []byte("edited"), 0644))
+	require.NoError(t, step.RecordAsserts(context.TODO(), testNamespace))
+	recorded, err = ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "edited", string(recorded))
+}
@@ -0,0 +1,661 @@
+package test
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	petname "github.com/dustinkirkland/golang-petname"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	"github.com/kudobuilder/kuttl/pkg/env"
+	"github.com/kudobuilder/kuttl/pkg/http"
+	"github.com/kudobuilder/kuttl/pkg/report"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+const (
+	defaultRemoteImage     = "kudobuilder/kuttl"
+	defaultRemoteNamespace = "kuttl-runner"
+	// defaultRemoteServiceAccount is the default service account of the runner Jobs.
+	defaultRemoteServiceAccount = "kuttl-runner"
+	// remoteSuiteDir is the directory the ConfigMap of a remote run is mounted to in the runner Jobs.
+	remoteSuiteDir = "/kuttl"
+	// remoteArtifactsDir is the artifacts directory of the test suite in the runner Jobs.
+	remoteArtifactsDir = "/tmp/artifacts"
+	// remoteReportMarker separates the test logs of a runner Job from its base64 encoded junit report.
+	remoteReportMarker = "--- kuttl report ---"
+	// maxRemoteSuiteSize is the size limit of the packaged test suite, the limit of the size of a ConfigMap.
+	maxRemoteSuiteSize = 1024 * 1024
+)
+
+// remoteScript is run by the runner Jobs, it unpacks the test suite, runs it and prints the report after the
+// test logs.
+var remoteScript = `mkdir -p /tmp/suite && cd /tmp/suite && tar -xzf ` + remoteSuiteDir + `/suite.tgz || exit 1
+kubectl-kuttl test --config ` + remoteSuiteDir + `/kuttl-test.yaml --artifacts-dir ` + remoteArtifactsDir + ` --report xml "$@"
+status=$?
+echo "` + remoteReportMarker + `"
+base64 ` + remoteArtifactsDir + `/kuttl-test.xml
+exit $status
+`
+
+// GetRemoteImage returns the image of the runner Jobs of a remote run.
+func (h *Harness) GetRemoteImage() string {
+	if h.TestSuite.RemoteImage != "" {
+		return h.TestSuite.RemoteImage
+	}
+	return defaultRemoteImage
+}
+
+// GetRemoteNamespace returns the namespace of the runner Jobs of a remote run.
+func (h *Harness) GetRemoteNamespace() string {
+	if h.TestSuite.RemoteNamespace != "" {
+		return h.TestSuite.RemoteNamespace
+	}
+	return defaultRemoteNamespace
+}
+
+// GetRemoteServiceAccount returns the service account of the runner Jobs of a remote run.
+func (h *Harness) GetRemoteServiceAccount() string {
+	if h.TestSuite.RemoteServiceAccount != "" {
+		return h.TestSuite.RemoteServiceAccount
+	}
+	return defaultRemoteServiceAccount
+}
+
+// remoteShards returns the number of runner Jobs of a remote run, one for each shard.
+func (h *Harness) remoteShards() int {
+	if h.TestSuite.ShardCount > 1 {
+		return h.TestSuite.ShardCount
+	}
+	return 1
+}
+
+// RunRemote runs the test suite inside the cluster, see TestSuite.Remote. The logs of the runner Jobs are written
+// to the test log as they happen, the reports of the shards are written to the artifacts directory and merged into
+// the report of the test suite. On SIGINT or SIGTERM the runner Jobs are deleted.
+func (h *Harness) RunRemote() {
+	ctx, stop := h.signalContext()
+	defer stop()
+	defer h.Stop()
+
+	if err := h.runRemote(ctx); err != nil {
+		h.T.Error(err)
+	}
+}
+
+func (h *Harness) runRemote(ctx context.Context) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	suite, dirs, err := remoteTestSuite(h.TestSuite, cwd)
+	if err != nil {
+		return err
+	}
+	stepPaths, err := remoteStepPaths(h.TestSuite, cwd)
+	if err != nil {
+		return err
+	}
+	dirs = append(dirs, stepPaths...)
+	suiteYAML, err := yaml.Marshal(suite)
+	if err != nil {
+		return err
+	}
+	archive, err := remoteArchive(cwd, dirs)
+	if err != nil {
+		return fmt.Errorf("packaging test suite: %w", err)
+	}
+	if size := len(archive) + len(suiteYAML); size > maxRemoteSuiteSize {
+		return fmt.Errorf("packaged test suite is %d bytes, larger than the %d bytes a ConfigMap can hold", size, maxRemoteSuiteSize)
+	}
+
+	cl, err := h.Client(false)
+	if err != nil {
+		return err
+	}
+	cfg, err := h.Config()
+	if err != nil {
+		return err
+	}
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	namespace := h.GetRemoteNamespace()
+	// the objects created for the runner Jobs are deleted after the Jobs, also if creating some of them failed
+	created, err := h.createRunnerServiceAccount(ctx, cl, namespace)
+	defer h.deleteRemoteRun(cl, created)
+	if err != nil {
+		return err
+	}
+
+	run := "kuttl-" + petname.Generate(2, "-")
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: run, Namespace: namespace, Labels: remoteLabels(run)},
+		Data:       map[string]string{"kuttl-test.yaml": string(suiteYAML)},
+		BinaryData: map[string][]byte{"suite.tgz": archive},
+	}
+	if err := cl.Create(ctx, configMap); err != nil {
+		return fmt.Errorf("creating %s: %w", testutils.ResourceID(configMap), err)
+	}
+	h.T.Logf("running %d shards of the test suite in namespace %s with image %s", h.remoteShards(), namespace, h.GetRemoteImage())
+
+	jobs := []*batchv1.Job{}
+	for i := 0; i < h.remoteShards(); i++ {
+		jobs = append(jobs, h.newRunnerJob(run, namespace, i))
+	}
+	defer h.deleteRemoteRun(cl, append([]runtime.Object{configMap}, jobObjects(jobs)...))
+
+	reports := make([][]byte, len(jobs))
+	errs := make([]error, len(jobs))
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		go func(i int, job *batchv1.Job) {
+			defer wg.Done()
+			reports[i], errs[i] = h.runRunnerJob(ctx, cl, clientset, job, i)
+		}(i, job)
+	}
+	wg.Wait()
+
+	return h.reportRemote(reports, errs)
+}
+
+// reportRemote writes the reports of the shards of a remote run to the artifacts directory and merges them into the
+// report of the test suite. It fails the run if any shard failed.
+func (h *Harness) reportRemote(shardReports [][]byte, errs []error) error {
+	reports := []*report.Testsuites{}
+	for i, contents := range shardReports {
+		if errs[i] != nil {
+			h.T.Errorf("shard %d: %v", i, errs[i])
+		}
+		if len(contents) == 0 {
+			continue
+		}
+
+		path := filepath.Join(h.TestSuite.ArtifactsDir, fmt.Sprintf("%s-shard-%d.xml", h.TestSuite.ReportName, i))
+		if err := ioutil.WriteFile(path, contents, 0644); err != nil {
+			return err
+		}
+		ts, err := report.ReadXML(path)
+		if err != nil {
+			return err
+		}
+		reports = append(reports, ts)
+	}
+
+	if len(h.TestSuite.ReportFormat) == 0 || len(reports) == 0 {
+		return nil
+	}
+	merged := report.Merge(h.TestSuite.Name, reports)
+	return merged.Write(h.TestSuite.ArtifactsDir, h.TestSuite.ReportName, report.Type(h.TestSuite.ReportFormat))
+}
+
+// remoteTestSuite returns the test suite run by the runner Jobs and the directories it needs relative to the working
+// directory. The options which depend on the local machine are cleared.
+func remoteTestSuite(suite harness.TestSuite, cwd string) (harness.TestSuite, []string, error) {
+	remote := *suite.DeepCopy()
+	remote.APIVersion = "kuttl.dev/v1beta1"
+	remote.Kind = "TestSuite"
+
+	remote.Remote = false
+	remote.RemoteImage = ""
+	remote.RemoteNamespace = ""
+	remote.StartControlPlane = false
+	remote.ControlPlaneArgs = nil
+	remote.StartKIND = false
+	remote.ClusterProvider = ""
+	remote.Provision = nil
+	remote.KINDConfig = ""
+	remote.KINDNodeCache = false
+	remote.KINDContainers = nil
+	remote.KINDImages = nil
+	remote.KINDCluster = nil
+	remote.ShardCount = 0
+	remote.ShardIndex = 0
+	remote.ArtifactsDir = ""
+	remote.ReportFormat = ""
+	remote.HistoryFile = ""
+	remote.FailedFile = ""
+	remote.RerunFailed = false
+	remote.Resume = false
+	remote.PauseOnFailure = 0
+	remote.DebugShell = false
+
+	// the kubeconfigs of the local machine are not available in the runner Jobs
+	for name, cluster := range remote.Clusters {
+		if cluster.Kubeconfig != "" {
+			return remote, nil, fmt.Errorf("the kubeconfig of cluster %s can not be used by a remote run", name)
+		}
+	}
+
+	dirs := []string{}
+	relative := func(dir string) (string, error) {
+		abs := dir
+		if !filepath.IsAbs(dir) {
+			abs = filepath.Join(cwd, dir)
+		}
+		rel, err := filepath.Rel(cwd, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", fmt.Errorf("directory %s is not in the working directory, it can not be run remotely", dir)
+		}
+		dirs = append(dirs, rel)
+		return rel, nil
+	}
+
+	var err error
+	if remote.CRDDir != "" {
+		if remote.CRDDir, err = relative(remote.CRDDir); err != nil {
+			return remote, nil, err
+		}
+	}
+	// the local CRDs are packaged, glob patterns are expanded to the matching files and directories
+	crds := []string{}
+	for _, source := range remote.CRDs {
+		if http.IsOCIReference(source) || http.IsURL(source) {
+			crds = append(crds, source)
+			continue
+		}
+		pattern := source
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(cwd, pattern)
+		}
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			return remote, nil, err
+		}
+		for _, path := range paths {
+			rel, err := relative(path)
+			if err != nil {
+				return remote, nil, err
+			}
+			crds = append(crds, rel)
+		}
+	}
+	remote.CRDs = crds
+	for i, dir := range remote.ManifestDirs {
+		if remote.ManifestDirs[i], err = relative(dir); err != nil {
+			return remote, nil, err
+		}
+	}
+	for i, dir := range remote.TestDirs {
+		if remote.TestDirs[i], err = relative(dir); err != nil {
+			return remote, nil, err
+		}
+	}
+
+	return remote, dirs, nil
+}
+
+// remoteStepPaths returns the paths relative to the working directory of the files applied and asserted by the
+// TestSteps of the test suite, they may be outside of the test directories, e.g. objects shared by several tests.
+// The paths are packaged at the same place relative to the test directories, so they are resolved like locally.
+func remoteStepPaths(suite harness.TestSuite, cwd string) ([]string, error) {
+	// all the tests are run by the shards of the remote run
+	suite.ShardCount = 0
+	suite.RerunFailed = false
+	tests, err := (&Harness{TestSuite: suite}).DiscoverTests("")
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+	for _, test := range tests {
+		for _, step := range test.Steps {
+			if step.Step == nil {
+				continue
+			}
+			stepPaths := append(append(append([]string{}, step.Step.Apply...), step.Step.Assert...), step.Step.Error...)
+			for _, generate := range step.Step.Generate {
+				stepPaths = append(append(append(stepPaths, generate.Apply...), generate.Assert...), generate.Error...)
+			}
+
+			for _, path := range stepPaths {
+				path = env.ExpandWithMap(path, step.values)
+				if http.IsOCIReference(path) || http.IsURL(path) {
+					continue
+				}
+				abs := filepath.Join(step.Dir, path)
+				if !filepath.IsAbs(abs) {
+					abs = filepath.Join(cwd, abs)
+				}
+				rel, err := filepath.Rel(cwd, abs)
+				if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+					return nil, fmt.Errorf("test %s step %s: path %s is not in the working directory, it can not be run remotely", test.Name, step.Name, path)
+				}
+				paths = append(paths, rel)
+			}
+		}
+	}
+	return paths, nil
+}
+
+// remoteArchive packages directories relative to the working directory into a gzipped tar archive.
+func remoteArchive(cwd string, dirs []string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+
+	added := map[string]bool{}
+	for _, dir := range dirs {
+		err := filepath.Walk(filepath.Join(cwd, dir), func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() && !info.IsDir() {
+				return nil
+			}
+
+			name, err := filepath.Rel(cwd, path)
+			if err != nil {
+				return err
+			}
+			name = filepath.ToSlash(name)
+			// a directory may be contained in another one, e.g. a test directory of the manifest directory
+			if added[name] {
+				return nil
+			}
+			added[name] = true
+
+			header, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err
+			}
+			header.Name = name
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.Copy(tw, f)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// remoteLabels are the labels of the objects of a remote run.
+func remoteLabels(run string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name": "kuttl",
+		"kuttl.dev/run":          run,
+	}
+}
+
+// createRunnerServiceAccount creates the namespace of the runner Jobs unless it exists. If RemoteClusterRole is set,
+// it creates their service account and binds it to the ClusterRole unless they exist, otherwise the service account
+// must exist. It returns the objects it created, in the order they are deleted in.
+func (h *Harness) createRunnerServiceAccount(ctx context.Context, cl client.Client, namespace string) ([]runtime.Object, error) {
+	serviceAccount := h.GetRemoteServiceAccount()
+	objs := []runtime.Object{&corev1.Namespace{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+		ObjectMeta: metav1.ObjectMeta{Name: namespace},
+	}}
+	if h.TestSuite.RemoteClusterRole != "" {
+		objs = append(objs,
+			&corev1.ServiceAccount{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+				ObjectMeta: metav1.ObjectMeta{Name: serviceAccount, Namespace: namespace},
+			},
+			&rbacv1.ClusterRoleBinding{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+				ObjectMeta: metav1.ObjectMeta{Name: serviceAccount + "-" + namespace},
+				RoleRef: rbacv1.RoleRef{
+					APIGroup: rbacv1.GroupName,
+					Kind:     "ClusterRole",
+					Name:     h.TestSuite.RemoteClusterRole,
+				},
+				Subjects: []rbacv1.Subject{{
+					Kind:      rbacv1.ServiceAccountKind,
+					Name:      serviceAccount,
+					Namespace: namespace,
+				}},
+			},
+		)
+	}
+
+	created := []runtime.Object{}
+	for _, obj := range objs {
+		err := cl.Create(ctx, obj)
+		if k8serrors.IsAlreadyExists(err) {
+			continue
+		}
+		if err != nil {
+			return created, fmt.Errorf("creating %s: %w", testutils.ResourceID(obj), err)
+		}
+		h.T.Logf("created %s for the remote run", testutils.ResourceID(obj))
+		// the objects are deleted in reverse order, the namespace last
+		created = append([]runtime.Object{obj}, created...)
+	}
+
+	if h.TestSuite.RemoteClusterRole == "" {
+		sa := &corev1.ServiceAccount{}
+		if err := cl.Get(ctx, client.ObjectKey{Name: serviceAccount, Namespace: namespace}, sa); err != nil {
+			if k8serrors.IsNotFound(err) {
+				return created, fmt.Errorf("service account %s/%s of the remote run does not exist, create it or set remoteClusterRole", namespace, serviceAccount)
+			}
+			return created, err
+		}
+	}
+	return created, nil
+}
+
+// newRunnerJob creates the runner Job of a shard of a remote run.
+func (h *Harness) newRunnerJob(run, namespace string, shard int) *batchv1.Job {
+	args := []string{}
+	if h.remoteShards() > 1 {
+		args = append(args, "--shard-index", fmt.Sprint(shard), "--shard-count", fmt.Sprint(h.remoteShards()))
+	}
+
+	// the runner is run exactly once, a retry would run the tests again
+	backoffLimit := int32(0)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%d", run, shard),
+			Namespace: namespace,
+			Labels:    remoteLabels(run),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: remoteLabels(run)},
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: h.GetRemoteServiceAccount(),
+					Containers: []corev1.Container{{
+						Name:    "kuttl",
+						Image:   h.GetRemoteImage(),
+						Command: append([]string{"sh", "-c", remoteScript, "kuttl"}, args...),
+						// an empty KUBECONFIG makes kuttl use the service account of the pod
+						Env: []corev1.EnvVar{{Name: "KUBECONFIG", Value: ""}},
+						VolumeMounts: []corev1.VolumeMount{{
+							Name:      "suite",
+							MountPath: remoteSuiteDir,
+						}},
+					}},
+					Volumes: []corev1.Volume{{
+						Name: "suite",
+						VolumeSource: corev1.VolumeSource{
+							ConfigMap: &corev1.ConfigMapVolumeSource{
+								LocalObjectReference: corev1.LocalObjectReference{Name: run},
+							},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+// runRunnerJob creates a runner Job and streams its logs to the test log until it completes. It returns the junit
+// report printed by the runner.
+func (h *Harness) runRunnerJob(ctx context.Context, cl client.Client, clientset kubernetes.Interface, job *batchv1.Job, shard int) ([]byte, error) {
+	if err := cl.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("creating job %s: %w", testutils.ResourceID(job), err)
+	}
+
+	pod, err := waitForRunnerPod(ctx, cl, job)
+	if err != nil {
+		return nil, err
+	}
+
+	logs, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Follow: true}).Stream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("streaming logs of pod %s: %w", pod.Name, err)
+	}
+	defer logs.Close()
+
+	contents, err := parseRunnerLogs(logs, func(line string) {
+		h.T.Logf("shard %d: %s", shard, line)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading logs of pod %s: %w", pod.Name, err)
+	}
+
+	err = wait.PollImmediateUntil(time.Second, func() (bool, error) {
+		if err := cl.Get(ctx, testutils.ObjectKey(job), job); err != nil {
+			return false, err
+		}
+		return job.Status.Succeeded > 0 || job.Status.Failed > 0, nil
+	}, ctx.Done())
+	if err != nil {
+		return contents, fmt.Errorf("waiting for job %s: %w", testutils.ResourceID(job), err)
+	}
+	if job.Status.Failed > 0 {
+		return contents, fmt.Errorf("job %s failed", testutils.ResourceID(job))
+	}
+	return contents, nil
+}
+
+// waitForRunnerPod waits for the pod of a runner Job to start, a pod which can not start fails.
+func waitForRunnerPod(ctx context.Context, cl client.Client, job *batchv1.Job) (*corev1.Pod, error) {
+	var pod *corev1.Pod
+
+	err := wait.PollImmediateUntil(time.Second, func() (bool, error) {
+		pods := &corev1.PodList{}
+		if err := cl.List(ctx, pods, client.InNamespace(job.Namespace), client.MatchingLabels{"job-name": job.Name}); err != nil {
+			return false, err
+		}
+
+		for i := range pods.Items {
+			switch pods.Items[i].Status.Phase {
+			case corev1.PodRunning, corev1.PodSucceeded, corev1.PodFailed:
+				pod = &pods.Items[i]
+				return true, nil
+			}
+
+			for _, container := range pods.Items[i].Status.ContainerStatuses {
+				if waiting := container.State.Waiting; waiting != nil && isRunnerStartFailure(waiting.Reason) {
+					return false, fmt.Errorf("pod %s of job %s can not start: %s: %s", pods.Items[i].Name, job.Name, waiting.Reason, waiting.Message)
+				}
+			}
+		}
+		return false, nil
+	}, ctx.Done())
+	if err == wait.ErrWaitTimeout {
+		return nil, fmt.Errorf("waiting for pod of job %s: %w", testutils.ResourceID(job), ctx.Err())
+	}
+	return pod, err
+}
+
+// isRunnerStartFailure checks if the reason of a waiting container means that it does not start without changes.
+func isRunnerStartFailure(reason string) bool {
+	switch reason {
+	case "ErrImagePull", "ImagePullBackOff", "InvalidImageName", "CreateContainerConfigError":
+		return true
+	}
+	return false
+}
+
+// parseRunnerLogs passes the lines of the logs of a runner Job before the report marker to log, and returns the
+// decoded report after it.
+func parseRunnerLogs(r io.Reader, log func(line string)) ([]byte, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	encoded := strings.Builder{}
+	inReport := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case inReport:
+			encoded.WriteString(strings.TrimSpace(line))
+		case line == remoteReportMarker:
+			inReport = true
+		default:
+			log(line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if encoded.Len() == 0 {
+		return nil, nil
+	}
+	return base64.StdEncoding.DecodeString(encoded.String())
+}
+
+// deleteRemoteRun deletes objects of a remote run, e.g. its ConfigMap and Jobs, unless SkipDelete is set.
+func (h *Harness) deleteRemoteRun(cl client.Client, objs []runtime.Object) {
+	if h.TestSuite.SkipDelete {
+		return
+	}
+
+	for _, obj := range objs {
+		err := cl.Delete(context.TODO(), obj, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !k8serrors.IsNotFound(err) {
+			h.T.Logf("error deleting %s: %v", testutils.ResourceID(obj), err)
+		}
+	}
+}
+
+// jobObjects returns Jobs as a list of objects.
+func jobObjects(jobs []*batchv1.Job) []runtime.Object {
+	objs := []runtime.Object{}
+	for _, job := range jobs {
+		objs = append(objs, job)
+	}
+	return objs
+}
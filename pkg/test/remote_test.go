@@ -0,0 +1,231 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	"github.com/kudobuilder/kuttl/pkg/file"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestParseRunnerLogs(t *testing.T) {
+	report := `<testsuites name="kuttl"></testsuites>`
+	encoded := base64.StdEncoding.EncodeToString([]byte(report))
+
+	for _, tt := range []struct {
+		name     string
+		logs     string
+		lines    []string
+		expected string
+	}{
+		{
+			name:     "report",
+			logs:     "=== RUN kuttl\n--- PASS: kuttl\n" + remoteReportMarker + "\n" + encoded[:10] + "\n" + encoded[10:] + "\n",
+			lines:    []string{"=== RUN kuttl", "--- PASS: kuttl"},
+			expected: report,
+		},
+		{
+			name:  "no report",
+			logs:  "fatal error\n" + remoteReportMarker + "\n",
+			lines: []string{"fatal error"},
+		},
+		{
+			name:  "no marker",
+			logs:  "tar: error\n",
+			lines: []string{"tar: error"},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			lines := []string{}
+			contents, err := parseRunnerLogs(strings.NewReader(tt.logs), func(line string) {
+				lines = append(lines, line)
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.lines, lines)
+			assert.Equal(t, tt.expected, string(contents))
+		})
+	}
+}
+
+func TestRemoteTestSuite(t *testing.T) {
+	suite := harness.TestSuite{
+		CRDDir:       "crds",
+		ManifestDirs: []string{"/work/manifests"},
+		TestDirs:     []string{"./tests/e2e"},
+		StartKIND:    true,
+		Remote:       true,
+		ShardCount:   3,
+		ArtifactsDir: "/tmp/artifacts",
+		ReportFormat: "xml",
+		Parallel:     4,
+	}
+
+	remote, dirs, err := remoteTestSuite(suite, "/work")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"crds", "manifests", filepath.Join("tests", "e2e")}, dirs)
+	assert.Equal(t, "crds", remote.CRDDir)
+	assert.Equal(t, []string{"manifests"}, remote.ManifestDirs)
+	assert.Equal(t, []string{filepath.Join("tests", "e2e")}, remote.TestDirs)
+	assert.Equal(t, "TestSuite", remote.Kind)
+	assert.False(t, remote.StartKIND)
+	assert.False(t, remote.Remote)
+	assert.Equal(t, 0, remote.ShardCount)
+	assert.Equal(t, "", remote.ArtifactsDir)
+	assert.Equal(t, 4, remote.Parallel)
+	// the test suite is not modified
+	assert.Equal(t, []string{"/work/manifests"}, suite.ManifestDirs)
+
+	suite.TestDirs = []string{"../tests"}
+	_, _, err = remoteTestSuite(suite, "/work")
+	assert.EqualError(t, err, "directory ../tests is not in the working directory, it can not be run remotely")
+
+	suite.TestDirs = []string{"tests"}
+	suite.Clusters = map[string]harness.Cluster{"other": {Kubeconfig: "/home/user/.kube/other"}}
+	_, _, err = remoteTestSuite(suite, "/work")
+	assert.EqualError(t, err, "the kubeconfig of cluster other can not be used by a remote run")
+}
+
+func TestRemoteStepPaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-remote")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cwd := filepath.Join(dir, "work")
+	pod := "apiVersion: v1\nkind: Pod\nmetadata:\n  name: test\n"
+	require.NoError(t, os.MkdirAll(filepath.Join(cwd, "tests", "a"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(cwd, "shared"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "outside"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(cwd, "shared", "pod.yaml"), []byte(pod), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "outside", "pod.yaml"), []byte(pod), 0644))
+
+	step := "apiVersion: kuttl.dev/v1beta1\nkind: TestStep\napply:\n- ../../shared/pod.yaml\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(cwd, "tests", "a", "00-install.yaml"), []byte(step), 0644))
+
+	suite := harness.TestSuite{TestDirs: []string{filepath.Join(cwd, "tests")}}
+	paths, err := remoteStepPaths(suite, cwd)
+	require.NoError(t, err)
+	// the paths outside of the test directories are packaged
+	assert.Equal(t, []string{filepath.Join("shared", "pod.yaml")}, paths)
+
+	step = "apiVersion: kuttl.dev/v1beta1\nkind: TestStep\nassert:\n- ../../../outside/pod.yaml\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(cwd, "tests", "a", "00-install.yaml"), []byte(step), 0644))
+	_, err = remoteStepPaths(suite, cwd)
+	assert.EqualError(t, err, "test a step install: path ../../../outside/pod.yaml is not in the working directory, it can not be run remotely")
+}
+
+func TestRemoteTestSuiteCRDs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-remote")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "crds"), 0755))
+	for _, name := range []string{"a.yaml", "b.yaml"} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "crds", name), []byte(testCRD(name)), 0644))
+	}
+
+	suite := harness.TestSuite{
+		CRDs: []string{"crds/*.yaml", "https://example.com/crds.yaml", "oci://ghcr.io/example/crds:v1"},
+	}
+
+	remote, dirs, err := remoteTestSuite(suite, dir)
+	require.NoError(t, err)
+	// the local CRDs are packaged, URLs and OCI artifacts are loaded by the remote run
+	local := []string{filepath.Join("crds", "a.yaml"), filepath.Join("crds", "b.yaml")}
+	assert.Equal(t, local, dirs)
+	assert.Equal(t, append(local, "https://example.com/crds.yaml", "oci://ghcr.io/example/crds:v1"), remote.CRDs)
+}
+
+func TestRemoteArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-remote")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "tests", "a"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "tests", "a", "00-install.yaml"), []byte("install"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "other.yaml"), []byte("other"), 0644))
+
+	// a directory contained in another one is archived once
+	archive, err := remoteArchive(dir, []string{"tests", filepath.Join("tests", "a")})
+	require.NoError(t, err)
+
+	dest := filepath.Join(dir, "dest")
+	require.NoError(t, file.UnTar(dest, bytes.NewReader(archive), true))
+
+	contents, err := ioutil.ReadFile(filepath.Join(dest, "tests", "a", "00-install.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "install", string(contents))
+	assert.NoFileExists(t, filepath.Join(dest, "other.yaml"))
+}
+
+func TestNewRunnerJob(t *testing.T) {
+	h := Harness{TestSuite: harness.TestSuite{ShardCount: 2, RemoteImage: "kuttl:dev"}}
+
+	job := h.newRunnerJob("kuttl-run", "runner", 1)
+	assert.Equal(t, "kuttl-run-1", job.Name)
+	assert.Equal(t, "runner", job.Namespace)
+
+	container := job.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "kuttl:dev", container.Image)
+	assert.Equal(t, []string{"--shard-index", "1", "--shard-count", "2"}, container.Command[4:])
+	assert.Equal(t, "kuttl-run", job.Spec.Template.Spec.Volumes[0].ConfigMap.Name)
+
+	h.TestSuite.ShardCount = 0
+	h.TestSuite.RemoteImage = ""
+	job = h.newRunnerJob("kuttl-run", "runner", 0)
+	assert.Len(t, job.Spec.Template.Spec.Containers[0].Command, 4)
+	assert.Equal(t, "kudobuilder/kuttl", job.Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestCreateRunnerServiceAccount(t *testing.T) {
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "existing"}})
+	h := Harness{T: t}
+
+	// without a cluster role the service account must exist, the namespace is created
+	created, err := h.createRunnerServiceAccount(context.TODO(), cl, "runner")
+	assert.EqualError(t, err, "service account runner/kuttl-runner of the remote run does not exist, create it or set remoteClusterRole")
+	require.Len(t, created, 1)
+	assert.Equal(t, "Namespace:/runner", testutils.ResourceID(created[0]))
+
+	require.NoError(t, cl.Create(context.TODO(), &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "tester", Namespace: "existing"}}))
+	h.TestSuite.RemoteServiceAccount = "tester"
+	created, err = h.createRunnerServiceAccount(context.TODO(), cl, "existing")
+	require.NoError(t, err)
+	assert.Empty(t, created)
+
+	// with a cluster role the service account and the binding are created and deleted after the run
+	h.TestSuite.RemoteServiceAccount = ""
+	h.TestSuite.RemoteClusterRole = "edit"
+	created, err = h.createRunnerServiceAccount(context.TODO(), cl, "existing")
+	require.NoError(t, err)
+	require.Len(t, created, 2)
+
+	binding := &rbacv1.ClusterRoleBinding{}
+	require.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "kuttl-runner-existing"}, binding))
+	assert.Equal(t, "edit", binding.RoleRef.Name)
+
+	h.deleteRemoteRun(cl, created)
+	err = cl.Get(context.TODO(), client.ObjectKey{Name: "kuttl-runner-existing"}, binding)
+	assert.True(t, k8serrors.IsNotFound(err))
+	err = cl.Get(context.TODO(), client.ObjectKey{Name: "kuttl-runner", Namespace: "existing"}, &corev1.ServiceAccount{})
+	assert.True(t, k8serrors.IsNotFound(err))
+	// the namespace existed before the run
+	assert.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: "existing"}, &corev1.Namespace{}))
+}
@@ -0,0 +1,198 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// defaultStorageClassAnnotation marks the default StorageClass of a cluster.
+const defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+// unmetRequirement checks the requirements of the test case or of one of its test steps against the cluster. It
+// returns the reason of the first requirement which is not met, it is empty if all requirements are met. The commands
+// of the requirements run in the namespace.
+func (t *Case) unmetRequirement(ctx context.Context, requires *harness.Requirements, namespace string) (string, error) {
+	if requires == nil {
+		return "", nil
+	}
+
+	if len(requires.APIGroups) > 0 || len(requires.CRDs) > 0 || requires.MinServerVersion != "" {
+		dClient, err := t.DiscoveryClient()
+		if err != nil {
+			return "", err
+		}
+
+		if reason, err := unmetDiscoveryRequirement(dClient, requires); reason != "" || err != nil {
+			return reason, err
+		}
+	}
+
+	if requires.StorageClass != "" || requires.DefaultStorageClass {
+		cl, err := t.Client(false)
+		if err != nil {
+			return "", err
+		}
+
+		if reason, err := unmetStorageClassRequirement(ctx, cl, requires); reason != "" || err != nil {
+			return reason, err
+		}
+	}
+
+	for _, cmd := range requires.Commands {
+		if cmd.Background {
+			return "", fmt.Errorf("required command %q can not run in the background", cmd.Command)
+		}
+
+		if _, err := testutils.RunCommand(ctx, namespace, cmd, t.Dir, t.Logger, t.Logger, t.Logger, t.Timeout, t.Env); err != nil {
+			if ctx.Err() != nil {
+				return "", err
+			}
+			name := cmd.Command
+			if name == "" {
+				name = cmd.Script
+			}
+			return fmt.Sprintf("required command %q failed: %v", name, err), nil
+		}
+	}
+
+	return "", nil
+}
+
+// requirements returns the requirements of the TestStep of a step, it is nil if the step has no TestStep.
+func (s *Step) requirements() *harness.Requirements {
+	if s.Step == nil {
+		return nil
+	}
+	return s.Step.Requires
+}
+
+// unmetDiscoveryRequirement checks the required API groups, resources and server version with the discovery client.
+func unmetDiscoveryRequirement(dClient discovery.DiscoveryInterface, requires *harness.Requirements) (string, error) {
+	if len(requires.APIGroups) > 0 || len(requires.CRDs) > 0 {
+		groups, err := dClient.ServerGroups()
+		if err != nil {
+			return "", err
+		}
+
+		// the served versions of the groups, the legacy core group is ""
+		served := map[string][]string{}
+		for _, group := range groups.Groups {
+			for _, groupVersion := range group.Versions {
+				served[group.Name] = append(served[group.Name], groupVersion.GroupVersion)
+			}
+		}
+
+		for _, apiGroup := range requires.APIGroups {
+			if !servesAPIGroup(served, apiGroup) {
+				return fmt.Sprintf("required API group %s is not served", apiGroup), nil
+			}
+		}
+
+		for _, crd := range requires.CRDs {
+			ok, err := servesResource(dClient, served, crd)
+			if err != nil {
+				return "", err
+			}
+			if !ok {
+				return fmt.Sprintf("required resource %s is not served", crd), nil
+			}
+		}
+	}
+
+	if requires.MinServerVersion != "" {
+		minVersion, err := version.ParseGeneric(requires.MinServerVersion)
+		if err != nil {
+			return "", fmt.Errorf("invalid minimum server version %q: %w", requires.MinServerVersion, err)
+		}
+
+		info, err := dClient.ServerVersion()
+		if err != nil {
+			return "", err
+		}
+
+		serverVersion, err := version.ParseGeneric(info.GitVersion)
+		if err != nil {
+			return "", fmt.Errorf("invalid server version %q: %w", info.GitVersion, err)
+		}
+
+		if !serverVersion.AtLeast(minVersion) {
+			return fmt.Sprintf("server version %s is older than the required version %s", info.GitVersion, requires.MinServerVersion), nil
+		}
+	}
+
+	return "", nil
+}
+
+// servesAPIGroup checks if an API group ("group") or group version ("group/version") is served.
+func servesAPIGroup(served map[string][]string, apiGroup string) bool {
+	if !strings.Contains(apiGroup, "/") {
+		_, ok := served[apiGroup]
+		return ok
+	}
+
+	gv, err := schema.ParseGroupVersion(apiGroup)
+	if err != nil {
+		return false
+	}
+	for _, groupVersion := range served[gv.Group] {
+		if groupVersion == apiGroup {
+			return true
+		}
+	}
+	return false
+}
+
+// servesResource checks if a resource ("plural.group" like the name of a CRD, or "plural" for the core group) is
+// served in any version of its group.
+func servesResource(dClient discovery.DiscoveryInterface, served map[string][]string, name string) (bool, error) {
+	gr := schema.ParseGroupResource(name)
+
+	for _, groupVersion := range served[gr.Group] {
+		resources, err := dClient.ServerResourcesForGroupVersion(groupVersion)
+		if err != nil {
+			return false, err
+		}
+		for _, resource := range resources.APIResources {
+			if resource.Name == gr.Resource {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// unmetStorageClassRequirement checks the required StorageClass and default StorageClass.
+func unmetStorageClassRequirement(ctx context.Context, cl client.Client, requires *harness.Requirements) (string, error) {
+	storageClasses := &storagev1.StorageClassList{}
+	if err := cl.List(ctx, storageClasses); err != nil {
+		return "", err
+	}
+
+	found, foundDefault := false, false
+	for _, storageClass := range storageClasses.Items {
+		if storageClass.Name == requires.StorageClass {
+			found = true
+		}
+		if storageClass.Annotations[defaultStorageClassAnnotation] == "true" {
+			foundDefault = true
+		}
+	}
+
+	if requires.StorageClass != "" && !found {
+		return fmt.Sprintf("required StorageClass %s does not exist", requires.StorageClass), nil
+	}
+	if requires.DefaultStorageClass && !foundDefault {
+		return "no default StorageClass exists", nil
+	}
+	return "", nil
+}
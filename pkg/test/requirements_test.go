@@ -0,0 +1,139 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	"github.com/kudobuilder/kuttl/pkg/report"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestUnmetRequirement(t *testing.T) {
+	dClient := testutils.FakeDiscoveryClient()
+	dClient.(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.18.2+k3s1"}
+
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme, &storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "standard"},
+	})
+
+	c := &Case{
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return dClient, nil },
+		Logger:          testutils.NewTestLogger(t, ""),
+	}
+
+	for _, tt := range []struct {
+		name     string
+		requires *harness.Requirements
+		reason   string
+		err      string
+	}{
+		{name: "no requirements"},
+		{name: "api group", requires: &harness.Requirements{APIGroups: []string{"apps"}}},
+		{name: "group version", requires: &harness.Requirements{APIGroups: []string{"batch/v1beta1"}}},
+		{
+			name:     "missing api group",
+			requires: &harness.Requirements{APIGroups: []string{"monitoring.coreos.com"}},
+			reason:   "required API group monitoring.coreos.com is not served",
+		},
+		{
+			name:     "missing group version",
+			requires: &harness.Requirements{APIGroups: []string{"apps/v1beta2"}},
+			reason:   "required API group apps/v1beta2 is not served",
+		},
+		{name: "resource", requires: &harness.Requirements{CRDs: []string{"statefulset.apps"}}},
+		{name: "core resource", requires: &harness.Requirements{CRDs: []string{"pod"}}},
+		{
+			name:     "missing resource",
+			requires: &harness.Requirements{CRDs: []string{"servicemonitors.monitoring.coreos.com"}},
+			reason:   "required resource servicemonitors.monitoring.coreos.com is not served",
+		},
+		{name: "server version", requires: &harness.Requirements{MinServerVersion: "1.18"}},
+		{
+			name:     "old server version",
+			requires: &harness.Requirements{MinServerVersion: "v1.19.0"},
+			reason:   "server version v1.18.2+k3s1 is older than the required version v1.19.0",
+		},
+		{
+			name:     "invalid server version",
+			requires: &harness.Requirements{MinServerVersion: "latest"},
+			err:      `invalid minimum server version "latest": could not parse "latest" as version`,
+		},
+		{name: "storage class", requires: &harness.Requirements{StorageClass: "standard"}},
+		{
+			name:     "missing storage class",
+			requires: &harness.Requirements{StorageClass: "fast"},
+			reason:   "required StorageClass fast does not exist",
+		},
+		{
+			name:     "missing default storage class",
+			requires: &harness.Requirements{DefaultStorageClass: true},
+			reason:   "no default StorageClass exists",
+		},
+		{name: "command", requires: &harness.Requirements{Commands: []harness.Command{{Command: "true"}}}},
+		{
+			name:     "failed command",
+			requires: &harness.Requirements{Commands: []harness.Command{{Command: "false"}}},
+			reason:   `required command "false" failed: exit status 1`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			reason, err := c.unmetRequirement(context.TODO(), tt.requires, testNamespace)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestRunSkipsStepWithUnmetRequirements(t *testing.T) {
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme)
+
+	c := &Case{
+		Name:               "requirements",
+		PreferredNamespace: testNamespace,
+		SkipDelete:         true,
+		Suppress:           []string{"events"},
+		Steps: []*Step{
+			{
+				Name:  "monitoring",
+				Index: 1,
+				Step: &harness.TestStep{
+					Requires: &harness.Requirements{APIGroups: []string{"monitoring.coreos.com"}},
+					Commands: []harness.Command{{Command: "false"}},
+				},
+			},
+			{
+				Name:  "create",
+				Index: 2,
+			},
+		},
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+		Logger:          testutils.NewTestLogger(t, "requirements"),
+	}
+
+	tc := report.NewCase(c.Name)
+	c.Run(context.TODO(), t, tc)
+	assert.Nil(t, tc.Failure)
+
+	steps := report.NewJSONReport(suiteOf(tc)).Suites[0].Cases[0].Steps
+	assert.Equal(t, report.StatusSkipped, steps[0].Status)
+	assert.Equal(t, "required API group monitoring.coreos.com is not served", steps[0].SkipReason)
+	assert.Equal(t, report.StatusPassed, steps[1].Status)
+}
@@ -0,0 +1,101 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// failedTest is a failed test case of a test directory.
+type failedTest struct {
+	Suite string `json:"suite"`
+	Name  string `json:"name"`
+}
+
+// addFailed records a failed test case of the run.
+func (h *Harness) addFailed(suite, name string) {
+	h.failedLock.Lock()
+	defer h.failedLock.Unlock()
+
+	h.failed = append(h.failed, failedTest{Suite: suite, Name: name})
+}
+
+// hasFailed checks if any test case of the run failed.
+func (h *Harness) hasFailed() bool {
+	h.failedLock.Lock()
+	defer h.failedLock.Unlock()
+
+	return len(h.failed) > 0
+}
+
+// failuresExceeded checks if the number of failed test cases reached the maximum number of failures.
+func (h *Harness) failuresExceeded() bool {
+	h.failedLock.Lock()
+	defer h.failedLock.Unlock()
+
+	max := h.GetMaxFailures()
+	return max > 0 && len(h.failed) >= max
+}
+
+// saveFailed writes the failed test cases of the run to TestSuite.FailedFile if it is set, replacing the failed test
+// cases of the previous run.
+func (h *Harness) saveFailed() error {
+	if h.TestSuite.FailedFile == "" {
+		return nil
+	}
+
+	h.failedLock.Lock()
+	defer h.failedLock.Unlock()
+
+	failed := append([]failedTest{}, h.failed...)
+	sort.Slice(failed, func(i, j int) bool {
+		if failed[i].Suite == failed[j].Suite {
+			return failed[i].Name < failed[j].Name
+		}
+		return failed[i].Suite < failed[j].Suite
+	})
+
+	contents, err := json.MarshalIndent(failed, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.TestSuite.FailedFile), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(h.TestSuite.FailedFile, contents, 0644)
+}
+
+// loadFailed reads the failed test cases saved by the previous run, there are none if the file does not exist.
+func loadFailed(path string) ([]failedTest, error) {
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	failed := []failedTest{}
+	if err := json.Unmarshal(contents, &failed); err != nil {
+		return nil, fmt.Errorf("reading failed tests from %s: %w", path, err)
+	}
+	return failed, nil
+}
+
+// filterFailed returns the test cases of a test directory which are in the failed test cases.
+func filterFailed(tests []*Case, suite string, failed []failedTest) []*Case {
+	filtered := []*Case{}
+	for _, test := range tests {
+		for _, f := range failed {
+			if f.Suite == suite && f.Name == test.Name {
+				filtered = append(filtered, test)
+				break
+			}
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,50 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveAndLoadFailed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-rerun")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ".kuttl", "last-failed.json")
+
+	// nothing failed before the first run
+	failed, err := loadFailed(path)
+	require.NoError(t, err)
+	assert.Empty(t, failed)
+
+	h := Harness{}
+	h.addFailed("suite-b", "test")
+	h.addFailed("suite-a", "test-2")
+	h.addFailed("suite-a", "test-1")
+
+	// the failed test cases are not saved without a file
+	require.NoError(t, h.saveFailed())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	h.TestSuite.FailedFile = path
+	require.NoError(t, h.saveFailed())
+
+	failed, err = loadFailed(path)
+	require.NoError(t, err)
+	assert.Equal(t, []failedTest{
+		{Suite: "suite-a", Name: "test-1"},
+		{Suite: "suite-a", Name: "test-2"},
+		{Suite: "suite-b", Name: "test"},
+	}, failed)
+
+	tests := []*Case{{Name: "test"}, {Name: "test-1"}, {Name: "test-3"}}
+	assert.Equal(t, []*Case{tests[1]}, filterFailed(tests, "suite-a", failed))
+	assert.Equal(t, []*Case{tests[0]}, filterFailed(tests, "suite-b", failed))
+	assert.Empty(t, filterFailed(tests, "suite-c", failed))
+}
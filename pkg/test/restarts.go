@@ -0,0 +1,182 @@
+package test
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+)
+
+// restartCounts are the restarts of containers by the UID of their pod and their name, see containerRestarts.
+type restartCounts map[string]int32
+
+// record adds the restarts of the containers of pods which are not recorded yet.
+func (r restartCounts) record(pods []corev1.Pod) {
+	for i := range pods {
+		for name, restarts := range containerRestarts(&pods[i]) {
+			key := fmt.Sprintf("%s/%s", pods[i].UID, name)
+			if _, ok := r[key]; !ok {
+				r[key] = restarts
+			}
+		}
+	}
+}
+
+// containerStatuses returns the statuses of the init containers and containers of a pod.
+func containerStatuses(pod *corev1.Pod) []corev1.ContainerStatus {
+	return append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+}
+
+// containerRestarts returns the restarts of the containers of a pod by their name. A container which was OOMKilled
+// and not restarted, e.g. with the Never restart policy, counts as restarted.
+func containerRestarts(pod *corev1.Pod) map[string]int32 {
+	restarts := map[string]int32{}
+	for _, status := range containerStatuses(pod) {
+		restarts[status.Name] = status.RestartCount
+		if terminated := status.State.Terminated; terminated != nil && terminated.Reason == "OOMKilled" {
+			restarts[status.Name]++
+		}
+	}
+	return restarts
+}
+
+// lastTermination describes the last termination of a container, e.g. "OOMKilled (exit code 137)".
+func lastTermination(status corev1.ContainerStatus) string {
+	terminated := status.State.Terminated
+	if terminated == nil {
+		terminated = status.LastTerminationState.Terminated
+	}
+	if terminated == nil {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s (exit code %d)", terminated.Reason, terminated.ExitCode)
+}
+
+// restartPods lists the pods of a restart assert.
+func restartPods(ctx context.Context, cl client.Client, expected harness.RestartAssert, namespace string) ([]corev1.Pod, error) {
+	if expected.Namespace != "" {
+		namespace = expected.Namespace
+	}
+
+	selector, err := labels.Parse(expected.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector %q of restart assert: %w", expected.Selector, err)
+	}
+
+	pods := &corev1.PodList{}
+	if err := cl.List(ctx, pods, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("listing the pods of selector %q: %w", expected.Selector, err)
+	}
+	return pods.Items, nil
+}
+
+// recordTestRestarts records the restarts of the pods of the restart asserts counted since the start of the test case
+// before its first step, the asserts of templated steps are not loaded yet. A failure is only logged, the restarts are
+// then recorded when the steps start.
+func (t *Case) recordTestRestarts(ctx context.Context, namespace string) {
+	cl, err := t.Client(false)
+	if err != nil {
+		t.Logger.Logf("recording the restarts of the test case: %v", err)
+		return
+	}
+
+	for _, testStep := range t.Steps {
+		for _, expected := range testStep.restartAsserts() {
+			if !expected.SinceTestStart {
+				continue
+			}
+
+			pods, err := restartPods(ctx, cl, expected, namespace)
+			if err != nil {
+				t.Logger.Logf("recording the restarts of the test case: %v", err)
+				continue
+			}
+			t.restarts.record(pods)
+		}
+	}
+}
+
+// restartAsserts returns the restart asserts of the TestAssert.
+func (s *Step) restartAsserts() []harness.RestartAssert {
+	if s.Assert == nil {
+		return nil
+	}
+	return s.Assert.Restarts
+}
+
+// RecordRestarts records the restarts of the pods of the restart asserts when the step starts, they are counted
+// from there on by CheckRestarts. The restarts counted since the start of the test case are only recorded if the
+// test case did not record them yet.
+func (s *Step) RecordRestarts(ctx context.Context, namespace string) error {
+	s.restarts = restartCounts{}
+	if len(s.restartAsserts()) == 0 {
+		return nil
+	}
+
+	cl, err := s.Client(false)
+	if err != nil {
+		return err
+	}
+
+	for _, expected := range s.restartAsserts() {
+		pods, err := restartPods(ctx, cl, expected, namespace)
+		if err != nil {
+			return err
+		}
+
+		s.restarts.record(pods)
+		if s.testRestarts != nil {
+			s.testRestarts.record(pods)
+		}
+	}
+
+	return nil
+}
+
+// CheckRestarts checks that no container of the pods of the restart asserts restarted since they were recorded, see
+// RecordRestarts. The containers of pods created since then must not have restarted at all.
+func (s *Step) CheckRestarts(ctx context.Context, namespace string) []error {
+	if len(s.restartAsserts()) == 0 {
+		return nil
+	}
+
+	cl, err := s.Client(false)
+	if err != nil {
+		return []error{err}
+	}
+
+	errs := []error{}
+
+	for _, expected := range s.restartAsserts() {
+		pods, err := restartPods(ctx, cl, expected, namespace)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		baseline, since := s.restarts, "step"
+		if expected.SinceTestStart && s.testRestarts != nil {
+			baseline, since = s.testRestarts, "test case"
+		}
+
+		for i := range pods {
+			pod := &pods[i]
+			restarts := containerRestarts(pod)
+			for _, status := range containerStatuses(pod) {
+				if expected.Container != "" && expected.Container != status.Name {
+					continue
+				}
+				if restarted := restarts[status.Name] - baseline[fmt.Sprintf("%s/%s", pod.UID, status.Name)]; restarted > 0 {
+					errs = append(errs, fmt.Errorf("container %s of pod %s/%s restarted %d times since the start of the %s, last termination: %s",
+						status.Name, pod.Namespace, pod.Name, restarted, since, lastTermination(status)))
+				}
+			}
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,95 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func restartPod(name string, restarts int32) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, UID: types.UID(name), Labels: map[string]string{"app": "operator"}},
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{{Name: "init"}},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "manager", RestartCount: restarts},
+				{Name: "proxy", RestartCount: restarts},
+			},
+		},
+	}
+}
+
+// restartContainer restarts a container of a pod of the test namespace which was OOMKilled.
+func restartContainer(t *testing.T, cl client.Client, name string, container int) {
+	pod := &corev1.Pod{}
+	require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: name}, pod))
+	pod.Status.ContainerStatuses[container].RestartCount++
+	pod.Status.ContainerStatuses[container].LastTerminationState.Terminated = &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}
+	require.NoError(t, cl.Update(context.TODO(), pod))
+}
+
+func errorStrings(errs []error) []string {
+	var strs []string
+	for _, err := range errs {
+		strs = append(strs, err.Error())
+	}
+	return strs
+}
+
+func TestStepCheckRestarts(t *testing.T) {
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme, restartPod("operator-0", 2), restartPod("operator-1", 0))
+	testRestarts := restartCounts{}
+	testRestarts.record([]corev1.Pod{*restartPod("operator-0", 1)})
+
+	step := &Step{
+		Logger: testutils.NewTestLogger(t, ""),
+		Assert: &harness.TestAssert{Restarts: []harness.RestartAssert{
+			{Selector: "app=operator"},
+			{Selector: "app=operator", Container: "manager", SinceTestStart: true},
+		}},
+		Client:       func(bool) (client.Client, error) { return cl, nil },
+		testRestarts: testRestarts,
+	}
+	require.NoError(t, step.RecordRestarts(context.TODO(), testNamespace))
+
+	// the restarts before the step only count since the start of the test case
+	assert.Equal(t, []string{
+		"container manager of pod world/operator-0 restarted 1 times since the start of the test case, last termination: unknown",
+	}, errorStrings(step.CheckRestarts(context.TODO(), testNamespace)))
+
+	restartContainer(t, cl, "operator-1", 1)
+	require.NoError(t, cl.Create(context.TODO(), restartPod("operator-2", 1)))
+	assert.Equal(t, []string{
+		"container proxy of pod world/operator-1 restarted 1 times since the start of the step, last termination: OOMKilled (exit code 137)",
+		"container manager of pod world/operator-2 restarted 1 times since the start of the step, last termination: unknown",
+		"container proxy of pod world/operator-2 restarted 1 times since the start of the step, last termination: unknown",
+		"container manager of pod world/operator-0 restarted 1 times since the start of the test case, last termination: unknown",
+		"container manager of pod world/operator-2 restarted 1 times since the start of the test case, last termination: unknown",
+	}, errorStrings(step.CheckRestarts(context.TODO(), testNamespace)))
+
+	// a container OOMKilled without a restart counts as restarted
+	pod := &corev1.Pod{}
+	require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: "operator-2"}, pod))
+	pod.Status.InitContainerStatuses[0].State.Terminated = &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}
+	require.NoError(t, cl.Update(context.TODO(), pod))
+	step.Assert.Restarts = []harness.RestartAssert{{Selector: "app=operator", Container: "init"}}
+	assert.Equal(t, []string{
+		"container init of pod world/operator-2 restarted 1 times since the start of the step, last termination: OOMKilled (exit code 137)",
+	}, errorStrings(step.CheckRestarts(context.TODO(), testNamespace)))
+
+	step.Assert.Restarts = []harness.RestartAssert{{Selector: "app in operator"}}
+	errs := step.CheckRestarts(context.TODO(), testNamespace)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), `invalid selector "app in operator" of restart assert`)
+}
@@ -0,0 +1,129 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// progressPath is the file the progress of a run is saved to, relative to the working directory.
+var progressPath = filepath.Join(".kuttl", "progress.json")
+
+// progress of a run which is resumed by --resume if the run was interrupted.
+type progress struct {
+	// KINDContext is the KIND cluster started by the run, a resumed run re-attaches to it if it is still running.
+	KINDContext string `json:"kindContext,omitempty"`
+	// Passed are the test cases which passed, they are not run again by a resumed run.
+	Passed []failedTest `json:"passed"`
+}
+
+// loadProgress reads the progress saved by the previous run, it is empty if the file does not exist.
+func loadProgress(path string) (progress, error) {
+	p := progress{}
+
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return p, nil
+	}
+	if err != nil {
+		return p, err
+	}
+
+	if err := json.Unmarshal(contents, &p); err != nil {
+		return p, fmt.Errorf("reading progress from %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// saveProgress writes the progress of the run to a file, it is called whenever the progress changes so that it is
+// saved even if the run is killed.
+func (h *Harness) saveProgress(path string) error {
+	h.progressLock.Lock()
+	defer h.progressLock.Unlock()
+
+	contents, err := json.MarshalIndent(h.progress, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, contents, 0644)
+}
+
+// addPassed records a passed test case of the run and saves the progress if TestSuite.Resume is set.
+func (h *Harness) addPassed(suite, name string) {
+	if !h.TestSuite.Resume {
+		return
+	}
+
+	h.progressLock.Lock()
+	h.progress.Passed = append(h.progress.Passed, failedTest{Suite: suite, Name: name})
+	h.progressLock.Unlock()
+
+	if err := h.saveProgress(progressPath); err != nil {
+		h.T.Log("error saving progress", err)
+	}
+}
+
+// setProgressKINDContext records the KIND cluster of the run and saves the progress if TestSuite.Resume is set.
+func (h *Harness) setProgressKINDContext() {
+	if !h.TestSuite.Resume {
+		return
+	}
+
+	h.progressLock.Lock()
+	h.progress.KINDContext = h.TestSuite.KINDContext
+	h.progressLock.Unlock()
+
+	if err := h.saveProgress(progressPath); err != nil {
+		h.T.Log("error saving progress", err)
+	}
+}
+
+// resumesKIND checks if the run re-attaches to the running KIND cluster of the previous run.
+func (h *Harness) resumesKIND() bool {
+	if !h.TestSuite.Resume {
+		return false
+	}
+
+	previous, err := loadProgress(progressPath)
+	if err != nil {
+		h.T.Log("error loading progress", err)
+		return false
+	}
+	return previous.KINDContext != "" && previous.KINDContext == h.TestSuite.KINDContext
+}
+
+// keepsKIND checks if the KIND cluster of the run is kept on Stop for a resumed run to re-attach to, i.e. if the run
+// was interrupted and its progress is pending.
+func (h *Harness) keepsKIND() bool {
+	h.progressLock.Lock()
+	defer h.progressLock.Unlock()
+
+	return h.interrupted && h.progress.KINDContext != ""
+}
+
+// filterPassed returns the test cases of a test directory which are not in the passed test cases.
+func filterPassed(tests []*Case, suite string, passed []failedTest) []*Case {
+	filtered := []*Case{}
+	for _, test := range tests {
+		if !containsTest(passed, suite, test.Name) {
+			filtered = append(filtered, test)
+		}
+	}
+	return filtered
+}
+
+// containsTest checks if a test case of a test directory is in a list of test cases.
+func containsTest(tests []failedTest, suite, name string) bool {
+	for _, t := range tests {
+		if t.Suite == suite && t.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveAndLoadProgress(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-resume")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ".kuttl", "progress.json")
+
+	// there is no progress before the first run
+	p, err := loadProgress(path)
+	require.NoError(t, err)
+	assert.Equal(t, progress{}, p)
+
+	h := Harness{}
+	h.progress.KINDContext = "kind"
+	h.progress.Passed = []failedTest{{Suite: "suite-a", Name: "test-1"}, {Suite: "suite-b", Name: "test"}}
+	require.NoError(t, h.saveProgress(path))
+
+	p, err = loadProgress(path)
+	require.NoError(t, err)
+	assert.Equal(t, h.progress, p)
+
+	tests := []*Case{{Name: "test"}, {Name: "test-1"}, {Name: "test-2"}}
+	assert.Equal(t, []*Case{tests[0], tests[2]}, filterPassed(tests, "suite-a", p.Passed))
+	assert.Equal(t, []*Case{tests[1], tests[2]}, filterPassed(tests, "suite-b", p.Passed))
+	assert.Equal(t, tests, filterPassed(tests, "suite-c", p.Passed))
+}
+
+func TestProgressRequiresResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-resume")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer func() { require.NoError(t, os.Chdir(cwd)) }()
+
+	// the progress is not saved without --resume
+	h := Harness{T: t}
+	h.TestSuite.KINDContext = "kind"
+	h.setProgressKINDContext()
+	h.addPassed("suite", "test")
+	_, err = os.Stat(progressPath)
+	assert.True(t, os.IsNotExist(err))
+	h.interrupted = true
+	assert.False(t, h.keepsKIND())
+
+	h = Harness{T: t}
+	h.TestSuite.KINDContext = "kind"
+	h.TestSuite.Resume = true
+	h.setProgressKINDContext()
+	h.addPassed("suite", "test")
+	p, err := loadProgress(progressPath)
+	require.NoError(t, err)
+	assert.Equal(t, progress{KINDContext: "kind", Passed: []failedTest{{Suite: "suite", Name: "test"}}}, p)
+
+	// the KIND cluster is only kept if the run was interrupted
+	assert.False(t, h.keepsKIND())
+	h.interrupted = true
+	assert.True(t, h.keepsKIND())
+}
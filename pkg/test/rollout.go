@@ -0,0 +1,85 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// restartedAtAnnotation is the annotation of the pod template `kubectl rollout restart` sets to restart the pods.
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// RunRestart restarts the workloads of the TestStep.Restart list in order and waits for their rollouts. If a workload
+// is not rolled out within its timeout, the following ones are skipped. Waiting stops if the context is done.
+func (s *Step) RunRestart(ctx context.Context, namespace string) []error {
+	if s.Step == nil || len(s.Step.Restart) == 0 {
+		return nil
+	}
+
+	cl, err := s.Client(false)
+	if err != nil {
+		return []error{err}
+	}
+
+	for _, restart := range s.Step.Restart {
+		kind := workloadKind(restart.Kind)
+		if kind == "" || kind == "ReplicaSet" {
+			return []error{fmt.Errorf("restart of %s requires a kind of Deployment, StatefulSet or DaemonSet, got %q", restart.Name, restart.Kind)}
+		}
+
+		key := types.NamespacedName{Namespace: namespace, Name: restart.Name}
+		if restart.Namespace != "" {
+			key.Namespace = restart.Namespace
+		}
+
+		timeout := s.GetTimeout()
+		if restart.Timeout > 0 {
+			timeout = restart.Timeout
+		}
+
+		if err := s.restartWorkload(ctx, cl, kind, key, timeout); err != nil {
+			return []error{err}
+		}
+	}
+
+	return nil
+}
+
+// restartWorkload restarts the pods of a workload like `kubectl rollout restart` and waits for its rollout to complete.
+func (s *Step) restartWorkload(ctx context.Context, cl client.Client, kind string, key types.NamespacedName, timeout int) error {
+	description := fmt.Sprintf("%s %s", kind, key)
+
+	// the pod template is patched, an update would conflict with the status updates of the controller
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("apps/v1")
+	obj.SetKind(kind)
+	obj.SetNamespace(key.Namespace)
+	obj.SetName(key.Name)
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{restartedAtAnnotation: time.Now().Format(time.RFC3339)},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := cl.Patch(ctx, obj, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return fmt.Errorf("restarting %s: %w", description, err)
+	}
+
+	s.Logger.Logf("%s restarted, waiting for its rollout", description)
+	if err := s.waitForRollout(ctx, cl, kind, key, timeout); err != nil {
+		return err
+	}
+	s.Logger.Logf("%s rolled out", description)
+	return nil
+}
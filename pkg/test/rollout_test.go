@@ -0,0 +1,59 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestRunRestart(t *testing.T) {
+	replicas := int32(2)
+	cl := generationClient{fake.NewFakeClientWithScheme(scheme.Scheme, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "operator", Namespace: testNamespace, Generation: 1},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+	})}
+
+	key := types.NamespacedName{Namespace: testNamespace, Name: "operator"}
+	stop := rollOutDeployment(cl, key)
+
+	step := &Step{
+		Name:    "restart",
+		Index:   1,
+		Timeout: 10,
+		Logger:  testutils.NewTestLogger(t, ""),
+		Step: &harness.TestStep{
+			Restart: []harness.Restart{{Kind: "deploy", Name: "operator"}},
+		},
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+	}
+	require.Empty(t, step.RunRestart(context.TODO(), testNamespace))
+	assert.Equal(t, []int32{2}, stop())
+
+	deployment := &appsv1.Deployment{}
+	require.NoError(t, cl.Get(context.TODO(), key, deployment))
+	assert.Contains(t, deployment.Spec.Template.Annotations, restartedAtAnnotation)
+
+	step.Step.Restart = []harness.Restart{{Kind: "ReplicaSet", Name: "operator-1234"}}
+	errs := step.RunRestart(context.TODO(), testNamespace)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], `restart of operator-1234 requires a kind of Deployment, StatefulSet or DaemonSet, got "ReplicaSet"`)
+
+	step.Step.Restart = []harness.Restart{{Kind: "Deployment", Name: "operator", Timeout: 1}}
+	errs = step.RunRestart(context.TODO(), testNamespace)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "Deployment "+testNamespace+"/operator is not rolled out within 1 sec timeout: the latest generation is not observed")
+}
@@ -0,0 +1,129 @@
+package test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// stepTemplate is the test step file of a new test step, it applies an example object.
+const stepTemplate = `apiVersion: kuttl.dev/v1beta1
+kind: TestStep
+# Commands to run before the objects of this file are applied.
+commands: []
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %[1]s
+data:
+  step: %[1]s
+`
+
+// assertTemplate is the assert file of a new test step, it asserts the example object of the step.
+const assertTemplate = `apiVersion: kuttl.dev/v1beta1
+kind: TestAssert
+timeout: 30
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %[1]s
+data:
+  step: %[1]s
+`
+
+// NewTestCase creates the directory of a new test case in the test directory with a first test step.
+// It returns the paths of the created files.
+func NewTestCase(testDir, name string) ([]string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return nil, fmt.Errorf("invalid test case name %q", name)
+	}
+
+	dir := filepath.Join(testDir, name)
+	if _, err := os.Stat(dir); err == nil {
+		return nil, fmt.Errorf("test case %s already exists", dir)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return NewTestStep(dir, "install", 0)
+}
+
+// NewTestStep creates the step and assert files of a new test step in a test case directory.
+// If the index is negative, the step is added after the last existing test step.
+// It returns the paths of the created files.
+func NewTestStep(caseDir, name string, index int) ([]string, error) {
+	// the name is used for the example object of the step
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid test step name %q: %s", name, strings.Join(errs, ", "))
+	}
+	if name == "assert" || name == "errors" {
+		return nil, fmt.Errorf("invalid test step name %q: it is reserved for assert and error files", name)
+	}
+
+	if index < 0 {
+		next, err := nextStepIndex(caseDir)
+		if err != nil {
+			return nil, err
+		}
+		index = next
+	}
+
+	files := []struct {
+		path     string
+		template string
+	}{
+		{path: filepath.Join(caseDir, fmt.Sprintf("%02d-%s.yaml", index, name)), template: stepTemplate},
+		{path: filepath.Join(caseDir, fmt.Sprintf("%02d-assert.yaml", index)), template: assertTemplate},
+	}
+
+	for _, file := range files {
+		if _, err := os.Stat(file.path); err == nil {
+			return nil, fmt.Errorf("test step file %s already exists", file.path)
+		}
+	}
+
+	created := []string{}
+	for _, file := range files {
+		if err := ioutil.WriteFile(file.path, []byte(fmt.Sprintf(file.template, name)), 0644); err != nil {
+			return created, err
+		}
+		created = append(created, file.path)
+	}
+
+	return created, nil
+}
+
+// nextStepIndex returns the index after the highest test step index of a test case directory.
+func nextStepIndex(caseDir string) (int, error) {
+	files, err := ioutil.ReadDir(caseDir)
+	if err != nil {
+		return 0, err
+	}
+
+	next := 0
+	for _, file := range files {
+		matches := testStepRegex.FindStringSubmatch(file.Name())
+		if len(matches) < 2 {
+			continue
+		}
+
+		index, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return 0, err
+		}
+		if index >= next {
+			next = index + 1
+		}
+	}
+
+	return next, nil
+}
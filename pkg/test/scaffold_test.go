@@ -0,0 +1,55 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestScaffold(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-scaffold")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	files, err := NewTestCase(dir, "my-test")
+	require.NoError(t, err)
+	caseDir := filepath.Join(dir, "my-test")
+	assert.Equal(t, []string{filepath.Join(caseDir, "00-install.yaml"), filepath.Join(caseDir, "00-assert.yaml")}, files)
+
+	_, err = NewTestCase(dir, "my-test")
+	assert.Error(t, err)
+
+	files, err = NewTestStep(caseDir, "update", -1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(caseDir, "01-update.yaml"), filepath.Join(caseDir, "01-assert.yaml")}, files)
+
+	files, err = NewTestStep(caseDir, "cleanup", 5)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(caseDir, "05-cleanup.yaml"), filepath.Join(caseDir, "05-assert.yaml")}, files)
+
+	for _, name := range []string{"", "assert", "Update", "my.step"} {
+		_, err = NewTestStep(caseDir, name, -1)
+		assert.Error(t, err, name)
+	}
+
+	// existing files are not overwritten
+	_, err = NewTestStep(caseDir, "other", 1)
+	assert.Error(t, err)
+
+	// the scaffolded test steps load with their example objects
+	test := &Case{Dir: caseDir, Logger: testutils.NewTestLogger(t, caseDir)}
+	require.NoError(t, test.LoadTestSteps())
+	require.Equal(t, 3, len(test.Steps))
+	for _, step := range test.Steps {
+		assert.NotNil(t, step.Step)
+		assert.NotNil(t, step.Assert)
+		assert.Equal(t, 1, len(step.Apply))
+		assert.Equal(t, 1, len(step.Asserts))
+	}
+}
@@ -0,0 +1,250 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// workloadKinds are the kinds of the workloads which are scaled and whose rollouts are waited for by their
+// lowercase names and the short names of kubectl.
+var workloadKinds = map[string]string{
+	"deployment":  "Deployment",
+	"deploy":      "Deployment",
+	"statefulset": "StatefulSet",
+	"sts":         "StatefulSet",
+	"replicaset":  "ReplicaSet",
+	"rs":          "ReplicaSet",
+	"daemonset":   "DaemonSet",
+	"ds":          "DaemonSet",
+}
+
+// workloadKind returns the kind of a workload of its name or short name, e.g. sts, it is empty if it is unknown.
+func workloadKind(name string) string {
+	return workloadKinds[strings.ToLower(name)]
+}
+
+// RunScale scales the workloads of the TestStep.Scale list in order and waits for their rollouts. If a workload
+// is not rolled out within its timeout, the following ones are skipped. Waiting stops if the context is done.
+func (s *Step) RunScale(ctx context.Context, namespace string) []error {
+	if s.Step == nil || len(s.Step.Scale) == 0 {
+		return nil
+	}
+
+	cl, err := s.Client(false)
+	if err != nil {
+		return []error{err}
+	}
+
+	for _, scale := range s.Step.Scale {
+		kind := workloadKind(scale.Kind)
+		if kind == "" || kind == "DaemonSet" {
+			return []error{fmt.Errorf("scale of %s requires a kind of Deployment, StatefulSet or ReplicaSet, got %q", scale.Name, scale.Kind)}
+		}
+
+		key := types.NamespacedName{Namespace: namespace, Name: scale.Name}
+		if scale.Namespace != "" {
+			key.Namespace = scale.Namespace
+		}
+
+		timeout := s.GetTimeout()
+		if scale.Timeout > 0 {
+			timeout = scale.Timeout
+		}
+
+		if err := s.scaleWorkload(ctx, cl, kind, key, scale.Replicas, timeout); err != nil {
+			return []error{err}
+		}
+	}
+
+	return nil
+}
+
+// scaleWorkload scales a workload to a number of replicas and waits for its rollout to complete.
+func (s *Step) scaleWorkload(ctx context.Context, cl client.Client, kind string, key types.NamespacedName, replicas int32, timeout int) error {
+	description := fmt.Sprintf("%s %s", kind, key)
+
+	// the replicas are patched, an update would conflict with the status updates of the controller
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("apps/v1")
+	obj.SetKind(kind)
+	obj.SetNamespace(key.Namespace)
+	obj.SetName(key.Name)
+	patch := client.RawPatch(types.MergePatchType, []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas)))
+	if err := cl.Patch(ctx, obj, patch); err != nil {
+		return fmt.Errorf("scaling %s to %d replicas: %w", description, replicas, err)
+	}
+
+	s.Logger.Logf("%s scaled to %d replicas, waiting for its rollout", description, replicas)
+	if err := s.waitForRollout(ctx, cl, kind, key, timeout); err != nil {
+		return err
+	}
+	s.Logger.Logf("%s rolled out", description)
+	return nil
+}
+
+// waitForRollout waits until the rollout of a workload completed like `kubectl rollout status`, see rolloutReason.
+func (s *Step) waitForRollout(ctx context.Context, cl client.Client, kind string, key types.NamespacedName, timeout int) error {
+	description := fmt.Sprintf("%s %s", kind, key)
+
+	reason := ""
+	waitCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+	err := wait.PollImmediateUntil(time.Second, func() (bool, error) {
+		var err error
+		reason, err = rolloutReason(ctx, cl, kind, key)
+		return reason == "", err
+	}, waitCtx.Done())
+
+	if err == wait.ErrWaitTimeout && ctx.Err() != nil {
+		return fmt.Errorf("waiting for the rollout of %s: %w", description, ctx.Err())
+	}
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("%s is not rolled out within %d sec timeout: %s", description, timeout, reason)
+	}
+	if err != nil {
+		return fmt.Errorf("waiting for the rollout of %s: %w", description, err)
+	}
+	return nil
+}
+
+// rolloutReason checks if the rollout of a workload completed with the logic of `kubectl rollout status`, it returns
+// why it did not or an error if it can not complete. A ReplicaSet is rolled out when its replicas are ready.
+func rolloutReason(ctx context.Context, cl client.Client, kind string, key types.NamespacedName) (string, error) {
+	var obj runtime.Object
+	switch kind {
+	case "Deployment":
+		obj = &appsv1.Deployment{}
+	case "StatefulSet":
+		obj = &appsv1.StatefulSet{}
+	case "DaemonSet":
+		obj = &appsv1.DaemonSet{}
+	default:
+		obj = &appsv1.ReplicaSet{}
+	}
+	if err := cl.Get(ctx, key, obj); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return "not found", nil
+		}
+		return "", err
+	}
+
+	switch workload := obj.(type) {
+	case *appsv1.Deployment:
+		return deploymentRolloutReason(workload)
+	case *appsv1.StatefulSet:
+		return statefulSetRolloutReason(workload)
+	case *appsv1.DaemonSet:
+		return daemonSetRolloutReason(workload)
+	default:
+		return replicaSetRolloutReason(obj.(*appsv1.ReplicaSet)), nil
+	}
+}
+
+func deploymentRolloutReason(deployment *appsv1.Deployment) (string, error) {
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return "the latest generation is not observed", nil
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			return "", fmt.Errorf("deployment %s exceeded its progress deadline", deployment.Name)
+		}
+	}
+
+	status := deployment.Status
+	switch {
+	case deployment.Spec.Replicas != nil && status.UpdatedReplicas < *deployment.Spec.Replicas:
+		return fmt.Sprintf("%d out of %d new replicas have been updated", status.UpdatedReplicas, *deployment.Spec.Replicas), nil
+	case status.Replicas > status.UpdatedReplicas:
+		return fmt.Sprintf("%d old replicas are pending termination", status.Replicas-status.UpdatedReplicas), nil
+	case status.AvailableReplicas < status.UpdatedReplicas:
+		return fmt.Sprintf("%d of %d updated replicas are available", status.AvailableReplicas, status.UpdatedReplicas), nil
+	default:
+		return "", nil
+	}
+}
+
+func statefulSetRolloutReason(sts *appsv1.StatefulSet) (string, error) {
+	if sts.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		return "", fmt.Errorf("the rollout of statefulset %s can only be waited for with the RollingUpdate strategy", sts.Name)
+	}
+	if sts.Status.ObservedGeneration == 0 || sts.Generation > sts.Status.ObservedGeneration {
+		return "the latest generation is not observed", nil
+	}
+
+	status := sts.Status
+	if sts.Spec.Replicas != nil && status.ReadyReplicas < *sts.Spec.Replicas {
+		return fmt.Sprintf("%d of %d replicas are ready", status.ReadyReplicas, *sts.Spec.Replicas), nil
+	}
+	if rollingUpdate := sts.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil && *rollingUpdate.Partition > 0 {
+		if sts.Spec.Replicas != nil && status.UpdatedReplicas < *sts.Spec.Replicas-*rollingUpdate.Partition {
+			return fmt.Sprintf("%d out of %d new replicas of the partition have been updated", status.UpdatedReplicas, *sts.Spec.Replicas-*rollingUpdate.Partition), nil
+		}
+		return "", nil
+	}
+	if status.UpdateRevision != status.CurrentRevision {
+		return fmt.Sprintf("%d replicas are at revision %s", status.UpdatedReplicas, status.UpdateRevision), nil
+	}
+	return "", nil
+}
+
+func daemonSetRolloutReason(ds *appsv1.DaemonSet) (string, error) {
+	if ds.Spec.UpdateStrategy.Type != appsv1.RollingUpdateDaemonSetStrategyType {
+		return "", fmt.Errorf("the rollout of daemonset %s can only be waited for with the RollingUpdate strategy", ds.Name)
+	}
+	if ds.Generation > ds.Status.ObservedGeneration {
+		return "the latest generation is not observed", nil
+	}
+
+	status := ds.Status
+	switch {
+	case status.UpdatedNumberScheduled < status.DesiredNumberScheduled:
+		return fmt.Sprintf("%d out of %d new pods have been updated", status.UpdatedNumberScheduled, status.DesiredNumberScheduled), nil
+	case status.NumberAvailable < status.DesiredNumberScheduled:
+		return fmt.Sprintf("%d of %d updated pods are available", status.NumberAvailable, status.DesiredNumberScheduled), nil
+	default:
+		return "", nil
+	}
+}
+
+func replicaSetRolloutReason(rs *appsv1.ReplicaSet) string {
+	replicas := int32(1)
+	if rs.Spec.Replicas != nil {
+		replicas = *rs.Spec.Replicas
+	}
+
+	switch {
+	case rs.Generation > rs.Status.ObservedGeneration:
+		return "the latest generation is not observed"
+	case rs.Status.Replicas != replicas:
+		return fmt.Sprintf("%d of %d replicas exist", rs.Status.Replicas, replicas)
+	case rs.Status.ReadyReplicas < replicas:
+		return fmt.Sprintf("%d of %d replicas are ready", rs.Status.ReadyReplicas, replicas)
+	default:
+		return ""
+	}
+}
+
+// rolloutCondition returns the description and the check of the WaitFor.Rollout condition, a kind and a name like
+// deployment/operator.
+func rolloutCondition(rollout, namespace string) (string, waitForCheck, error) {
+	parts := strings.SplitN(rollout, "/", 2)
+	kind := workloadKind(parts[0])
+	if len(parts) != 2 || parts[1] == "" || kind == "" || kind == "ReplicaSet" {
+		return "", nil, fmt.Errorf("invalid rollout %q, must be deployment/name, statefulset/name or daemonset/name", rollout)
+	}
+
+	key := types.NamespacedName{Namespace: namespace, Name: parts[1]}
+	return fmt.Sprintf("rollout of %s %s", kind, key), func(ctx context.Context, cl client.Client) (string, error) {
+		return rolloutReason(ctx, cl, kind, key)
+	}, nil
+}
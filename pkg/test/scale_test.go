@@ -0,0 +1,243 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// generationClient increments the generation of the patched objects like the API server.
+type generationClient struct {
+	client.Client
+}
+
+func (c generationClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if err := c.Client.Patch(ctx, obj, patch, opts...); err != nil {
+		return err
+	}
+	accessor := obj.(metav1.Object)
+	accessor.SetGeneration(accessor.GetGeneration() + 1)
+	return c.Client.Update(ctx, obj)
+}
+
+// rollOutDeployment acts like the deployment controller, it rolls out the replicas of a deployment when it observes
+// a new generation. The returned function stops it and returns the rolled out replicas.
+func rollOutDeployment(cl client.Client, key types.NamespacedName) func() []int32 {
+	ctx, cancel := context.WithCancel(context.TODO())
+	done := make(chan struct{})
+	rolledOut := []int32{}
+	go func() {
+		defer close(done)
+		for ctx.Err() == nil {
+			deployment := &appsv1.Deployment{}
+			if err := cl.Get(ctx, key, deployment); err == nil && deployment.Status.ObservedGeneration < deployment.Generation {
+				replicas := *deployment.Spec.Replicas
+				deployment.Status = appsv1.DeploymentStatus{
+					ObservedGeneration: deployment.Generation,
+					Replicas:           replicas,
+					UpdatedReplicas:    replicas,
+					AvailableReplicas:  replicas,
+				}
+				// a conflict with a patch is retried
+				if cl.Update(ctx, deployment) == nil {
+					rolledOut = append(rolledOut, replicas)
+				}
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}()
+	return func() []int32 {
+		cancel()
+		<-done
+		return rolledOut
+	}
+}
+
+func TestRunScale(t *testing.T) {
+	replicas := int32(1)
+	cl := generationClient{fake.NewFakeClientWithScheme(scheme.Scheme, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "operator", Namespace: testNamespace, Generation: 1},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+	})}
+
+	stop := rollOutDeployment(cl, types.NamespacedName{Namespace: testNamespace, Name: "operator"})
+
+	step := &Step{
+		Name:    "scale",
+		Index:   1,
+		Timeout: 10,
+		Logger:  testutils.NewTestLogger(t, ""),
+		Step: &harness.TestStep{
+			Scale: []harness.Scale{{Kind: "deploy", Name: "operator", Replicas: 3}},
+		},
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+	}
+	require.Empty(t, step.RunScale(context.TODO(), testNamespace))
+	assert.Equal(t, []int32{3}, stop())
+
+	step.Step.Scale = []harness.Scale{{Kind: "DaemonSet", Name: "agent", Replicas: 1}}
+	errs := step.RunScale(context.TODO(), testNamespace)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], `scale of agent requires a kind of Deployment, StatefulSet or ReplicaSet, got "DaemonSet"`)
+
+	step.Step.Scale = []harness.Scale{{Kind: "Deployment", Name: "operator", Replicas: 2, Timeout: 1}}
+	errs = step.RunScale(context.TODO(), testNamespace)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "Deployment "+testNamespace+"/operator is not rolled out within 1 sec timeout: the latest generation is not observed")
+}
+
+func TestRolloutReason(t *testing.T) {
+	replicas := int32(3)
+	partition := int32(2)
+
+	for _, test := range []struct {
+		name   string
+		obj    runtime.Object
+		reason string
+		err    string
+	}{
+		{
+			name: "deployment rolled out",
+			obj: &appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: &replicas},
+				Status: appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3},
+			},
+		},
+		{
+			name: "deployment updating",
+			obj: &appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: &replicas},
+				Status: appsv1.DeploymentStatus{Replicas: 4, UpdatedReplicas: 1, AvailableReplicas: 3},
+			},
+			reason: "1 out of 3 new replicas have been updated",
+		},
+		{
+			name: "deployment terminating old replicas",
+			obj: &appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: &replicas},
+				Status: appsv1.DeploymentStatus{Replicas: 4, UpdatedReplicas: 3, AvailableReplicas: 3},
+			},
+			reason: "1 old replicas are pending termination",
+		},
+		{
+			name: "deployment progress deadline exceeded",
+			obj: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "operator"},
+				Status: appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentProgressing, Reason: "ProgressDeadlineExceeded"},
+				}},
+			},
+			err: "deployment operator exceeded its progress deadline",
+		},
+		{
+			name: "statefulset not ready",
+			obj: &appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{
+					Replicas:       &replicas,
+					UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+				},
+				Status: appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 2},
+			},
+			reason: "2 of 3 replicas are ready",
+		},
+		{
+			name: "statefulset partition rolled out",
+			obj: &appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: &replicas,
+					UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+						Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+						RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+					},
+				},
+				Status: appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 3, UpdatedReplicas: 1, UpdateRevision: "db-2", CurrentRevision: "db-1"},
+			},
+		},
+		{
+			name: "statefulset updating revision",
+			obj: &appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{
+					Replicas:       &replicas,
+					UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+				},
+				Status: appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 3, UpdatedReplicas: 1, UpdateRevision: "db-2", CurrentRevision: "db-1"},
+			},
+			reason: "1 replicas are at revision db-2",
+		},
+		{
+			name: "statefulset on delete",
+			obj: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "db"},
+				Spec:       appsv1.StatefulSetSpec{UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}},
+			},
+			err: "the rollout of statefulset db can only be waited for with the RollingUpdate strategy",
+		},
+		{
+			name: "daemonset unavailable",
+			obj: &appsv1.DaemonSet{
+				Spec:   appsv1.DaemonSetSpec{UpdateStrategy: appsv1.DaemonSetUpdateStrategy{Type: appsv1.RollingUpdateDaemonSetStrategyType}},
+				Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 2},
+			},
+			reason: "2 of 3 updated pods are available",
+		},
+		{
+			name: "replicaset scaling",
+			obj: &appsv1.ReplicaSet{
+				Spec:   appsv1.ReplicaSetSpec{Replicas: &replicas},
+				Status: appsv1.ReplicaSetStatus{Replicas: 3, ReadyReplicas: 1},
+			},
+			reason: "1 of 3 replicas are ready",
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			var reason string
+			var err error
+			switch obj := test.obj.(type) {
+			case *appsv1.Deployment:
+				reason, err = deploymentRolloutReason(obj)
+			case *appsv1.StatefulSet:
+				reason, err = statefulSetRolloutReason(obj)
+			case *appsv1.DaemonSet:
+				reason, err = daemonSetRolloutReason(obj)
+			case *appsv1.ReplicaSet:
+				reason = replicaSetRolloutReason(obj)
+			}
+
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.reason, reason)
+		})
+	}
+}
+
+func TestRolloutCondition(t *testing.T) {
+	description, _, err := rolloutCondition("sts/db", testNamespace)
+	require.NoError(t, err)
+	assert.Equal(t, "rollout of StatefulSet "+testNamespace+"/db", description)
+
+	for _, rollout := range []string{"db", "replicaset/db", "pod/db", "deployment/"} {
+		_, _, err := rolloutCondition(rollout, testNamespace)
+		assert.EqualError(t, err, `invalid rollout "`+rollout+`", must be deployment/name, statefulset/name or daemonset/name`)
+	}
+}
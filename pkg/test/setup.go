@@ -0,0 +1,119 @@
+package test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	"github.com/kudobuilder/kuttl/pkg/report"
+)
+
+// Names of the test cases of the setup and teardown directories of a test suite.
+const (
+	setupCase    = "setup"
+	teardownCase = "teardown"
+)
+
+// suiteCase returns the test case of the setup or teardown directory of the test suite. It runs in the namespace
+// of the test suite, or the default namespace, and its objects are shared by all test cases: they are not deleted
+// after its steps.
+func (h *Harness) suiteCase(name, dir string) *Case {
+	namespace := h.TestSuite.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	return &Case{
+		Timeout:            h.GetTimeout(),
+		Steps:              []*Step{},
+		Name:               name,
+		PreferredNamespace: namespace,
+		Dir:                dir,
+		SkipDelete:         true,
+		Suppress:           h.TestSuite.Suppress,
+		Env:                h.commandEnv,
+		IgnoredFields:      h.TestSuite.IgnoredFields,
+		ValidateManifests:  h.TestSuite.ValidateManifests,
+		StrictApply:        h.TestSuite.StrictApply,
+		NoColor:            h.TestSuite.NoColor || h.TestSuite.LogFormat == harness.LogFormatJSON,
+		Template:           h.TestSuite.Template,
+		Values:             h.TestSuite.Values,
+		Client:             h.Client,
+		DiscoveryClient:    h.DiscoveryClient,
+		Config:             h.Config,
+		Clusters:           h.ClusterClients,
+	}
+}
+
+// runSuiteCase runs the setup or teardown directory of the test suite as a test case named name, it is reported in
+// a test suite of the directory. It returns whether all of its steps succeeded.
+func (h *Harness) runSuiteCase(ctx context.Context, name, dir string) bool {
+	test := h.suiteCase(name, filepath.Clean(dir))
+	return h.runSuiteTest(ctx, h.report.NewSuite(test.Dir), test, nil)
+}
+
+// runTeardown runs the teardown directory of the test suite. It runs after the run was interrupted too, so its context
+// is not derived from the context of the run: it is only bounded by the test suite timeout, and each of its steps by
+// the step timeout.
+func (h *Harness) runTeardown() {
+	ctx := context.Background()
+	if h.TestSuite.SuiteTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(h.TestSuite.SuiteTimeout)*time.Second)
+		defer cancel()
+	}
+	h.runSuiteCase(ctx, teardownCase, h.TestSuite.Teardown)
+}
+
+// isSuiteDir returns whether a directory is the setup or teardown directory of the test suite, they are not loaded
+// as test cases.
+func isSuiteDir(suite harness.TestSuite, dir string) bool {
+	for _, suiteDir := range []string{suite.Setup, suite.Teardown} {
+		if suiteDir == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(suiteDir); err == nil && abs == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// runSuiteTest runs a test case of the test suite itself, e.g. the setup, and reports it in suite. If previous is set,
+// the test case continues from its state, see Case.continueFrom. It returns whether all of its steps succeeded.
+func (h *Harness) runSuiteTest(ctx context.Context, suite *report.Testsuite, test *Case, previous *Case) bool {
+	return h.T.Run(test.Name, func(t *testing.T) {
+		test.Logger = h.newLogger(t, test.Dir, test.Name)
+
+		tc := report.NewCase(test.Name)
+		defer suite.AddTestcase(tc)
+
+		if err := test.LoadTestSteps(); err != nil {
+			tc.Failure = report.NewFailure("failed loading the test steps", []error{err})
+			t.Fatal(err)
+		}
+		test.continueFrom(previous)
+
+		test.Run(ctx, t, tc)
+	})
+}
+
+// skipTests reports all test cases of the test suite as skipped, e.g. after the setup failed.
+func (h *Harness) skipTests(tests map[string][]*Case, reason string) {
+	for testDir, cases := range tests {
+		suite := h.report.NewSuite(testDir)
+		for _, test := range cases {
+			suite.AddTestcase(skippedCase(test, reason))
+		}
+	}
+}
+
+// skippedCase returns the report testcase of a test case which is skipped before it runs.
+func skippedCase(test *Case, reason string) *report.Testcase {
+	tc := report.NewCase(test.Name)
+	tc.Skip(reason)
+	tc.Tags = test.Tags
+	return tc
+}
@@ -0,0 +1,80 @@
+package test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kudobuilder/kuttl/pkg/report"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestSuiteCase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "setup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	step := "apiVersion: kuttl.dev/v1beta1\nkind: TestStep\ncommands:\n- script: echo $NAMESPACE > namespace\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "00-install.yaml"), []byte(step), 0644))
+
+	h := Harness{T: t}
+	assert.Equal(t, "default", h.suiteCase(setupCase, dir).PreferredNamespace)
+
+	h.TestSuite.Namespace = testNamespace
+	test := h.suiteCase(setupCase, dir)
+	assert.True(t, test.SkipDelete)
+
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme)
+	test.Client = func(bool) (client.Client, error) { return cl, nil }
+	test.DiscoveryClient = func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil }
+	test.Logger = testutils.NewTestLogger(t, setupCase)
+	require.NoError(t, test.LoadTestSteps())
+
+	tc := report.NewCase(setupCase)
+	test.Run(context.TODO(), t, tc)
+	assert.Nil(t, tc.Failure)
+
+	// the steps run in the namespace of the test suite
+	namespace, err := ioutil.ReadFile(filepath.Join(dir, "namespace"))
+	require.NoError(t, err)
+	assert.Equal(t, testNamespace+"\n", string(namespace))
+}
+
+func TestLoadTestsSuiteDirs(t *testing.T) {
+	h := Harness{T: t}
+	tests, err := h.LoadTests("test_data")
+	require.NoError(t, err)
+	require.NotEmpty(t, tests)
+
+	// the setup and teardown directories of the test suite are not test cases
+	h.TestSuite.Setup = filepath.Join("test_data", tests[0].Name)
+	h.TestSuite.Teardown = filepath.Join("test_data", tests[1].Name)
+	suiteTests, err := h.LoadTests("test_data")
+	require.NoError(t, err)
+	assert.Len(t, suiteTests, len(tests)-2)
+	for _, test := range suiteTests {
+		assert.NotEqual(t, tests[0].Name, test.Name)
+		assert.NotEqual(t, tests[1].Name, test.Name)
+	}
+}
+
+func TestSkipTests(t *testing.T) {
+	h := Harness{T: t, report: report.NewSuiteCollection("")}
+	h.skipTests(map[string][]*Case{"suite": {{Name: "a"}, {Name: "b"}}}, "setup failed")
+	h.report.Close()
+
+	r := report.NewJSONReport(h.report)
+	assert.Equal(t, 2, r.Tests)
+	for _, c := range r.Suites[0].Cases {
+		assert.Equal(t, report.StatusSkipped, c.Status)
+	}
+}
@@ -0,0 +1,71 @@
+package test
+
+import (
+	"hash/fnv"
+	"path/filepath"
+)
+
+// filterShard returns the test cases of a test directory which belong to a shard of the test suite, see
+// TestSuite.ShardCount. A test case belongs to a shard by the hash of its name, so the shards stay stable when test
+// cases are added. The combinations of the matrix of a test case and the test cases depending on each other are in
+// the same shard, the dependencies of a test case run before it.
+func filterShard(tests []*Case, suite string, index, count int) []*Case {
+	keys := shardKeys(tests)
+
+	filtered := []*Case{}
+	for _, test := range tests {
+		if shardOf(filepath.Base(suite)+"/"+keys[filepath.Base(test.Dir)], count) == index {
+			filtered = append(filtered, test)
+		}
+	}
+	return filtered
+}
+
+// shardKeys returns the key of the shard of the test case directories, it is the first name of the directories which
+// depend on each other.
+func shardKeys(tests []*Case) map[string]string {
+	// parents of the union-find of the test case directories connected by dependencies
+	parents := map[string]string{}
+
+	var find func(dir string) string
+	find = func(dir string) string {
+		parent, ok := parents[dir]
+		if !ok || parent == dir {
+			parents[dir] = dir
+			return dir
+		}
+		root := find(parent)
+		parents[dir] = root
+		return root
+	}
+
+	union := func(a, b string) {
+		rootA, rootB := find(a), find(b)
+		// the first name is the root, so the key does not depend on the order of the test cases
+		if rootB < rootA {
+			rootA, rootB = rootB, rootA
+		}
+		parents[rootB] = rootA
+	}
+
+	for _, test := range tests {
+		dir := filepath.Base(test.Dir)
+		find(dir)
+		for _, dependency := range test.DependsOn {
+			union(dir, dependency)
+		}
+	}
+
+	keys := map[string]string{}
+	for dir := range parents {
+		keys[dir] = find(dir)
+	}
+	return keys
+}
+
+// shardOf returns the shard of a key.
+func shardOf(key string, count int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(count))
+}
@@ -0,0 +1,49 @@
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterShard(t *testing.T) {
+	newCase := func(name, dir string, dependsOn ...string) *Case {
+		return &Case{Name: name, Dir: filepath.Join("suite", dir), DependsOn: dependsOn}
+	}
+
+	tests := []*Case{}
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("test-%d", i)
+		tests = append(tests, newCase(name, name))
+	}
+	tests = append(tests,
+		newCase("matrix[version=1]", "matrix"),
+		newCase("matrix[version=2]", "matrix"),
+		newCase("a", "a", "c"),
+		newCase("b", "b"),
+		newCase("c", "c", "b"),
+	)
+
+	// every test case is in exactly one shard
+	shards := map[string]int{}
+	for index := 0; index < 3; index++ {
+		for _, test := range filterShard(tests, "suite", index, 3) {
+			_, ok := shards[test.Name]
+			assert.False(t, ok, "test %s is in several shards", test.Name)
+			shards[test.Name] = index
+		}
+	}
+	assert.Equal(t, len(tests), len(shards))
+
+	// the combinations of a matrix and the test cases depending on each other are in the same shard
+	assert.Equal(t, shards["matrix[version=1]"], shards["matrix[version=2]"])
+	assert.Equal(t, shards["a"], shards["b"])
+	assert.Equal(t, shards["a"], shards["c"])
+
+	// the shards are stable regardless of the other test cases
+	for _, test := range filterShard(tests[:5], "suite", 1, 3) {
+		assert.Equal(t, 1, shards[test.Name])
+	}
+}
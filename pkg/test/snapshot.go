@@ -0,0 +1,178 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// snapshotFields are removed from objects before they are compared with their snapshots, they change on every update.
+var snapshotFields = []string{"metadata.resourceVersion", "metadata.managedFields"}
+
+// snapshot is the state of the objects of the references of a TestStep snapshot.
+type snapshot struct {
+	// refs are read again by the snapshot asserts to find the objects created since the snapshot.
+	refs []harness.ObjectReference
+	// objects by their resource ID.
+	objects map[string]*unstructured.Unstructured
+}
+
+// Snapshot records the objects of the TestStep snapshot list in the snapshots of the test case.
+func (s *Step) Snapshot(ctx context.Context, namespace string) []error {
+	if s.Step == nil || len(s.Step.Snapshot) == 0 {
+		return nil
+	}
+
+	taken := map[string]*snapshot{}
+	errs := []error{}
+
+	for _, ref := range s.Step.Snapshot {
+		if ref.As == "" {
+			errs = append(errs, fmt.Errorf("snapshot of kind %s has no name", ref.Kind))
+			continue
+		}
+
+		if taken[ref.As] == nil {
+			taken[ref.As] = &snapshot{}
+		}
+		taken[ref.As].refs = append(taken[ref.As].refs, ref.ObjectReference)
+	}
+
+	for name, snap := range taken {
+		objects, err := s.snapshotObjects(ctx, namespace, snap.refs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("snapshot %s: %w", name, err))
+			continue
+		}
+
+		snap.objects = objects
+		s.Logger.Logf("snapshotted %d objects as %s", len(objects), name)
+		s.snapshots[name] = snap
+	}
+
+	return errs
+}
+
+// snapshotObjects fetches the objects of references by their resource ID.
+func (s *Step) snapshotObjects(ctx context.Context, namespace string, refs []harness.ObjectReference) (map[string]*unstructured.Unstructured, error) {
+	cl, err := s.Client(false)
+	if err != nil {
+		return nil, err
+	}
+
+	dClient, err := s.DiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+
+	objects := map[string]*unstructured.Unstructured{}
+
+	for _, ref := range refs {
+		objs, err := s.objectsFromRef(ctx, cl, dClient, ref, namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range objs {
+			// named references are not fetched by objectsFromRef, missing objects are not part of the snapshot
+			if ref.Name != "" {
+				if err := cl.Get(ctx, testutils.ObjectKey(obj), obj); k8serrors.IsNotFound(err) {
+					continue
+				} else if err != nil {
+					return nil, err
+				}
+			}
+
+			actual := obj.(*unstructured.Unstructured)
+			testutils.RemoveFields(actual.Object, snapshotFields)
+			objects[testutils.ResourceID(actual)] = actual
+		}
+	}
+
+	return objects, nil
+}
+
+// CheckSnapshots compares the objects of the snapshots of the snapshot asserts of the TestAssert with their current state.
+func (s *Step) CheckSnapshots(ctx context.Context, namespace string) []error {
+	if s.Assert == nil || len(s.Assert.Snapshots) == 0 {
+		return nil
+	}
+
+	errs := []error{}
+
+	for _, expected := range s.Assert.Snapshots {
+		snap, ok := s.snapshots[expected.Snapshot]
+		if !ok {
+			errs = append(errs, fmt.Errorf("snapshot %s was not taken by a previous test step", expected.Snapshot))
+			continue
+		}
+
+		actual, err := s.snapshotObjects(ctx, namespace, snap.refs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("snapshot %s: %w", expected.Snapshot, err))
+			continue
+		}
+
+		changes := s.snapshotChanges(expected, snap.objects, actual)
+		if expected.Changed {
+			if len(changes) == 0 {
+				errs = append(errs, fmt.Errorf("the %d objects of snapshot %s did not change", len(snap.objects), expected.Snapshot))
+			}
+			continue
+		}
+		errs = append(errs, changes...)
+	}
+
+	return errs
+}
+
+// snapshotChanges returns the objects which were created, deleted or changed since a snapshot, in the order of their
+// resource IDs.
+func (s *Step) snapshotChanges(expected harness.SnapshotAssert, snapshotted, actual map[string]*unstructured.Unstructured) []error {
+	ignoredFields := append(s.ignoredFields(), expected.IgnoredFields...)
+
+	ids := []string{}
+	for id := range snapshotted {
+		ids = append(ids, id)
+	}
+	for id := range actual {
+		if _, ok := snapshotted[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	changes := []error{}
+
+	for _, id := range ids {
+		before, after := snapshotted[id], actual[id]
+		switch {
+		case before == nil:
+			changes = append(changes, fmt.Errorf("%s was created since snapshot %s", id, expected.Snapshot))
+		case after == nil:
+			changes = append(changes, fmt.Errorf("%s was deleted since snapshot %s", id, expected.Snapshot))
+		default:
+			before, after = before.DeepCopy(), after.DeepCopy()
+			testutils.RemoveFields(before.Object, ignoredFields)
+			testutils.RemoveFields(after.Object, ignoredFields)
+			if reflect.DeepEqual(before.Object, after.Object) {
+				continue
+			}
+
+			diff, err := testutils.Diff(before, after, testutils.DiffOptions{Color: !s.NoColor})
+			if err != nil {
+				diff = err.Error()
+			}
+			changes = append(changes, fmt.Errorf("%s changed since snapshot %s:\n%s", id, expected.Snapshot, diff))
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,81 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestStepSnapshots(t *testing.T) {
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme, driftDeployment("operator", 1), driftDeployment("child", 1))
+	snapshots := map[string]*snapshot{}
+
+	deployment := func(name string) harness.ObjectReference {
+		return harness.ObjectReference{ObjectReference: driftRef("Deployment", "apps/v1", name)}
+	}
+	step := &Step{
+		Logger: testutils.NewTestLogger(t, ""),
+		Step: &harness.TestStep{Snapshot: []harness.Snapshot{
+			{ObjectReference: deployment("operator"), As: "children"},
+			{ObjectReference: deployment("child"), As: "children"},
+			// objects which do not exist are not part of the snapshot
+			{ObjectReference: deployment("other"), As: "children"},
+			{ObjectReference: deployment("operator"), As: "operator"},
+		}},
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+		snapshots:       snapshots,
+	}
+	require.Empty(t, step.Snapshot(context.TODO(), testNamespace))
+	assert.Len(t, snapshots["children"].objects, 2)
+	assert.Len(t, snapshots["operator"].objects, 1)
+
+	// the snapshots are asserted by the following steps
+	step = &Step{
+		Logger: testutils.NewTestLogger(t, ""),
+		Assert: &harness.TestAssert{Snapshots: []harness.SnapshotAssert{
+			{Snapshot: "children"},
+			{Snapshot: "operator", Changed: true},
+		}},
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+		snapshots:       snapshots,
+	}
+
+	// updates only change the resource version of the objects
+	updateDeployment(t, cl, "operator", func(*appsv1.Deployment) {})
+	assert.Equal(t, []string{
+		"the 1 objects of snapshot operator did not change",
+	}, errorStrings(step.CheckSnapshots(context.TODO(), testNamespace)))
+
+	updateDeployment(t, cl, "operator", func(d *appsv1.Deployment) { d.Spec.Paused = true })
+	require.NoError(t, cl.Delete(context.TODO(), driftDeployment("child", 1)))
+	require.NoError(t, cl.Create(context.TODO(), driftDeployment("other", 1)))
+	errs := step.CheckSnapshots(context.TODO(), testNamespace)
+	require.Len(t, errs, 3)
+	assert.Equal(t, "Deployment:world/child was deleted since snapshot children", errs[0].Error())
+	assert.Contains(t, errs[1].Error(), "Deployment:world/operator changed since snapshot children")
+	assert.Contains(t, errs[1].Error(), "+  paused: true")
+	assert.Equal(t, "Deployment:world/other was created since snapshot children", errs[2].Error())
+
+	// ignored fields are not compared
+	require.NoError(t, cl.Create(context.TODO(), driftDeployment("child", 1)))
+	require.NoError(t, cl.Delete(context.TODO(), driftDeployment("other", 1)))
+	step.Assert.Snapshots = []harness.SnapshotAssert{{Snapshot: "children", IgnoredFields: []string{"spec.paused"}}}
+	assert.Empty(t, step.CheckSnapshots(context.TODO(), testNamespace))
+
+	step.Assert.Snapshots = []harness.SnapshotAssert{{Snapshot: "missing"}}
+	assert.Equal(t, []string{
+		"snapshot missing was not taken by a previous test step",
+	}, errorStrings(step.CheckSnapshots(context.TODO(), testNamespace)))
+}
@@ -2,17 +2,33 @@ package test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/thoas/go-funk"
+	"helm.sh/helm/v3/pkg/action"
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
@@ -24,11 +40,39 @@ import (
 
 var fileNameRegex = regexp.MustCompile(`^(\d+-)?([^.]+)(.yaml)?$`)
 
+// timeoutAnnotation overrides the timeout of the test step for an assert object (in seconds).
+const timeoutAnnotation = "kuttl.dev/timeout"
+
+// arrayMatchingAnnotation sets the array matching modes of the lists of an assert or error object,
+// e.g. "status.conditions=anyElementMatches,spec.containers=setEquality".
+const arrayMatchingAnnotation = "kuttl.dev/array-matching"
+
+// observedGenerationAnnotation overrides TestAssert.ObservedGeneration for an assert object, "true" or "false".
+const observedGenerationAnnotation = "kuttl.dev/observed-generation"
+
+// applyOrderAnnotation orders the objects a step applies, the objects with a lower order are applied first. Objects
+// with the same order are applied at once. The order of objects without it is 0.
+const applyOrderAnnotation = "kuttl.dev/apply-order"
+
+// waveAnnotation orders the objects a step applies like the apply order annotation, the objects with it are waves
+// which must be ready before the objects of the next order are applied, see waveReason.
+const waveAnnotation = "kuttl.dev/wave"
+
+// implicitApplyOrder is the order of the kinds of objects other objects depend on, they are applied before the objects
+// of other kinds with the same apply order.
+var implicitApplyOrder = map[string]int{
+	"Namespace":                0,
+	"CustomResourceDefinition": 0,
+	"ServiceAccount":           1,
+}
+
 // A Step contains the name of the test step, its index in the test,
 // and all of the test step's settings (including objects to apply and assert on).
 type Step struct {
 	Name  string
 	Index int
+	// Hook is set for the steps of the hooks directory of the test suite, they run regardless of the range of steps.
+	Hook bool
 
 	Dir string
 
@@ -43,11 +87,95 @@ type Step struct {
 
 	Client          func(forceNew bool) (client.Client, error)
 	DiscoveryClient func() (discovery.DiscoveryInterface, error)
+	// Config is the configuration of the cluster for requests which are not done with the Client.
+	Config func() (*rest.Config, error)
+	// Clusters returns the clients of the clusters of the test suite by name, see TestStep.Cluster.
+	Clusters func(name string) (*ClusterClients, error)
 
 	Logger testutils.Logger
+	// Suppress is used to suppress logs
+	Suppress []string
+	// Env is the environment of the test suite injected into the commands of the step.
+	Env map[string]string
+	// IgnoredFields of the test suite which are not compared by asserts.
+	IgnoredFields []string
+	// CachedKinds of the test suite whose objects are read from the Cache by asserts.
+	CachedKinds []string
+	// Cache is the informer cache of the harness, see Harness.Cache.
+	Cache func() (client.Reader, error)
+	// Backoff of the retries of the asserts of the test suite, the backoff of the TestAssert overrides it.
+	Backoff *harness.Backoff
+	// UpdateGolden rewrites the golden files of the TestAssert instead of comparing them.
+	UpdateGolden bool
+	// Record writes the objects applied by the step as an assert file after the step succeeded, see RecordAsserts.
+	Record bool
+	// ValidateManifests validates the objects of the step with a dry run before they are applied, see Validate.
+	ValidateManifests bool
+	// StrictApply fails to apply objects which already exist with different content managed by others, see
+	// applyConflict.
+	StrictApply bool
+	// NoColor disables the colorized diffs of failed asserts.
+	NoColor bool
+
+	// acting is the client of the user the step acts as, see actingClient.
+	acting client.Client
+	// jobs created by the step which are deleted on Clean.
+	jobs []runtime.Object
+	// releases are the Helm releases installed by the step which are uninstalled on Clean.
+	releases []helmRelease
+	// releaseObjects are the rendered objects of the Helm releases asserted by the step, see CheckReleases.
+	releaseObjects []runtime.Object
+	// olmObjects are the objects created to install operators with OLM which are deleted on Clean, see RunOLM.
+	olmObjects []runtime.Object
+	// chaosObjects are the network policies isolating pods which are deleted on Clean, see RunChaos.
+	chaosObjects []runtime.Object
+	// cordoned are the nodes cordoned by the step which are uncordoned on Clean.
+	cordoned []string
+	// restarts are the restarts of the pods of the restart asserts when the step started, see RecordRestarts.
+	restarts restartCounts
+	// clientset overrides the clientset of the requests which are not supported by the Client, see kubernetesClientset.
+	clientset func() (kubernetes.Interface, error)
+	// helmConfig overrides the configuration of the Helm actions of a namespace, see helmConfiguration.
+	helmConfig func(namespace string) (*action.Configuration, error)
+	// processes of background commands which are killed on StopProcesses.
+	processes []*backgroundProcess
+	// forwards are the stop channels of the port forwards of the step, see startPortForwards.
+	forwards []chan struct{}
+	// forwardEnv are the local addresses of the port forwards by environment variable.
+	forwardEnv map[string]string
+	// template is the data to render the test files with, they are not rendered if nil.
+	template *TemplateData
+	// values of the test suite which are available in the expansion of the TestStep paths.
+	values map[string]string
+	// variables of the test case shared by all steps, see Capture.
+	variables map[string]string
+	// testRestarts are the restarts of pods when the test case started, shared by all steps, see RecordRestarts.
+	testRestarts restartCounts
+	// snapshots of the test case by name shared by all steps, see Snapshot.
+	snapshots map[string]*snapshot
+	// applied are the objects applied by the steps of the test case by their resource ID, shared by all steps, see
+	// Prune.
+	applied map[string]runtime.Object
+	// tracking of the objects created by the test case, the applied objects are labeled with it, see startTracking.
+	tracking *tracking
+	// files of the step which are loaded right before the step runs, see loadTemplates.
+	files []string
+	// artifacts are the files written by the step, e.g. recorded assert files, they are attached to its report testcase.
+	artifacts []string
+}
+
+// addArtifact records a file written by the step, files which are rewritten while the step is retried are recorded once.
+func (s *Step) addArtifact(path string) {
+	for _, artifact := range s.artifacts {
+		if artifact == path {
+			return
+		}
+	}
+	s.artifacts = append(s.artifacts, path)
 }
 
-// Clean deletes all resources defined in the Apply list.
+// Clean deletes all resources defined in the Apply list, the objects created to install operators and the network
+// policies isolating pods, uncordons the nodes cordoned by the step and uninstalls the Helm releases installed by it.
 func (s *Step) Clean(namespace string) error {
 	cl, err := s.Client(false)
 	if err != nil {
@@ -60,21 +188,131 @@ func (s *Step) Clean(namespace string) error {
 	}
 
 	for _, obj := range s.Apply {
-		_, _, err := testutils.Namespaced(dClient, obj, namespace)
+		objClient, objDClient, err := s.objectClients(cl, dClient, obj)
+		if err != nil {
+			return err
+		}
+
+		_, _, err = testutils.Namespaced(objDClient, obj, namespace)
 		if err != nil {
 			return err
 		}
 
-		if err := cl.Delete(context.TODO(), obj); err != nil && !k8serrors.IsNotFound(err) {
+		if err := objClient.Delete(context.TODO(), obj); err != nil && !k8serrors.IsNotFound(err) {
 			return err
 		}
 	}
 
-	return nil
+	// jobs are deleted with their pods
+	for _, job := range s.jobs {
+		if err := cl.Delete(context.TODO(), job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	if err := s.deleteOLMObjects(cl); err != nil {
+		return err
+	}
+
+	if err := s.cleanChaos(cl); err != nil {
+		return err
+	}
+
+	return s.uninstallReleases()
+}
+
+// Teardown deletes the objects and runs the commands of the TestStep.Cleanup section.
+// It is run after the test case finished regardless of the test result.
+func (s *Step) Teardown(namespace string) []error {
+	if s.Step == nil || s.Step.Cleanup == nil {
+		return nil
+	}
+
+	cl, err := s.Client(false)
+	if err != nil {
+		return []error{err}
+	}
+
+	dClient, err := s.DiscoveryClient()
+	if err != nil {
+		return []error{err}
+	}
+
+	errors := []error{}
+
+	for _, ref := range s.Step.Cleanup.Delete {
+		objs, err := s.objectsFromRef(context.TODO(), cl, dClient, ref, namespace)
+		if err != nil {
+			errors = append(errors, err)
+			continue
+		}
+		for _, obj := range objs {
+			if err := cl.Delete(context.TODO(), obj); err != nil && !k8serrors.IsNotFound(err) {
+				errors = append(errors, err)
+				continue
+			}
+			testutils.LogResource(s.Logger, testutils.ResourceID(obj), "deleted")
+		}
+	}
+
+	env, err := s.commandEnv(context.TODO(), namespace)
+	if err != nil {
+		return append(errors, err)
+	}
+	if _, err := testutils.RunCommands(context.TODO(), s.Logger, namespace, s.Step.Cleanup.Commands, s.Dir, s.Timeout, env); err != nil {
+		errors = append(errors, err)
+	}
+
+	return errors
+}
+
+// objectsFromRef returns the objects an ObjectReference refers to. If the reference has no name,
+// all objects of the kind matching the reference labels are listed.
+func (s *Step) objectsFromRef(ctx context.Context, cl client.Client, dClient discovery.DiscoveryInterface, ref harness.ObjectReference, namespace string) ([]runtime.Object, error) {
+	gvk := ref.GroupVersionKind()
+
+	obj := testutils.NewResource(gvk.GroupVersion().String(), gvk.Kind, ref.Name, "")
+
+	objNs := namespace
+	if ref.Namespace != "" {
+		objNs = ref.Namespace
+	}
+
+	_, objNs, err := testutils.Namespaced(dClient, obj, objNs)
+	if err != nil {
+		return nil, err
+	}
+
+	if ref.Name != "" {
+		return []runtime.Object{obj}, nil
+	}
+
+	u := &unstructured.UnstructuredList{}
+	u.SetGroupVersionKind(gvk)
+
+	listOptions := []client.ListOption{}
+
+	if ref.Labels != nil {
+		listOptions = append(listOptions, client.MatchingLabels(ref.Labels))
+	}
+
+	if objNs != "" {
+		listOptions = append(listOptions, client.InNamespace(objNs))
+	}
+
+	if err := cl.List(ctx, u, listOptions...); err != nil {
+		return nil, fmt.Errorf("listing matching resources: %w", err)
+	}
+
+	objs := []runtime.Object{}
+	for index := range u.Items {
+		objs = append(objs, &u.Items[index])
+	}
+	return objs, nil
 }
 
 // DeleteExisting deletes any resources in the TestStep.Delete list prior to running the tests.
-func (s *Step) DeleteExisting(namespace string) error {
+func (s *Step) DeleteExisting(ctx context.Context, namespace string) error {
 	cl, err := s.Client(false)
 	if err != nil {
 		return err
@@ -92,59 +330,47 @@ func (s *Step) DeleteExisting(namespace string) error {
 	}
 
 	for _, ref := range s.Step.Delete {
-		gvk := ref.GroupVersionKind()
-
-		obj := testutils.NewResource(gvk.GroupVersion().String(), gvk.Kind, ref.Name, "")
-
-		objNs := namespace
-		if ref.Namespace != "" {
-			objNs = ref.Namespace
-		}
-
-		_, objNs, err := testutils.Namespaced(dClient, obj, objNs)
+		objs, err := s.objectsFromRef(ctx, cl, dClient, ref, namespace)
 		if err != nil {
 			return err
 		}
-
-		if ref.Name == "" {
-			u := &unstructured.UnstructuredList{}
-			u.SetGroupVersionKind(gvk)
-
-			listOptions := []client.ListOption{}
-
-			if ref.Labels != nil {
-				listOptions = append(listOptions, client.MatchingLabels(ref.Labels))
-			}
-
-			if objNs != "" {
-				listOptions = append(listOptions, client.InNamespace(objNs))
-			}
-
-			err := cl.List(context.TODO(), u, listOptions...)
-			if err != nil {
-				return fmt.Errorf("listing matching resources: %w", err)
-			}
-
-			for index := range u.Items {
-				toDelete = append(toDelete, &u.Items[index])
-			}
-		} else {
-			// Otherwise just append the object specified.
+		for _, obj := range objs {
 			toDelete = append(toDelete, obj.DeepCopyObject())
 		}
 	}
 
+	deleteOptions, err := s.deleteOptions()
+	if err != nil {
+		return err
+	}
+
+	// the objects are looked up by the test suite, they are deleted by the user the step acts as
+	actingClient, err := s.actingClient(namespace)
+	if err != nil {
+		return err
+	}
+
 	for _, obj := range toDelete {
-		err := cl.Delete(context.TODO(), obj.DeepCopyObject())
+		err := actingClient.Delete(ctx, obj.DeepCopyObject(), deleteOptions...)
 		if err != nil && !k8serrors.IsNotFound(err) {
 			return err
 		}
 	}
 
+	timeout := s.GetTimeout()
+	if s.Step.DeleteOptions != nil {
+		if s.Step.DeleteOptions.SkipWait {
+			return nil
+		}
+		if s.Step.DeleteOptions.Timeout > 0 {
+			timeout = s.Step.DeleteOptions.Timeout
+		}
+	}
+
 	// Wait for resources to be deleted.
-	return wait.PollImmediate(100*time.Millisecond, time.Duration(s.GetTimeout())*time.Second, func() (done bool, err error) {
+	return wait.PollImmediate(100*time.Millisecond, time.Duration(timeout)*time.Second, func() (done bool, err error) {
 		for _, obj := range toDelete {
-			err = cl.Get(context.TODO(), testutils.ObjectKey(obj), obj.DeepCopyObject())
+			err = cl.Get(ctx, testutils.ObjectKey(obj), obj.DeepCopyObject())
 			if err == nil || !k8serrors.IsNotFound(err) {
 				return false, err
 			}
@@ -154,9 +380,50 @@ func (s *Step) DeleteExisting(namespace string) error {
 	})
 }
 
-// Create applies all resources defined in the Apply list.
-func (s *Step) Create(namespace string) []error {
-	cl, err := s.Client(true)
+// deleteOptions converts the TestStep delete options to client delete options.
+func (s *Step) deleteOptions() ([]client.DeleteOption, error) {
+	opts := []client.DeleteOption{}
+	if s.Step == nil || s.Step.DeleteOptions == nil {
+		return opts, nil
+	}
+
+	if policy := s.Step.DeleteOptions.PropagationPolicy; policy != "" {
+		switch p := metav1.DeletionPropagation(policy); p {
+		case metav1.DeletePropagationForeground, metav1.DeletePropagationBackground, metav1.DeletePropagationOrphan:
+			opts = append(opts, client.PropagationPolicy(p))
+		default:
+			return nil, fmt.Errorf("unknown propagation policy %q, must be one of Foreground, Background or Orphan", policy)
+		}
+	}
+
+	if s.Step.DeleteOptions.GracePeriodSeconds != nil {
+		opts = append(opts, client.GracePeriodSeconds(*s.Step.DeleteOptions.GracePeriodSeconds))
+	}
+
+	return opts, nil
+}
+
+// patchType converts the TestStep patch type to the Kubernetes patch type.
+func patchType(t string) (types.PatchType, error) {
+	switch strings.ToLower(t) {
+	case "", "merge":
+		return types.MergePatchType, nil
+	case "json":
+		return types.JSONPatchType, nil
+	case "strategic":
+		return types.StrategicMergePatchType, nil
+	default:
+		return "", fmt.Errorf("unknown patch type %q, must be one of merge, json or strategic", t)
+	}
+}
+
+// Patch applies the patches in the TestStep.Patch list to existing resources.
+func (s *Step) Patch(ctx context.Context, namespace string) []error {
+	if s.Step == nil || len(s.Step.Patch) == 0 {
+		return nil
+	}
+
+	cl, err := s.Client(false)
 	if err != nil {
 		return []error{err}
 	}
@@ -166,35 +433,265 @@ func (s *Step) Create(namespace string) []error {
 		return []error{err}
 	}
 
+	// the objects are looked up by the test suite, they are patched by the user the step acts as
+	actingClient, err := s.actingClient(namespace)
+	if err != nil {
+		return []error{err}
+	}
+
 	errors := []error{}
 
-	for _, obj := range s.Apply {
-		_, _, err := testutils.Namespaced(dClient, obj, namespace)
+	for _, p := range s.Step.Patch {
+		pt, err := patchType(p.Type)
 		if err != nil {
 			errors = append(errors, err)
 			continue
 		}
-		ctx := context.Background()
-		if s.Timeout > 0 {
-			var cancel context.CancelFunc
-			ctx, cancel = context.WithTimeout(ctx, time.Duration(s.Timeout)*time.Second)
-			defer cancel()
+
+		data, err := yaml.ToJSON([]byte(p.Patch))
+		if err != nil {
+			errors = append(errors, fmt.Errorf("patch for %s/%s is not valid YAML or JSON: %w", p.Kind, p.Name, err))
+			continue
 		}
 
-		if updated, err := testutils.CreateOrUpdate(ctx, cl, obj, true); err != nil {
+		objs, err := s.objectsFromRef(ctx, cl, dClient, p.ObjectReference, namespace)
+		if err != nil {
 			errors = append(errors, err)
-		} else {
-			action := "created"
-			if updated {
-				action = "updated"
+			continue
+		}
+
+		if len(objs) == 0 {
+			errors = append(errors, fmt.Errorf("no resources matched patch for kind: %s", p.GroupVersionKind().String()))
+			continue
+		}
+
+		for _, obj := range objs {
+			if err := actingClient.Patch(ctx, obj, client.RawPatch(pt, data)); err != nil {
+				errors = append(errors, fmt.Errorf("patching %s: %w", testutils.ResourceID(obj), err))
+				continue
+			}
+			testutils.LogResource(s.Logger, testutils.ResourceID(obj), "patched")
+		}
+	}
+
+	return errors
+}
+
+// Create applies all resources defined in the Apply list. They are applied in the groups of applyGroups, the
+// objects of a group at once. The objects of a wave must be ready before the next group is applied, the following
+// groups are not applied if a wave fails or, with ValidateManifests, if the validation of a group fails. The objects
+// of previous steps are pruned once all objects are applied.
+func (s *Step) Create(ctx context.Context, namespace string) []error {
+	cl, err := s.actingClient(namespace)
+	if err != nil {
+		return []error{err}
+	}
+
+	dClient, err := s.DiscoveryClient()
+	if err != nil {
+		return []error{err}
+	}
+
+	groups, err := applyGroups(s.Apply)
+	if err != nil {
+		return []error{err}
+	}
+
+	// the groups are applied one after another, the objects of a group at once
+	results := make([][]error, len(s.Apply))
+	var validationErrors []error
+	var waveErr error
+	for _, group := range groups {
+		group := group
+
+		// the objects of a group are validated right before they are applied, they may be of the resources of the
+		// CRDs of the previous groups
+		if s.ValidateManifests {
+			if validationErrors = s.validateObjects(ctx, cl, dClient, groupObjects(s.Apply, group), namespace); len(validationErrors) > 0 {
+				break
+			}
+		}
+
+		groupErrors := concurrently(len(group), func(i int) []error {
+			if err := s.createObject(ctx, cl, dClient, s.Apply[group[i]], namespace); err != nil {
+				return []error{err}
+			}
+			return nil
+		})
+		failed := false
+		for i, errs := range groupErrors {
+			results[group[i]] = errs
+			failed = failed || len(errs) > 0
+			if len(errs) == 0 {
+				s.recordApplied(s.Apply[group[i]])
+			}
+		}
+
+		// the following groups are not applied until the objects of a wave are ready
+		if wave := waveObjects(s.Apply, group); len(wave) > 0 {
+			if failed {
+				break
+			}
+			if err := s.waitForWave(ctx, wave); err != nil {
+				waveErr = err
+				break
+			}
+		}
+
+		// the objects of the following groups may be of the resources of the CRDs of the group
+		if hasCRD(s.Apply, group) {
+			if cached, ok := dClient.(discovery.CachedDiscoveryInterface); ok {
+				cached.Invalidate()
 			}
-			s.Logger.Log(testutils.ResourceID(obj), action)
 		}
 	}
 
+	errors := []error{}
+	for _, errs := range results {
+		errors = append(errors, errs...)
+	}
+	errors = append(errors, validationErrors...)
+	if waveErr != nil {
+		errors = append(errors, waveErr)
+	}
+	if len(errors) == 0 {
+		errors = append(errors, s.Prune(ctx, namespace)...)
+	}
 	return errors
 }
 
+// createObject creates or updates an object to apply.
+func (s *Step) createObject(ctx context.Context, cl client.Client, dClient discovery.DiscoveryInterface, obj runtime.Object, namespace string) error {
+	cl, dClient, err := s.objectClients(cl, dClient, obj)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := testutils.Namespaced(dClient, obj, namespace); err != nil {
+		return err
+	}
+
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(s.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	if s.tracking != nil {
+		if err := s.tracking.labelObject(obj); err != nil {
+			return err
+		}
+	}
+
+	if s.StrictApply {
+		if err := applyConflict(ctx, cl, obj); err != nil {
+			return err
+		}
+		cl = fieldOwnerClient{Client: cl, owner: applyFieldManager}
+	}
+
+	updated, err := testutils.CreateOrUpdate(ctx, cl, obj, true)
+	if err != nil {
+		return err
+	}
+
+	action := "created"
+	if updated {
+		action = "updated"
+	}
+	testutils.LogResource(s.Logger, testutils.ResourceID(obj), action)
+	return nil
+}
+
+// groupObjects returns the objects with the indexes.
+func groupObjects(objs []runtime.Object, indexes []int) []runtime.Object {
+	group := []runtime.Object{}
+	for _, i := range indexes {
+		group = append(group, objs[i])
+	}
+	return group
+}
+
+// hasCRD checks if any of the objects with the indexes is a CRD.
+func hasCRD(objs []runtime.Object, indexes []int) bool {
+	for _, i := range indexes {
+		if objs[i].GetObjectKind().GroupVersionKind().Kind == "CustomResourceDefinition" {
+			return true
+		}
+	}
+	return false
+}
+
+// applyOrder returns the apply order of an object set by the apply order or the wave annotation, 0 if neither is
+// set. wave is true if it is set by the wave annotation.
+func applyOrder(obj runtime.Object) (order int, wave bool, err error) {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return 0, false, err
+	}
+
+	annotation := applyOrderAnnotation
+	value, ok := m.GetAnnotations()[applyOrderAnnotation]
+	if waveValue, isWave := m.GetAnnotations()[waveAnnotation]; isWave {
+		if ok {
+			return 0, false, fmt.Errorf("%s has both the %s and the %s annotation, only one may be set", testutils.ResourceID(obj), applyOrderAnnotation, waveAnnotation)
+		}
+		annotation, value, ok, wave = waveAnnotation, waveValue, true, true
+	}
+	if !ok {
+		return 0, false, nil
+	}
+
+	order, err = strconv.Atoi(value)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid %s annotation %q on %s: must be a number", annotation, value, testutils.ResourceID(obj))
+	}
+	return order, wave, nil
+}
+
+// applyGroups groups the objects to apply by their apply order and the implicit order of their kind, see
+// implicitApplyOrder. It returns the indexes of the objects of each group, the groups are in the order they are
+// applied in.
+func applyGroups(objs []runtime.Object) ([][]int, error) {
+	type groupKey struct {
+		order    int
+		implicit int
+	}
+
+	keys := []groupKey{}
+	byKey := map[groupKey][]int{}
+	for i, obj := range objs {
+		order, _, err := applyOrder(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		implicit, ok := implicitApplyOrder[obj.GetObjectKind().GroupVersionKind().Kind]
+		if !ok {
+			implicit = len(implicitApplyOrder)
+		}
+
+		key := groupKey{order: order, implicit: implicit}
+		if _, ok := byKey[key]; !ok {
+			keys = append(keys, key)
+		}
+		byKey[key] = append(byKey[key], i)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].order == keys[j].order {
+			return keys[i].implicit < keys[j].implicit
+		}
+		return keys[i].order < keys[j].order
+	})
+
+	groups := [][]int{}
+	for _, key := range keys {
+		groups = append(groups, byKey[key])
+	}
+	return groups, nil
+}
+
 // GetTimeout gets the timeout defined for the test step.
 func (s *Step) GetTimeout() int {
 	timeout := s.Timeout
@@ -204,7 +701,7 @@ func (s *Step) GetTimeout() int {
 	return timeout
 }
 
-func list(cl client.Client, gvk schema.GroupVersionKind, namespace string) ([]unstructured.Unstructured, error) {
+func list(ctx context.Context, cl client.Reader, gvk schema.GroupVersionKind, namespace string) ([]unstructured.Unstructured, error) {
 	list := unstructured.UnstructuredList{}
 	list.SetGroupVersionKind(gvk)
 
@@ -213,7 +710,7 @@ func list(cl client.Client, gvk schema.GroupVersionKind, namespace string) ([]un
 		listOptions = append(listOptions, client.InNamespace(namespace))
 	}
 
-	if err := cl.List(context.TODO(), &list, listOptions...); err != nil {
+	if err := cl.List(ctx, &list, listOptions...); err != nil {
 		return []unstructured.Unstructured{}, err
 	}
 
@@ -221,7 +718,7 @@ func list(cl client.Client, gvk schema.GroupVersionKind, namespace string) ([]un
 }
 
 // CheckResource checks if the expected resource's state in Kubernetes is correct.
-func (s *Step) CheckResource(expected runtime.Object, namespace string) []error {
+func (s *Step) CheckResource(ctx context.Context, expected runtime.Object, namespace string) []error {
 	cl, err := s.Client(false)
 	if err != nil {
 		return []error{err}
@@ -234,6 +731,11 @@ func (s *Step) CheckResource(expected runtime.Object, namespace string) []error
 
 	testErrors := []error{}
 
+	cl, dClient, err = s.objectClients(cl, dClient, expected)
+	if err != nil {
+		return append(testErrors, err)
+	}
+
 	name, namespace, err := testutils.Namespaced(dClient, expected, namespace)
 	if err != nil {
 		return append(testErrors, err)
@@ -241,20 +743,25 @@ func (s *Step) CheckResource(expected runtime.Object, namespace string) []error
 
 	gvk := expected.GetObjectKind().GroupVersionKind()
 
+	reader, err := s.reader(cl, expected)
+	if err != nil {
+		return append(testErrors, err)
+	}
+
 	actuals := []unstructured.Unstructured{}
 
 	if name != "" {
 		actual := unstructured.Unstructured{}
 		actual.SetGroupVersionKind(gvk)
 
-		err = cl.Get(context.TODO(), client.ObjectKey{
+		err = reader.Get(ctx, client.ObjectKey{
 			Namespace: namespace,
 			Name:      name,
 		}, &actual)
 
 		actuals = append(actuals, actual)
 	} else {
-		actuals, err = list(cl, gvk, namespace)
+		actuals, err = list(ctx, reader, gvk, namespace)
 		if len(actuals) == 0 {
 			testErrors = append(testErrors, fmt.Errorf("no resources matched of kind: %s", gvk.String()))
 		}
@@ -263,18 +770,49 @@ func (s *Step) CheckResource(expected runtime.Object, namespace string) []error
 		return append(testErrors, err)
 	}
 
-	expectedObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(expected)
+	// the expected object is copied, the content of unstructured objects is shared by the converter
+	expectedObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(expected.DeepCopyObject())
 	if err != nil {
 		return append(testErrors, err)
 	}
+	removeAnnotations(expectedObj)
+
+	options, err := s.subsetOptions(expected)
+	if err != nil {
+		return append(testErrors, err)
+	}
+
+	observedGeneration, err := s.observedGeneration(expected)
+	if err != nil {
+		return append(testErrors, err)
+	}
+	_, hasStatus := expectedObj["status"]
+
+	ignoredFields := s.ignoredFields()
+	testutils.RemoveFields(expectedObj, ignoredFields)
 
 	for _, actual := range actuals {
 		actual := actual
 
+		// the status is not compared until it was written for the latest generation
+		if observedGeneration && hasStatus {
+			if reason := staleStatus(&actual); reason != "" {
+				testErrors = append(testErrors, fmt.Errorf("resource %s: the status is stale, %s", testutils.ResourceID(&actual), reason))
+				continue
+			}
+		}
+
+		testutils.RemoveFields(actual.Object, ignoredFields)
+
 		tmpTestErrors := []error{}
 
-		if err := testutils.IsSubset(expectedObj, actual.UnstructuredContent()); err != nil {
-			diff, diffErr := testutils.PrettyDiff(expected, &actual)
+		if err := testutils.IsSubsetWithOptions(expectedObj, actual.UnstructuredContent(), options); err != nil {
+			diffOptions := testutils.DiffOptions{Color: !s.NoColor}
+			if subsetErr, ok := err.(*testutils.SubsetError); ok {
+				diffOptions.Path = subsetErr.Path()
+			}
+
+			diff, diffErr := testutils.Diff(&unstructured.Unstructured{Object: expectedObj}, &actual, diffOptions)
 			if diffErr == nil {
 				tmpTestErrors = append(tmpTestErrors, fmt.Errorf(diff))
 			} else {
@@ -295,7 +833,7 @@ func (s *Step) CheckResource(expected runtime.Object, namespace string) []error
 }
 
 // CheckResourceAbsent checks if the expected resource's state is absent in Kubernetes.
-func (s *Step) CheckResourceAbsent(expected runtime.Object, namespace string) error {
+func (s *Step) CheckResourceAbsent(ctx context.Context, expected runtime.Object, namespace string) error {
 	cl, err := s.Client(false)
 	if err != nil {
 		return err
@@ -306,6 +844,11 @@ func (s *Step) CheckResourceAbsent(expected runtime.Object, namespace string) er
 		return err
 	}
 
+	cl, dClient, err = s.objectClients(cl, dClient, expected)
+	if err != nil {
+		return err
+	}
+
 	name, namespace, err := testutils.Namespaced(dClient, expected, namespace)
 	if err != nil {
 		return err
@@ -313,13 +856,18 @@ func (s *Step) CheckResourceAbsent(expected runtime.Object, namespace string) er
 
 	gvk := expected.GetObjectKind().GroupVersionKind()
 
+	reader, err := s.reader(cl, expected)
+	if err != nil {
+		return err
+	}
+
 	var actuals []unstructured.Unstructured
 
 	if name != "" {
 		actual := unstructured.Unstructured{}
 		actual.SetGroupVersionKind(gvk)
 
-		if err := cl.Get(context.TODO(), client.ObjectKey{
+		if err := reader.Get(ctx, client.ObjectKey{
 			Namespace: namespace,
 			Name:      name,
 		}, &actual); err != nil {
@@ -332,81 +880,507 @@ func (s *Step) CheckResourceAbsent(expected runtime.Object, namespace string) er
 
 		actuals = []unstructured.Unstructured{actual}
 	} else {
-		actuals, err = list(cl, gvk, namespace)
+		actuals, err = list(ctx, reader, gvk, namespace)
 		if err != nil {
 			return err
 		}
 	}
 
-	expectedObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(expected)
+	return s.matchErrorObject(expected, actuals)
+}
+
+// matchErrorObject returns an error wrapping errResourceMatched if any of the actual objects matches an error object.
+func (s *Step) matchErrorObject(expected runtime.Object, actuals []unstructured.Unstructured) error {
+	// the expected object is copied, the content of unstructured objects is shared by the converter
+	expectedObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(expected.DeepCopyObject())
+	if err != nil {
+		return err
+	}
+	removeAnnotations(expectedObj)
+
+	options, err := s.subsetOptions(expected)
 	if err != nil {
 		return err
 	}
 
+	ignoredFields := s.ignoredFields()
+	testutils.RemoveFields(expectedObj, ignoredFields)
+
 	for _, actual := range actuals {
-		if err := testutils.IsSubset(expectedObj, actual.UnstructuredContent()); err == nil {
-			return fmt.Errorf("resource matched of kind: %s", gvk.String())
+		testutils.RemoveFields(actual.Object, ignoredFields)
+		if err := testutils.IsSubsetWithOptions(expectedObj, actual.UnstructuredContent(), options); err == nil {
+			return fmt.Errorf("%w of kind: %s", errResourceMatched, expected.GetObjectKind().GroupVersionKind().String())
 		}
 	}
 
 	return nil
 }
 
-// Check checks if the resources defined in Asserts and Errors are in the correct state.
-func (s *Step) Check(namespace string) []error {
-	testErrors := []error{}
+// ignoredFields returns the fields ignored by the asserts of the step, see testutils.RemoveFields.
+func (s *Step) ignoredFields() []string {
+	if s.Assert == nil {
+		return s.IgnoredFields
+	}
+	return append(append([]string{}, s.IgnoredFields...), s.Assert.IgnoredFields...)
+}
+
+// subsetOptions returns the options to compare an expected object with, the array matching modes of the
+// TestAssert are overridden by the array matching annotation of the object. Unknown modes are an error.
+func (s *Step) subsetOptions(expected runtime.Object) (testutils.SubsetOptions, error) {
+	options := testutils.SubsetOptions{ArrayMatching: map[string]testutils.ArrayMatching{}}
+
+	if s.Assert != nil {
+		for path, mode := range s.Assert.ArrayMatching {
+			if !testutils.ArrayMatching(mode).Valid() {
+				return options, fmt.Errorf("invalid array matching mode %q of %s: must be exactOrder, setEquality, anyElementMatches or allElementsMatch", mode, path)
+			}
+			options.ArrayMatching[path] = testutils.ArrayMatching(mode)
+		}
+	}
+
+	m, err := meta.Accessor(expected)
+	if err != nil {
+		return options, err
+	}
 
-	for _, expected := range s.Asserts {
-		testErrors = append(testErrors, s.CheckResource(expected, namespace)...)
+	value, ok := m.GetAnnotations()[arrayMatchingAnnotation]
+	if !ok {
+		return options, nil
 	}
 
-	for _, expected := range s.Errors {
-		if testError := s.CheckResourceAbsent(expected, namespace); testError != nil {
-			testErrors = append(testErrors, testError)
+	for _, pathMode := range strings.Split(value, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pathMode), "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return options, fmt.Errorf("invalid %s annotation %q on %s: must be a list of path=mode", arrayMatchingAnnotation, value, testutils.ResourceID(expected))
+		}
+		if !testutils.ArrayMatching(kv[1]).Valid() {
+			return options, fmt.Errorf("invalid %s annotation %q on %s: unknown mode %q", arrayMatchingAnnotation, value, testutils.ResourceID(expected), kv[1])
 		}
+		options.ArrayMatching[kv[0]] = testutils.ArrayMatching(kv[1])
+	}
+
+	return options, nil
+}
+
+// removeAnnotations removes the annotations of kuttl from an expected object, they are not part of the expected state.
+func removeAnnotations(obj map[string]interface{}) {
+	annotations, _, _ := unstructured.NestedStringMap(obj, "metadata", "annotations")
+	_, hasTimeout := annotations[timeoutAnnotation]
+	_, hasArrayMatching := annotations[arrayMatchingAnnotation]
+	_, hasCluster := annotations[clusterAnnotation]
+	_, hasObservedGeneration := annotations[observedGenerationAnnotation]
+	if !hasTimeout && !hasArrayMatching && !hasCluster && !hasObservedGeneration {
+		return
+	}
+
+	delete(annotations, timeoutAnnotation)
+	delete(annotations, arrayMatchingAnnotation)
+	delete(annotations, clusterAnnotation)
+	delete(annotations, observedGenerationAnnotation)
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(obj, "metadata", "annotations")
+		return
+	}
+	_ = unstructured.SetNestedStringMap(obj, annotations, "metadata", "annotations")
+}
+
+// observedGeneration returns whether the status of the objects matching an assert object must be written for their
+// latest generation, the observed generation annotation overrides TestAssert.ObservedGeneration.
+func (s *Step) observedGeneration(expected runtime.Object) (bool, error) {
+	m, err := meta.Accessor(expected)
+	if err != nil {
+		return false, err
+	}
+
+	value, ok := m.GetAnnotations()[observedGenerationAnnotation]
+	if !ok {
+		return s.Assert != nil && s.Assert.ObservedGeneration, nil
+	}
+
+	required, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s annotation %q on %s: must be true or false", observedGenerationAnnotation, value, testutils.ResourceID(expected))
+	}
+	return required, nil
+}
+
+// staleStatus returns why the status of an object was not written for its latest generation, it is empty if it was.
+func staleStatus(actual *unstructured.Unstructured) string {
+	observed, found, err := unstructured.NestedInt64(actual.Object, "status", "observedGeneration")
+	if err != nil || !found {
+		return "status.observedGeneration is not set"
+	}
+	if observed != actual.GetGeneration() {
+		return fmt.Sprintf("status.observedGeneration %d is not metadata.generation %d", observed, actual.GetGeneration())
+	}
+	return ""
+}
+
+// objectTimeout returns the timeout of an assert object, the timeout annotation overrides the step timeout.
+func (s *Step) objectTimeout(obj runtime.Object) (int, error) {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return 0, err
+	}
+
+	value, ok := m.GetAnnotations()[timeoutAnnotation]
+	if !ok {
+		return s.GetTimeout(), nil
 	}
 
+	timeout, err := strconv.Atoi(value)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid %s annotation %q on %s: must be a positive number of seconds", timeoutAnnotation, value, testutils.ResourceID(obj))
+	}
+	return timeout, nil
+}
+
+// Check checks if the resources defined in Asserts and Errors are in the correct state
+// and if the commands, exec commands and the event, log, metrics, HTTP, access and golden asserts of the TestAssert succeed.
+func (s *Step) Check(ctx context.Context, namespace string) []error {
+	testErrors, _ := s.check(ctx, namespace, time.Now())
 	return testErrors
 }
 
+// check is Check which also returns the deadline of the failed checks of a step started at a time: the earliest
+// deadline of the failed assert objects, which is their own timeout after the start, see objectTimeout, and the step
+// timeout after the start if any other check failed.
+func (s *Step) check(ctx context.Context, namespace string, start time.Time) ([]error, time.Time) {
+	testErrors := []error{}
+	deadline := time.Time{}
+	addDeadline := func(timeout int) {
+		if d := start.Add(time.Duration(timeout) * time.Second); deadline.IsZero() || d.Before(deadline) {
+			deadline = d
+		}
+	}
+
+	assertErrors := concurrently(len(s.Asserts), func(i int) []error {
+		return s.CheckResource(ctx, s.Asserts[i], namespace)
+	})
+	for i, errs := range assertErrors {
+		if len(errs) == 0 {
+			continue
+		}
+
+		testErrors = append(testErrors, errs...)
+		// invalid annotations are rejected when the step is loaded
+		objectTimeout, _ := s.objectTimeout(s.Asserts[i])
+		addDeadline(objectTimeout)
+	}
+
+	otherErrors := []error{}
+
+	errorErrors := concurrently(len(s.Errors), func(i int) []error {
+		if testError := s.CheckResourceAbsent(ctx, s.Errors[i], namespace); testError != nil {
+			return []error{testError}
+		}
+		return nil
+	})
+	for _, errs := range errorErrors {
+		otherErrors = append(otherErrors, errs...)
+	}
+
+	if commands, err := s.assertCommands(); err != nil {
+		otherErrors = append(otherErrors, err)
+	} else if len(commands) > 0 {
+		env, err := s.commandEnv(ctx, namespace)
+		if err == nil {
+			_, err = testutils.RunCommands(ctx, s.Logger, namespace, commands, s.Dir, s.Timeout, env)
+		}
+		if err != nil {
+			otherErrors = append(otherErrors, err)
+		}
+	}
+
+	if s.Assert != nil {
+		otherErrors = append(otherErrors, s.RunExec(ctx, namespace, s.Assert.Exec)...)
+	}
+	otherErrors = append(otherErrors, s.CheckEvents(ctx, namespace)...)
+	otherErrors = append(otherErrors, s.CheckMetrics(ctx, namespace)...)
+	otherErrors = append(otherErrors, s.CheckHTTP(ctx, namespace)...)
+	otherErrors = append(otherErrors, s.CheckAccess(ctx, namespace)...)
+	otherErrors = append(otherErrors, s.CheckJobs(ctx, namespace)...)
+	otherErrors = append(otherErrors, s.CheckRestarts(ctx, namespace)...)
+	otherErrors = append(otherErrors, s.CheckSnapshots(ctx, namespace)...)
+	otherErrors = append(otherErrors, s.CheckConditions(ctx, namespace)...)
+	otherErrors = append(otherErrors, s.CheckOwners(ctx, namespace)...)
+	otherErrors = append(otherErrors, s.CheckFinalizers(ctx, namespace)...)
+	otherErrors = append(otherErrors, s.CheckReleases(ctx, namespace)...)
+
+	// golden files are only updated once all other checks succeeded, so they contain the asserted state
+	if !s.UpdateGolden || (len(testErrors) == 0 && len(otherErrors) == 0) {
+		otherErrors = append(otherErrors, s.CheckGolden(ctx, namespace)...)
+	}
+
+	if len(otherErrors) > 0 {
+		addDeadline(s.GetTimeout())
+	}
+
+	return append(testErrors, otherErrors...), deadline
+}
+
+// stepParallelism is the maximum number of objects of a step which are applied or checked at once.
+const stepParallelism = 8
+
+// concurrently runs a function for n objects with at most stepParallelism calls at once. The errors are returned in
+// the order of the objects, regardless of the order the calls finished in.
+func concurrently(n int, f func(i int) []error) [][]error {
+	results := make([][]error, n)
+	sem := make(chan struct{}, stepParallelism)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		i := i
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = f(i)
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// assertCommands returns the commands of the TestAssert followed by the commands fetching the logs of its log asserts.
+func (s *Step) assertCommands() ([]harness.Command, error) {
+	if s.Assert == nil {
+		return nil, nil
+	}
+
+	commands := append([]harness.Command{}, s.Assert.Commands...)
+	for _, logs := range s.Assert.Logs {
+		cmd, err := logs.Command()
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, *cmd)
+	}
+	return commands, nil
+}
+
+// CheckConsistently checks every second that the asserts keep succeeding for a duration (in seconds).
+// It returns the errors of the first failed check or the error of the context if it is done.
+func (s *Step) CheckConsistently(ctx context.Context, namespace string, duration int) []error {
+	s.Logger.Logf("checking that the asserts succeed for %d seconds", duration)
+
+	deadline := time.Now().Add(time.Duration(duration) * time.Second)
+	for time.Now().Before(deadline) {
+		if err := sleep(ctx, time.Second); err != nil {
+			return []error{err}
+		}
+
+		if testErrors := s.Check(ctx, namespace); len(testErrors) > 0 {
+			return append([]error{fmt.Errorf("asserts did not succeed consistently for %d seconds", duration)}, testErrors...)
+		}
+	}
+
+	return []error{}
+}
+
+// retryBackoff returns the backoff of the retries of the checks of the step, the backoff of the TestAssert overrides
+// the backoff of the test suite.
+func (s *Step) retryBackoff() *wait.Backoff {
+	b := s.Backoff
+	if s.Assert != nil && s.Assert.Backoff != nil {
+		b = s.Assert.Backoff
+	}
+	if b == nil {
+		b = &harness.Backoff{}
+	}
+
+	backoff := &wait.Backoff{
+		Duration: time.Second,
+		Factor:   1,
+		Jitter:   float64(b.Jitter) / 100,
+		Cap:      time.Duration(b.Max) * time.Millisecond,
+		// the intervals grow until the cap, the retries are limited by the timeout
+		Steps: math.MaxInt32,
+	}
+	if b.Initial > 0 {
+		backoff.Duration = time.Duration(b.Initial) * time.Millisecond
+	}
+	if b.Factor != nil {
+		backoff.Factor = backoffFactor(b.Factor)
+	}
+	return backoff
+}
+
+// backoffFactor converts the factor of a backoff to a float, e.g. 1.5.
+func backoffFactor(factor *resource.Quantity) float64 {
+	return float64(factor.MilliValue()) / 1000
+}
+
+// validateBackoff validates the intervals and the factor of a backoff.
+func validateBackoff(b *harness.Backoff) error {
+	if b.Initial < 0 || b.Max < 0 || b.Jitter < 0 {
+		return errors.New("backoff initial, max and jitter must not be negative")
+	}
+	if b.Factor != nil && backoffFactor(b.Factor) < 1 {
+		return fmt.Errorf("backoff factor %s must be at least 1", b.Factor.String())
+	}
+	return nil
+}
+
+// sleep waits for a duration or until the context is done, in which case the error of the context is returned.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Run runs a KUTTL test step:
 // 1. Apply all desired objects to Kubernetes.
 // 2. Wait for all of the states defined in the test step's asserts to be true.'
-func (s *Step) Run(namespace string) []error {
+// If the context is done, the running commands are killed and the step fails with the error of the context.
+func (s *Step) Run(ctx context.Context, namespace string) []error {
 	s.Logger.Log("starting test step", s.String())
 
-	if err := s.DeleteExisting(namespace); err != nil {
+	if err := s.DeleteExisting(ctx, namespace); err != nil {
+		return []error{err}
+	}
+
+	if err := s.Terminate(ctx, namespace); err != nil {
+		return []error{err}
+	}
+
+	if err := s.RecordRestarts(ctx, namespace); err != nil {
 		return []error{err}
 	}
 
+	// port forwards are established before the commands run and torn down at the end of the step
+	defer s.stopPortForwards()
+	if err := s.startPortForwards(ctx, namespace); err != nil {
+		return []error{err}
+	}
+
+	// error objects are watched for the whole step if TestAssert.WatchErrors is set
+	watcher := s.watchErrors(ctx, namespace)
+	defer watcher.Stop()
+
+	// the objects of drift asserts are watched for changes during the whole step
+	drift := s.watchDrift(ctx, namespace)
+	defer drift.Stop()
+
 	testErrors := []error{}
 
 	if s.Step != nil {
-		for _, command := range s.Step.Commands {
-			if command.Background {
-				s.Logger.Log("background commands are not allowed for steps and will be run in foreground")
-				command.Background = false
-			}
-		}
-		if _, err := testutils.RunCommands(s.Logger, namespace, s.Step.Commands, s.Dir, s.Timeout); err != nil {
+		if err := s.runCommands(ctx, namespace); err != nil {
 			testErrors = append(testErrors, err)
 		}
+		if len(testErrors) == 0 {
+			testErrors = append(testErrors, s.RunExec(ctx, namespace, s.Step.Exec)...)
+		}
+	}
+
+	if len(testErrors) == 0 {
+		testErrors = append(testErrors, s.RunJobs(ctx, namespace)...)
+	}
+
+	if len(testErrors) == 0 {
+		testErrors = append(testErrors, s.RunHelm(ctx, namespace)...)
 	}
 
-	testErrors = append(testErrors, s.Create(namespace)...)
+	if len(testErrors) == 0 {
+		testErrors = append(testErrors, s.RunOLM(ctx, namespace)...)
+	}
+
+	if len(testErrors) == 0 {
+		testErrors = append(testErrors, s.RunChaos(ctx, namespace)...)
+	}
+
+	if len(testErrors) == 0 {
+		testErrors = append(testErrors, s.RunScale(ctx, namespace)...)
+	}
+
+	if len(testErrors) == 0 {
+		testErrors = append(testErrors, s.RunRestart(ctx, namespace)...)
+	}
+
+	if len(testErrors) == 0 {
+		testErrors = append(testErrors, s.WaitFor(ctx, namespace)...)
+	}
+
+	testErrors = append(testErrors, s.Patch(ctx, namespace)...)
+	testErrors = append(testErrors, s.Create(ctx, namespace)...)
 
 	if len(testErrors) != 0 {
+		s.Logger.Log("test step failed", s.String())
+		s.logEvents(namespace)
 		return testErrors
 	}
 
-	for i := 0; i < s.GetTimeout(); i++ {
-		testErrors = s.Check(namespace)
+	// the checks are retried until the deadline of a failing check, the timeout of the step or of the failing assert
+	// object after the start, passed
+	backoff := s.retryBackoff()
+	start := time.Now()
+	for {
+		var deadline time.Time
+		testErrors, deadline = s.check(ctx, namespace, start)
 
-		if len(testErrors) == 0 {
+		if err := watcher.Matched(); err != nil {
+			testErrors = append(testErrors, err)
+			break
+		}
+		if errs := drift.Drifted(); len(errs) > 0 {
+			testErrors = append(testErrors, errs...)
+			break
+		}
+
+		remaining := time.Until(deadline)
+		if len(testErrors) == 0 || remaining <= 0 {
 			break
 		}
 
-		time.Sleep(time.Second)
+		// long intervals of the backoff do not extend the deadline
+		interval := backoff.Step()
+		if interval > remaining {
+			interval = remaining
+		}
+		if err := sleep(ctx, interval); err != nil {
+			testErrors = append(testErrors, err)
+			break
+		}
+	}
+
+	if len(testErrors) == 0 && s.Assert != nil && s.Assert.Consistently > 0 {
+		testErrors = s.CheckConsistently(ctx, namespace, s.Assert.Consistently)
+	}
+
+	if len(testErrors) == 0 {
+		if err := watcher.Stop(); err != nil {
+			testErrors = append(testErrors, err)
+		}
+	}
+
+	if len(testErrors) == 0 {
+		testErrors = append(testErrors, drift.Stop()...)
+	}
+
+	if len(testErrors) == 0 {
+		testErrors = s.CheckProcesses()
+	}
+
+	if len(testErrors) == 0 {
+		testErrors = append(testErrors, s.Capture(ctx, namespace)...)
+	}
+
+	if len(testErrors) == 0 {
+		testErrors = append(testErrors, s.Snapshot(ctx, namespace)...)
+	}
+
+	if len(testErrors) == 0 && s.Record {
+		if err := s.RecordAsserts(ctx, namespace); err != nil {
+			testErrors = append(testErrors, err)
+		}
 	}
 
 	// all is good
@@ -416,6 +1390,7 @@ func (s *Step) Run(namespace string) []error {
 	}
 	// test failure processing
 	s.Logger.Log("test step failed", s.String())
+	s.logEvents(namespace)
 	if s.Assert == nil {
 		return testErrors
 	}
@@ -425,7 +1400,7 @@ func (s *Step) Run(namespace string) []error {
 			s.Logger.Log("skipping invalid assertion collector")
 			continue
 		}
-		_, err := testutils.RunCommand(context.TODO(), namespace, *collector.Command(), s.Dir, s.Logger, s.Logger, s.Logger, s.Timeout)
+		_, err := testutils.RunCommand(context.TODO(), namespace, *collector.Command(), s.Dir, s.Logger, s.Logger, s.Logger, s.Timeout, s.Env)
 		if err != nil {
 			s.Logger.Log("post assert collector failure: %s", err)
 		}
@@ -436,6 +1411,53 @@ func (s *Step) Run(namespace string) []error {
 	return testErrors
 }
 
+// byLastTimestamp sorts a slice of core events by last timestamp, using their involvedObject's name as a tie breaker.
+type byLastTimestamp []corev1.Event
+
+func (o byLastTimestamp) Len() int      { return len(o) }
+func (o byLastTimestamp) Swap(i, j int) { o[i], o[j] = o[j], o[i] }
+
+func (o byLastTimestamp) Less(i, j int) bool {
+	if o[i].LastTimestamp.Equal(&o[j].LastTimestamp) {
+		return o[i].InvolvedObject.Name < o[j].InvolvedObject.Name
+	}
+	return o[i].LastTimestamp.Before(&o[j].LastTimestamp)
+}
+
+// CollectEvents lists all core/v1 events of the test namespace sorted by time and prints them to the step log.
+// It is used on a step failure to provide the context of what happened in the cluster while the step was running.
+func (s *Step) CollectEvents(namespace string) {
+	cl, err := s.Client(false)
+	if err != nil {
+		s.Logger.Logf("failed to collect events for step %s in ns %s: %v", s.String(), namespace, err)
+		return
+	}
+
+	eventList := &corev1.EventList{}
+	if err := cl.List(context.TODO(), eventList, client.InNamespace(namespace)); err != nil {
+		s.Logger.Logf("failed to collect events for step %s in ns %s: %v", s.String(), namespace, err)
+		return
+	}
+
+	events := eventList.Items
+	sort.Sort(byLastTimestamp(events))
+
+	s.Logger.Logf("step %s events from ns %s:", s.String(), namespace)
+	for _, e := range events {
+		// time type reason object message
+		s.Logger.Logf("%s\t%s\t%s\t%s/%s\t%s", e.LastTimestamp, e.Type, e.Reason, e.InvolvedObject.Kind, e.InvolvedObject.Name, e.Message)
+	}
+}
+
+// logEvents collects the namespace events on a step failure unless event logging is suppressed.
+func (s *Step) logEvents(namespace string) {
+	if funk.Contains(s.Suppress, "events") {
+		s.Logger.Log("skipping kubernetes event logging")
+		return
+	}
+	s.CollectEvents(namespace)
+}
+
 // String implements the string interface, returning the name of the test step.
 func (s *Step) String() string {
 	return fmt.Sprintf("%d-%s", s.Index, s.Name)
@@ -448,7 +1470,7 @@ func (s *Step) String() string {
 //   if seen, mark a test immediately failed.
 // * All other YAML files are considered resources to create.
 func (s *Step) LoadYAML(file string) error {
-	objects, err := testutils.LoadYAMLFromFile(file)
+	objects, err := s.loadFile(file)
 	if err != nil {
 		return fmt.Errorf("loading %s: %s", file, err)
 	}
@@ -507,8 +1529,8 @@ func (s *Step) LoadYAML(file string) error {
 	if s.Step != nil {
 		// process configured step applies
 		for _, applyPath := range s.Step.Apply {
-			exApply := env.Expand(applyPath)
-			apply, err := RuntimeObjectsFromPath(exApply, s.Dir)
+			exApply := env.ExpandWithMap(applyPath, s.values)
+			apply, err := s.objectsFromPath(exApply)
 			if err != nil {
 				return fmt.Errorf("step %q apply path %s: %w", s.Name, exApply, err)
 			}
@@ -516,8 +1538,8 @@ func (s *Step) LoadYAML(file string) error {
 		}
 		// process configured step asserts
 		for _, assertPath := range s.Step.Assert {
-			exAssert := env.Expand(assertPath)
-			assert, err := RuntimeObjectsFromPath(exAssert, s.Dir)
+			exAssert := env.ExpandWithMap(assertPath, s.values)
+			assert, err := s.objectsFromPath(exAssert)
 			if err != nil {
 				return fmt.Errorf("step %q assert path %s: %w", s.Name, exAssert, err)
 			}
@@ -525,13 +1547,55 @@ func (s *Step) LoadYAML(file string) error {
 		}
 		// process configured errors
 		for _, errorPath := range s.Step.Error {
-			exError := env.Expand(errorPath)
-			errObjs, err := RuntimeObjectsFromPath(exError, s.Dir)
+			exError := env.ExpandWithMap(errorPath, s.values)
+			errObjs, err := s.objectsFromPath(exError)
 			if err != nil {
 				return fmt.Errorf("step %q error path %s: %w", s.Name, exError, err)
 			}
 			s.Errors = append(s.Errors, errObjs...)
 		}
+		// process the generators of the step
+		for _, generate := range s.Step.Generate {
+			apply, err := s.generateObjects(generate, generate.Apply)
+			if err != nil {
+				return fmt.Errorf("step %q generate: %w", s.Name, err)
+			}
+			applies = append(applies, apply...)
+
+			assert, err := s.generateObjects(generate, generate.Assert)
+			if err != nil {
+				return fmt.Errorf("step %q generate: %w", s.Name, err)
+			}
+			asserts = append(asserts, assert...)
+
+			errObjs, err := s.generateObjects(generate, generate.Error)
+			if err != nil {
+				return fmt.Errorf("step %q generate: %w", s.Name, err)
+			}
+			s.Errors = append(s.Errors, errObjs...)
+		}
+	}
+
+	for _, obj := range asserts {
+		if _, err := s.objectTimeout(obj); err != nil {
+			return fmt.Errorf("step %q: %w", s.Name, err)
+		}
+		if _, err := s.observedGeneration(obj); err != nil {
+			return fmt.Errorf("step %q: %w", s.Name, err)
+		}
+	}
+	for _, obj := range append(append([]runtime.Object{}, asserts...), s.Errors...) {
+		if _, err := s.subsetOptions(obj); err != nil {
+			return fmt.Errorf("step %q: %w", s.Name, err)
+		}
+	}
+	if s.Assert != nil && s.Assert.Backoff != nil {
+		if err := validateBackoff(s.Assert.Backoff); err != nil {
+			return fmt.Errorf("step %q: %w", s.Name, err)
+		}
+	}
+	if _, err := applyGroups(applies); err != nil {
+		return fmt.Errorf("step %q: %w", s.Name, err)
 	}
 
 	s.Apply = applies
@@ -539,8 +1603,13 @@ func (s *Step) LoadYAML(file string) error {
 	return nil
 }
 
-// RuntimeObjectsFromPath returns an array of runtime.Objects for files / urls provided
+// RuntimeObjectsFromPath returns an array of runtime.Objects for files / urls / OCI artifacts provided, kustomization
+// directories are built
 func RuntimeObjectsFromPath(path, dir string) ([]runtime.Object, error) {
+	if http.IsOCIReference(path) {
+		return http.OCIToRuntimeObjects(path)
+	}
+
 	if http.IsURL(path) {
 		apply, err := http.ToRuntimeObjects(path)
 		if err != nil {
@@ -549,6 +1618,10 @@ func RuntimeObjectsFromPath(path, dir string) ([]runtime.Object, error) {
 		return apply, nil
 	}
 
+	if isKustomization(filepath.Join(dir, path)) {
+		return kustomizeObjects(filepath.Join(dir, path))
+	}
+
 	// it's a directory or file
 	paths, err := kfile.FromPath(filepath.Join(dir, path), "*.yaml")
 	if err != nil {
@@ -253,7 +253,7 @@ func TestCheckResourceIntegration(t *testing.T) {
 				DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testenv.DiscoveryClient, nil },
 			}
 
-			errors := step.CheckResource(test.expected, namespace)
+			errors := step.CheckResource(context.TODO(), test.expected, namespace)
 
 			if test.shouldError {
 				assert.NotEqual(t, []error{}, errors)
@@ -316,7 +316,7 @@ func TestStepDeleteExistingLabelMatch(t *testing.T) {
 	assert.Nil(t, testenv.Client.Get(context.TODO(), testutils.ObjectKey(podToDelete), podToDelete))
 	assert.Nil(t, testenv.Client.Get(context.TODO(), testutils.ObjectKey(podToDelete2), podToDelete2))
 
-	assert.Nil(t, step.DeleteExisting(namespace))
+	assert.Nil(t, step.DeleteExisting(context.TODO(), namespace))
 
 	assert.Nil(t, testenv.Client.Get(context.TODO(), testutils.ObjectKey(podToKeep), podToKeep))
 	assert.True(t, k8serrors.IsNotFound(testenv.Client.Get(context.TODO(), testutils.ObjectKey(podToDelete), podToDelete)))
@@ -427,6 +427,6 @@ func TestStepFailure(t *testing.T) {
 		Timeout:         1,
 	}
 
-	errs := step.Run(namespace)
+	errs := step.Run(context.TODO(), namespace)
 	assert.Equal(t, len(errs), 1)
 }
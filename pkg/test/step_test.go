@@ -2,16 +2,28 @@ package test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/discovery"
+	fakediscovery "k8s.io/client-go/discovery/fake"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -75,7 +87,7 @@ func TestStepCreate(t *testing.T) {
 		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
 	}
 
-	assert.Equal(t, []error{}, step.Create(testNamespace))
+	assert.Equal(t, []error{}, step.Create(context.TODO(), testNamespace))
 
 	assert.Nil(t, cl.Get(context.TODO(), testutils.ObjectKey(pod), pod))
 	assert.Nil(t, cl.Get(context.TODO(), testutils.ObjectKey(clusterScopedResource), clusterScopedResource))
@@ -127,13 +139,133 @@ func TestStepDeleteExisting(t *testing.T) {
 	assert.Nil(t, cl.Get(context.TODO(), testutils.ObjectKey(podToDelete), podToDelete))
 	assert.Nil(t, cl.Get(context.TODO(), testutils.ObjectKey(podToDeleteDefaultNS), podToDeleteDefaultNS))
 
-	assert.Nil(t, step.DeleteExisting(testNamespace))
+	assert.Nil(t, step.DeleteExisting(context.TODO(), testNamespace))
 
 	assert.Nil(t, cl.Get(context.TODO(), testutils.ObjectKey(podToKeep), podToKeep))
 	assert.True(t, k8serrors.IsNotFound(cl.Get(context.TODO(), testutils.ObjectKey(podToDelete), podToDelete)))
 	assert.True(t, k8serrors.IsNotFound(cl.Get(context.TODO(), testutils.ObjectKey(podToDeleteDefaultNS), podToDeleteDefaultNS)))
 }
 
+// Verify that the Teardown method deletes the cleanup resources of a test step.
+func TestStepTeardown(t *testing.T) {
+	podToDelete := testutils.NewPod("cleanup-me", testNamespace)
+	podToKeep := testutils.NewPod("keep-me", testNamespace)
+
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme, podToDelete, podToKeep)
+
+	step := Step{
+		Logger: testutils.NewTestLogger(t, ""),
+		Step: &harness.TestStep{
+			Cleanup: &harness.Cleanup{
+				Delete: []harness.ObjectReference{
+					{
+						ObjectReference: corev1.ObjectReference{
+							Kind:       "Pod",
+							APIVersion: "v1",
+							Name:       "cleanup-me",
+						},
+					},
+					{
+						ObjectReference: corev1.ObjectReference{
+							Kind:       "Pod",
+							APIVersion: "v1",
+							Name:       "already-deleted",
+						},
+					},
+				},
+			},
+		},
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+	}
+
+	assert.Equal(t, []error{}, step.Teardown(testNamespace))
+
+	assert.Nil(t, cl.Get(context.TODO(), testutils.ObjectKey(podToKeep), podToKeep))
+	assert.True(t, k8serrors.IsNotFound(cl.Get(context.TODO(), testutils.ObjectKey(podToDelete), podToDelete)))
+}
+
+func TestStepDeleteOptions(t *testing.T) {
+	gracePeriod := int64(5)
+
+	for _, test := range []struct {
+		name        string
+		options     *harness.DeleteOptions
+		expected    int
+		shouldError bool
+	}{
+		{name: "no options"},
+		{name: "propagation policy", options: &harness.DeleteOptions{PropagationPolicy: "Foreground"}, expected: 1},
+		{name: "grace period", options: &harness.DeleteOptions{PropagationPolicy: "Orphan", GracePeriodSeconds: &gracePeriod}, expected: 2},
+		{name: "invalid propagation policy", options: &harness.DeleteOptions{PropagationPolicy: "Never"}, shouldError: true},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			step := Step{Step: &harness.TestStep{DeleteOptions: test.options}}
+
+			opts, err := step.deleteOptions()
+			if test.shouldError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, len(opts))
+		})
+	}
+}
+
+// Verify that the Patch method patches existing resources during a test step.
+func TestStepPatch(t *testing.T) {
+	podToPatch := testutils.NewPod("patch-me", testNamespace)
+	podToJSONPatch := testutils.NewPod("json-patch-me", testNamespace)
+
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme, podToPatch, podToJSONPatch)
+
+	step := Step{
+		Logger: testutils.NewTestLogger(t, ""),
+		Step: &harness.TestStep{
+			Patch: []harness.Patch{
+				{
+					ObjectReference: harness.ObjectReference{
+						ObjectReference: corev1.ObjectReference{
+							Kind:       "Pod",
+							APIVersion: "v1",
+							Name:       "patch-me",
+						},
+					},
+					Patch: "metadata:\n  annotations:\n    patched: \"true\"",
+				},
+				{
+					ObjectReference: harness.ObjectReference{
+						ObjectReference: corev1.ObjectReference{
+							Kind:       "Pod",
+							APIVersion: "v1",
+							Name:       "json-patch-me",
+						},
+					},
+					Type:  "json",
+					Patch: `[{"op": "add", "path": "/metadata/annotations", "value": {"patched": "json"}}]`,
+				},
+			},
+		},
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+	}
+
+	assert.Equal(t, []error{}, step.Patch(context.TODO(), testNamespace))
+
+	actual := &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "Pod"}}
+	assert.Nil(t, cl.Get(context.TODO(), testutils.ObjectKey(podToPatch), actual))
+	assert.Equal(t, map[string]string{"patched": "true"}, actual.GetAnnotations())
+
+	assert.Nil(t, cl.Get(context.TODO(), testutils.ObjectKey(podToJSONPatch), actual))
+	assert.Equal(t, map[string]string{"patched": "json"}, actual.GetAnnotations())
+
+	step.Step.Patch[0].Type = "unknown"
+	assert.Equal(t, 1, len(step.Patch(context.TODO(), testNamespace)))
+}
+
 func TestCheckResource(t *testing.T) {
 	for _, test := range []struct {
 		testName    string
@@ -168,6 +300,11 @@ func TestCheckResource(t *testing.T) {
 			expected:    testutils.NewPod("hello", ""),
 			shouldError: true,
 		},
+		{
+			testName: "timeout annotation is not matched",
+			actual:   testutils.NewPod("hello", ""),
+			expected: testutils.WithAnnotations(testutils.NewPod("hello", ""), map[string]string{timeoutAnnotation: "120"}),
+		},
 	} {
 		test := test
 
@@ -186,7 +323,7 @@ func TestCheckResource(t *testing.T) {
 				DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return fakeDiscovery, nil },
 			}
 
-			errors := step.CheckResource(test.expected, namespace)
+			errors := step.CheckResource(context.TODO(), test.expected, namespace)
 
 			if test.shouldError {
 				assert.NotEqual(t, []error{}, errors)
@@ -197,6 +334,404 @@ func TestCheckResource(t *testing.T) {
 	}
 }
 
+func TestCheckResourceIgnoredFields(t *testing.T) {
+	actual := testutils.WithSpec(t, testutils.NewPod("hello", testNamespace), map[string]interface{}{"nodeName": "node-1"})
+	expected := testutils.WithSpec(t, testutils.NewPod("hello", ""), map[string]interface{}{"nodeName": "node-2"})
+
+	step := Step{
+		Logger:          testutils.NewTestLogger(t, ""),
+		Client:          func(bool) (client.Client, error) { return fake.NewFakeClientWithScheme(scheme.Scheme, actual), nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+	}
+	assert.NotEqual(t, []error{}, step.CheckResource(context.TODO(), expected, testNamespace))
+
+	step.IgnoredFields = []string{"metadata.resourceVersion"}
+	step.Assert = &harness.TestAssert{IgnoredFields: []string{"spec.nodeName"}}
+	assert.Equal(t, []error{}, step.CheckResource(context.TODO(), expected, testNamespace))
+	assert.Equal(t, []string{"metadata.resourceVersion", "spec.nodeName"}, step.ignoredFields())
+}
+
+func TestCheckResourceArrayMatching(t *testing.T) {
+	containers := func(names ...string) map[string]interface{} {
+		list := []interface{}{}
+		for _, name := range names {
+			list = append(list, map[string]interface{}{"name": name})
+		}
+		return map[string]interface{}{"containers": list}
+	}
+
+	actual := testutils.WithSpec(t, testutils.NewPod("hello", testNamespace), containers("nginx", "sidecar"))
+	expected := testutils.WithSpec(t, testutils.NewPod("hello", ""), containers("sidecar"))
+
+	step := Step{
+		Logger:          testutils.NewTestLogger(t, ""),
+		Client:          func(bool) (client.Client, error) { return fake.NewFakeClientWithScheme(scheme.Scheme, actual), nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+	}
+	assert.NotEqual(t, []error{}, step.CheckResource(context.TODO(), expected, testNamespace))
+
+	step.Assert = &harness.TestAssert{ArrayMatching: map[string]string{"spec.containers": "anyElementMatches"}}
+	assert.Equal(t, []error{}, step.CheckResource(context.TODO(), expected, testNamespace))
+
+	// the annotation overrides the TestAssert
+	annotated := testutils.WithAnnotations(expected.DeepCopyObject(), map[string]string{arrayMatchingAnnotation: "spec.containers=exactOrder"})
+	assert.NotEqual(t, []error{}, step.CheckResource(context.TODO(), annotated, testNamespace))
+
+	invalid := testutils.WithAnnotations(expected.DeepCopyObject(), map[string]string{arrayMatchingAnnotation: "spec.containers"})
+	assert.NotEqual(t, []error{}, step.CheckResource(context.TODO(), invalid, testNamespace))
+}
+
+func TestCheckResourceObservedGeneration(t *testing.T) {
+	actual := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello", Namespace: testNamespace, Generation: 2},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, ReadyReplicas: 1},
+	}
+	expected := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "hello"},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: 1},
+	}
+
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme, actual)
+	step := Step{
+		Logger:          testutils.NewTestLogger(t, ""),
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+	}
+	assert.Equal(t, []error{}, step.CheckResource(context.TODO(), expected, testNamespace))
+
+	// the status of the previous generation is stale
+	step.Assert = &harness.TestAssert{ObservedGeneration: true}
+	errs := step.CheckResource(context.TODO(), expected, testNamespace)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "resource Deployment:world/hello: the status is stale, status.observedGeneration 1 is not metadata.generation 2")
+
+	// objects without a status are not affected
+	assert.Equal(t, []error{}, step.CheckResource(context.TODO(), testutils.NewResource("apps/v1", "Deployment", "hello", ""), testNamespace))
+
+	// the annotation overrides the TestAssert
+	annotated := testutils.WithAnnotations(expected.DeepCopyObject(), map[string]string{observedGenerationAnnotation: "false"})
+	assert.Equal(t, []error{}, step.CheckResource(context.TODO(), annotated, testNamespace))
+
+	actual.Status.ObservedGeneration = 2
+	require.NoError(t, cl.Update(context.TODO(), actual))
+	assert.Equal(t, []error{}, step.CheckResource(context.TODO(), expected, testNamespace))
+
+	_, err := step.observedGeneration(testutils.WithAnnotations(expected.DeepCopyObject(), map[string]string{observedGenerationAnnotation: "yes"}))
+	assert.EqualError(t, err, `invalid kuttl.dev/observed-generation annotation "yes" on Deployment:world/hello: must be true or false`)
+}
+
+func TestStepCheckTimeout(t *testing.T) {
+	slow := testutils.WithAnnotations(testutils.NewPod("slow", ""), map[string]string{timeoutAnnotation: "120"})
+	fast := testutils.WithAnnotations(testutils.NewPod("fast", ""), map[string]string{timeoutAnnotation: "5"})
+
+	for _, test := range []struct {
+		name            string
+		asserts         []runtime.Object
+		expectedTimeout int
+	}{
+		{
+			name:            "step timeout",
+			asserts:         []runtime.Object{testutils.NewPod("missing", "")},
+			expectedTimeout: 30,
+		},
+		{
+			name:            "object timeout of failing object",
+			asserts:         []runtime.Object{slow},
+			expectedTimeout: 120,
+		},
+		{
+			name:            "earliest deadline of failing objects",
+			asserts:         []runtime.Object{testutils.NewPod("missing", ""), slow, fast},
+			expectedTimeout: 5,
+		},
+		{
+			name:            "object timeout of succeeding object",
+			asserts:         []runtime.Object{testutils.NewPod("missing", ""), testutils.WithAnnotations(testutils.NewPod("hello", ""), map[string]string{timeoutAnnotation: "5"})},
+			expectedTimeout: 30,
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			step := Step{
+				Timeout: 30,
+				Asserts: test.asserts,
+				Logger:  testutils.NewTestLogger(t, ""),
+				Client: func(bool) (client.Client, error) {
+					return fake.NewFakeClientWithScheme(scheme.Scheme, testutils.NewPod("hello", testNamespace)), nil
+				},
+				DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+			}
+
+			start := time.Now()
+			errs, deadline := step.check(context.TODO(), testNamespace, start)
+			assert.NotEqual(t, 0, len(errs))
+			assert.Equal(t, start.Add(time.Duration(test.expectedTimeout)*time.Second), deadline)
+		})
+	}
+
+	step := Step{Timeout: 30}
+	_, err := step.objectTimeout(testutils.WithAnnotations(testutils.NewPod("hello", ""), map[string]string{timeoutAnnotation: "soon"}))
+	assert.Error(t, err)
+}
+
+func TestStepRunObjectTimeout(t *testing.T) {
+	step := Step{
+		Timeout: 30,
+		Asserts: []runtime.Object{
+			testutils.NewPod("missing", ""),
+			testutils.WithAnnotations(testutils.NewPod("fast", ""), map[string]string{timeoutAnnotation: "1"}),
+		},
+		Logger:          testutils.NewTestLogger(t, ""),
+		Client:          func(bool) (client.Client, error) { return fake.NewFakeClientWithScheme(scheme.Scheme), nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+	}
+
+	// the step fails once the deadline of the object with the shorter timeout passed
+	start := time.Now()
+	assert.NotEmpty(t, step.Run(context.TODO(), testNamespace))
+	assert.Less(t, int64(time.Since(start)), int64(10*time.Second))
+}
+
+func TestStepCheckConsistently(t *testing.T) {
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme, testutils.NewPod("hello", testNamespace))
+
+	step := Step{
+		Asserts:         []runtime.Object{testutils.NewPod("hello", "")},
+		Logger:          testutils.NewTestLogger(t, ""),
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+	}
+
+	assert.Equal(t, []error{}, step.CheckConsistently(context.TODO(), testNamespace, 2))
+
+	// the pod is deleted while the asserts are checked
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+		assert.Nil(t, cl.Delete(context.TODO(), testutils.NewPod("hello", testNamespace)))
+	}()
+	assert.NotEqual(t, 0, len(step.CheckConsistently(context.TODO(), testNamespace, 3)))
+}
+
+func TestConcurrently(t *testing.T) {
+	var running, maxRunning int32
+
+	results := concurrently(20, func(i int) []error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+				break
+			}
+		}
+
+		// the later objects finish first
+		time.Sleep(time.Duration(20-i) * time.Millisecond)
+		if i%2 == 0 {
+			return nil
+		}
+		return []error{fmt.Errorf("object %d", i)}
+	})
+
+	require.Equal(t, 20, len(results))
+	for i, errs := range results {
+		if i%2 == 0 {
+			assert.Empty(t, errs)
+		} else {
+			assert.Equal(t, []error{fmt.Errorf("object %d", i)}, errs)
+		}
+	}
+	assert.True(t, maxRunning <= stepParallelism, "%d checks ran at once", maxRunning)
+}
+
+func TestApplyGroups(t *testing.T) {
+	withOrder := func(obj runtime.Object, order string) runtime.Object {
+		return testutils.WithAnnotations(obj, map[string]string{applyOrderAnnotation: order})
+	}
+
+	for _, tt := range []struct {
+		name     string
+		objs     []runtime.Object
+		expected [][]int
+		err      string
+	}{
+		{name: "no objects", expected: [][]int{}},
+		{
+			name:     "independent",
+			objs:     []runtime.Object{testutils.NewPod("a", ""), testutils.NewPod("b", "")},
+			expected: [][]int{{0, 1}},
+		},
+		{
+			name: "implicit order",
+			objs: []runtime.Object{
+				testutils.NewPod("a", ""),
+				testutils.NewResource("v1", "ServiceAccount", "sa", ""),
+				testutils.NewResource("apiextensions.k8s.io/v1", "CustomResourceDefinition", "crd", ""),
+				testutils.NewResource("v1", "Namespace", "ns", ""),
+			},
+			expected: [][]int{{2, 3}, {1}, {0}},
+		},
+		{
+			name: "apply order",
+			objs: []runtime.Object{
+				testutils.NewPod("a", ""),
+				withOrder(testutils.NewPod("b", ""), "1"),
+				withOrder(testutils.NewResource("v1", "Namespace", "ns", ""), "1"),
+				withOrder(testutils.NewPod("c", ""), "-1"),
+			},
+			expected: [][]int{{3}, {0}, {2}, {1}},
+		},
+		{
+			name: "invalid apply order",
+			objs: []runtime.Object{withOrder(testutils.NewPod("a", ""), "first")},
+			err:  `invalid kuttl.dev/apply-order annotation "first" on Pod:/a: must be a number`,
+		},
+		{
+			name: "waves",
+			objs: []runtime.Object{
+				testutils.NewPod("a", ""),
+				testutils.WithAnnotations(testutils.NewPod("b", ""), map[string]string{waveAnnotation: "-1"}),
+				withOrder(testutils.NewPod("c", ""), "-1"),
+			},
+			expected: [][]int{{1, 2}, {0}},
+		},
+		{
+			name: "apply order and wave",
+			objs: []runtime.Object{
+				testutils.WithAnnotations(testutils.NewPod("a", ""), map[string]string{applyOrderAnnotation: "1", waveAnnotation: "1"}),
+			},
+			err: "Pod:/a has both the kuttl.dev/apply-order and the kuttl.dev/wave annotation, only one may be set",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			groups, err := applyGroups(tt.objs)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, groups)
+		})
+	}
+}
+
+func TestStepCreateWaves(t *testing.T) {
+	readyPod := func(name, wave, ready string) runtime.Object {
+		pod := testutils.WithAnnotations(testutils.NewPod(name, ""), map[string]string{waveAnnotation: wave}).(*unstructured.Unstructured)
+		require.NoError(t, unstructured.SetNestedSlice(pod.Object, []interface{}{
+			map[string]interface{}{"type": "Ready", "status": ready},
+		}, "status", "conditions"))
+		return pod
+	}
+
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme)
+	step := Step{
+		Logger:  testutils.NewTestLogger(t, ""),
+		Timeout: 1,
+		Apply: []runtime.Object{
+			readyPod("ready", "0", "True"),
+			readyPod("not-ready", "1", "False"),
+			testutils.WithAnnotations(testutils.NewPod("after", ""), map[string]string{applyOrderAnnotation: "2"}),
+		},
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+	}
+
+	// the objects after a wave which is not ready are not applied
+	assert.Equal(t, []string{
+		"wave 1 is not ready within 1 sec timeout, Pod:world/not-ready: condition Ready is not true",
+	}, errorStrings(step.Create(context.TODO(), testNamespace)))
+
+	assert.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: "not-ready"}, &corev1.Pod{}))
+	err := cl.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: "after"}, &corev1.Pod{})
+	assert.True(t, k8serrors.IsNotFound(err))
+}
+
+// crdInstallingClient is a fake client which adds the kinds of the API group of a CRD to a fake discovery client when
+// the CRD is created.
+type crdInstallingClient struct {
+	client.Client
+	discovery *fakediscovery.FakeDiscovery
+	kind      metav1.APIResource
+}
+
+func (c *crdInstallingClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	if err := c.Client.Create(ctx, obj, opts...); err != nil {
+		return err
+	}
+	if obj.GetObjectKind().GroupVersionKind().Kind == "CustomResourceDefinition" && len(opts) == 0 {
+		c.discovery.Resources = append(c.discovery.Resources, &metav1.APIResourceList{
+			GroupVersion: appsv1.SchemeGroupVersion.String(),
+			APIResources: []metav1.APIResource{c.kind},
+		})
+	}
+	return nil
+}
+
+func TestStepCreateValidatesGroups(t *testing.T) {
+	dClient := testutils.FakeDiscoveryClient().(*fakediscovery.FakeDiscovery)
+	// the kind of the custom resource is only known once its CRD is applied
+	resources := []*metav1.APIResourceList{}
+	for _, list := range dClient.Resources {
+		if list.GroupVersion != appsv1.SchemeGroupVersion.String() {
+			resources = append(resources, list)
+		}
+	}
+	dClient.Resources = resources
+
+	cl := &crdInstallingClient{
+		Client:    fake.NewFakeClientWithScheme(scheme.Scheme),
+		discovery: dClient,
+		kind:      metav1.APIResource{Name: "deployments", Namespaced: true, Kind: "Deployment"},
+	}
+	step := Step{
+		Logger: testutils.NewTestLogger(t, ""),
+		Apply: []runtime.Object{
+			testutils.NewResource("apps/v1", "Deployment", "app", ""),
+			testutils.NewResource("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "deployments.apps", ""),
+		},
+		ValidateManifests: true,
+		Client:            func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient:   func() (discovery.DiscoveryInterface, error) { return dClient, nil },
+	}
+
+	// the custom resource is validated after its CRD was applied
+	assert.Empty(t, step.Create(context.TODO(), testNamespace))
+	assert.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: "app"}, &appsv1.Deployment{}))
+}
+
+func TestStepCheckErrorOrder(t *testing.T) {
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme, testutils.NewPod("pod-5", testNamespace))
+
+	asserts := []runtime.Object{}
+	for i := 0; i < 10; i++ {
+		asserts = append(asserts, testutils.NewPod(fmt.Sprintf("pod-%d", i), ""))
+	}
+
+	step := Step{
+		Asserts:         asserts,
+		Errors:          []runtime.Object{testutils.NewPod("pod-5", "")},
+		Logger:          testutils.NewTestLogger(t, ""),
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+	}
+
+	// the errors of the objects checked at once are in the order of the objects
+	errs := step.Check(context.TODO(), testNamespace)
+	require.Equal(t, 10, len(errs))
+	for i, err := range errs[:9] {
+		pod := i
+		if i >= 5 {
+			pod++
+		}
+		assert.Contains(t, err.Error(), fmt.Sprintf(`"pod-%d" not found`, pod))
+	}
+	assert.True(t, errors.Is(errs[9], errResourceMatched))
+}
+
 func TestCheckResourceAbsent(t *testing.T) {
 	for _, test := range []struct {
 		name        string
@@ -237,7 +772,7 @@ func TestCheckResourceAbsent(t *testing.T) {
 				DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return fakeDiscovery, nil },
 			}
 
-			error := step.CheckResourceAbsent(test.expected, testNamespace)
+			error := step.CheckResourceAbsent(context.TODO(), test.expected, testNamespace)
 
 			if test.shouldError {
 				assert.NotNil(t, error)
@@ -320,7 +855,7 @@ func TestRun(t *testing.T) {
 				}()
 			}
 
-			errors := test.Step.Run(testNamespace)
+			errors := test.Step.Run(context.TODO(), testNamespace)
 
 			if test.shouldError {
 				assert.NotEqual(t, []error{}, errors)
@@ -330,3 +865,187 @@ func TestRun(t *testing.T) {
 		})
 	}
 }
+
+func TestRetryBackoff(t *testing.T) {
+	intervals := func(s *Step, n int) []time.Duration {
+		backoff := s.retryBackoff()
+		durations := []time.Duration{}
+		for i := 0; i < n; i++ {
+			durations = append(durations, backoff.Step())
+		}
+		return durations
+	}
+
+	// a constant interval of 1 second by default
+	assert.Equal(t, []time.Duration{time.Second, time.Second, time.Second}, intervals(&Step{}, 3))
+
+	factor := resource.MustParse("2")
+	step := &Step{Backoff: &harness.Backoff{Initial: 100, Factor: &factor, Max: 500}}
+	assert.Equal(t, []time.Duration{
+		100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond,
+	}, intervals(step, 5))
+
+	// the factor may be a decimal number
+	factor = resource.MustParse("1.5")
+	step.Backoff.Max = 0
+	assert.Equal(t, []time.Duration{
+		100 * time.Millisecond, 150 * time.Millisecond, 225 * time.Millisecond,
+	}, intervals(step, 3))
+
+	// the backoff of the TestAssert overrides the backoff of the test suite
+	step.Assert = &harness.TestAssert{Backoff: &harness.Backoff{Initial: 200, Jitter: 50}}
+	for _, interval := range intervals(step, 10) {
+		assert.True(t, interval >= 200*time.Millisecond && interval <= 300*time.Millisecond, "interval %v", interval)
+	}
+}
+
+func TestStepRunCancelled(t *testing.T) {
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme)
+
+	for _, tt := range []struct {
+		name string
+		step *Step
+	}{
+		{
+			name: "running command is killed",
+			step: &Step{
+				Step: &harness.TestStep{Commands: []harness.Command{{Command: "sleep 30"}}},
+			},
+		},
+		{
+			name: "failing assert is not retried",
+			step: &Step{
+				Timeout: 30,
+				Asserts: []runtime.Object{testutils.NewPod("missing", "")},
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.step.Client = func(bool) (client.Client, error) { return cl, nil }
+			tt.step.DiscoveryClient = func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil }
+			tt.step.Logger = testutils.NewTestLogger(t, "")
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			start := time.Now()
+			errs := tt.step.Run(ctx, testNamespace)
+			assert.Less(t, int64(time.Since(start)), int64(10*time.Second))
+			require.NotEmpty(t, errs)
+			assert.True(t, errors.Is(errs[len(errs)-1], context.DeadlineExceeded), errs[len(errs)-1].Error())
+		})
+	}
+}
+
+func TestEventsByLastTimestamp(t *testing.T) {
+	now := time.Now()
+	events := []corev1.Event{
+		{InvolvedObject: corev1.ObjectReference{Name: "c"}, LastTimestamp: metav1.NewTime(now.Add(time.Minute))},
+		{InvolvedObject: corev1.ObjectReference{Name: "b"}, LastTimestamp: metav1.NewTime(now)},
+		{InvolvedObject: corev1.ObjectReference{Name: "a"}, LastTimestamp: metav1.NewTime(now)},
+	}
+
+	sort.Sort(byLastTimestamp(events))
+
+	assert.Equal(t, "a", events[0].InvolvedObject.Name)
+	assert.Equal(t, "b", events[1].InvolvedObject.Name)
+	assert.Equal(t, "c", events[2].InvolvedObject.Name)
+}
+
+func TestStepLoadYAMLBackoff(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-backoff")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, test := range []struct {
+		name    string
+		backoff string
+		err     string
+	}{
+		{name: "valid", backoff: "initial: 100\n  factor: 1.5\n  max: 1000\n  jitter: 10"},
+		{name: "integer factor", backoff: "factor: 2"},
+		{name: "negative initial", backoff: "initial: -100", err: "backoff initial, max and jitter must not be negative"},
+		{name: "negative max", backoff: "max: -1", err: "backoff initial, max and jitter must not be negative"},
+		{name: "negative jitter", backoff: "jitter: -10", err: "backoff initial, max and jitter must not be negative"},
+		{name: "factor below 1", backoff: "factor: 0.5", err: "backoff factor 500m must be at least 1"},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, "00-assert.yaml")
+			manifest := "apiVersion: kuttl.dev/v1beta1\nkind: TestAssert\nbackoff:\n  " + test.backoff + "\n"
+			require.NoError(t, ioutil.WriteFile(path, []byte(manifest), 0644))
+
+			step := Step{Name: "assert", Dir: dir}
+			err := step.LoadYAML(path)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, `step "assert": `+test.err)
+			}
+		})
+	}
+}
+
+func TestStepLoadYAMLArrayMatching(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-array-matching")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, test := range []struct {
+		name     string
+		manifest string
+		valid    bool
+	}{
+		{
+			name: "annotation",
+			manifest: `apiVersion: v1
+kind: Pod
+metadata:
+  name: hello
+  annotations:
+    kuttl.dev/array-matching: spec.containers=setEquality
+`,
+			valid: true,
+		},
+		{
+			name: "unknown annotation mode",
+			manifest: `apiVersion: v1
+kind: Pod
+metadata:
+  name: hello
+  annotations:
+    kuttl.dev/array-matching: spec.containers=anyOrder
+`,
+		},
+		{
+			name: "unknown test assert mode",
+			manifest: `apiVersion: kuttl.dev/v1beta1
+kind: TestAssert
+arrayMatching:
+  spec.containers: anyOrder
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: hello
+`,
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, "00-assert.yaml")
+			require.NoError(t, ioutil.WriteFile(path, []byte(test.manifest), 0644))
+
+			step := Step{Name: "assert", Dir: dir}
+			err := step.LoadYAML(path)
+			if test.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Contains(t, fmt.Sprint(err), "anyOrder")
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// testCaseFile is the file of a test case directory with the TestCase metadata of the test case.
+const testCaseFile = "kuttl-case.yaml"
+
+// loadTestCase reads the TestCase metadata of a test case directory, it is empty if it has no metadata file. The tags
+// dependencies and fixtures of multiple TestCase objects are merged, the requirements of the last one with requirements are used.
+func loadTestCase(dir string) (*harness.TestCase, error) {
+	metadata := &harness.TestCase{}
+
+	path := filepath.Join(dir, testCaseFile)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return metadata, nil
+	}
+
+	objects, err := testutils.LoadYAMLFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", path, err)
+	}
+
+	for _, obj := range objects {
+		testCase, ok := obj.(*harness.TestCase)
+		if !ok {
+			return nil, fmt.Errorf("%s: unexpected object %s, only a TestCase is allowed", path, testutils.ResourceID(obj))
+		}
+		metadata.Tags = append(metadata.Tags, testCase.Tags...)
+		metadata.DependsOn = append(metadata.DependsOn, testCase.DependsOn...)
+		metadata.Fixtures = append(metadata.Fixtures, testCase.Fixtures...)
+		if testCase.Requires != nil {
+			metadata.Requires = testCase.Requires
+		}
+	}
+	return metadata, nil
+}
+
+// selectsTags checks if a test case with the tags is selected by the Tags and SkipTags of the test suite.
+func (h *Harness) selectsTags(tags []string) bool {
+	if len(h.TestSuite.Tags) > 0 && !hasAnyTag(tags, h.TestSuite.Tags) {
+		return false
+	}
+	return !hasAnyTag(tags, h.TestSuite.SkipTags)
+}
+
+// hasAnyTag checks if any of the tags is one of the wanted tags.
+func hasAnyTag(tags, wanted []string) bool {
+	for _, tag := range tags {
+		for _, w := range wanted {
+			if tag == w {
+				return true
+			}
+		}
+	}
+	return false
+}
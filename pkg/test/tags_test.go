@@ -0,0 +1,77 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+)
+
+func TestLoadTestsTags(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tags")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for name, tags := range map[string]string{
+		"fast":     "[smoke]",
+		"slow":     "[smoke, slow]",
+		"untagged": "",
+	} {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, name), 0755))
+		if tags == "" {
+			continue
+		}
+		testCase := "apiVersion: kuttl.dev/v1beta1\nkind: TestCase\ntags: " + tags + "\n"
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name, testCaseFile), []byte(testCase), 0644))
+	}
+
+	for _, tt := range []struct {
+		name     string
+		tags     []string
+		skipTags []string
+		expected []string
+	}{
+		{name: "all", expected: []string{"fast", "slow", "untagged"}},
+		{name: "tags", tags: []string{"smoke"}, expected: []string{"fast", "slow"}},
+		{name: "skip tags", skipTags: []string{"slow"}, expected: []string{"fast", "untagged"}},
+		{name: "tags and skip tags", tags: []string{"smoke"}, skipTags: []string{"slow"}, expected: []string{"fast"}},
+		{name: "unknown tag", tags: []string{"unknown"}, expected: []string{}},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			h := Harness{T: t, TestSuite: harness.TestSuite{Tags: tt.tags, SkipTags: tt.skipTags}}
+
+			tests, err := h.LoadTests(dir)
+			require.NoError(t, err)
+
+			names := []string{}
+			for _, test := range tests {
+				names = append(names, test.Name)
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+
+	// the tags of a test case are loaded with it
+	h := Harness{T: t}
+	tests, err := h.LoadTests(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"smoke", "slow"}, tests[1].Tags)
+}
+
+func TestLoadTestCaseInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tags")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	configMap := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: tags\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, testCaseFile), []byte(configMap), 0644))
+
+	_, err = loadTestCase(dir)
+	assert.EqualError(t, err, filepath.Join(dir, testCaseFile)+": unexpected object ConfigMap:/tags, only a TestCase is allowed")
+}
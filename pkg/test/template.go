@@ -0,0 +1,152 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kfile "github.com/kudobuilder/kuttl/pkg/file"
+	"github.com/kudobuilder/kuttl/pkg/http"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// TemplateData is the data available to test files rendered as go templates.
+type TemplateData struct {
+	// Namespace is the namespace of the test case.
+	Namespace string
+	// Values are the values of the test suite.
+	Values map[string]string
+	// Env are the environment variables of the kuttl process.
+	Env map[string]string
+	// Vars are the variables captured by the previous steps of the test case.
+	Vars map[string]string
+}
+
+// NewTemplateData returns the template data for a test case namespace, the test suite values
+// and the variables of the test case.
+func NewTemplateData(namespace string, values, vars map[string]string) *TemplateData {
+	env := map[string]string{}
+	for _, envVar := range os.Environ() {
+		if splitVar := strings.SplitN(envVar, "=", 2); len(splitVar) == 2 {
+			env[splitVar[0]] = splitVar[1]
+		}
+	}
+
+	return &TemplateData{
+		Namespace: namespace,
+		Values:    values,
+		Env:       env,
+		Vars:      vars,
+	}
+}
+
+// Render renders the contents of a file as a go template.
+// Referencing a missing value is an error.
+func (d *TemplateData) Render(path string, contents []byte) ([]byte, error) {
+	tmpl, err := template.New(filepath.Base(path)).Option("missingkey=error").Parse(string(contents))
+	if err != nil {
+		return nil, fmt.Errorf("parsing template %s: %w", path, err)
+	}
+
+	rendered := &bytes.Buffer{}
+	if err := tmpl.Execute(rendered, d); err != nil {
+		return nil, fmt.Errorf("rendering template %s: %w", path, err)
+	}
+
+	return rendered.Bytes(), nil
+}
+
+// loadTemplates loads the files of a step which are rendered as templates.
+// They are loaded right before the step runs to render the variables captured by the previous steps.
+func (s *Step) loadTemplates() error {
+	for _, file := range s.files {
+		if err := s.LoadYAML(file); err != nil {
+			return err
+		}
+	}
+
+	s.files = nil
+	return nil
+}
+
+// LoadValues loads the values of a YAML values file. The file must contain a map of scalar values.
+func LoadValues(path string) (map[string]string, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := map[string]interface{}{}
+	if err := yaml.Unmarshal(contents, &raw); err != nil {
+		return nil, fmt.Errorf("parsing values %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	for key, value := range raw {
+		switch value.(type) {
+		case map[interface{}]interface{}, []interface{}:
+			return nil, fmt.Errorf("value %q in %s is not a scalar", key, path)
+		case nil:
+			values[key] = ""
+		default:
+			values[key] = fmt.Sprint(value)
+		}
+	}
+
+	return values, nil
+}
+
+// loadFile loads the objects of a file, the file is rendered as a template if templating is enabled. A kustomization
+// directory is built, its files are not rendered.
+func (s *Step) loadFile(path string) ([]runtime.Object, error) {
+	if isKustomization(path) {
+		return kustomizeObjects(path)
+	}
+
+	if s.template == nil {
+		return testutils.LoadYAMLFromFile(path)
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := s.template.Render(path, contents)
+	if err != nil {
+		return nil, err
+	}
+
+	return testutils.LoadYAML(path, bytes.NewReader(rendered))
+}
+
+// objectsFromPath is RuntimeObjectsFromPath for the step directory which renders local files as templates
+// if templating is enabled. Files from URLs are not rendered.
+func (s *Step) objectsFromPath(path string) ([]runtime.Object, error) {
+	if s.template == nil || http.IsURL(path) || isKustomization(filepath.Join(s.Dir, path)) {
+		return RuntimeObjectsFromPath(path, s.Dir)
+	}
+
+	paths, err := kfile.FromPath(filepath.Join(s.Dir, path), "*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find YAML files in %s: %w", filepath.Join(s.Dir, path), err)
+	}
+
+	objects := []runtime.Object{}
+	for _, path := range paths {
+		objs, err := s.loadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, objs...)
+	}
+
+	return objects, nil
+}
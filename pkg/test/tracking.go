@@ -0,0 +1,151 @@
+package test
+
+import (
+	"context"
+	"fmt"
+
+	petname "github.com/dustinkirkland/golang-petname"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// trackingLabel labels the objects applied by a test case with its tracking ID, see TestSuite.Tracking.
+const trackingLabel = "kuttl.dev/test-run"
+
+// trackingLabelEnv is the environment variable of the tracking label of the commands of a test case, e.g.
+// kuttl.dev/test-run=kuttl-test-happy-cat, to label the objects they create.
+const trackingLabelEnv = "KUTTL_TRACKING_LABEL"
+
+// tracking is the state of the tracking of the objects created by a test case.
+type tracking struct {
+	// id is the value of the tracking label of the objects of the test case.
+	id string
+	// existing are the objects in the namespace of the test case when it started by kind and name, it is nil
+	// unless the namespace is snapshotted.
+	existing map[string]bool
+}
+
+// label returns the tracking label as a label selector, e.g. for `kubectl label`.
+func (t *tracking) label() string {
+	return trackingLabel + "=" + t.id
+}
+
+// labelObject sets the tracking label on an object to apply.
+func (t *tracking) labelObject(obj runtime.Object) error {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+
+	labels := m.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[trackingLabel] = t.id
+	m.SetLabels(labels)
+	return nil
+}
+
+// startTracking starts tracking the objects created by the test case in a namespace. The tracking ID is the name of
+// an auto-created namespace which is unique, otherwise a random name.
+func (t *Case) startTracking(ns *namespace) (*tracking, error) {
+	tracked := &tracking{id: ns.Name}
+	if !ns.AutoCreated {
+		tracked.id = "kuttl-test-" + petname.Generate(3, "-")
+	}
+
+	// an auto-created namespace is deleted with all of its objects
+	if !t.Tracking.SnapshotNamespace || ns.AutoCreated {
+		return tracked, nil
+	}
+
+	cl, err := t.Client(false)
+	if err != nil {
+		return nil, err
+	}
+
+	dClient, err := t.DiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+
+	kinds, err := namespacedResources(dClient)
+	if err != nil {
+		return nil, err
+	}
+
+	tracked.existing = map[string]bool{}
+	for _, gvk := range kinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := cl.List(context.TODO(), list, client.InNamespace(ns.Name)); err != nil {
+			return nil, fmt.Errorf("listing %s: %w", gvk.Kind, err)
+		}
+		for _, obj := range list.Items {
+			tracked.existing[fmt.Sprintf("%s/%s", gvk.Kind, obj.GetName())] = true
+		}
+	}
+
+	t.Logger.Logf("recorded %d objects in namespace %s", len(tracked.existing), ns.Name)
+	return tracked, nil
+}
+
+// deleteTracked deletes the objects with the tracking label of the test case in all namespaces and the cluster
+// scope and, if the namespace was snapshotted, the objects created in it since the test case started. It does not
+// wait for the objects to be deleted.
+func (t *Case) deleteTracked(ns *namespace) error {
+	cl, err := t.Client(false)
+	if err != nil {
+		return err
+	}
+
+	dClient, err := t.DiscoveryClient()
+	if err != nil {
+		return err
+	}
+
+	lists, err := discovery.ServerPreferredResources(dClient)
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return err
+	}
+	kinds, err := deletableKinds(lists)
+	if err != nil {
+		return err
+	}
+
+	for _, gvk := range kinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := cl.List(context.TODO(), list, client.MatchingLabels{trackingLabel: t.tracking.id}); err != nil {
+			return fmt.Errorf("listing %s: %w", gvk.Kind, err)
+		}
+
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if obj.GetDeletionTimestamp() != nil {
+				continue
+			}
+			if err := cl.Delete(context.TODO(), obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("deleting tracked %s %s: %w", gvk.Kind, obj.GetName(), err)
+			}
+			t.Logger.Logf("deleted tracked %s %s", gvk.Kind, obj.GetName())
+		}
+	}
+
+	if t.tracking.existing == nil {
+		return nil
+	}
+
+	namespacedKinds, err := namespacedResources(dClient)
+	if err != nil {
+		return err
+	}
+	_, err = deleteNamespaceObjects(context.TODO(), cl, namespacedKinds, ns.Name, func(kind string, obj *unstructured.Unstructured) bool {
+		return t.tracking.existing[fmt.Sprintf("%s/%s", kind, obj.GetName())]
+	})
+	return err
+}
@@ -0,0 +1,95 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	coretesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestCaseTracking(t *testing.T) {
+	verbs := metav1.Verbs{"create", "delete", "get", "list"}
+	dClient := &fakediscovery.FakeDiscovery{
+		Fake: &coretesting.Fake{
+			Resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "v1",
+					APIResources: []metav1.APIResource{
+						{Name: "pods", Namespaced: true, Kind: "Pod", Verbs: verbs},
+						{Name: "namespaces", Namespaced: false, Kind: "Namespace", Verbs: verbs},
+					},
+				},
+			},
+		},
+	}
+
+	pod := func(name, namespace string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme, pod("existing", testNamespace))
+	test := &Case{
+		Logger:          testutils.NewTestLogger(t, ""),
+		Tracking:        &harness.Tracking{SnapshotNamespace: true},
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return dClient, nil },
+	}
+	ns := &namespace{Name: testNamespace}
+
+	var err error
+	test.tracking, err = test.startTracking(ns)
+	require.NoError(t, err)
+
+	// the objects applied by the steps are labeled, the commands label the objects they create
+	step := &Step{
+		Logger: testutils.NewTestLogger(t, ""),
+		Apply: []runtime.Object{&corev1.Namespace{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+			ObjectMeta: metav1.ObjectMeta{Name: "applied"},
+		}},
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+		tracking:        test.tracking,
+	}
+	assert.Equal(t, []error{}, step.Create(context.TODO(), testNamespace))
+
+	env, err := step.commandEnv(context.TODO(), testNamespace)
+	require.NoError(t, err)
+	assert.Equal(t, "kuttl.dev/test-run="+test.tracking.id, env[trackingLabelEnv])
+
+	labeled := pod("labeled", "other")
+	labeled.Labels = map[string]string{trackingLabel: test.tracking.id}
+	for _, obj := range []runtime.Object{labeled, pod("created", testNamespace), pod("untracked", "other")} {
+		require.NoError(t, cl.Create(context.TODO(), obj))
+	}
+
+	require.NoError(t, test.deleteTracked(ns))
+
+	exists := func(obj runtime.Object, namespace, name string) bool {
+		err := cl.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, obj)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			t.Fatal(err)
+		}
+		return err == nil
+	}
+	assert.False(t, exists(&corev1.Namespace{}, "", "applied"))
+	assert.False(t, exists(&corev1.Pod{}, "other", "labeled"))
+	assert.False(t, exists(&corev1.Pod{}, testNamespace, "created"))
+	assert.True(t, exists(&corev1.Pod{}, testNamespace, "existing"))
+	assert.True(t, exists(&corev1.Pod{}, "other", "untracked"))
+}
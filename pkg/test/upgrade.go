@@ -0,0 +1,68 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+)
+
+// upgradeSuite is the name of the report test suite of the phases of an upgrade test.
+const upgradeSuite = "upgrade"
+
+// upgradePhase is a phase of an upgrade test, it is run as a test case named after the phase.
+type upgradePhase struct {
+	name string
+	dir  string
+}
+
+// upgradePhases returns the phases of an upgrade test in the order they run, the optional phases without a directory
+// are left out.
+func upgradePhases(upgrade *harness.Upgrade) []upgradePhase {
+	phases := []upgradePhase{}
+	for _, phase := range []upgradePhase{
+		{name: "install", dir: upgrade.Install},
+		{name: "pre-upgrade", dir: upgrade.PreUpgrade},
+		{name: "upgrade", dir: upgrade.Upgrade},
+		{name: "post-upgrade", dir: upgrade.PostUpgrade},
+	} {
+		if phase.dir != "" {
+			phases = append(phases, phase)
+		}
+	}
+	return phases
+}
+
+// runUpgrade runs the phases of the upgrade test of the test suite in order like the setup, each phase continues from
+// the state of the previous one. The phases after a failed phase are reported as skipped. It returns whether all
+// phases succeeded.
+func (h *Harness) runUpgrade(ctx context.Context) bool {
+	upgrade := h.TestSuite.Upgrade
+	if upgrade.Install == "" || upgrade.Upgrade == "" {
+		h.T.Fatal(errors.New("upgrade requires an install and an upgrade directory"))
+	}
+
+	suite := h.report.NewSuite(upgradeSuite)
+
+	var previous *Case
+	failed := ""
+
+	for _, phase := range upgradePhases(upgrade) {
+		test := h.suiteCase(phase.name, filepath.Clean(phase.dir))
+
+		if failed != "" {
+			h.T.Logf("skipping %s phase of the upgrade", phase.name)
+			suite.AddTestcase(skippedCase(test, fmt.Sprintf("%s phase failed", failed)))
+			continue
+		}
+
+		if !h.runSuiteTest(ctx, suite, test, previous) {
+			failed = phase.name
+		}
+		previous = test
+	}
+
+	return failed == ""
+}
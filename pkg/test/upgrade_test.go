@@ -0,0 +1,86 @@
+package test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	"github.com/kudobuilder/kuttl/pkg/report"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestUpgradePhases(t *testing.T) {
+	phases := upgradePhases(&harness.Upgrade{Install: "v1", Upgrade: "v2", PostUpgrade: "after"})
+	assert.Equal(t, []upgradePhase{
+		{name: "install", dir: "v1"},
+		{name: "upgrade", dir: "v2"},
+		{name: "post-upgrade", dir: "after"},
+	}, phases)
+}
+
+func TestRunUpgrade(t *testing.T) {
+	dir, err := ioutil.TempDir("", "upgrade")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	service := func(version string) string {
+		return "apiVersion: v1\nkind: Service\nmetadata:\n  name: operator\n  labels:\n    version: " + version + "\n"
+	}
+	for path, content := range map[string]string{
+		"install/00-install.yaml": service("a"),
+		"pre-upgrade/00-snapshot.yaml": "apiVersion: kuttl.dev/v1beta1\nkind: TestStep\n" +
+			"capture:\n- {apiVersion: v1, kind: Service, name: operator, variable: VERSION, jsonPath: .metadata.labels.version}\n" +
+			"snapshot:\n- {apiVersion: v1, kind: Service, name: operator, as: operator}\n",
+		"upgrade/00-upgrade.yaml": service("b"),
+		// the variables and snapshots of the pre-upgrade phase are available after the upgrade
+		"post-upgrade/00-assert.yaml": "apiVersion: kuttl.dev/v1beta1\nkind: TestAssert\n" +
+			"commands:\n- script: test \"$VERSION\" = a\n" +
+			"snapshots:\n- {snapshot: operator, changed: true}\n",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, path), []byte(content), 0644))
+	}
+
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme)
+	h := Harness{
+		T:       t,
+		report:  report.NewSuiteCollection(""),
+		client:  cl,
+		dclient: testutils.NewCachedDiscoveryClient(testutils.FakeDiscoveryClient()),
+	}
+	h.TestSuite.Namespace = testNamespace
+	h.TestSuite.Upgrade = &harness.Upgrade{
+		Install:     filepath.Join(dir, "install"),
+		PreUpgrade:  filepath.Join(dir, "pre-upgrade"),
+		Upgrade:     filepath.Join(dir, "upgrade"),
+		PostUpgrade: filepath.Join(dir, "post-upgrade"),
+	}
+
+	assert.True(t, h.runUpgrade(context.TODO()))
+
+	actual := &corev1.Service{}
+	require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: "operator"}, actual))
+	assert.Equal(t, "b", actual.Labels["version"])
+
+	// the phases are reported as the test cases of the upgrade test suite
+	h.report.Close()
+	r := report.NewJSONReport(h.report)
+	require.Len(t, r.Suites, 1)
+	assert.Equal(t, upgradeSuite, r.Suites[0].Name)
+	names := []string{}
+	for _, c := range r.Suites[0].Cases {
+		assert.Equal(t, report.StatusPassed, c.Status)
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{"install", "pre-upgrade", "upgrade", "post-upgrade"}, names)
+}
@@ -0,0 +1,209 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ANSI escape codes of the colorized diff.
+const (
+	colorReset = "\x1b[0m"
+	colorBold  = "\x1b[1m"
+	colorFaint = "\x1b[2m"
+	colorRed   = "\x1b[1;31m"
+	colorGreen = "\x1b[1;32m"
+	colorCyan  = "\x1b[36m"
+)
+
+// hunkRegex matches the header of a hunk of a unified diff, e.g. "@@ -1,4 +1,5 @@".
+var hunkRegex = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// yamlKeyRegex matches the key of a line of a YAML mapping after its indentation and list markers.
+var yamlKeyRegex = regexp.MustCompile(`^("[^"]*"|'[^']*'|[^\s:#][^:]*):(\s|$)`)
+
+// DiffOptions configure how Diff renders the differences between two resources.
+type DiffOptions struct {
+	// Color highlights the diff with ANSI escape codes.
+	Color bool
+	// Path is the path of the mismatched field, see SubsetError.Path. Only the changed lines of the field
+	// are highlighted, the other changed lines are dimmed. If it is empty, all changed lines are highlighted.
+	Path []string
+}
+
+// Diff creates a unified diff of two Kubernetes resources like PrettyDiff. With the Color option, the removed and
+// added lines of the mismatched field are highlighted.
+func Diff(expected runtime.Object, actual runtime.Object, options DiffOptions) (string, error) {
+	diff, err := PrettyDiff(expected, actual)
+	if err != nil || !options.Color {
+		return diff, err
+	}
+
+	expectedBuf := &bytes.Buffer{}
+	actualBuf := &bytes.Buffer{}
+
+	if err := MarshalObject(expected, expectedBuf); err != nil {
+		return "", err
+	}
+
+	if err := MarshalObject(actual, actualBuf); err != nil {
+		return "", err
+	}
+
+	return colorizeDiff(diff, yamlLinePaths(expectedBuf.String()), yamlLinePaths(actualBuf.String()), options.Path), nil
+}
+
+// colorizeDiff colorizes a unified diff of two YAML documents given the field paths of their lines.
+func colorizeDiff(diff string, expectedPaths, actualPaths [][]string, path []string) string {
+	out := &strings.Builder{}
+
+	// the indexes of the next lines of the expected and actual documents in the current hunk
+	expectedLine, actualLine := 0, 0
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if line == "" {
+			continue
+		}
+
+		text := strings.TrimSuffix(line, "\n")
+		color := ""
+
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			color = colorBold
+		case strings.HasPrefix(line, "@@"):
+			color = colorCyan
+			if matches := hunkRegex.FindStringSubmatch(line); matches != nil {
+				expectedLine = hunkStart(matches[1], matches[2])
+				actualLine = hunkStart(matches[3], matches[4])
+			}
+		case strings.HasPrefix(line, "-"):
+			color = changeColor(colorRed, linePath(expectedPaths, expectedLine), path)
+			expectedLine++
+		case strings.HasPrefix(line, "+"):
+			color = changeColor(colorGreen, linePath(actualPaths, actualLine), path)
+			actualLine++
+		default:
+			expectedLine++
+			actualLine++
+		}
+
+		if color == "" {
+			out.WriteString(line)
+		} else {
+			fmt.Fprintf(out, "%s%s%s%s", color, text, colorReset, line[len(text):])
+		}
+	}
+
+	return out.String()
+}
+
+// changeColor returns the color of a changed line, lines outside of the mismatched field are dimmed.
+func changeColor(color string, linePath, path []string) string {
+	if hasPathPrefix(linePath, path) {
+		return color
+	}
+	return colorFaint
+}
+
+// hunkStart returns the index of the first line of a hunk range of a unified diff, e.g. 0 for "1,4".
+// Empty ranges start after the line they refer to.
+func hunkStart(start, length string) int {
+	index, _ := strconv.Atoi(start)
+	if length == "0" {
+		return index
+	}
+	return index - 1
+}
+
+// linePath returns the field path of a line by its index, it is nil for lines which are out of range.
+func linePath(paths [][]string, index int) []string {
+	if index < 0 || index >= len(paths) {
+		return nil
+	}
+	return paths[index]
+}
+
+// hasPathPrefix checks if a field path is the prefix path or a field below it.
+func hasPathPrefix(path, prefix []string) bool {
+	if len(path) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// yamlLinePaths returns the path of the mapping keys of every line of a YAML document as marshalled by
+// MarshalObject. List indices are not part of the paths, like in the paths of SubsetError.
+func yamlLinePaths(document string) [][]string {
+	paths := [][]string{}
+	stack := []yamlKey{}
+	// the indentation of the key of a block scalar whose lines are being read, -1 outside of block scalars
+	scalarIndent := -1
+
+	for _, line := range strings.Split(strings.TrimSuffix(document, "\n"), "\n") {
+		content := strings.TrimLeft(line, " ")
+		indent := len(line) - len(content)
+
+		if scalarIndent >= 0 && (content == "" || indent > scalarIndent) {
+			paths = append(paths, keyPath(stack))
+			continue
+		}
+		scalarIndent = -1
+
+		if content != "" {
+			// list items are indented like their parent key, the keys of an item are indented after the marker
+			itemIndent := -1
+			for strings.HasPrefix(content, "- ") || content == "-" {
+				if itemIndent < 0 {
+					itemIndent = indent
+				}
+				content = strings.TrimPrefix(strings.TrimPrefix(content, "-"), " ")
+				indent += 2
+			}
+
+			for len(stack) > 0 {
+				top := stack[len(stack)-1]
+				if (itemIndent >= 0 && top.indent <= itemIndent) || (itemIndent < 0 && top.indent < indent) {
+					break
+				}
+				stack = stack[:len(stack)-1]
+			}
+
+			if matches := yamlKeyRegex.FindStringSubmatch(content); matches != nil {
+				stack = append(stack, yamlKey{indent: indent, name: strings.Trim(matches[1], `"'`)})
+				value := strings.TrimSpace(content[len(matches[0]):])
+				if strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+					scalarIndent = indent
+				}
+			}
+		}
+
+		paths = append(paths, keyPath(stack))
+	}
+
+	return paths
+}
+
+// yamlKey is a mapping key of a YAML document with the indentation of its line.
+type yamlKey struct {
+	indent int
+	name   string
+}
+
+// keyPath returns the names of nested mapping keys.
+func keyPath(keys []yamlKey) []string {
+	path := make([]string, 0, len(keys))
+	for _, k := range keys {
+		path = append(path, k.name)
+	}
+	return path
+}
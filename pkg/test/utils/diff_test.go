@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestYAMLLinePaths(t *testing.T) {
+	document := `apiVersion: v1
+kind: Pod
+metadata:
+  labels:
+    app: test
+spec:
+  containers:
+  - image: nginx
+    name: nginx
+    ports:
+    - containerPort: 80
+  - image: redis
+    args: |
+      --port: 6379
+  restartPolicy: Never
+`
+
+	assert.Equal(t, [][]string{
+		{"apiVersion"},
+		{"kind"},
+		{"metadata"},
+		{"metadata", "labels"},
+		{"metadata", "labels", "app"},
+		{"spec"},
+		{"spec", "containers"},
+		{"spec", "containers", "image"},
+		{"spec", "containers", "name"},
+		{"spec", "containers", "ports"},
+		{"spec", "containers", "ports", "containerPort"},
+		{"spec", "containers", "image"},
+		{"spec", "containers", "args"},
+		{"spec", "containers", "args"},
+		{"spec", "restartPolicy"},
+	}, yamlLinePaths(document))
+}
+
+func TestDiff(t *testing.T) {
+	expected := NewPod("hello", "").(*unstructured.Unstructured)
+	expected.Object["spec"] = map[string]interface{}{"restartPolicy": "Never", "hostname": "a"}
+
+	actual := NewPod("hello", "").(*unstructured.Unstructured)
+	actual.Object["spec"] = map[string]interface{}{"restartPolicy": "Always", "hostname": "b"}
+
+	plain, err := PrettyDiff(expected, actual)
+	require.NoError(t, err)
+
+	diff, err := Diff(expected, actual, DiffOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, plain, diff)
+
+	diff, err = Diff(expected, actual, DiffOptions{Color: true, Path: []string{"spec", "restartPolicy"}})
+	require.NoError(t, err)
+
+	assert.Contains(t, diff, colorRed+"-  restartPolicy: Never"+colorReset+"\n")
+	assert.Contains(t, diff, colorGreen+"+  restartPolicy: Always"+colorReset+"\n")
+	assert.Contains(t, diff, colorFaint+"-  hostname: a"+colorReset+"\n")
+	assert.Contains(t, diff, colorFaint+"+  hostname: b"+colorReset+"\n")
+	assert.Contains(t, diff, colorCyan+"@@")
+
+	diff, err = Diff(expected, actual, DiffOptions{Color: true})
+	require.NoError(t, err)
+	assert.Contains(t, diff, colorRed+"-  hostname: a"+colorReset+"\n")
+	assert.Equal(t, plain, stripColors(diff))
+}
+
+func TestSubsetErrorPath(t *testing.T) {
+	err := IsSubset(map[string]interface{}{
+		"spec": map[string]interface{}{"replicas": 1},
+	}, map[string]interface{}{
+		"spec": map[string]interface{}{"replicas": 2},
+	})
+	require.Error(t, err)
+
+	subsetErr, ok := err.(*SubsetError)
+	require.True(t, ok)
+	assert.Equal(t, []string{"spec", "replicas"}, subsetErr.Path())
+}
+
+// stripColors removes the ANSI escape codes of a colorized diff.
+func stripColors(s string) string {
+	for _, color := range []string{colorReset, colorBold, colorFaint, colorRed, colorGreen, colorCyan} {
+		s = strings.ReplaceAll(s, color, "")
+	}
+	return s
+}
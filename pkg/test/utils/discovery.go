@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+)
+
+// CachedDiscoveryClient is a discovery client which caches the API groups and the API resources of the group versions
+// of the server, so the steps of parallel test cases do not repeat the discovery requests. The resources of a group
+// version are cached when they are first looked up, a group version which is not found is not cached.
+// Invalidate drops the cache, e.g. after CRDs were installed.
+type CachedDiscoveryClient struct {
+	discovery.DiscoveryInterface
+
+	lock      sync.Mutex
+	groups    *metav1.APIGroupList
+	resources map[string]*metav1.APIResourceList
+}
+
+// NewCachedDiscoveryClient returns a discovery client caching the responses of a discovery client.
+func NewCachedDiscoveryClient(delegate discovery.DiscoveryInterface) *CachedDiscoveryClient {
+	return &CachedDiscoveryClient{
+		DiscoveryInterface: delegate,
+		resources:          map[string]*metav1.APIResourceList{},
+	}
+}
+
+// ServerGroups returns the cached API groups of the server.
+func (d *CachedDiscoveryClient) ServerGroups() (*metav1.APIGroupList, error) {
+	d.lock.Lock()
+	groups := d.groups
+	d.lock.Unlock()
+
+	if groups != nil {
+		return groups, nil
+	}
+
+	groups, err := d.DiscoveryInterface.ServerGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	d.lock.Lock()
+	d.groups = groups
+	d.lock.Unlock()
+	return groups, nil
+}
+
+// ServerResourcesForGroupVersion returns the cached API resources of a group version of the server.
+func (d *CachedDiscoveryClient) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	d.lock.Lock()
+	resources, ok := d.resources[groupVersion]
+	d.lock.Unlock()
+
+	if ok {
+		return resources, nil
+	}
+
+	resources, err := d.DiscoveryInterface.ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	d.lock.Lock()
+	d.resources[groupVersion] = resources
+	d.lock.Unlock()
+	return resources, nil
+}
+
+// ServerResources returns the cached API resources of all group versions of the server.
+func (d *CachedDiscoveryClient) ServerResources() ([]*metav1.APIResourceList, error) {
+	return discovery.ServerResources(d)
+}
+
+// ServerGroupsAndResources returns the cached API groups and the API resources of all group versions of the server.
+func (d *CachedDiscoveryClient) ServerGroupsAndResources() ([]*metav1.APIGroup, []*metav1.APIResourceList, error) {
+	return discovery.ServerGroupsAndResources(d)
+}
+
+// ServerPreferredResources returns the cached API resources of the preferred versions of the groups of the server.
+func (d *CachedDiscoveryClient) ServerPreferredResources() ([]*metav1.APIResourceList, error) {
+	return discovery.ServerPreferredResources(d)
+}
+
+// ServerPreferredNamespacedResources returns the cached namespaced API resources of the preferred versions of the
+// groups of the server.
+func (d *CachedDiscoveryClient) ServerPreferredNamespacedResources() ([]*metav1.APIResourceList, error) {
+	return discovery.ServerPreferredNamespacedResources(d)
+}
+
+// Fresh returns false, the cache may be outdated by CRDs installed by the tests. A RESTMapper using the client
+// invalidates it and retries a lookup which failed.
+func (d *CachedDiscoveryClient) Fresh() bool {
+	return false
+}
+
+// Invalidate drops the cache, the API groups and resources are looked up again.
+func (d *CachedDiscoveryClient) Invalidate() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.groups = nil
+	d.resources = map[string]*metav1.APIResourceList{}
+}
+
+var _ discovery.CachedDiscoveryInterface = &CachedDiscoveryClient{}
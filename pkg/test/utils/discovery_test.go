@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	coretesting "k8s.io/client-go/testing"
+)
+
+func TestCachedDiscoveryClient(t *testing.T) {
+	fake := &fakediscovery.FakeDiscovery{
+		Fake: &coretesting.Fake{
+			Resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "v1",
+					APIResources: []metav1.APIResource{{Name: "pods", Namespaced: true, Kind: "Pod"}},
+				},
+			},
+		},
+	}
+	dClient := NewCachedDiscoveryClient(fake)
+
+	// the resources of a group version are only looked up once
+	for i := 0; i < 3; i++ {
+		resource, err := GetAPIResource(dClient, schema.GroupVersionKind{Version: "v1", Kind: "Pod"})
+		require.NoError(t, err)
+		assert.Equal(t, "pods", resource.Name)
+	}
+	assert.Equal(t, 1, len(fake.Actions()))
+
+	// a group version which is not found is not cached
+	_, err := GetAPIResource(dClient, schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Database"})
+	assert.Error(t, err)
+
+	fake.Resources = append(fake.Resources, &metav1.APIResourceList{
+		GroupVersion: "example.com/v1",
+		APIResources: []metav1.APIResource{{Name: "databases", Namespaced: true, Kind: "Database"}},
+	})
+	resource, err := GetAPIResource(dClient, schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Database"})
+	require.NoError(t, err)
+	assert.Equal(t, "databases", resource.Name)
+
+	// a resource added to a cached group version is found after the cache is invalidated
+	fake.Resources[0] = &metav1.APIResourceList{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{
+			{Name: "pods", Namespaced: true, Kind: "Pod"},
+			{Name: "services", Namespaced: true, Kind: "Service"},
+		},
+	}
+	resource, err = GetAPIResource(dClient, schema.GroupVersionKind{Version: "v1", Kind: "Service"})
+	require.NoError(t, err)
+	assert.Equal(t, "services", resource.Name)
+}
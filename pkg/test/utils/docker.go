@@ -13,4 +13,5 @@ type DockerClient interface {
 	NegotiateAPIVersion(context.Context)
 	VolumeCreate(context.Context, volumetypes.VolumeCreateBody) (dockertypes.Volume, error)
 	ImageSave(context.Context, []string) (io.ReadCloser, error)
+	ImageInspectWithRaw(context.Context, string) (dockertypes.ImageInspect, []byte, error)
 }
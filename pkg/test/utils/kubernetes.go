@@ -8,12 +8,15 @@ import (
 	"context"
 	ejson "encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
 	"testing"
@@ -22,6 +25,7 @@ import (
 	"github.com/google/shlex"
 	"github.com/pmezard/go-difflib/difflib"
 	"github.com/spf13/pflag"
+	"github.com/thoas/go-funk"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
@@ -32,6 +36,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer/json"
@@ -237,8 +242,12 @@ func (r *RetryClient) Watch(ctx context.Context, obj runtime.Object) (watch.Inte
 		return nil, err
 	}
 
-	gvk := obj.GetObjectKind().GroupVersionKind()
+	return r.WatchResources(ctx, obj.GetObjectKind().GroupVersionKind(), meta.GetNamespace(), meta.GetName(), "")
+}
 
+// WatchResources watches the objects of a kind in a namespace, or only the object with a name if it is set, and
+// returns all events for them. The watch starts after resourceVersion, or with the current objects if it is empty.
+func (r *RetryClient) WatchResources(ctx context.Context, gvk schema.GroupVersionKind, namespace, name, resourceVersion string) (watch.Interface, error) {
 	groupResources, err := restmapper.GetAPIGroupResources(r.discovery)
 	if err != nil {
 		return nil, err
@@ -249,10 +258,11 @@ func (r *RetryClient) Watch(ctx context.Context, obj runtime.Object) (watch.Inte
 		return nil, err
 	}
 
-	return r.dynamic.Resource(mapping.Resource).Watch(context.TODO(), metav1.SingleObject(metav1.ObjectMeta{
-		Name:      meta.GetName(),
-		Namespace: meta.GetNamespace(),
-	}))
+	options := metav1.ListOptions{ResourceVersion: resourceVersion}
+	if name != "" {
+		options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+	}
+	return r.dynamic.Resource(mapping.Resource).Namespace(namespace).Watch(ctx, options)
 }
 
 // Status returns a client which can update status subresource for kubernetes objects.
@@ -379,6 +389,8 @@ func ConvertUnstructured(in runtime.Object) (runtime.Object, error) {
 		converted = &harness.TestAssert{}
 	} else if (group == kudoGroup || group == kuttlGroup) && kind == "TestSuite" {
 		converted = &harness.TestSuite{}
+	} else if group == kuttlGroup && kind == "TestCase" {
+		converted = &harness.TestCase{}
 	} else {
 		return in, nil
 	}
@@ -466,7 +478,22 @@ func LoadYAMLFromFile(path string) ([]runtime.Object, error) {
 	return LoadYAML(path, opened)
 }
 
+// LoadYAML loads the objects of a YAML or JSON manifest. If DecryptSops is set, a sops-encrypted manifest is decrypted
+// before it is parsed.
 func LoadYAML(path string, r io.Reader) ([]runtime.Object, error) {
+	if DecryptSops {
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("error reading yaml %s: %w", path, err)
+		}
+		if IsSopsEncrypted(data) {
+			if data, err = SopsDecrypt(path, data); err != nil {
+				return nil, err
+			}
+		}
+		r = bytes.NewReader(data)
+	}
+
 	yamlReader := yaml.NewYAMLReader(bufio.NewReader(r))
 
 	objects := []runtime.Object{}
@@ -813,8 +840,20 @@ func SetAnnotation(obj runtime.Object, key, value string) runtime.Object {
 	return obj
 }
 
-// GetAPIResource returns the APIResource object for a specific GroupVersionKind.
+// GetAPIResource returns the APIResource object for a specific GroupVersionKind. If it is not found with a cached
+// discovery client, the cache is invalidated and it is looked up again, e.g. because its CRD was installed after the
+// resources of its group version were cached.
 func GetAPIResource(dClient discovery.DiscoveryInterface, gvk schema.GroupVersionKind) (metav1.APIResource, error) {
+	resource, err := getAPIResource(dClient, gvk)
+	if cached, ok := dClient.(discovery.CachedDiscoveryInterface); ok && err != nil {
+		cached.Invalidate()
+		resource, err = getAPIResource(dClient, gvk)
+	}
+	return resource, err
+}
+
+// getAPIResource returns the APIResource object for a specific GroupVersionKind.
+func getAPIResource(dClient discovery.DiscoveryInterface, gvk schema.GroupVersionKind) (metav1.APIResource, error) {
 	resourceTypes, err := dClient.ServerResourcesForGroupVersion(gvk.GroupVersion().String())
 	if err != nil {
 		return metav1.APIResource{}, err
@@ -1003,13 +1042,18 @@ func GetArgs(ctx context.Context, cmd harness.Command, namespace string, envMap
 // RunCommand runs a command with args.
 // args gets split on spaces (respecting quoted strings).
 // if the command is run in the background a reference to the process is returned for later cleanup
-func RunCommand(ctx context.Context, namespace string, cmd harness.Command, cwd string, stdout io.Writer, stderr io.Writer, logger Logger, timeout int) (*exec.Cmd, error) {
+// env is a set of additional environment variables of the command
+func RunCommand(ctx context.Context, namespace string, cmd harness.Command, cwd string, stdout io.Writer, stderr io.Writer, logger Logger, timeout int, env map[string]string) (*exec.Cmd, error) {
 	actualDir, err := os.Getwd()
 	if err != nil {
 		return nil, fmt.Errorf("command %q with %w", cmd.Command, err)
 	}
 
+	// the provided env is injected into the command, the variables set by kuttl can not be overridden
 	kudoENV := make(map[string]string)
+	for key, value := range env {
+		kudoENV[key] = value
+	}
 	kudoENV["NAMESPACE"] = namespace
 	kudoENV["KUBECONFIG"] = fmt.Sprintf("%s/kubeconfig", actualDir)
 	kudoENV["PATH"] = fmt.Sprintf("%s/bin/:%s", actualDir, os.Getenv("PATH"))
@@ -1044,8 +1088,21 @@ func RunCommand(ctx context.Context, namespace string, cmd harness.Command, cwd
 	logger.Logf("running command: %v", builtCmd.Args)
 
 	builtCmd.Dir = cwd
-	if !cmd.SkipLogOutput {
+	if cmd.SkipLogOutput {
+		stdout, stderr = ioutil.Discard, ioutil.Discard
+	}
+	// the output is captured to verify it against the expected output of the command
+	var stdoutBuf, stderrBuf bytes.Buffer
+	if cmd.Stdout != nil && !cmd.Background {
+		stdout = io.MultiWriter(stdout, &stdoutBuf)
+	}
+	if cmd.Stderr != nil && !cmd.Background {
+		stderr = io.MultiWriter(stderr, &stderrBuf)
+	}
+	if stdout != ioutil.Discard {
 		builtCmd.Stdout = stdout
+	}
+	if stderr != ioutil.Discard {
 		builtCmd.Stderr = stderr
 	}
 	builtCmd.Env = os.Environ()
@@ -1068,19 +1125,76 @@ func RunCommand(ctx context.Context, namespace string, cmd harness.Command, cwd
 	}
 
 	err = builtCmd.Wait()
+	// the command is killed if the provided context is done, e.g. the test timed out
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("command %q: %w", cmd.Command, ctx.Err())
+	}
+	if errors.Is(cmdCtx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("command %q exceeded %v sec timeout", cmd.Command, timeout)
+		if cmd.IgnoreFailure {
+			logger.Logf("ignoring failure: %v", err)
+			return nil, nil
+		}
+		return nil, err
+	}
 	if errors.As(err, &exerr) && cmd.IgnoreFailure {
+		logger.Logf("ignoring failure of command %v: %v", builtCmd.Args, err)
 		return nil, nil
 	}
-	if errors.Is(cmdCtx.Err(), context.DeadlineExceeded) {
-		return nil, fmt.Errorf("command %q exceeded %v sec timeout", cmd.Command, timeout)
+	if len(cmd.ExitCodes) > 0 && (err == nil || errors.As(err, &exerr)) {
+		err = nil
+		if exitCode := builtCmd.ProcessState.ExitCode(); !funk.ContainsInt(cmd.ExitCodes, exitCode) {
+			err = fmt.Errorf("command %q exited with %d, expected one of %v", builtCmd.Args, exitCode, cmd.ExitCodes)
+		}
 	}
-	return nil, err
+	if err != nil {
+		return nil, err
+	}
+
+	if err := CheckOutput("stdout", cmd.Stdout, stdoutBuf.String()); err != nil {
+		return nil, fmt.Errorf("command %q: %w", builtCmd.Args, err)
+	}
+	if err := CheckOutput("stderr", cmd.Stderr, stderrBuf.String()); err != nil {
+		return nil, fmt.Errorf("command %q: %w", builtCmd.Args, err)
+	}
+	return nil, nil
+}
+
+// CheckOutput verifies the output of a command against the expected output.
+// A trailing newline of the output is not matched.
+func CheckOutput(name string, expected *harness.CommandOutput, output string) error {
+	if expected == nil {
+		return nil
+	}
+	output = strings.TrimSuffix(output, "\n")
+
+	for _, match := range expected.Match {
+		re, err := regexp.Compile(match)
+		if err != nil {
+			return fmt.Errorf("invalid %s match %q: %w", name, match, err)
+		}
+		if !re.MatchString(output) {
+			return fmt.Errorf("%s does not match %q", name, match)
+		}
+	}
+
+	for _, notMatch := range expected.NotMatch {
+		re, err := regexp.Compile(notMatch)
+		if err != nil {
+			return fmt.Errorf("invalid %s notMatch %q: %w", name, notMatch, err)
+		}
+		if re.MatchString(output) {
+			return fmt.Errorf("%s matches %q", name, notMatch)
+		}
+	}
+
+	return nil
 }
 
 // RunCommands runs a set of commands, returning any errors.
 // If any (non-background) command fails, the following commands are skipped
 // commands running in the background are returned
-func RunCommands(logger Logger, namespace string, commands []harness.Command, workdir string, timeout int) ([]*exec.Cmd, error) {
+func RunCommands(ctx context.Context, logger Logger, namespace string, commands []harness.Command, workdir string, timeout int, env map[string]string) ([]*exec.Cmd, error) {
 	bgs := []*exec.Cmd{}
 
 	if commands == nil {
@@ -1089,7 +1203,7 @@ func RunCommands(logger Logger, namespace string, commands []harness.Command, wo
 
 	for i, cmd := range commands {
 
-		bg, err := RunCommand(context.Background(), namespace, cmd, workdir, logger, logger, logger, timeout)
+		bg, err := RunCommand(ctx, namespace, cmd, workdir, logger, logger, logger, timeout, env)
 		if err != nil {
 			cmdListSize := len(commands)
 			if i+1 < cmdListSize {
@@ -1170,6 +1284,7 @@ func Kubeconfig(cfg *rest.Config, w io.Writer) error {
 					ClientCertificateData: cfg.TLSClientConfig.CertData,
 					ClientKeyData:         cfg.TLSClientConfig.KeyData,
 					Token:                 cfg.BearerToken,
+					TokenFile:             cfg.BearerTokenFile,
 					Username:              cfg.Username,
 					Password:              cfg.Password,
 					Impersonate:           cfg.Impersonate.UserName,
@@ -1204,3 +1319,29 @@ func InClusterConfig() (bool, error) {
 	}
 	return false, err
 }
+
+// UsesInClusterConfig returns true if the Kubernetes configuration is the service account of the pod kuttl runs in,
+// i.e. if it runs in a pod and neither the --kubeconfig flag nor the KUBECONFIG environment variable are set.
+func UsesInClusterConfig() bool {
+	if os.Getenv("KUBECONFIG") != "" {
+		return false
+	}
+	if kubeconfig := flag.Lookup("kubeconfig"); kubeconfig != nil && kubeconfig.Value.String() != "" {
+		return false
+	}
+
+	inCluster, _ := InClusterConfig()
+	return inCluster
+}
+
+// serviceAccountNamespacePath is the file of the namespace of the service account mounted into pods.
+var serviceAccountNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// InClusterNamespace returns the namespace of the service account of the pod kuttl runs in.
+func InClusterNamespace() (string, error) {
+	namespace, err := ioutil.ReadFile(serviceAccountNamespacePath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(namespace)), nil
+}
@@ -462,7 +462,7 @@ func TestRunScript(t *testing.T) {
 
 			logger := NewTestLogger(t, "")
 			// script runs with output
-			_, err := RunCommand(context.TODO(), "", hcmd, "", stdout, stderr, logger, 0)
+			_, err := RunCommand(context.TODO(), "", hcmd, "", stdout, stderr, logger, 0, nil)
 
 			if tt.wantedErr {
 				assert.Error(t, err)
@@ -477,3 +477,135 @@ func TestRunScript(t *testing.T) {
 		})
 	}
 }
+
+func TestRunCommandExpectedOutput(t *testing.T) {
+	tests := []struct {
+		name      string
+		command   harness.Command
+		wantedErr bool
+	}{
+		{
+			name:    "stdout matches",
+			command: harness.Command{Command: "echo hello world", Stdout: &harness.CommandOutput{Match: []string{"^hello", "world$"}}},
+		},
+		{
+			name:      "stdout does not match",
+			command:   harness.Command{Command: "echo hello", Stdout: &harness.CommandOutput{Match: []string{"world"}}},
+			wantedErr: true,
+		},
+		{
+			name:      "stdout matches not match",
+			command:   harness.Command{Command: "echo hello", Stdout: &harness.CommandOutput{NotMatch: []string{"hel+o"}}},
+			wantedErr: true,
+		},
+		{
+			name:    "stderr matches",
+			command: harness.Command{Script: "echo oops >&2", Stderr: &harness.CommandOutput{Match: []string{"oops"}, NotMatch: []string{"hello"}}},
+		},
+		{
+			name:      "invalid regular expression",
+			command:   harness.Command{Command: "echo hello", Stdout: &harness.CommandOutput{Match: []string{"("}}},
+			wantedErr: true,
+		},
+		{
+			name:    "output is verified when it is not logged",
+			command: harness.Command{Command: "echo hello", SkipLogOutput: true, Stdout: &harness.CommandOutput{Match: []string{"hello"}}},
+		},
+		{
+			name:    "expected non zero exit code",
+			command: harness.Command{Script: "exit 3", ExitCodes: []int{1, 3}},
+		},
+		{
+			name:      "unexpected zero exit code",
+			command:   harness.Command{Command: "echo hello", ExitCodes: []int{1}},
+			wantedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			logger := NewTestLogger(t, "")
+			_, err := RunCommand(context.TODO(), "", tt.command, "", &bytes.Buffer{}, &bytes.Buffer{}, logger, 0, nil)
+			if tt.wantedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRunCommandTimeout(t *testing.T) {
+	tests := []struct {
+		name      string
+		command   harness.Command
+		timeout   int
+		wantedErr bool
+	}{
+		{
+			name:      "suite timeout",
+			command:   harness.Command{Command: "sleep 5"},
+			timeout:   1,
+			wantedErr: true,
+		},
+		{
+			name:    "command timeout overrides suite timeout",
+			command: harness.Command{Command: "sleep 2", Timeout: 4},
+			timeout: 1,
+		},
+		{
+			name:    "timeout with ignored failure",
+			command: harness.Command{Command: "sleep 5", Timeout: 1, IgnoreFailure: true},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			logger := NewTestLogger(t, "")
+			_, err := RunCommand(context.TODO(), "", tt.command, "", &bytes.Buffer{}, &bytes.Buffer{}, logger, tt.timeout, nil)
+			if tt.wantedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRunCommandEnv(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	hcmd := harness.Command{
+		Script: "echo $GREETING $NAMESPACE",
+	}
+
+	logger := NewTestLogger(t, "")
+	env := map[string]string{"GREETING": "hello", "NAMESPACE": "overridden"}
+	_, err := RunCommand(context.TODO(), "world", hcmd, "", stdout, &bytes.Buffer{}, logger, 0, env)
+	assert.NoError(t, err)
+	// the variables set by kuttl can not be overridden
+	assert.Equal(t, "hello world\n", stdout.String())
+}
+
+func TestInClusterNamespace(t *testing.T) {
+	defer func(path string) { serviceAccountNamespacePath = path }(serviceAccountNamespacePath)
+
+	f, err := ioutil.TempFile("", "namespace")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("kuttl\n")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	serviceAccountNamespacePath = f.Name()
+	namespace, err := InClusterNamespace()
+	assert.NoError(t, err)
+	assert.Equal(t, "kuttl", namespace)
+
+	serviceAccountNamespacePath = f.Name() + "-missing"
+	_, err = InClusterNamespace()
+	assert.True(t, os.IsNotExist(err))
+}
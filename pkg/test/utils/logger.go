@@ -2,7 +2,11 @@ package utils
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -73,3 +77,294 @@ func (t *TestLogger) Flush() {
 		t.buffer = []byte{}
 	}
 }
+
+// ResourceLogger is implemented by the loggers which log the actions on resources with explicit fields, see LogResource.
+type ResourceLogger interface {
+	LogResource(resource, action string)
+}
+
+// LogResource logs an action on a resource of a test step, e.g. `Pod:ns/name created`, with the resource and action
+// fields of a ResourceLogger or as a log line of other loggers.
+func LogResource(logger Logger, resource, action string) {
+	if resourceLogger, ok := logger.(ResourceLogger); ok {
+		resourceLogger.LogResource(resource, action)
+		return
+	}
+	logger.Log(resource, action)
+}
+
+// ErrorLogger is implemented by the loggers which log the failures of test cases themselves, e.g. as JSON lines,
+// instead of the go test operator.
+type ErrorLogger interface {
+	LogError(args ...interface{})
+}
+
+// jsonLogEntry is a log line of the JSONLogger.
+type jsonLogEntry struct {
+	Time     string `json:"time"`
+	Suite    string `json:"suite,omitempty"`
+	Test     string `json:"test,omitempty"`
+	Step     string `json:"step,omitempty"`
+	Level    string `json:"level,omitempty"`
+	Resource string `json:"resource,omitempty"`
+	Action   string `json:"action,omitempty"`
+	Message  string `json:"msg"`
+}
+
+// lockedWriter serializes the writes of the loggers of parallel tests.
+type lockedWriter struct {
+	lock sync.Mutex
+	out  io.Writer
+}
+
+// JSONLogger implements the Logger interface by writing each log line as a JSON object with the test suite,
+// test and step it belongs to. Actions on resources are written with resource and action fields, see LogResource,
+// failures with the error level, see LogError. The lines are written immediately instead of being buffered by the
+// go test operator.
+type JSONLogger struct {
+	suite  string
+	test   string
+	step   string
+	out    *lockedWriter
+	buffer []byte
+}
+
+// NewJSONLogger creates a new JSON logger for a test of a test suite writing to out.
+func NewJSONLogger(out io.Writer, suite, test string) *JSONLogger {
+	return &JSONLogger{
+		suite:  suite,
+		test:   test,
+		out:    &lockedWriter{out: out},
+		buffer: []byte{},
+	}
+}
+
+// Log logs the provided arguments as a JSON object, they are formatted like fmt.Sprintln without the newline.
+func (j *JSONLogger) Log(args ...interface{}) {
+	j.write(j.entry(args...))
+}
+
+// LogResource logs an action on a resource as a JSON object with the resource and action fields.
+func (j *JSONLogger) LogResource(resource, action string) {
+	entry := j.entry(resource, action)
+	entry.Resource, entry.Action = resource, action
+	j.write(entry)
+}
+
+// LogError logs a failure, e.g. of a test step including the diff of an assert, as a JSON object with the error level.
+func (j *JSONLogger) LogError(args ...interface{}) {
+	entry := j.entry(args...)
+	entry.Level = "error"
+	j.write(entry)
+}
+
+// entry returns a log line of the logger with the provided arguments formatted like fmt.Sprintln without the newline.
+func (j *JSONLogger) entry(args ...interface{}) jsonLogEntry {
+	return jsonLogEntry{
+		Time:    time.Now().Format(time.RFC3339Nano),
+		Suite:   j.suite,
+		Test:    j.test,
+		Step:    j.step,
+		Message: strings.TrimSuffix(fmt.Sprintln(args...), "\n"),
+	}
+}
+
+// write writes a log line to the output of the logger.
+func (j *JSONLogger) write(entry jsonLogEntry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	j.out.lock.Lock()
+	defer j.out.lock.Unlock()
+	fmt.Fprintln(j.out.out, string(line))
+}
+
+// Logf logs the provided arguments as a JSON object, they are formatted like fmt.Sprintf.
+func (j *JSONLogger) Logf(format string, args ...interface{}) {
+	j.Log(fmt.Sprintf(format, args...))
+}
+
+// WithTest returns a new JSONLogger for a test of a test suite which writes to the same output.
+// The lines of the loggers sharing an output are not interleaved.
+func (j *JSONLogger) WithTest(suite, test string) *JSONLogger {
+	return &JSONLogger{
+		suite:  suite,
+		test:   test,
+		out:    j.out,
+		buffer: []byte{},
+	}
+}
+
+// WithPrefix returns a new JSONLogger for a step of the test, the prefix is appended to the step of a step logger.
+func (j *JSONLogger) WithPrefix(prefix string) Logger {
+	step := prefix
+	if j.step != "" {
+		step = fmt.Sprintf("%s/%s", j.step, prefix)
+	}
+
+	return &JSONLogger{
+		suite:  j.suite,
+		test:   j.test,
+		step:   step,
+		out:    j.out,
+		buffer: []byte{},
+	}
+}
+
+// Write implements the io.Writer interface.
+// Logs each line written to it, buffers incomplete lines until the next Write() call.
+func (j *JSONLogger) Write(p []byte) (n int, err error) {
+	j.buffer = append(j.buffer, p...)
+
+	splitBuf := bytes.Split(j.buffer, []byte{'\n'})
+	j.buffer = splitBuf[len(splitBuf)-1]
+
+	for _, line := range splitBuf[:len(splitBuf)-1] {
+		j.Log(string(line))
+	}
+
+	return len(p), nil
+}
+
+// Flush logs the incomplete line buffered by Write.
+func (j *JSONLogger) Flush() {
+	if len(j.buffer) != 0 {
+		j.Log(string(j.buffer))
+		j.buffer = []byte{}
+	}
+}
+
+// StreamLogger implements the Logger interface by writing the log lines immediately, prefixed with the test
+// and step, instead of buffering them until the test finished like the go test operator does for parallel tests.
+// The lines of the loggers sharing an output are not interleaved.
+type StreamLogger struct {
+	prefix string
+	out    *lockedWriter
+	buffer []byte
+}
+
+// NewStreamLogger creates a new stream logger writing to out.
+func NewStreamLogger(out io.Writer, prefix string) *StreamLogger {
+	return &StreamLogger{
+		prefix: prefix,
+		out:    &lockedWriter{out: out},
+		buffer: []byte{},
+	}
+}
+
+// Log logs the provided arguments with the logger's prefix, they are formatted like fmt.Sprintln.
+func (s *StreamLogger) Log(args ...interface{}) {
+	line := fmt.Sprintf("%s | %s | %s", time.Now().Format("15:04:05"), s.prefix, fmt.Sprintln(args...))
+
+	s.out.lock.Lock()
+	defer s.out.lock.Unlock()
+	fmt.Fprint(s.out.out, line)
+}
+
+// Logf logs the provided arguments with the logger's prefix, they are formatted like fmt.Sprintf.
+func (s *StreamLogger) Logf(format string, args ...interface{}) {
+	s.Log(fmt.Sprintf(format, args...))
+}
+
+// WithPrefix returns a new StreamLogger writing to the same output with the provided prefix appended to the current prefix.
+func (s *StreamLogger) WithPrefix(prefix string) Logger {
+	if s.prefix != "" {
+		prefix = fmt.Sprintf("%s/%s", s.prefix, prefix)
+	}
+
+	return &StreamLogger{
+		prefix: prefix,
+		out:    s.out,
+		buffer: []byte{},
+	}
+}
+
+// Write implements the io.Writer interface.
+// Logs each line written to it, buffers incomplete lines until the next Write() call.
+func (s *StreamLogger) Write(p []byte) (n int, err error) {
+	s.buffer = append(s.buffer, p...)
+
+	splitBuf := bytes.Split(s.buffer, []byte{'\n'})
+	s.buffer = splitBuf[len(splitBuf)-1]
+
+	for _, line := range splitBuf[:len(splitBuf)-1] {
+		s.Log(string(line))
+	}
+
+	return len(p), nil
+}
+
+// Flush logs the incomplete line buffered by Write.
+func (s *StreamLogger) Flush() {
+	if len(s.buffer) != 0 {
+		s.Log(string(s.buffer))
+		s.buffer = []byte{}
+	}
+}
+
+// CaptureLogger implements the Logger interface by logging to another Logger and capturing the log lines,
+// e.g. to include the logs of a test step in a report.
+type CaptureLogger struct {
+	logger Logger
+	out    *lockedWriter
+}
+
+// NewCaptureLogger creates a new capture logger which logs to logger.
+func NewCaptureLogger(logger Logger) *CaptureLogger {
+	return &CaptureLogger{
+		logger: logger,
+		out:    &lockedWriter{out: &bytes.Buffer{}},
+	}
+}
+
+// Log logs the provided arguments to the wrapped logger and captures them like fmt.Sprintln.
+func (c *CaptureLogger) Log(args ...interface{}) {
+	c.logger.Log(args...)
+	c.capture([]byte(fmt.Sprintf("%s | %s", time.Now().Format("15:04:05"), fmt.Sprintln(args...))))
+}
+
+// Logf logs the provided arguments to the wrapped logger and captures them like fmt.Sprintf.
+func (c *CaptureLogger) Logf(format string, args ...interface{}) {
+	c.Log(fmt.Sprintf(format, args...))
+}
+
+// WithPrefix returns a new CaptureLogger for the wrapped logger with the prefix which captures to the same output.
+func (c *CaptureLogger) WithPrefix(prefix string) Logger {
+	return &CaptureLogger{
+		logger: c.logger.WithPrefix(prefix),
+		out:    c.out,
+	}
+}
+
+// LogResource logs an action on a resource to the wrapped logger, see LogResource, and captures it like Log.
+func (c *CaptureLogger) LogResource(resource, action string) {
+	LogResource(c.logger, resource, action)
+	c.capture([]byte(fmt.Sprintf("%s | %s", time.Now().Format("15:04:05"), fmt.Sprintln(resource, action))))
+}
+
+// Write implements the io.Writer interface, the output is captured as it is.
+func (c *CaptureLogger) Write(p []byte) (n int, err error) {
+	c.capture(p)
+	return c.logger.Write(p)
+}
+
+// Flush flushes the wrapped logger.
+func (c *CaptureLogger) Flush() {
+	c.logger.Flush()
+}
+
+// Captured returns the log lines captured so far by the logger and the loggers derived from it.
+func (c *CaptureLogger) Captured() string {
+	c.out.lock.Lock()
+	defer c.out.lock.Unlock()
+	return c.out.out.(*bytes.Buffer).String()
+}
+
+// capture writes to the output of the logger.
+func (c *CaptureLogger) capture(p []byte) {
+	c.out.lock.Lock()
+	defer c.out.lock.Unlock()
+	_, _ = c.out.out.Write(p)
+}
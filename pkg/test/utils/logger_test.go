@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	root := NewJSONLogger(out, "", "")
+	root.Log("starting setup")
+
+	logger := root.WithTest("./test/e2e", "hello").WithPrefix("0-install")
+	LogResource(logger, "Pod:world/hello", "created")
+	logger.Log("Pod:world/hello", "not a resource action")
+	logger.Logf("running command: %v", []string{"kubectl", "get", "pods"})
+	_, err := logger.Write([]byte("line 1\nline"))
+	require.NoError(t, err)
+	_, err = logger.Write([]byte(" 2"))
+	require.NoError(t, err)
+	logger.Flush()
+	logger.(ErrorLogger).LogError("resource Pod:world/hello: .status.phase: value mismatch")
+
+	entries := []jsonLogEntry{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		entry := jsonLogEntry{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		assert.NotEmpty(t, entry.Time)
+		entry.Time = ""
+		entries = append(entries, entry)
+	}
+
+	assert.Equal(t, []jsonLogEntry{
+		{Message: "starting setup"},
+		{Suite: "./test/e2e", Test: "hello", Step: "0-install", Resource: "Pod:world/hello", Action: "created", Message: "Pod:world/hello created"},
+		{Suite: "./test/e2e", Test: "hello", Step: "0-install", Message: "Pod:world/hello not a resource action"},
+		{Suite: "./test/e2e", Test: "hello", Step: "0-install", Message: "running command: [kubectl get pods]"},
+		{Suite: "./test/e2e", Test: "hello", Step: "0-install", Message: "line 1"},
+		{Suite: "./test/e2e", Test: "hello", Step: "0-install", Message: "line 2"},
+		{Suite: "./test/e2e", Test: "hello", Step: "0-install", Level: "error", Message: "resource Pod:world/hello: .status.phase: value mismatch"},
+	}, entries)
+}
+
+func TestStreamLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	logger := NewStreamLogger(out, "").WithPrefix("hello").WithPrefix("0-install")
+	logger.Log("Pod:world/hello", "created")
+	_, err := logger.Write([]byte("line 1\nline 2"))
+	require.NoError(t, err)
+	logger.Flush()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Equal(t, 3, len(lines))
+	for i, expected := range []string{"hello/0-install | Pod:world/hello created", "hello/0-install | line 1", "hello/0-install | line 2"} {
+		// the lines start with the time
+		assert.Equal(t, expected, strings.SplitN(lines[i], " | ", 2)[1])
+	}
+}
+
+func TestCaptureLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	logger := NewCaptureLogger(NewStreamLogger(out, "hello"))
+	logger.WithPrefix("0-install").Logf("step %s", "failed")
+	LogResource(logger, "Pod:world/hello", "created")
+	_, err := logger.Write([]byte("collector output\n"))
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(logger.Captured()), "\n")
+	require.Equal(t, 3, len(lines))
+	assert.Equal(t, "step failed", strings.SplitN(lines[0], " | ", 2)[1])
+	assert.Equal(t, "Pod:world/hello created", strings.SplitN(lines[1], " | ", 2)[1])
+	assert.Equal(t, "collector output", lines[2])
+
+	// the lines are logged to the wrapped logger too
+	assert.Contains(t, out.String(), "hello/0-install | step failed")
+	assert.Contains(t, out.String(), "hello | collector output")
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DecryptSops enables the decryption of sops-encrypted manifests by LoadYAML, see TestSuite.Sops.
+var DecryptSops = false
+
+// sopsMetadata matches the top-level sops key of an encrypted YAML document or JSON file.
+var sopsMetadata = regexp.MustCompile(`(?m)^sops:|"sops"\s*:\s*\{`)
+
+// IsSopsEncrypted returns true if a manifest is encrypted with sops.
+func IsSopsEncrypted(data []byte) bool {
+	return sopsMetadata.Match(data)
+}
+
+// SopsDecrypt decrypts a sops-encrypted manifest with the sops binary. The keys are found by sops, e.g. the age keys
+// of $SOPS_AGE_KEY_FILE, the GPG keys of the agent or the KMS keys of the cloud credentials of the environment.
+func SopsDecrypt(path string, data []byte) ([]byte, error) {
+	sops, err := exec.LookPath("sops")
+	if err != nil {
+		return nil, fmt.Errorf("decrypting %s: the sops binary is not found in PATH", path)
+	}
+
+	// sops infers the format of the file from its extension
+	ext := ".yaml"
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		ext = ".json"
+	}
+	tmp, err := ioutil.TempFile("", "kuttl-sops-*"+ext)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	tmp.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd := exec.Command(sops, "--decrypt", tmp.Name())
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("decrypting %s: %w: %s", path, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const encryptedSecret = `apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+stringData:
+  password: ENC[AES256_GCM,data:cGFzc3dvcmQ=,iv:aXY=,tag:dGFn,type:str]
+sops:
+  mac: ENC[AES256_GCM,data:bWFj,iv:aXY=,tag:dGFn,type:str]
+  version: 3.7.1
+`
+
+func TestLoadYAMLSops(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kuttl-sops")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// the fake sops binary replaces the encrypted value and drops the metadata like sops --decrypt
+	script := "#!/bin/sh\nsed -e 's/ENC\\[.*\\]/password/' -e '/^sops:/,$d' \"$2\"\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sops"), []byte(script), 0755))
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	require.NoError(t, os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH")))
+
+	defer func() { DecryptSops = false }()
+
+	// the manifest is not decrypted unless opted in
+	objs, err := LoadYAML("secret.yaml", strings.NewReader(encryptedSecret))
+	require.NoError(t, err)
+	require.Len(t, objs, 1)
+	password, _, _ := unstructured.NestedString(objs[0].(*unstructured.Unstructured).Object, "stringData", "password")
+	assert.Contains(t, password, "ENC[")
+
+	DecryptSops = true
+	objs, err = LoadYAML("secret.yaml", strings.NewReader(encryptedSecret))
+	require.NoError(t, err)
+	require.Len(t, objs, 1)
+	password, _, _ = unstructured.NestedString(objs[0].(*unstructured.Unstructured).Object, "stringData", "password")
+	assert.Equal(t, "password", password)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sops"), []byte("#!/bin/sh\necho no key found >&2\nexit 128\n"), 0755))
+	_, err = LoadYAML("secret.yaml", strings.NewReader(encryptedSecret))
+	assert.EqualError(t, err, "decrypting secret.yaml: exit status 128: no key found")
+}
+
+func TestIsSopsEncrypted(t *testing.T) {
+	assert.True(t, IsSopsEncrypted([]byte(encryptedSecret)))
+	assert.True(t, IsSopsEncrypted([]byte(`{"data": "ENC[...]", "sops": {"mac": "ENC[...]"}}`)))
+	assert.False(t, IsSopsEncrypted([]byte("apiVersion: v1\nkind: ConfigMap\ndata:\n  sops: enabled\n")))
+}
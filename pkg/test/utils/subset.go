@@ -3,8 +3,23 @@ package utils
 import (
 	"fmt"
 	"reflect"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
+// operatorRegex matches string values of expected objects which are inline operators, e.g. "kuttl.gte(2)".
+var operatorRegex = regexp.MustCompile(`^kuttl\.(\w+)\((.*)\)$`)
+
+// comparisons are the inline operators comparing quantities, the argument is compared to the actual value.
+var comparisons = map[string]func(cmp int) bool{
+	"gt":  func(cmp int) bool { return cmp > 0 },
+	"gte": func(cmp int) bool { return cmp >= 0 },
+	"lt":  func(cmp int) bool { return cmp < 0 },
+	"lte": func(cmp int) bool { return cmp <= 0 },
+}
+
 // SubsetError is an error type used by IsSubset for tracking the path in the struct.
 type SubsetError struct {
 	path    []string
@@ -20,6 +35,15 @@ func (e *SubsetError) AppendPath(key string) {
 	e.path = append(e.path, key)
 }
 
+// Path returns the path of the mismatched field from the root of the object, e.g. ["Key1", "Key2"].
+func (e *SubsetError) Path() []string {
+	path := make([]string, 0, len(e.path))
+	for i := len(e.path) - 1; i >= 0; i-- {
+		path = append(path, e.path[i])
+	}
+	return path
+}
+
 // Error implements the error interface.
 func (e *SubsetError) Error() string {
 	if e.path == nil || len(e.path) == 0 {
@@ -34,9 +58,58 @@ func (e *SubsetError) Error() string {
 	return fmt.Sprintf("%s: %s", path, e.message)
 }
 
+// ArrayMatching is the mode lists of an expected object are matched against the actual lists with.
+type ArrayMatching string
+
+const (
+	// ExactOrder requires lists of the same length whose elements match in order. It is the default.
+	ExactOrder ArrayMatching = "exactOrder"
+	// SetEquality requires lists of the same length whose elements match in any order.
+	SetEquality ArrayMatching = "setEquality"
+	// AnyElementMatches requires every expected element to match any element of the actual list.
+	AnyElementMatches ArrayMatching = "anyElementMatches"
+	// AllElementsMatch requires every element of a non-empty actual list to match any expected element.
+	AllElementsMatch ArrayMatching = "allElementsMatch"
+)
+
+// Valid checks if the array matching mode is one of the known modes.
+func (m ArrayMatching) Valid() bool {
+	switch m {
+	case ExactOrder, SetEquality, AnyElementMatches, AllElementsMatch:
+		return true
+	}
+	return false
+}
+
+// SubsetOptions configure how IsSubsetWithOptions compares objects.
+type SubsetOptions struct {
+	// ArrayMatching is the matching mode of lists by their dot separated path, e.g. `status.conditions`.
+	// The path does not include list indices.
+	ArrayMatching map[string]ArrayMatching
+}
+
 // IsSubset checks to see if `expected` is a subset of `actual`. A "subset" is an object that is equivalent to
 // the other object, but where map keys found in actual that are not defined in expected are ignored.
+//
+// String values of expected can be inline operators which are evaluated against the actual value:
+// kuttl.gt(n), kuttl.gte(n), kuttl.lt(n) and kuttl.lte(n) compare numbers and quantities like "10Gi".
+// kuttl.regexp(re) matches a string against a regular expression, it is not anchored unless ^ and $ are used.
 func IsSubset(expected, actual interface{}) error {
+	return IsSubsetWithOptions(expected, actual, SubsetOptions{})
+}
+
+// IsSubsetWithOptions is IsSubset with options, e.g. to match lists in another mode than ExactOrder.
+func IsSubsetWithOptions(expected, actual interface{}, options SubsetOptions) error {
+	return isSubset(expected, actual, "", options)
+}
+
+func isSubset(expected, actual interface{}, path string, options SubsetOptions) error {
+	if expectedString, ok := expected.(string); ok {
+		if matches := operatorRegex.FindStringSubmatch(expectedString); matches != nil {
+			return matchOperator(matches[1], matches[2], actual)
+		}
+	}
+
 	if reflect.TypeOf(expected) != reflect.TypeOf(actual) {
 		return &SubsetError{
 			message: fmt.Sprintf("type mismatch: %v != %v", reflect.TypeOf(expected), reflect.TypeOf(actual)),
@@ -48,17 +121,7 @@ func IsSubset(expected, actual interface{}) error {
 	}
 
 	if reflect.TypeOf(expected).Kind() == reflect.Slice {
-		if reflect.ValueOf(expected).Len() != reflect.ValueOf(actual).Len() {
-			return &SubsetError{
-				message: fmt.Sprintf("slice length mismatch: %d != %d", reflect.ValueOf(expected).Len(), reflect.ValueOf(actual).Len()),
-			}
-		}
-
-		for i := 0; i < reflect.ValueOf(expected).Len(); i++ {
-			if err := IsSubset(reflect.ValueOf(expected).Index(i).Interface(), reflect.ValueOf(actual).Index(i).Interface()); err != nil {
-				return err
-			}
-		}
+		return isSubsetSlice(reflect.ValueOf(expected), reflect.ValueOf(actual), path, options)
 	} else if reflect.TypeOf(expected).Kind() == reflect.Map {
 		iter := reflect.ValueOf(expected).MapRange()
 
@@ -72,7 +135,12 @@ func IsSubset(expected, actual interface{}) error {
 				}
 			}
 
-			if err := IsSubset(iter.Value().Interface(), actualValue.Interface()); err != nil {
+			keyPath := iter.Key().String()
+			if path != "" {
+				keyPath = path + "." + keyPath
+			}
+
+			if err := isSubset(iter.Value().Interface(), actualValue.Interface(), keyPath, options); err != nil {
 				subsetErr, ok := err.(*SubsetError)
 				if ok {
 					subsetErr.AppendPath(iter.Key().String())
@@ -89,3 +157,180 @@ func IsSubset(expected, actual interface{}) error {
 
 	return nil
 }
+
+// isSubsetSlice matches an expected list against an actual list in the array matching mode of the path.
+func isSubsetSlice(expected, actual reflect.Value, path string, options SubsetOptions) error {
+	mode := options.ArrayMatching[path]
+	if mode == "" {
+		mode = ExactOrder
+	}
+
+	// matches returns if the expected element i matches the actual element j
+	matches := func(i, j int) bool {
+		return isSubset(expected.Index(i).Interface(), actual.Index(j).Interface(), path, options) == nil
+	}
+
+	switch mode {
+	case ExactOrder:
+		if expected.Len() != actual.Len() {
+			return &SubsetError{
+				message: fmt.Sprintf("slice length mismatch: %d != %d", expected.Len(), actual.Len()),
+			}
+		}
+
+		for i := 0; i < expected.Len(); i++ {
+			if err := isSubset(expected.Index(i).Interface(), actual.Index(i).Interface(), path, options); err != nil {
+				return err
+			}
+		}
+	case SetEquality:
+		if expected.Len() != actual.Len() {
+			return &SubsetError{
+				message: fmt.Sprintf("slice length mismatch (%s): %d != %d", mode, expected.Len(), actual.Len()),
+			}
+		}
+
+		if !matchDistinct(expected.Len(), actual.Len(), 0, map[int]bool{}, matches) {
+			return &SubsetError{
+				message: fmt.Sprintf("the elements do not match in any order (%s)", mode),
+			}
+		}
+	case AnyElementMatches:
+		for i := 0; i < expected.Len(); i++ {
+			if !matchesAny(actual.Len(), func(j int) bool { return matches(i, j) }) {
+				return &SubsetError{
+					message: fmt.Sprintf("expected element %d does not match any actual element (%s)", i, mode),
+				}
+			}
+		}
+	case AllElementsMatch:
+		if actual.Len() == 0 {
+			return &SubsetError{
+				message: fmt.Sprintf("the actual slice is empty (%s)", mode),
+			}
+		}
+
+		for j := 0; j < actual.Len(); j++ {
+			if !matchesAny(expected.Len(), func(i int) bool { return matches(i, j) }) {
+				return &SubsetError{
+					message: fmt.Sprintf("actual element %d does not match any expected element (%s)", j, mode),
+				}
+			}
+		}
+	default:
+		return &SubsetError{
+			message: fmt.Sprintf("unknown array matching mode %q", mode),
+		}
+	}
+
+	return nil
+}
+
+// matchesAny returns true if match returns true for any index lower than n.
+func matchesAny(n int, match func(int) bool) bool {
+	for i := 0; i < n; i++ {
+		if match(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchDistinct returns true if every expected element from index i on matches a distinct actual element
+// which is not used yet.
+func matchDistinct(expectedLen, actualLen, i int, used map[int]bool, matches func(i, j int) bool) bool {
+	if i == expectedLen {
+		return true
+	}
+
+	for j := 0; j < actualLen; j++ {
+		if used[j] || !matches(i, j) {
+			continue
+		}
+
+		used[j] = true
+		if matchDistinct(expectedLen, actualLen, i+1, used, matches) {
+			return true
+		}
+		used[j] = false
+	}
+
+	return false
+}
+
+// matchOperator evaluates an inline operator with its argument against an actual value.
+func matchOperator(operator, argument string, actual interface{}) error {
+	if compare, ok := comparisons[operator]; ok {
+		expectedQuantity, err := resource.ParseQuantity(argument)
+		if err != nil {
+			return &SubsetError{message: fmt.Sprintf("invalid argument of kuttl.%s: %q is not a quantity", operator, argument)}
+		}
+
+		actualQuantity, err := toQuantity(actual)
+		if err != nil {
+			return &SubsetError{message: fmt.Sprintf("kuttl.%s(%s): %v", operator, argument, err)}
+		}
+
+		if !compare(actualQuantity.Cmp(expectedQuantity)) {
+			return &SubsetError{message: fmt.Sprintf("value mismatch, expected: kuttl.%s(%s) != actual: %v", operator, argument, actual)}
+		}
+		return nil
+	}
+
+	if operator == "regexp" {
+		re, err := regexp.Compile(argument)
+		if err != nil {
+			return &SubsetError{message: fmt.Sprintf("invalid argument of kuttl.regexp: %v", err)}
+		}
+
+		actualString, ok := actual.(string)
+		if !ok {
+			return &SubsetError{message: fmt.Sprintf("kuttl.regexp(%s): actual value %v is not a string", argument, actual)}
+		}
+
+		if !re.MatchString(actualString) {
+			return &SubsetError{message: fmt.Sprintf("value mismatch, expected: kuttl.regexp(%s) != actual: %s", argument, actualString)}
+		}
+		return nil
+	}
+
+	return &SubsetError{message: fmt.Sprintf("unknown operator kuttl.%s", operator)}
+}
+
+// toQuantity converts an actual value of an unstructured object to a quantity.
+func toQuantity(actual interface{}) (resource.Quantity, error) {
+	switch value := actual.(type) {
+	case string:
+		return resource.ParseQuantity(value)
+	case int64, int32, int, float64:
+		return resource.ParseQuantity(fmt.Sprint(value))
+	default:
+		return resource.Quantity{}, fmt.Errorf("actual value %v is not a quantity", actual)
+	}
+}
+
+// RemoveFields removes the fields of an unstructured object at the given paths.
+// A path is a dot separated list of keys like `metadata.resourceVersion`, lists are traversed
+// with `[*]` or implicitly, e.g. `status.conditions[*].lastTransitionTime`.
+func RemoveFields(obj map[string]interface{}, paths []string) {
+	for _, path := range paths {
+		path = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(path, "{"), "."), "}")
+		path = strings.ReplaceAll(path, "[*]", "")
+		removeField(obj, strings.Split(path, "."))
+	}
+}
+
+func removeField(value interface{}, keys []string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(keys) == 1 {
+			delete(v, keys[0])
+			return
+		}
+		removeField(v[keys[0]], keys[1:])
+	case []interface{}:
+		for _, item := range v {
+			removeField(item, keys)
+		}
+	}
+}
@@ -124,3 +124,126 @@ func TestIsSubset(t *testing.T) {
 		},
 	}))
 }
+
+func TestIsSubsetComparisons(t *testing.T) {
+	for _, test := range []struct {
+		expected    string
+		actual      interface{}
+		shouldError bool
+	}{
+		{expected: "kuttl.gte(2)", actual: int64(2)},
+		{expected: "kuttl.gte(2)", actual: int64(1), shouldError: true},
+		{expected: "kuttl.gt(2)", actual: int64(2), shouldError: true},
+		{expected: "kuttl.gt(2)", actual: float64(2.5)},
+		{expected: "kuttl.lt(10Gi)", actual: "5Gi"},
+		{expected: "kuttl.lte(10Gi)", actual: "10240Mi"},
+		{expected: "kuttl.lt(10Gi)", actual: "20Gi", shouldError: true},
+		{expected: "kuttl.gte(500m)", actual: "1"},
+		{expected: "kuttl.gte(2)", actual: "ready", shouldError: true},
+		{expected: "kuttl.gte(two)", actual: int64(2), shouldError: true},
+		{expected: "kuttl.unknown(2)", actual: int64(2), shouldError: true},
+	} {
+		err := IsSubset(map[string]interface{}{"value": test.expected}, map[string]interface{}{"value": test.actual})
+		if test.shouldError {
+			assert.Error(t, err, test.expected)
+		} else {
+			assert.NoError(t, err, test.expected)
+		}
+	}
+}
+
+func TestIsSubsetRegexp(t *testing.T) {
+	for _, test := range []struct {
+		expected    string
+		actual      interface{}
+		shouldError bool
+	}{
+		{expected: `kuttl.regexp(^nginx:1\.7\..*$)`, actual: "nginx:1.7.9"},
+		{expected: `kuttl.regexp(^nginx:1\.7\..*$)`, actual: "nginx:1.8.0", shouldError: true},
+		{expected: `kuttl.regexp(hello-[a-z0-9]{5})`, actual: "my-hello-x7k2p"},
+		{expected: `kuttl.regexp(^\d{4}-\d{2}-\d{2}T)`, actual: "2020-08-01T10:00:00Z"},
+		{expected: `kuttl.regexp(2)`, actual: int64(2), shouldError: true},
+		{expected: `kuttl.regexp(()`, actual: "(", shouldError: true},
+	} {
+		err := IsSubset(map[string]interface{}{"value": test.expected}, map[string]interface{}{"value": test.actual})
+		if test.shouldError {
+			assert.Error(t, err, test.expected)
+		} else {
+			assert.NoError(t, err, test.expected)
+		}
+	}
+}
+
+func TestRemoveFields(t *testing.T) {
+	obj := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":            "hello",
+			"resourceVersion": "42",
+		},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "lastTransitionTime": "2020-08-01T10:00:00Z"},
+				map[string]interface{}{"type": "Synced", "lastTransitionTime": "2020-08-01T10:00:01Z"},
+			},
+		},
+	}
+
+	RemoveFields(obj, []string{"metadata.resourceVersion", "{.status.conditions[*].lastTransitionTime}", "spec.missing", "metadata.name.invalid"})
+
+	assert.Equal(t, map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name": "hello",
+		},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready"},
+				map[string]interface{}{"type": "Synced"},
+			},
+		},
+	}, obj)
+}
+
+func TestIsSubsetArrayMatching(t *testing.T) {
+	ready := map[string]interface{}{"type": "Ready", "status": "True"}
+	synced := map[string]interface{}{"type": "Synced", "status": "True"}
+	failed := map[string]interface{}{"type": "Failed", "status": "False"}
+	anyTrue := map[string]interface{}{"status": "True"}
+
+	for _, test := range []struct {
+		name        string
+		mode        ArrayMatching
+		expected    []interface{}
+		actual      []interface{}
+		shouldError bool
+	}{
+		{name: "exact order", mode: ExactOrder, expected: []interface{}{ready, synced}, actual: []interface{}{ready, synced}},
+		{name: "exact order mismatch", mode: ExactOrder, expected: []interface{}{synced, ready}, actual: []interface{}{ready, synced}, shouldError: true},
+		{name: "default is exact order", expected: []interface{}{synced, ready}, actual: []interface{}{ready, synced}, shouldError: true},
+		{name: "set equality", mode: SetEquality, expected: []interface{}{synced, ready}, actual: []interface{}{ready, synced}},
+		{name: "set equality length mismatch", mode: SetEquality, expected: []interface{}{ready}, actual: []interface{}{ready, synced}, shouldError: true},
+		{name: "set equality distinct elements", mode: SetEquality, expected: []interface{}{anyTrue, ready}, actual: []interface{}{ready, failed}, shouldError: true},
+		{name: "set equality backtracking", mode: SetEquality, expected: []interface{}{anyTrue, ready}, actual: []interface{}{ready, synced}},
+		{name: "any element matches", mode: AnyElementMatches, expected: []interface{}{synced}, actual: []interface{}{ready, failed, synced}},
+		{name: "any element matches missing", mode: AnyElementMatches, expected: []interface{}{synced}, actual: []interface{}{ready, failed}, shouldError: true},
+		{name: "all elements match", mode: AllElementsMatch, expected: []interface{}{anyTrue}, actual: []interface{}{ready, synced}},
+		{name: "all elements match mismatch", mode: AllElementsMatch, expected: []interface{}{anyTrue}, actual: []interface{}{ready, failed}, shouldError: true},
+		{name: "all elements match empty", mode: AllElementsMatch, expected: []interface{}{anyTrue}, actual: []interface{}{}, shouldError: true},
+		{name: "unknown mode", mode: "someElements", expected: []interface{}{ready}, actual: []interface{}{synced}, shouldError: true},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			options := SubsetOptions{ArrayMatching: map[string]ArrayMatching{"status.conditions": test.mode}}
+			err := IsSubsetWithOptions(
+				map[string]interface{}{"status": map[string]interface{}{"conditions": test.expected}},
+				map[string]interface{}{"status": map[string]interface{}{"conditions": test.actual}},
+				options,
+			)
+			if test.shouldError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
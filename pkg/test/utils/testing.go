@@ -2,6 +2,8 @@ package utils
 
 import (
 	"flag"
+	"reflect"
+	"time"
 	"fmt"
 	"io"
 	"os"
@@ -44,7 +46,7 @@ func RunTests(testName string, testToRun string, parallelism int, testFunc func(
 			Name: testName,
 			F:    testFunc,
 		},
-	}, nil, nil).Run())
+	}, nil, nil, nil).Run())
 }
 
 // testDeps implements the testDeps interface for MainStart.
@@ -87,3 +89,42 @@ func (testDeps) StartTestLog(w io.Writer) {}
 func (testDeps) StopTestLog() error {
 	return nil
 }
+
+func (testDeps) SetPanicOnExit0(bool) {}
+func (testDeps) CoordinateFuzzing(time.Duration, int64, time.Duration, int64, int, []struct {
+	Parent     string
+	Path       string
+	Data       []byte
+	Values     []interface{}
+	Generation int
+	IsSeed     bool
+}, []reflect.Type, string, string) error {
+	return nil
+}
+func (testDeps) RunFuzzWorker(func(struct {
+	Parent     string
+	Path       string
+	Data       []byte
+	Values     []interface{}
+	Generation int
+	IsSeed     bool
+}) error) error {
+	return nil
+}
+func (testDeps) ReadCorpus(string, []reflect.Type) ([]struct {
+	Parent     string
+	Path       string
+	Data       []byte
+	Values     []interface{}
+	Generation int
+	IsSeed     bool
+}, error) {
+	return nil, nil
+}
+func (testDeps) CheckCorpus([]interface{}, []reflect.Type) error { return nil }
+func (testDeps) ResetCoverage()                                 {}
+func (testDeps) SnapshotCoverage()                              {}
+func (testDeps) InitRuntimeCoverage() (string, func(string, string) (string, error), func() float64) {
+	return "", nil, nil
+}
+func (testDeps) ModulePath() string { return "" }
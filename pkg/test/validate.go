@@ -0,0 +1,139 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// Validate validates the objects of the step with a server-side dry run of their creation or update.
+// Besides the objects rejected by the server, the objects with fields which are pruned by the server
+// because they are unknown to the schema of their kind are reported.
+func (s *Step) Validate(ctx context.Context, cl client.Client, dClient discovery.DiscoveryInterface, namespace string) []error {
+	return s.validateObjects(ctx, cl, dClient, s.Apply, namespace)
+}
+
+// validateObjects validates objects of the step, see Validate.
+func (s *Step) validateObjects(ctx context.Context, cl client.Client, dClient discovery.DiscoveryInterface, objs []runtime.Object, namespace string) []error {
+	errors := []error{}
+
+	for _, obj := range objs {
+		objClient, objDClient, err := s.objectClients(cl, dClient, obj)
+		if err != nil {
+			errors = append(errors, err)
+			continue
+		}
+
+		if _, _, err := testutils.Namespaced(objDClient, obj, namespace); err != nil {
+			errors = append(errors, err)
+			continue
+		}
+
+		if err := validateObject(ctx, objClient, obj); err != nil {
+			errors = append(errors, fmt.Errorf("invalid %s: %w", testutils.ResourceID(obj), err))
+		}
+	}
+
+	return errors
+}
+
+// validateObject creates or updates an object with a dry run and checks that none of its fields were pruned.
+func validateObject(ctx context.Context, cl client.Client, obj runtime.Object) error {
+	expected := obj.DeepCopyObject()
+	actual := obj.DeepCopyObject()
+
+	err := cl.Get(ctx, testutils.ObjectKey(actual), actual)
+	switch {
+	case k8serrors.IsNotFound(err):
+		actual = expected.DeepCopyObject()
+		err = cl.Create(ctx, actual, client.DryRunAll)
+	case err == nil:
+		if err = testutils.PatchObject(actual, expected); err != nil {
+			return err
+		}
+		var patch []byte
+		if patch, err = json.Marshal(expected); err != nil {
+			return err
+		}
+		err = cl.Patch(ctx, actual, client.RawPatch(types.MergePatchType, patch), client.DryRunAll)
+	}
+	if err != nil {
+		return err
+	}
+
+	expectedContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(expected.DeepCopyObject())
+	if err != nil {
+		return err
+	}
+	actualContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(actual)
+	if err != nil {
+		return err
+	}
+
+	if pruned := prunedFields(expectedContent, actualContent, ""); len(pruned) > 0 {
+		sort.Strings(pruned)
+		return fmt.Errorf("unknown fields %s", strings.Join(pruned, ", "))
+	}
+	return nil
+}
+
+// prunedFields returns the paths of the fields of the expected object which are missing in the actual object.
+// Values which differ are not reported, they can be changed by defaulting or admission.
+func prunedFields(expected, actual interface{}, path string) []string {
+	pruned := []string{}
+
+	switch expectedValue := expected.(type) {
+	case map[string]interface{}:
+		actualMap, ok := actual.(map[string]interface{})
+		if !ok {
+			return pruned
+		}
+		for key, value := range expectedValue {
+			keyPath := key
+			if path != "" {
+				keyPath = path + "." + key
+			}
+
+			actualValue, ok := actualMap[key]
+			if !ok {
+				// empty values are dropped when the object is serialized, e.g. `annotations: {}`
+				if value != nil && !isEmptyValue(value) {
+					pruned = append(pruned, keyPath)
+				}
+				continue
+			}
+			pruned = append(pruned, prunedFields(value, actualValue, keyPath)...)
+		}
+	case []interface{}:
+		actualSlice, ok := actual.([]interface{})
+		if !ok || len(actualSlice) != len(expectedValue) {
+			return pruned
+		}
+		for index, value := range expectedValue {
+			pruned = append(pruned, prunedFields(value, actualSlice[index], fmt.Sprintf("%s[%d]", path, index))...)
+		}
+	}
+
+	return pruned
+}
+
+// isEmptyValue checks if a value is an empty map or list.
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
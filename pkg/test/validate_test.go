@@ -0,0 +1,71 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func TestPrunedFields(t *testing.T) {
+	expected := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "hello", "annotations": map[string]interface{}{}},
+		"spec": map[string]interface{}{
+			"replcas": int64(3),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "nginx", "imagePullPolicyy": "Always"},
+					},
+				},
+			},
+		},
+	}
+	actual := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "hello", "uid": "1234"},
+		"spec": map[string]interface{}{
+			"replicas": int64(1),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "nginx", "imagePullPolicy": "IfNotPresent"},
+					},
+				},
+			},
+		},
+	}
+
+	assert.ElementsMatch(t, []string{"spec.replcas", "spec.template.spec.containers[0].imagePullPolicyy"}, prunedFields(expected, actual, ""))
+	assert.Empty(t, prunedFields(actual, actual, ""))
+}
+
+func TestValidateObject(t *testing.T) {
+	existing := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: testNamespace},
+		Spec:       corev1.ServiceSpec{ClusterIP: "10.0.0.1"},
+	}
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme, existing)
+
+	for _, obj := range []runtime.Object{
+		testutils.WithSpec(t, testutils.NewPod("new", testNamespace), map[string]interface{}{
+			"containers": []interface{}{map[string]interface{}{"name": "nginx", "image": "nginx"}},
+		}),
+		testutils.WithSpec(t, testutils.NewResource("v1", "Service", "existing", testNamespace), map[string]interface{}{
+			"clusterIP": "10.0.0.1",
+		}),
+	} {
+		assert.NoError(t, validateObject(context.TODO(), cl, obj))
+	}
+
+	// the dry run does not create objects
+	err := cl.Get(context.TODO(), testutils.ObjectKey(testutils.NewPod("new", testNamespace)), testutils.NewPod("new", testNamespace))
+	assert.True(t, k8serrors.IsNotFound(err))
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	"github.com/kudobuilder/kuttl/pkg/file"
+	"github.com/kudobuilder/kuttl/pkg/http"
+)
+
+// envtestAssetsURL is the URL of the archive of the kube-apiserver, etcd and kubectl binaries of a Kubernetes
+// version, operating system and architecture.
+var envtestAssetsURL = "https://storage.googleapis.com/kubebuilder-tools/kubebuilder-tools-%s-%s-%s.tar.gz"
+
+// kubernetesVersionEnv is the environment variable of the Kubernetes version of the commands of the provision.
+const kubernetesVersionEnv = "KUTTL_KUBERNETES_VERSION"
+
+// RunKubernetesVersions runs the test suite against each of its KubernetesVersions one after the other, in a
+// subtest for each version.
+func (h *Harness) RunKubernetesVersions() {
+	if !h.TestSuite.StartControlPlane && !h.TestSuite.StartKIND && h.TestSuite.Provision == nil {
+		h.T.Fatal(errors.New("kubernetes versions require a cluster which is started or provisioned by kuttl"))
+	}
+
+	for _, version := range h.TestSuite.KubernetesVersions {
+		version := strings.TrimPrefix(version, "v")
+		h.T.Run(version, func(t *testing.T) {
+			versionHarness := Harness{
+				TestSuite:         versionTestSuite(h.TestSuite, version),
+				T:                 t,
+				logger:            h.logger,
+				kubernetesVersion: version,
+			}
+			versionHarness.Run()
+		})
+	}
+}
+
+// versionTestSuite returns the test suite of a Kubernetes version, its report is named after the version.
+func versionTestSuite(suite harness.TestSuite, version string) harness.TestSuite {
+	versionSuite := *suite.DeepCopy()
+	versionSuite.KubernetesVersions = nil
+
+	if versionSuite.ReportName == "" {
+		versionSuite.ReportName = "kuttl-test"
+	}
+	versionSuite.ReportName = fmt.Sprintf("%s-%s", versionSuite.ReportName, version)
+
+	if versionSuite.StartKIND {
+		if versionSuite.KINDCluster == nil {
+			versionSuite.KINDCluster = &harness.KINDCluster{}
+		}
+		versionSuite.KINDCluster.NodeImage = "kindest/node:v" + version
+	}
+
+	return versionSuite
+}
+
+// envtestAssets returns the directory of the kube-apiserver and etcd binaries of a Kubernetes version. They are
+// downloaded to the user cache directory on first use.
+func envtestAssets(version string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(cacheDir, "kuttl", "envtest", fmt.Sprintf("%s-%s-%s", version, runtime.GOOS, runtime.GOARCH))
+	assets := filepath.Join(dir, "kubebuilder", "bin")
+	if _, err := os.Stat(filepath.Join(assets, "kube-apiserver")); err == nil {
+		return assets, nil
+	}
+
+	url := fmt.Sprintf(envtestAssetsURL, version, runtime.GOOS, runtime.GOARCH)
+	resp, err := http.NewClient().Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("failed to fetch %s : %s", url, resp.Status)
+	}
+
+	// the archive is extracted to a temporary directory which is renamed, so an interrupted download is not used
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return "", err
+	}
+	tmp, err := ioutil.TempDir(filepath.Dir(dir), "download")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := file.UnTar(tmp, resp.Body, true); err != nil {
+		return "", fmt.Errorf("extracting %s: %w", url, err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return "", err
+	}
+
+	return assets, nil
+}
@@ -0,0 +1,98 @@
+package test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+)
+
+func TestVersionTestSuite(t *testing.T) {
+	suite := harness.TestSuite{
+		StartKIND:          true,
+		KubernetesVersions: []string{"1.17.5", "1.18.2"},
+		KINDCluster:        &harness.KINDCluster{Workers: 1},
+	}
+
+	versionSuite := versionTestSuite(suite, "1.18.2")
+	assert.Nil(t, versionSuite.KubernetesVersions)
+	assert.Equal(t, "kuttl-test-1.18.2", versionSuite.ReportName)
+	assert.Equal(t, "kindest/node:v1.18.2", versionSuite.KINDCluster.NodeImage)
+	assert.Equal(t, 1, versionSuite.KINDCluster.Workers)
+	// the test suite is not modified
+	assert.Equal(t, "", suite.KINDCluster.NodeImage)
+
+	suite = harness.TestSuite{StartControlPlane: true, ReportName: "e2e"}
+	versionSuite = versionTestSuite(suite, "1.17.5")
+	assert.Equal(t, "e2e-1.17.5", versionSuite.ReportName)
+	assert.Nil(t, versionSuite.KINDCluster)
+}
+
+func TestEnvtestAssets(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "kuttl-cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(cacheDir)
+	defer os.Setenv("XDG_CACHE_HOME", os.Getenv("XDG_CACHE_HOME"))
+	require.NoError(t, os.Setenv("XDG_CACHE_HOME", cacheDir))
+
+	archive := &bytes.Buffer{}
+	gz := gzip.NewWriter(archive)
+	tw := tar.NewWriter(gz)
+	for _, dir := range []string{"kubebuilder/", "kubebuilder/bin/"} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: dir, Typeflag: tar.TypeDir, Mode: 0755}))
+	}
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "kubebuilder/bin/kube-apiserver", Typeflag: tar.TypeReg, Mode: 0755, Size: 4}))
+	_, err = tw.Write([]byte("test"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/1.18.2" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(archive.Bytes())
+	}))
+	defer server.Close()
+
+	defer func(url string) { envtestAssetsURL = url }(envtestAssetsURL)
+	envtestAssetsURL = server.URL + "/%s?os=%s&arch=%s"
+
+	// the binaries are downloaded once
+	for i := 0; i < 2; i++ {
+		assets, err := envtestAssets("1.18.2")
+		require.NoError(t, err)
+		contents, err := ioutil.ReadFile(filepath.Join(assets, "kube-apiserver"))
+		require.NoError(t, err)
+		assert.Equal(t, "test", string(contents))
+	}
+	assert.Equal(t, 1, requests)
+
+	_, err = envtestAssets("1.0.0")
+	assert.Error(t, err)
+}
+
+func TestMinikubeKubernetesVersion(t *testing.T) {
+	commands := []string{}
+	m := &minikube{name: "e2e", kubeconfig: "/work/kubeconfig", kubernetesVersion: "1.18.2", run: recordCommands(&commands, "")}
+
+	require.NoError(t, m.Start())
+	assert.Equal(t, []string{"KUBECONFIG=/work/kubeconfig minikube start --profile e2e --wait all --kubernetes-version v1.18.2"}, commands)
+
+	h := Harness{T: t, TestSuite: harness.TestSuite{ClusterProvider: "k3d"}, kubernetesVersion: "1.18.2"}
+	_, err := h.newClusterProvider()
+	assert.EqualError(t, err, "kubernetes versions are not supported by k3d")
+}
@@ -0,0 +1,217 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+)
+
+// waitForCheck checks a condition of TestStep.WaitFor, it returns why the condition is not met or an empty
+// string if it is.
+type waitForCheck func(ctx context.Context, cl client.Client) (string, error)
+
+// WaitFor waits for the conditions of the TestStep.WaitFor list in order. If a condition is not met within its
+// timeout, the following conditions are skipped. Waiting stops if the context is done.
+func (s *Step) WaitFor(ctx context.Context, namespace string) []error {
+	if s.Step == nil || len(s.Step.WaitFor) == 0 {
+		return nil
+	}
+
+	cl, err := s.Client(false)
+	if err != nil {
+		return []error{err}
+	}
+
+	for _, waitFor := range s.Step.WaitFor {
+		if err := s.waitFor(ctx, cl, waitFor, namespace); err != nil {
+			return []error{err}
+		}
+	}
+
+	// the objects of the step may be of the resources of the CRDs and API services waited for
+	dClient, err := s.DiscoveryClient()
+	if err != nil {
+		return []error{err}
+	}
+	if cached, ok := dClient.(discovery.CachedDiscoveryInterface); ok {
+		cached.Invalidate()
+	}
+
+	return nil
+}
+
+func (s *Step) waitFor(ctx context.Context, cl client.Client, waitFor harness.WaitFor, namespace string) error {
+	description, check, err := waitForCondition(waitFor, namespace)
+	if err != nil {
+		return err
+	}
+
+	timeout := s.GetTimeout()
+	if waitFor.Timeout > 0 {
+		timeout = waitFor.Timeout
+	}
+
+	s.Logger.Logf("waiting for %s", description)
+
+	reason := ""
+	waitCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+	err = wait.PollImmediateUntil(time.Second, func() (bool, error) {
+		var err error
+		reason, err = check(ctx, cl)
+		return reason == "", err
+	}, waitCtx.Done())
+
+	if err == wait.ErrWaitTimeout && ctx.Err() != nil {
+		return fmt.Errorf("waiting for %s: %w", description, ctx.Err())
+	}
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("%s is not ready within %d sec timeout: %s", description, timeout, reason)
+	}
+	if err != nil {
+		return fmt.Errorf("waiting for %s: %w", description, err)
+	}
+
+	s.Logger.Logf("%s is ready", description)
+	return nil
+}
+
+// waitForCondition returns the description and the check of a condition of TestStep.WaitFor.
+func waitForCondition(waitFor harness.WaitFor, namespace string) (string, waitForCheck, error) {
+	set := 0
+	for _, name := range []string{waitFor.CRD, waitFor.APIService, waitFor.Deployment, waitFor.Webhook, waitFor.Rollout} {
+		if name != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return "", nil, errors.New("waitFor requires exactly one of crd, apiService, deployment, webhook and rollout")
+	}
+
+	if waitFor.Namespace != "" {
+		namespace = waitFor.Namespace
+	}
+
+	switch {
+	case waitFor.CRD != "":
+		return "CustomResourceDefinition " + waitFor.CRD, func(ctx context.Context, cl client.Client) (string, error) {
+			return conditionReason(ctx, cl, "apiextensions.k8s.io/v1", "CustomResourceDefinition", types.NamespacedName{Name: waitFor.CRD}, "Established")
+		}, nil
+	case waitFor.APIService != "":
+		return "APIService " + waitFor.APIService, func(ctx context.Context, cl client.Client) (string, error) {
+			return conditionReason(ctx, cl, "apiregistration.k8s.io/v1", "APIService", types.NamespacedName{Name: waitFor.APIService}, "Available")
+		}, nil
+	case waitFor.Deployment != "":
+		key := types.NamespacedName{Namespace: namespace, Name: waitFor.Deployment}
+		return "Deployment " + key.String(), func(ctx context.Context, cl client.Client) (string, error) {
+			return deploymentReason(ctx, cl, key)
+		}, nil
+	case waitFor.Rollout != "":
+		return rolloutCondition(waitFor.Rollout, namespace)
+	default:
+		return "webhook " + waitFor.Webhook, func(ctx context.Context, cl client.Client) (string, error) {
+			return webhookReason(ctx, cl, waitFor.Webhook)
+		}, nil
+	}
+}
+
+// getWaitForObject gets an object of a condition, it returns false if the object does not exist.
+func getWaitForObject(ctx context.Context, cl client.Client, apiVersion, kind string, key types.NamespacedName) (*unstructured.Unstructured, bool, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+
+	if err := cl.Get(ctx, key, obj); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return obj, true, nil
+}
+
+// conditionReason checks if a condition of the status of an object is true.
+func conditionReason(ctx context.Context, cl client.Client, apiVersion, kind string, key types.NamespacedName, conditionType string) (string, error) {
+	obj, found, err := getWaitForObject(ctx, cl, apiVersion, kind, key)
+	if err != nil || !found {
+		return "not found", err
+	}
+
+	if !conditionTrue(obj, conditionType) {
+		return fmt.Sprintf("condition %s is not true", conditionType), nil
+	}
+	return "", nil
+}
+
+// deploymentReason checks if a deployment is available and its controller observed its latest generation.
+func deploymentReason(ctx context.Context, cl client.Client, key types.NamespacedName) (string, error) {
+	deployment, found, err := getWaitForObject(ctx, cl, "apps/v1", "Deployment", key)
+	if err != nil || !found {
+		return "not found", err
+	}
+
+	observed, _, _ := unstructured.NestedInt64(deployment.Object, "status", "observedGeneration")
+	if observed < deployment.GetGeneration() {
+		return "the latest generation is not observed", nil
+	}
+	if !conditionTrue(deployment, "Available") {
+		return "condition Available is not true", nil
+	}
+	return "", nil
+}
+
+// webhookReason checks if the services of the webhooks of a validating or mutating webhook configuration have
+// ready endpoints. Webhooks called by URL are assumed to be ready.
+func webhookReason(ctx context.Context, cl client.Client, name string) (string, error) {
+	config, found, err := getWaitForObject(ctx, cl, "admissionregistration.k8s.io/v1", "ValidatingWebhookConfiguration", types.NamespacedName{Name: name})
+	if err == nil && !found {
+		config, found, err = getWaitForObject(ctx, cl, "admissionregistration.k8s.io/v1", "MutatingWebhookConfiguration", types.NamespacedName{Name: name})
+	}
+	if err != nil || !found {
+		return "not found", err
+	}
+
+	webhooks, _, _ := unstructured.NestedSlice(config.Object, "webhooks")
+	for _, webhook := range webhooks {
+		w, ok := webhook.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		serviceName, found, _ := unstructured.NestedString(w, "clientConfig", "service", "name")
+		if !found {
+			continue
+		}
+		serviceNamespace, _, _ := unstructured.NestedString(w, "clientConfig", "service", "namespace")
+
+		key := types.NamespacedName{Namespace: serviceNamespace, Name: serviceName}
+		endpoints := &corev1.Endpoints{}
+		if err := cl.Get(ctx, key, endpoints); err != nil && !k8serrors.IsNotFound(err) {
+			return "", err
+		}
+		if !hasReadyAddress(endpoints) {
+			return fmt.Sprintf("service %s of webhook %s has no ready endpoints", key, w["name"]), nil
+		}
+	}
+	return "", nil
+}
+
+// hasReadyAddress checks if endpoints have a ready address.
+func hasReadyAddress(endpoints *corev1.Endpoints) bool {
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return true
+		}
+	}
+	return false
+}
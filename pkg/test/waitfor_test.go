@@ -0,0 +1,168 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+func loadObject(t *testing.T, manifest string) runtime.Object {
+	objs, err := testutils.LoadYAML("object.yaml", strings.NewReader(manifest))
+	require.NoError(t, err)
+	require.Len(t, objs, 1)
+	return objs[0]
+}
+
+func TestWaitFor(t *testing.T) {
+	crd := loadObject(t, `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.stable.example.com
+status:
+  conditions:
+  - type: Established
+    status: "True"
+`)
+	apiService := loadObject(t, `apiVersion: apiregistration.k8s.io/v1
+kind: APIService
+metadata:
+  name: v1beta1.metrics.k8s.io
+status:
+  conditions:
+  - type: Available
+    status: "False"
+`)
+	deployment := loadObject(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: operator
+  namespace: operators
+  generation: 2
+status:
+  observedGeneration: 2
+  conditions:
+  - type: Available
+    status: "True"
+`)
+	rollout := loadObject(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: operator
+  namespace: `+testNamespace+`
+  generation: 2
+status:
+  observedGeneration: 1
+  conditions:
+  - type: Available
+    status: "True"
+`)
+	webhook := loadObject(t, `apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: operator
+webhooks:
+- name: validate.example.com
+  clientConfig:
+    url: https://example.com/validate
+- name: operator.example.com
+  clientConfig:
+    service:
+      name: operator-webhook
+      namespace: operators
+      port: 443
+`)
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "operator-webhook", Namespace: "operators"},
+		Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}},
+	}
+	mutatingWebhook := loadObject(t, `apiVersion: admissionregistration.k8s.io/v1
+kind: MutatingWebhookConfiguration
+metadata:
+  name: defaults
+webhooks:
+- name: defaults.example.com
+  clientConfig:
+    service:
+      name: defaults-webhook
+      namespace: operators
+`)
+
+	for _, test := range []struct {
+		name    string
+		waitFor []harness.WaitFor
+		err     string
+	}{
+		{
+			name: "ready",
+			waitFor: []harness.WaitFor{
+				{CRD: "crontabs.stable.example.com"},
+				{Deployment: "operator", Namespace: "operators"},
+				{Webhook: "operator"},
+			},
+		},
+		{
+			name:    "condition is false",
+			waitFor: []harness.WaitFor{{APIService: "v1beta1.metrics.k8s.io", Timeout: 1}},
+			err:     "APIService v1beta1.metrics.k8s.io is not ready within 1 sec timeout: condition Available is not true",
+		},
+		{
+			name:    "not found",
+			waitFor: []harness.WaitFor{{CRD: "missing.stable.example.com", Timeout: 1}},
+			err:     "CustomResourceDefinition missing.stable.example.com is not ready within 1 sec timeout: not found",
+		},
+		{
+			name:    "rollout in progress",
+			waitFor: []harness.WaitFor{{Deployment: "operator", Timeout: 1}},
+			err:     "Deployment " + testNamespace + "/operator is not ready within 1 sec timeout: the latest generation is not observed",
+		},
+		{
+			name:    "webhook without endpoints",
+			waitFor: []harness.WaitFor{{Webhook: "defaults", Timeout: 1}},
+			err:     "webhook defaults is not ready within 1 sec timeout: service operators/defaults-webhook of webhook defaults.example.com has no ready endpoints",
+		},
+		{
+			name:    "invalid",
+			waitFor: []harness.WaitFor{{CRD: "crontabs.stable.example.com", Deployment: "operator"}},
+			err:     "waitFor requires exactly one of crd, apiService, deployment, webhook and rollout",
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			cl := fake.NewFakeClientWithScheme(scheme.Scheme, crd, apiService, deployment, rollout, webhook, endpoints, mutatingWebhook)
+
+			step := Step{
+				Name:    "install",
+				Index:   1,
+				Timeout: 10,
+				Logger:  testutils.NewTestLogger(t, ""),
+				Step: &harness.TestStep{
+					WaitFor: test.waitFor,
+				},
+				Client:          func(bool) (client.Client, error) { return cl, nil },
+				DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+			}
+
+			errs := step.WaitFor(context.TODO(), testNamespace)
+			if test.err == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			require.Len(t, errs, 1)
+			assert.EqualError(t, errs[0], test.err)
+		})
+	}
+}
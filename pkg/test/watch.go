@@ -0,0 +1,223 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// errorWatchInterval is the interval in which watches which failed to start are retried, e.g. of kinds which are not
+// installed yet.
+const errorWatchInterval = 250 * time.Millisecond
+
+// errResourceMatched is returned by CheckResourceAbsent if an error object matched.
+var errResourceMatched = errors.New("resource matched")
+
+// resourceWatcher is implemented by the clients which can watch objects, see testutils.RetryClient.
+type resourceWatcher interface {
+	WatchResources(ctx context.Context, gvk schema.GroupVersionKind, namespace, name, resourceVersion string) (watch.Interface, error)
+}
+
+// watchResources watches the objects of a kind in a namespace, or only the object with a name if it is set, and
+// passes their events to handle until stop is closed, the context is done or handle returns false. The watch starts
+// after resourceVersion, or with the current objects if it is empty. Watches which end are resumed after the last
+// event, watches which fail to start are retried every errorWatchInterval.
+func watchResources(ctx context.Context, cl client.Client, gvk schema.GroupVersionKind, namespace, name, resourceVersion string, stop <-chan struct{}, handle func(watch.Event) bool) error {
+	watcher, ok := cl.(resourceWatcher)
+	if !ok {
+		return fmt.Errorf("client %T can not watch objects", cl)
+	}
+
+	for {
+		w, err := watcher.WatchResources(ctx, gvk, namespace, name, resourceVersion)
+		if err != nil {
+			// the resource version may be too old to resume the watch from
+			resourceVersion = ""
+			select {
+			case <-stop:
+				return nil
+			case <-ctx.Done():
+				return nil
+			case <-time.After(errorWatchInterval):
+			}
+			continue
+		}
+
+		for open := true; open; {
+			select {
+			case <-stop:
+				w.Stop()
+				return nil
+			case <-ctx.Done():
+				w.Stop()
+				return nil
+			case event, ok := <-w.ResultChan():
+				switch {
+				case !ok:
+					open = false
+				case event.Type == watch.Error:
+					// e.g. the resource version is too old, the watch restarts with the current objects
+					resourceVersion = ""
+					w.Stop()
+					open = false
+				default:
+					if m, err := meta.Accessor(event.Object); err == nil {
+						resourceVersion = m.GetResourceVersion()
+					}
+					if !handle(event) {
+						w.Stop()
+						return nil
+					}
+				}
+			}
+		}
+	}
+}
+
+// errorWatcher watches the error objects of a test step while the step runs and records the first match.
+// A nil errorWatcher never matches.
+type errorWatcher struct {
+	stop     chan struct{}
+	stopOnce sync.Once
+	// done is closed when the watcher stopped, err is the first match.
+	done chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+// watchErrors starts an errorWatcher for the error objects of the step if TestAssert.WatchErrors is set. Every
+// change of the objects matching an error object is compared, so states which only last for a moment are matched.
+func (s *Step) watchErrors(ctx context.Context, namespace string) *errorWatcher {
+	if s.Assert == nil || !s.Assert.WatchErrors || len(s.Errors) == 0 {
+		return nil
+	}
+
+	w := &errorWatcher{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	var wg sync.WaitGroup
+	for _, expected := range s.Errors {
+		expected := expected
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.watchErrorObject(ctx, namespace, expected, w); err != nil {
+				w.record(err)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(w.done)
+	}()
+
+	return w
+}
+
+// watchErrorObject watches the objects matching an error object until the watcher is stopped or one of them matched.
+func (s *Step) watchErrorObject(ctx context.Context, namespace string, expected runtime.Object, w *errorWatcher) error {
+	cl, err := s.Client(false)
+	if err != nil {
+		return err
+	}
+
+	dClient, err := s.DiscoveryClient()
+	if err != nil {
+		return err
+	}
+
+	cl, dClient, err = s.objectClients(cl, dClient, expected)
+	if err != nil {
+		return err
+	}
+
+	// the kind of the error object may not be installed yet
+	var name, objectNamespace string
+	for {
+		if name, objectNamespace, err = testutils.Namespaced(dClient, expected, namespace); err == nil {
+			break
+		}
+		select {
+		case <-w.stop:
+			return nil
+		case <-ctx.Done():
+			return nil
+		case <-time.After(errorWatchInterval):
+		}
+	}
+
+	return watchResources(ctx, cl, expected.GetObjectKind().GroupVersionKind(), objectNamespace, name, "", w.stop, func(event watch.Event) bool {
+		if event.Type != watch.Added && event.Type != watch.Modified {
+			return true
+		}
+
+		actual, ok := event.Object.(*unstructured.Unstructured)
+		if !ok {
+			return true
+		}
+
+		err := s.matchErrorObject(expected, []unstructured.Unstructured{*actual.DeepCopy()})
+		if !errors.Is(err, errResourceMatched) {
+			return !w.matched()
+		}
+
+		s.Logger.Logf("error object matched while the step was running: %v", err)
+		w.record(err)
+		return false
+	})
+}
+
+// record records a match of an error object, only the first one is kept. The watches of the other error objects end
+// on their next event.
+func (w *errorWatcher) record(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// matched returns whether an error object matched.
+func (w *errorWatcher) matched() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err != nil
+}
+
+// Matched returns the first match of an error object without stopping the watcher.
+func (w *errorWatcher) Matched() error {
+	if w == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Stop stops the watcher and returns the first match of an error object.
+func (w *errorWatcher) Stop() error {
+	if w == nil {
+		return nil
+	}
+
+	w.stopOnce.Do(func() { close(w.stop) })
+	<-w.done
+	return w.Matched()
+}
@@ -0,0 +1,121 @@
+package test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	harness "github.com/kudobuilder/kuttl/pkg/apis/testharness/v1beta1"
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// fakeWatchClient is a fake client whose watches are fake watchers by the name of the watched object.
+type fakeWatchClient struct {
+	client.Client
+
+	mu       sync.Mutex
+	watchers map[string]*watch.FakeWatcher
+	started  chan string
+}
+
+func newFakeWatchClient(cl client.Client, names ...string) *fakeWatchClient {
+	c := &fakeWatchClient{
+		Client:   cl,
+		watchers: map[string]*watch.FakeWatcher{},
+		started:  make(chan string, len(names)),
+	}
+	for _, name := range names {
+		c.watchers[name] = watch.NewFake()
+	}
+	return c
+}
+
+func (c *fakeWatchClient) WatchResources(ctx context.Context, gvk schema.GroupVersionKind, namespace, name, resourceVersion string) (watch.Interface, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.started <- name
+	return c.watchers[name], nil
+}
+
+// send sends an event of an object to the watcher of its name, it returns once the event was received.
+func (c *fakeWatchClient) send(t *testing.T, eventType watch.EventType, obj runtime.Object) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	require.NoError(t, err)
+	actual := &unstructured.Unstructured{Object: u}
+
+	c.mu.Lock()
+	w := c.watchers[actual.GetName()]
+	c.mu.Unlock()
+	w.Action(eventType, actual)
+}
+
+// awaitWatches waits until the watches of a number of objects started.
+func (c *fakeWatchClient) awaitWatches(n int) {
+	for i := 0; i < n; i++ {
+		<-c.started
+	}
+}
+
+func TestStepWatchErrors(t *testing.T) {
+	cl := newFakeWatchClient(fake.NewFakeClientWithScheme(scheme.Scheme), "crashing")
+
+	step := Step{
+		Assert:          &harness.TestAssert{WatchErrors: true},
+		Errors:          []runtime.Object{testutils.WithStatus(t, testutils.NewPod("crashing", ""), map[string]interface{}{"phase": "Failed"})},
+		Logger:          testutils.NewTestLogger(t, ""),
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+	}
+
+	watcher := step.watchErrors(context.TODO(), testNamespace)
+	require.NotNil(t, watcher)
+	cl.awaitWatches(1)
+	assert.Nil(t, watcher.Matched())
+
+	// the error state only lasts for a moment
+	cl.send(t, watch.Added, testutils.NewPod("crashing", testNamespace))
+	cl.send(t, watch.Modified, testutils.WithStatus(t, testutils.NewPod("crashing", testNamespace), map[string]interface{}{"phase": "Failed"}))
+	assert.Error(t, watcher.Stop())
+	assert.Error(t, watcher.Matched())
+	assert.Equal(t, []error{}, step.Check(context.TODO(), testNamespace))
+
+	// the watcher is not started without the option
+	step.Assert.WatchErrors = false
+	watcher = step.watchErrors(context.TODO(), testNamespace)
+	assert.Nil(t, watcher)
+	assert.Nil(t, watcher.Matched())
+	assert.Nil(t, watcher.Stop())
+}
+
+func TestStepWatchErrorsNoMatch(t *testing.T) {
+	cl := newFakeWatchClient(fake.NewFakeClientWithScheme(scheme.Scheme), "crashing")
+
+	step := Step{
+		Assert:          &harness.TestAssert{WatchErrors: true},
+		Errors:          []runtime.Object{testutils.WithStatus(t, testutils.NewPod("crashing", ""), map[string]interface{}{"phase": "Failed"})},
+		Logger:          testutils.NewTestLogger(t, ""),
+		Client:          func(bool) (client.Client, error) { return cl, nil },
+		DiscoveryClient: func() (discovery.DiscoveryInterface, error) { return testutils.FakeDiscoveryClient(), nil },
+	}
+
+	watcher := step.watchErrors(context.TODO(), testNamespace)
+	cl.awaitWatches(1)
+	cl.send(t, watch.Added, testutils.WithStatus(t, testutils.NewPod("crashing", testNamespace), map[string]interface{}{"phase": "Running"}))
+	assert.Nil(t, watcher.Stop())
+
+	// clients which can not watch objects fail the step
+	step.Client = func(bool) (client.Client, error) { return fake.NewFakeClientWithScheme(scheme.Scheme), nil }
+	watcher = step.watchErrors(context.TODO(), testNamespace)
+	assert.Error(t, watcher.Stop())
+}
@@ -0,0 +1,133 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	testutils "github.com/kudobuilder/kuttl/pkg/test/utils"
+)
+
+// waveObjects returns the objects with the indexes which are a part of a wave by their wave annotation.
+func waveObjects(objs []runtime.Object, indexes []int) []runtime.Object {
+	wave := []runtime.Object{}
+	for _, i := range indexes {
+		if _, isWave, _ := applyOrder(objs[i]); isWave {
+			wave = append(wave, objs[i])
+		}
+	}
+	return wave
+}
+
+// waitForWave waits until the applied objects of a wave are ready, see waveReason. Waiting stops if the context is
+// done.
+func (s *Step) waitForWave(ctx context.Context, objs []runtime.Object) error {
+	suiteClient, err := s.Client(false)
+	if err != nil {
+		return err
+	}
+
+	dClient, err := s.DiscoveryClient()
+	if err != nil {
+		return err
+	}
+
+	order, _, _ := applyOrder(objs[0])
+	timeout := s.GetTimeout()
+	s.Logger.Logf("waiting for wave %d", order)
+
+	pending := append([]runtime.Object{}, objs...)
+	reason := ""
+	waitCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+	err = wait.PollImmediateUntil(time.Second, func() (bool, error) {
+		remaining := []runtime.Object{}
+		for _, obj := range pending {
+			// the objects of a wave may be applied to the clusters of their cluster annotations
+			cl, _, err := s.objectClients(suiteClient, dClient, obj)
+			if err != nil {
+				return false, err
+			}
+
+			objReason, err := waveReason(ctx, cl, obj)
+			if err != nil {
+				return false, fmt.Errorf("%s: %w", testutils.ResourceID(obj), err)
+			}
+			if objReason != "" {
+				if len(remaining) == 0 {
+					reason = fmt.Sprintf("%s: %s", testutils.ResourceID(obj), objReason)
+				}
+				remaining = append(remaining, obj)
+			}
+		}
+		pending = remaining
+		return len(pending) == 0, nil
+	}, waitCtx.Done())
+
+	if err == wait.ErrWaitTimeout && ctx.Err() != nil {
+		return fmt.Errorf("waiting for wave %d: %w", order, ctx.Err())
+	}
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("wave %d is not ready within %d sec timeout, %s", order, timeout, reason)
+	}
+	if err != nil {
+		return fmt.Errorf("waiting for wave %d: %w", order, err)
+	}
+
+	s.Logger.Logf("wave %d is ready", order)
+	return nil
+}
+
+// waveReason checks if an applied object of a wave is ready, it returns why it is not or an empty string if it is:
+// CRDs must be established, namespaces active and workloads rolled out. Objects of other kinds are ready once they
+// exist, unless they have a Ready condition which must be true.
+func waveReason(ctx context.Context, cl client.Client, obj runtime.Object) (string, error) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	key := testutils.ObjectKey(obj)
+
+	if kind := workloadKind(gvk.Kind); kind != "" && gvk.Group == "apps" {
+		return rolloutReason(ctx, cl, kind, key)
+	}
+
+	actual := &unstructured.Unstructured{}
+	actual.SetGroupVersionKind(gvk)
+	if err := cl.Get(ctx, key, actual); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return "not found", nil
+		}
+		return "", err
+	}
+
+	switch gvk.Kind {
+	case "CustomResourceDefinition":
+		if !conditionTrue(actual, "Established") {
+			return "condition Established is not true", nil
+		}
+	case "Namespace":
+		if phase, _, _ := unstructured.NestedString(actual.Object, "status", "phase"); phase != "Active" {
+			return fmt.Sprintf("phase is %q", phase), nil
+		}
+	default:
+		if hasCondition(actual, "Ready") && !conditionTrue(actual, "Ready") {
+			return "condition Ready is not true", nil
+		}
+	}
+	return "", nil
+}
+
+// hasCondition checks if the status of an object has a condition.
+func hasCondition(obj *unstructured.Unstructured, conditionType string) bool {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, condition := range conditions {
+		if c, ok := condition.(map[string]interface{}); ok && c["type"] == conditionType {
+			return true
+		}
+	}
+	return false
+}